@@ -0,0 +1,8 @@
+// Command sqlblaster tests MySQL and MariaDB credentials and works with the accounts it finds
+package main
+
+import "github.com/xmarkinmtlx/sqlblaster/internal/core"
+
+func main() {
+    core.Main()
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "strings"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// errMustChangePassword is returned for every statement but SET PASSWORD on an expired account
+const errMustChangePassword = 1820
+
+// accountInfo describes how usable a successfully authenticated account is
+type accountInfo struct {
+    Plugin             string
+    PasswordExpired    bool
+    AccountLocked      bool
+    MaxUserConnections int
+    SSLType            string
+}
+
+// accountQueries read the logged-in account's row from mysql.user, newest server first.
+// account_locked only exists from MySQL 5.7.6.
+var accountQueries = []string{
+    "SELECT plugin, password_expired, account_locked, max_user_connections, ssl_type FROM mysql.user WHERE CONCAT(User, '@', Host) = CURRENT_USER()",
+    "SELECT plugin, password_expired, 'N', max_user_connections, ssl_type FROM mysql.user WHERE CONCAT(User, '@', Host) = CURRENT_USER()",
+}
+
+// queryAccountInfo reads the account's metadata from mysql.user when readable. The plugin is
+// left empty when the metadata could not be read.
+func (e *Engine) queryAccountInfo(ctx context.Context, db *sql.DB) accountInfo {
+    var info accountInfo
+    for _, query := range accountQueries {
+        var plugin sql.NullString
+        var expired, locked, sslType string
+        err := db.QueryRowContext(ctx, query).Scan(&plugin, &expired, &locked, &info.MaxUserConnections, &sslType)
+        if err == nil {
+            info.Plugin = plugin.String
+            if info.Plugin == "" {
+                // Servers before 5.5 have no plugin column value for native accounts
+                info.Plugin = "mysql_native_password"
+            }
+            info.PasswordExpired = strings.EqualFold(expired, "Y")
+            info.AccountLocked = strings.EqualFold(locked, "Y")
+            info.SSLType = sslType
+            return info
+        }
+
+        // An expired password fails every query, which tells us all we can learn
+        var myErr *mysql.MySQLError
+        if errors.As(err, &myErr) && myErr.Number == errMustChangePassword {
+            info.PasswordExpired = true
+            return info
+        }
+        e.verbosePrintln("Account metadata not readable:", err)
+    }
+    return info
+}
+
+// restrictions lists what limits the account, empty for a fully usable one
+func (a accountInfo) restrictions() []string {
+    var r []string
+    if a.PasswordExpired {
+        r = append(r, "password expired")
+    }
+    if a.AccountLocked {
+        r = append(r, "account locked")
+    }
+    if a.SSLType != "" {
+        r = append(r, "requires SSL ("+a.SSLType+")")
+    }
+    if a.MaxUserConnections > 0 {
+        r = append(r, fmt.Sprintf("max %d connections", a.MaxUserConnections))
+    }
+    return r
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// errorWindow is the period the error rate is measured over
+const errorWindow = time.Minute
+
+// minErrorSample is the number of attempts in the window needed before the rate is trusted
+const minErrorSample = 20
+
+// AlertEvent is the data available to --on-error-threshold templates, e.g. {{.Reason}}
+type AlertEvent struct {
+    Host      string
+    Port      int
+    Reason    string
+    ErrorRate float64
+    Errors    int
+    Attempts  int
+    Time      string
+}
+
+// attemptEvent is one finished attempt in the error window
+type attemptEvent struct {
+    at    time.Time
+    isErr bool
+}
+
+// setupErrorHook parses the --on-error-threshold command
+func (e *Engine) setupErrorHook(cmdLine string) error {
+    if cmdLine == "" {
+        return nil
+    }
+    templates, err := parseHookCommand(cmdLine)
+    if err != nil {
+        return err
+    }
+    e.errorHook = templates
+    return nil
+}
+
+// isAuthFailure reports whether err is an ordinary rejected credential rather than an
+// operational problem such as a timeout or refused connection
+func isAuthFailure(err error) bool {
+    var myErr *mysql.MySQLError
+    if !errors.As(err, &myErr) {
+        return false
+    }
+    switch myErr.Number {
+    case 1044, 1045, 1698: // ER_DBACCESS_DENIED_ERROR, ER_ACCESS_DENIED_ERROR, ER_ACCESS_DENIED_NO_PASSWORD_ERROR
+        return true
+    case errAccountLocked, errAccountBlockedByLock, errMariaDBAccountLocked:
+        return true
+    }
+    return false
+}
+
+// noteAttemptResult records the outcome of an attempt (nil for success) and fires the
+// --on-error-threshold hook when the error rate over the last minute crosses --error-threshold
+func (e *Engine) noteAttemptResult(err error, log *os.File) {
+    if e.errorHook == nil {
+        return
+    }
+
+    now := time.Now()
+    e.errorMu.Lock()
+    e.errorEvents = append(e.errorEvents, attemptEvent{at: now, isErr: err != nil && !isAuthFailure(err)})
+    cut := 0
+    for cut < len(e.errorEvents) && now.Sub(e.errorEvents[cut].at) > errorWindow {
+        cut++
+    }
+    e.errorEvents = e.errorEvents[cut:]
+
+    errCount := 0
+    for _, ev := range e.errorEvents {
+        if ev.isErr {
+            errCount++
+        }
+    }
+    total := len(e.errorEvents)
+    rate := float64(errCount) / float64(total)
+
+    fire := false
+    if !e.errorAlerted && total >= minErrorSample && rate >= e.opts.ErrorThreshold {
+        e.errorAlerted = true
+        fire = true
+    } else if e.errorAlerted && rate < e.opts.ErrorThreshold/2 {
+        // Re-arm once the rate has clearly recovered
+        e.errorAlerted = false
+    }
+    e.errorMu.Unlock()
+
+    if fire {
+        e.fireErrorAlert(fmt.Sprintf("error rate %.0f%% over the last minute", rate*100), rate, errCount, total, log)
+    }
+}
+
+// fireErrorAlert reports an operational problem and runs the --on-error-threshold hook
+func (e *Engine) fireErrorAlert(reason string, rate float64, errCount, total int, log *os.File) {
+    if e.errorHook == nil {
+        return
+    }
+
+    msg := fmt.Sprintf("Alert: %s:%d %s, running --on-error-threshold hook", e.opts.Host, e.opts.Port, reason)
+    e.printWarning("\n%s", msg)
+    if log != nil {
+        log.WriteString(msg + "\n")
+    }
+
+    event := AlertEvent{
+        Host:      e.opts.Host,
+        Port:      e.opts.Port,
+        Reason:    reason,
+        ErrorRate: rate,
+        Errors:    errCount,
+        Attempts:  total,
+        Time:      time.Now().Format(time.RFC3339),
+    }
+    e.hooksWG.Add(1)
+    go func() {
+        defer e.hooksWG.Done()
+        e.runHook("on-error-threshold", e.errorHook, event, log)
+    }()
+}
@@ -0,0 +1,292 @@
+package core
+
+import (
+    "bufio"
+    "bytes"
+    "database/sql"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// sqliteMagic starts every SQLite 3 database file
+const sqliteMagic = "SQLite format 3\x00"
+
+// sensitiveColumns maps a data category to the column name fragments that suggest it
+var sensitiveColumns = []struct {
+    category string
+    keywords []string
+}{
+    {"credential", []string{"pass", "pwd", "hash", "secret", "token", "api_key", "apikey", "auth"}},
+    {"payment", []string{"card", "cc_num", "ccnum", "cvv", "iban", "account_number"}},
+    {"personal", []string{"email", "phone", "ssn", "dob", "birth", "address", "passport"}},
+}
+
+// Patterns for hashes found in SQL dump text
+var (
+    nativeHashPattern  = regexp.MustCompile(`\*[0-9A-F]{40}\b`)
+    createTablePattern = regexp.MustCompile("(?i)^CREATE TABLE (?:IF NOT EXISTS )?`?([^`\\s(]+)`?")
+    columnDefPattern   = regexp.MustCompile("^\\s+`([^`]+)`\\s")
+)
+
+// analyzeReport collects what was found in one offline file
+type analyzeReport struct {
+    tables    map[string]int
+    sensitive map[string][]string
+    hashes    []authHash
+}
+
+// runAnalyze handles 'sqlblaster analyze', classifying the tables and columns of local SQLite
+// databases or MySQL dump files and looting any MySQL password hashes they contain
+func (e *Engine) runAnalyze(args []string) {
+    fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+    fs.StringVar(&e.opts.LootDir, "loot-dir", "loot", "Directory to write extracted password hashes to")
+    fs.BoolVar(&e.opts.Verbose, "v", false, "Enable verbose mode")
+    fs.Parse(args)
+
+    if fs.NArg() == 0 {
+        e.printError("Error: analyze requires at least one file.")
+        fmt.Fprintln(e.out, "Usage: sqlblaster analyze [--loot-dir <dir>] <file.db|dump.sql> ...")
+        os.Exit(1)
+    }
+
+    failed := false
+    for _, path := range fs.Args() {
+        if err := e.analyzeFile(path); err != nil {
+            e.printError("Error analyzing %s: %v", path, err)
+            failed = true
+        }
+    }
+    if failed {
+        os.Exit(1)
+    }
+}
+
+// analyzeFile analyzes one SQLite database or SQL dump and prints its report
+func (e *Engine) analyzeFile(path string) error {
+    isSQLite, err := isSQLiteFile(path)
+    if err != nil {
+        return err
+    }
+
+    report := &analyzeReport{
+        tables:    make(map[string]int),
+        sensitive: make(map[string][]string),
+    }
+    if isSQLite {
+        err = e.analyzeSQLite(path, report)
+    } else {
+        err = analyzeSQLDump(path, report)
+    }
+    if err != nil {
+        return err
+    }
+
+    e.theme.heading.Fprintf(e.out, "\n%s\n", path)
+    names := make([]string, 0, len(report.tables))
+    for name := range report.tables {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    fmt.Fprintf(e.out, "Tables: %d\n", len(names))
+    for _, name := range names {
+        if isSQLite {
+            fmt.Fprintf(e.out, "  %s (%d rows)\n", name, report.tables[name])
+        } else {
+            fmt.Fprintf(e.out, "  %s\n", name)
+        }
+        e.emitRecord("analyze_table", map[string]interface{}{"file": path, "table": name, "rows": report.tables[name]})
+    }
+
+    if len(report.sensitive) > 0 {
+        fmt.Fprintln(e.out, "Sensitive columns:")
+        for _, c := range sensitiveColumns {
+            for _, column := range report.sensitive[c.category] {
+                e.printWarning("  [%s] %s", c.category, column)
+                e.emitRecord("analyze_column", map[string]interface{}{"file": path, "category": c.category, "column": column})
+            }
+        }
+    }
+
+    if len(report.hashes) > 0 {
+        e.writeLoot(filepath.Join(e.opts.LootDir, sanitizeFilename(filepath.Base(path))), report.hashes, nil)
+    } else {
+        fmt.Fprintln(e.out, "No MySQL password hashes found.")
+    }
+    return nil
+}
+
+// isSQLiteFile checks a file's header for the SQLite magic string
+func isSQLiteFile(path string) (bool, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return false, err
+    }
+    defer file.Close()
+
+    header := make([]byte, len(sqliteMagic))
+    n, _ := file.Read(header)
+    return n == len(sqliteMagic) && string(header) == sqliteMagic, nil
+}
+
+// classifyColumn returns the data category a column name suggests, or "" if none
+func classifyColumn(name string) string {
+    lower := strings.ToLower(name)
+    for _, c := range sensitiveColumns {
+        for _, kw := range c.keywords {
+            if strings.Contains(lower, kw) {
+                return c.category
+            }
+        }
+    }
+    return ""
+}
+
+// hashFromValue returns v when it looks like a MySQL native or caching_sha2 password hash
+func hashFromValue(v []byte) []byte {
+    v = bytes.TrimSpace(v)
+    if len(v) == 41 && nativeHashPattern.Match(v) {
+        return v
+    }
+    if len(v) == 70 && bytes.HasPrefix(v, []byte("$A$")) && v[6] == '$' {
+        return v
+    }
+    return nil
+}
+
+// analyzeSQLite walks every table of a SQLite database
+func (e *Engine) analyzeSQLite(path string, report *analyzeReport) error {
+    db, err := sql.Open("sqlite", path)
+    if err != nil {
+        return err
+    }
+    defer db.Close()
+
+    rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+    if err != nil {
+        return err
+    }
+    var tables []string
+    for rows.Next() {
+        var name string
+        if err := rows.Scan(&name); err != nil {
+            rows.Close()
+            return err
+        }
+        tables = append(tables, name)
+    }
+    rows.Close()
+
+    for _, table := range tables {
+        e.verbosePrintln("Analyzing table:", table)
+        if err := analyzeSQLiteTable(db, table, report); err != nil {
+            e.printWarning("Skipping table %s: %v", table, err)
+        }
+    }
+    return nil
+}
+
+// analyzeSQLiteTable classifies a table's columns and scans its rows for password hashes
+func analyzeSQLiteTable(db *sql.DB, table string, report *analyzeReport) error {
+    rows, err := db.Query(fmt.Sprintf(`SELECT * FROM "%s"`, strings.ReplaceAll(table, `"`, `""`)))
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    columns, err := rows.Columns()
+    if err != nil {
+        return err
+    }
+    userCol, hostCol := -1, -1
+    for i, col := range columns {
+        if category := classifyColumn(col); category != "" {
+            report.sensitive[category] = append(report.sensitive[category], table+"."+col)
+        }
+        switch strings.ToLower(col) {
+        case "user", "username", "login":
+            userCol = i
+        case "host":
+            hostCol = i
+        }
+    }
+
+    values := make([]sql.RawBytes, len(columns))
+    scanArgs := make([]interface{}, len(columns))
+    for i := range values {
+        scanArgs[i] = &values[i]
+    }
+
+    count := 0
+    for rows.Next() {
+        if err := rows.Scan(scanArgs...); err != nil {
+            return err
+        }
+        count++
+        for _, v := range values {
+            hash := hashFromValue(v)
+            if hash == nil {
+                continue
+            }
+            // Name the account after the row's user and host columns where the table has them
+            h := authHash{user: fmt.Sprintf("%s#%d", table, count), host: "offline", hash: append([]byte(nil), hash...)}
+            if userCol >= 0 {
+                h.user = string(values[userCol])
+            }
+            if hostCol >= 0 {
+                h.host = string(values[hostCol])
+            }
+            report.hashes = append(report.hashes, h)
+        }
+    }
+    report.tables[table] = count
+    return rows.Err()
+}
+
+// analyzeSQLDump scans a mysqldump-style file for table definitions and native password hashes
+func analyzeSQLDump(path string, report *analyzeReport) error {
+    file, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    scanner := bufio.NewScanner(file)
+    // Extended INSERT lines in dumps can be many megabytes long
+    scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+    table := ""
+    lineNum := 0
+    for scanner.Scan() {
+        lineNum++
+        line := scanner.Text()
+
+        if m := createTablePattern.FindStringSubmatch(line); m != nil {
+            table = m[1]
+            report.tables[table] = 0
+            continue
+        }
+        if table != "" {
+            if m := columnDefPattern.FindStringSubmatch(line); m != nil {
+                if category := classifyColumn(m[1]); category != "" {
+                    report.sensitive[category] = append(report.sensitive[category], table+"."+m[1])
+                }
+                continue
+            }
+        }
+
+        for _, hash := range nativeHashPattern.FindAllString(line, -1) {
+            report.hashes = append(report.hashes, authHash{
+                user: fmt.Sprintf("line%d", lineNum),
+                host: filepath.Base(path),
+                hash: []byte(hash),
+            })
+        }
+    }
+    return scanner.Err()
+}
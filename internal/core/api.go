@@ -0,0 +1,279 @@
+// Package core implements sqlblaster: the command line in Main, and the runs behind the
+// bruteforce, enum, dump and shell library packages
+package core
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/schollz/progressbar/v3"
+)
+
+// Result is a credential that logged in during a library run
+type Result struct {
+    Host         string
+    Port         int
+    User         string
+    Pass         string
+    Backend      string
+    Plugin       string
+    Restrictions []string
+    Time         time.Time
+}
+
+// DefaultOptions returns the options a library run starts from
+func DefaultOptions() Options {
+    c := defaultOptions()
+    // Library callers get the credentials back; the default SHOW DATABASES is for the terminal
+    c.VerifyQuery = "none"
+    return c
+}
+
+// beginRun creates the engine of a library run with c and sets up what Main would for it.
+// Human-readable output goes to output, or nowhere when it is nil. The options are checked as
+// Main checks the flags, and every problem found is returned at once.
+func beginRun(c Options, output io.Writer) (*Engine, error) {
+    e := NewEngine(&c)
+    e.out = output
+    if e.out == nil {
+        e.out = io.Discard
+    }
+    err := errors.Join(
+        e.setupSources(e.opts.Sources),
+        e.setupSessionVars(e.opts.SessionVars),
+        e.setupConnAttrs(e.opts.ConnAttrs),
+        e.setupTimeouts(e.opts.AttemptTimeout, e.opts.ConnectTimeout, e.opts.ReadTimeout, e.opts.WriteTimeout, e.opts.QueryTimeout),
+        e.checkTLSFiles(e.opts.TLSCert, e.opts.TLSKey, e.opts.TLSCA, e.opts.SkipSSL),
+        e.setupTLS(),
+        e.setupDumpFilter(e.opts.DumpInclude, e.opts.DumpExclude),
+        e.setupDumpWhere(e.opts.DumpWhere),
+        e.setupDumpEncrypt(e.opts.DumpEncrypt),
+    )
+    if e.opts.Host == "" {
+        err = errors.Join(errors.New("no host given"), err)
+    }
+    if e.opts.Workers < 1 {
+        err = errors.Join(err, errors.New("workers must be at least 1"))
+    }
+    if e.opts.ReadOnly && (e.opts.AllowDangerous || e.opts.DeployUDF) {
+        err = errors.Join(err, errors.New("read-only mode cannot be combined with allow dangerous or deploy UDF"))
+    }
+    if _, budgetErr := parseDumpBudget(e.opts.MaxBytes, e.opts.MaxBytesPerTable); budgetErr != nil {
+        err = errors.Join(err, budgetErr)
+    }
+    if e.opts.DumpSchemaOnly && e.opts.DumpDataOnly {
+        err = errors.Join(err, errors.New("dump schema only and dump data only cannot be combined"))
+    }
+    if e.opts.AuthPlugin != "" && !validAuthPlugin(e.opts.AuthPlugin) {
+        err = errors.Join(err, fmt.Errorf("auth plugin must be one of: %s", strings.Join(authPlugins, ", ")))
+    } else if e.opts.AuthPlugin == "mysql_clear_password" && !e.opts.AllowCleartext {
+        err = errors.Join(err, errors.New("auth plugin mysql_clear_password requires allow cleartext"))
+    }
+    if !validDumpFormat(e.opts.DumpFormat) {
+        err = errors.Join(err, fmt.Errorf("dump format must be one of: %s", strings.Join(dumpFormats, ", ")))
+    }
+    if _, csvErr := parseCSVDelimiter(e.opts.CSVDelimiter); csvErr != nil {
+        err = errors.Join(err, csvErr)
+    }
+    if e.opts.BlockPause != "" {
+        if d, pauseErr := time.ParseDuration(e.opts.BlockPause); pauseErr != nil || d <= 0 {
+            err = errors.Join(err, fmt.Errorf("invalid block pause '%s', use a duration such as 15m", e.opts.BlockPause))
+        } else {
+            e.blockPause = d
+        }
+    }
+    if e.opts.BlobMode != "" && !validBlobMode(e.opts.BlobMode) {
+        err = errors.Join(err, fmt.Errorf("blob mode must be one of: %s", strings.Join(blobModes, ", ")))
+    }
+    if err != nil {
+        return nil, err
+    }
+    e.setupRateLimit(e.opts.Rate, e.opts.Delay)
+    return e, nil
+}
+
+// ErrHostBlocked is returned by BruteforceRun.Wait when the host refused further connections
+// from this client before every pair was tested
+var ErrHostBlocked = errBlockedHost
+
+// BruteforceRun is a Bruteforce in progress
+type BruteforceRun struct {
+    // Results receives each credential that logs in and is closed when the run is over
+    Results <-chan Result
+
+    done chan struct{}
+    err  error
+}
+
+// Wait waits for the run to be over and returns why it stopped before testing every pair:
+// ErrHostBlocked, or ctx's error when it was cancelled. A run that tested every pair, or that
+// FirstOnly stopped at a success, returns nil. Results must be read until it is closed first.
+func (r *BruteforceRun) Wait() error {
+    <-r.done
+    return r.err
+}
+
+// Bruteforce tests every user with every password against c's target, sending each credential
+// that logs in on the run's Results. c.FirstOnly stops the run at the first success. progress,
+// when not nil, receives an EventProgress after every attempt.
+func Bruteforce(ctx context.Context, c Options, users, passwords []string, output io.Writer, progress EventHandler) (*BruteforceRun, error) {
+    if len(users) == 0 || len(passwords) == 0 {
+        return nil, errors.New("at least one user and one password are required")
+    }
+    e, err := beginRun(c, output)
+    if err != nil {
+        return nil, err
+    }
+
+    results := make(chan Result)
+    run := &BruteforceRun{Results: results, done: make(chan struct{})}
+    e.RegisterHandler(EventSuccess, func(ev Event) {
+        select {
+        case results <- Result{Host: ev.Host, Port: ev.Port, User: ev.User, Pass: ev.Pass, Backend: ev.Backend,
+            Plugin: ev.Plugin, Restrictions: ev.Restrictions, Time: ev.Time}:
+        case <-ctx.Done():
+        }
+    })
+    if progress != nil {
+        e.RegisterHandler(EventProgress, progress)
+    }
+    go func() {
+        defer close(run.done)
+        defer close(results)
+        // Pairs are made as the workers take them, so large lists are never multiplied out
+        runCtx, cancel := context.WithCancel(ctx)
+        defer cancel()
+        pairs := e.buildCredentialPairs(runCtx, func() <-chan string { return valuesChannel(users) },
+            func() <-chan string { return valuesChannel(passwords) }, e.opts.UserFirst, 0)
+        stats := e.newRunStats("Testing credentials", len(users)*len(passwords), 0, 0, progressbar.OptionSetWriter(io.Discard))
+        _, err := e.testPairs(runCtx, pairs, stats, nil, nil)
+        switch {
+        case ctx.Err() != nil:
+            run.err = ctx.Err()
+        case errors.Is(err, errFirstSuccess):
+        default:
+            run.err = err
+        }
+    }()
+    return run, nil
+}
+
+// login connects as the run's SingleUser with its SinglePass, with the session variables the server
+// accepts, and returns the pool, its DSN and the server's backend
+func (e *Engine) login(ctx context.Context) (*sql.DB, string, string, error) {
+    dsn := e.buildDSN(e.opts.SingleUser, e.opts.SinglePass, e.opts.Host, e.opts.Port)
+    db, err := e.openDB(dsn)
+    if err != nil {
+        return nil, "", "", err
+    }
+    configurePool(db)
+
+    attemptCtx, cancel := context.WithTimeout(ctx, e.attemptTimeout)
+    defer cancel()
+    if err := db.PingContext(attemptCtx); err != nil {
+        db.Close()
+        return nil, "", "", err
+    }
+
+    queryCtx, cancelQuery := e.withQueryTimeout(ctx)
+    defer cancelQuery()
+    if params := e.sessionVarParams(queryCtx, db); params != "" {
+        if postDB, err := e.openDB(dsn + params); err == nil {
+            db.Close()
+            configurePool(postDB)
+            db, dsn = postDB, dsn+params
+        }
+    }
+    return db, dsn, e.queryBackend(queryCtx, db), nil
+}
+
+// openPinnedSession opens a multi-statement session on dsn, as the dump and interactive
+// modes use
+func (e *Engine) openPinnedSession(ctx context.Context, dsn string) (*sql.DB, *session, error) {
+    if !strings.Contains(dsn, "multiStatements=true") {
+        dsn += "&multiStatements=true"
+    }
+    db, err := e.openDB(dsn)
+    if err != nil {
+        return nil, nil, err
+    }
+    sess, err := e.openSession(ctx, db)
+    if err != nil {
+        db.Close()
+        return nil, nil, err
+    }
+    return db, sess, nil
+}
+
+// Enumerate logs in as c.SingleUser and returns the -Enum report of the server
+func Enumerate(ctx context.Context, c Options, output io.Writer) (string, error) {
+    e, err := beginRun(c, output)
+    if err != nil {
+        return "", err
+    }
+
+    db, _, _, err := e.login(ctx)
+    if err != nil {
+        return "", err
+    }
+    defer db.Close()
+    queryCtx, cancel := e.withQueryTimeout(ctx)
+    defer cancel()
+    return e.enumerateMySQL(queryCtx, db), nil
+}
+
+// Dump logs in as c.SingleUser and dumps every database it can read into c.DumpDir in
+// c.DumpFormat, returning the dump's summary
+func Dump(ctx context.Context, c Options, output io.Writer) (string, error) {
+    e, err := beginRun(c, output)
+    if err != nil {
+        return "", err
+    }
+
+    db, dsn, backend, err := e.login(ctx)
+    if err != nil {
+        return "", err
+    }
+    db.Close()
+    dumpDB, sess, err := e.openPinnedSession(ctx, dsn+backendDSNParams(backend))
+    if err != nil {
+        return "", err
+    }
+    defer dumpDB.Close()
+    defer sess.close()
+    return e.dumpAllDatabases(ctx, sess, backend), nil
+}
+
+// Shell logs in as c.SingleUser and runs the interactive SQL shell until the user leaves it or
+// input ends. It reads from input and writes to output; a nil input is the process's terminal,
+// with line editing and history, and a nil output is standard output.
+func Shell(ctx context.Context, c Options, input io.Reader, output io.Writer) error {
+    if output == nil {
+        output = os.Stdout
+    }
+    e, err := beginRun(c, output)
+    if err != nil {
+        return err
+    }
+    e.in = input
+
+    db, dsn, _, err := e.login(ctx)
+    if err != nil {
+        return err
+    }
+    db.Close()
+    shellDB, sess, err := e.openPinnedSession(ctx, dsn)
+    if err != nil {
+        return err
+    }
+    defer shellDB.Close()
+    defer sess.close()
+    e.enterInteractiveMode(ctx, sess, e.opts.SingleUser)
+    return nil
+}
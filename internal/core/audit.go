@@ -0,0 +1,396 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "reflect"
+    "sync"
+    "time"
+)
+
+// auditArgMax is the longest argument value kept in an audit record
+const auditArgMax = 256
+
+// auditRecord is one statement in the --audit-log. Rows is the number of rows returned by a
+// query or affected by any other statement.
+type auditRecord struct {
+    Time       string   `json:"time"`
+    Target     string   `json:"target"`
+    User       string   `json:"user"`
+    Statement  string   `json:"statement"`
+    Args       []string `json:"args,omitempty"`
+    DurationMs int64    `json:"durationMs"`
+    Rows       int64    `json:"rows"`
+    Outcome    string   `json:"outcome"`
+    Error      string   `json:"error,omitempty"`
+}
+
+// auditLog appends records to the --audit-log file, one JSON object per line
+type auditLog struct {
+    mu   sync.Mutex
+    file *os.File
+}
+
+// openAuditLog opens the --audit-log file for appending. It is never truncated, so one file can
+// hold the evidence of several runs.
+func (e *Engine) openAuditLog(path string) error {
+    if path == "" {
+        return nil
+    }
+    file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+    if err != nil {
+        return err
+    }
+    e.audit = &auditLog{file: file}
+    e.noteCreated(path)
+    e.verbosePrintln("Auditing executed statements to", path)
+    return nil
+}
+
+// closeAuditLog flushes the --audit-log to disk
+func (e *Engine) closeAuditLog() {
+    if e.audit == nil {
+        return
+    }
+    e.audit.mu.Lock()
+    defer e.audit.mu.Unlock()
+    e.audit.file.Sync()
+    e.audit.file.Close()
+}
+
+// write appends one record as a single write, so concurrent runs sharing the file don't interleave
+func (a *auditLog) write(rec auditRecord) {
+    data, err := json.Marshal(rec)
+    if err != nil {
+        return
+    }
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    a.file.Write(append(data, '\n'))
+}
+
+// openDB opens a pool for dsn like sql.Open, dialing through the engine and recording every
+// statement it runs when --audit-log is set. Pings are not statements, so login attempts never
+// reach the audit log.
+func (e *Engine) openDB(dsn string) (*sql.DB, error) {
+    base, cfg, err := e.connector(dsn)
+    if err != nil {
+        return nil, err
+    }
+    if e.audit == nil {
+        return sql.OpenDB(base), nil
+    }
+    return sql.OpenDB(&auditConnector{base: base, log: e.audit, target: cfg.Addr, user: cfg.User}), nil
+}
+
+// auditConnector hands out connections that record their statements
+type auditConnector struct {
+    base   driver.Connector
+    log    *auditLog
+    target string
+    user   string
+}
+
+// Connect opens a connection with the underlying connector and wraps it
+func (c *auditConnector) Connect(ctx context.Context) (driver.Conn, error) {
+    conn, err := c.base.Connect(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return &auditConn{Conn: conn, c: c}, nil
+}
+
+// Driver returns the MySQL driver
+func (c *auditConnector) Driver() driver.Driver {
+    return c.base.Driver()
+}
+
+// record writes the outcome of one statement
+func (c *auditConnector) record(query string, args []driver.NamedValue, start time.Time, rows int64, err error) {
+    rec := auditRecord{
+        Time:       start.UTC().Format(time.RFC3339Nano),
+        Target:     c.target,
+        User:       c.user,
+        Statement:  query,
+        DurationMs: time.Since(start).Milliseconds(),
+        Rows:       rows,
+        Outcome:    "ok",
+    }
+    for _, arg := range args {
+        rec.Args = append(rec.Args, auditArg(arg.Value))
+    }
+    if err != nil {
+        rec.Outcome = "error"
+        rec.Error = err.Error()
+    }
+    c.log.write(rec)
+}
+
+// auditArg renders a bound argument for the audit log, cutting long values
+func auditArg(v driver.Value) string {
+    var s string
+    switch v := v.(type) {
+    case nil:
+        return "NULL"
+    case []byte:
+        s = fmt.Sprintf("0x%X", v)
+    default:
+        s = fmt.Sprint(v)
+    }
+    if len(s) > auditArgMax {
+        s = s[:auditArgMax] + "..."
+    }
+    return s
+}
+
+// auditConn passes everything through to the MySQL connection, recording queries and statements
+type auditConn struct {
+    driver.Conn
+    c *auditConnector
+}
+
+// QueryContext runs a query directly, recording it once its rows are closed. ErrSkip sends
+// queries with arguments through PrepareContext, where they are recorded instead.
+func (ac *auditConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+    q, ok := ac.Conn.(driver.QueryerContext)
+    if !ok {
+        return nil, driver.ErrSkip
+    }
+    start := time.Now()
+    rows, err := q.QueryContext(ctx, query, args)
+    if err == driver.ErrSkip {
+        return nil, err
+    }
+    if err != nil {
+        ac.c.record(query, args, start, 0, err)
+        return nil, err
+    }
+    return &auditRows{Rows: rows, c: ac.c, query: query, args: args, start: start}, nil
+}
+
+// ExecContext runs a statement directly and records it with the rows it affected
+func (ac *auditConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+    x, ok := ac.Conn.(driver.ExecerContext)
+    if !ok {
+        return nil, driver.ErrSkip
+    }
+    start := time.Now()
+    result, err := x.ExecContext(ctx, query, args)
+    if err == driver.ErrSkip {
+        return nil, err
+    }
+    ac.c.record(query, args, start, rowsAffected(result), err)
+    return result, err
+}
+
+// PrepareContext prepares a statement whose executions are recorded
+func (ac *auditConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+    var stmt driver.Stmt
+    var err error
+    if p, ok := ac.Conn.(driver.ConnPrepareContext); ok {
+        stmt, err = p.PrepareContext(ctx, query)
+    } else {
+        stmt, err = ac.Conn.Prepare(query)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &auditStmt{Stmt: stmt, c: ac.c, query: query}, nil
+}
+
+// Prepare prepares a statement whose executions are recorded
+func (ac *auditConn) Prepare(query string) (driver.Stmt, error) {
+    return ac.PrepareContext(context.Background(), query)
+}
+
+// BeginTx starts a transaction on the MySQL connection
+func (ac *auditConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+    if b, ok := ac.Conn.(driver.ConnBeginTx); ok {
+        return b.BeginTx(ctx, opts)
+    }
+    return ac.Conn.Begin()
+}
+
+// Ping checks the MySQL connection
+func (ac *auditConn) Ping(ctx context.Context) error {
+    if p, ok := ac.Conn.(driver.Pinger); ok {
+        return p.Ping(ctx)
+    }
+    return nil
+}
+
+// ResetSession prepares the connection for reuse from the pool
+func (ac *auditConn) ResetSession(ctx context.Context) error {
+    if r, ok := ac.Conn.(driver.SessionResetter); ok {
+        return r.ResetSession(ctx)
+    }
+    return nil
+}
+
+// IsValid reports whether the connection can go back into the pool
+func (ac *auditConn) IsValid() bool {
+    if v, ok := ac.Conn.(driver.Validator); ok {
+        return v.IsValid()
+    }
+    return true
+}
+
+// CheckNamedValue lets the MySQL driver convert arguments as it would without the audit layer
+func (ac *auditConn) CheckNamedValue(nv *driver.NamedValue) error {
+    if n, ok := ac.Conn.(driver.NamedValueChecker); ok {
+        return n.CheckNamedValue(nv)
+    }
+    return driver.ErrSkip
+}
+
+// auditStmt records each execution of a prepared statement
+type auditStmt struct {
+    driver.Stmt
+    c     *auditConnector
+    query string
+}
+
+// QueryContext runs the prepared query, recording it once its rows are closed
+func (s *auditStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+    q, ok := s.Stmt.(driver.StmtQueryContext)
+    if !ok {
+        return nil, fmt.Errorf("audit: driver statement has no QueryContext")
+    }
+    start := time.Now()
+    rows, err := q.QueryContext(ctx, args)
+    if err != nil {
+        s.c.record(s.query, args, start, 0, err)
+        return nil, err
+    }
+    return &auditRows{Rows: rows, c: s.c, query: s.query, args: args, start: start}, nil
+}
+
+// ExecContext runs the prepared statement and records it with the rows it affected
+func (s *auditStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+    x, ok := s.Stmt.(driver.StmtExecContext)
+    if !ok {
+        return nil, fmt.Errorf("audit: driver statement has no ExecContext")
+    }
+    start := time.Now()
+    result, err := x.ExecContext(ctx, args)
+    s.c.record(s.query, args, start, rowsAffected(result), err)
+    return result, err
+}
+
+// CheckNamedValue lets the MySQL driver convert arguments as it would without the audit layer
+func (s *auditStmt) CheckNamedValue(nv *driver.NamedValue) error {
+    if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+        return n.CheckNamedValue(nv)
+    }
+    return driver.ErrSkip
+}
+
+// rowsAffected reads the affected row count of a result, 0 when there is none
+func rowsAffected(result driver.Result) int64 {
+    if result == nil {
+        return 0
+    }
+    n, err := result.RowsAffected()
+    if err != nil {
+        return 0
+    }
+    return n
+}
+
+// auditRows counts the rows read and records the query when they are closed, so the duration
+// covers fetching them and errors partway through are kept
+type auditRows struct {
+    driver.Rows
+    c     *auditConnector
+    query string
+    args  []driver.NamedValue
+    start time.Time
+    count int64
+    err   error
+    once  sync.Once
+}
+
+// Next reads the next row
+func (r *auditRows) Next(dest []driver.Value) error {
+    err := r.Rows.Next(dest)
+    switch {
+    case err == nil:
+        r.count++
+    case err != io.EOF:
+        r.err = err
+    }
+    return err
+}
+
+// Close closes the rows and records the query
+func (r *auditRows) Close() error {
+    err := r.Rows.Close()
+    r.once.Do(func() {
+        r.c.record(r.query, r.args, r.start, r.count, r.err)
+    })
+    return err
+}
+
+// The column type and result set methods database/sql looks for are passed through, so
+// ColumnTypes and multi-statement results behave as without the audit layer
+
+// ColumnTypeDatabaseTypeName returns the column's database type, e.g. VARCHAR
+func (r *auditRows) ColumnTypeDatabaseTypeName(index int) string {
+    if t, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+        return t.ColumnTypeDatabaseTypeName(index)
+    }
+    return ""
+}
+
+// ColumnTypeLength returns the column's length for variable-length types
+func (r *auditRows) ColumnTypeLength(index int) (int64, bool) {
+    if t, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+        return t.ColumnTypeLength(index)
+    }
+    return 0, false
+}
+
+// ColumnTypeNullable reports whether the column may be NULL
+func (r *auditRows) ColumnTypeNullable(index int) (bool, bool) {
+    if t, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+        return t.ColumnTypeNullable(index)
+    }
+    return false, false
+}
+
+// ColumnTypePrecisionScale returns the column's precision and scale for decimal types
+func (r *auditRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+    if t, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+        return t.ColumnTypePrecisionScale(index)
+    }
+    return 0, 0, false
+}
+
+// ColumnTypeScanType returns the Go type values of the column scan into
+func (r *auditRows) ColumnTypeScanType(index int) reflect.Type {
+    if t, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+        return t.ColumnTypeScanType(index)
+    }
+    return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// HasNextResultSet reports whether a multi-statement query has another result set
+func (r *auditRows) HasNextResultSet() bool {
+    if n, ok := r.Rows.(driver.RowsNextResultSet); ok {
+        return n.HasNextResultSet()
+    }
+    return false
+}
+
+// NextResultSet advances to the next result set
+func (r *auditRows) NextResultSet() error {
+    if n, ok := r.Rows.(driver.RowsNextResultSet); ok {
+        return n.NextResultSet()
+    }
+    return io.EOF
+}
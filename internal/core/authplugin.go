@@ -0,0 +1,122 @@
+package core
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "os"
+    "strconv"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// authPlugins are the client auth plugins --auth-plugin can force
+var authPlugins = []string{"mysql_native_password", "caching_sha2_password", "mysql_clear_password"}
+
+// pluginMismatchError is an attempt that never tested the password because the server wanted
+// an auth plugin the client could not or would not use
+type pluginMismatchError struct {
+    offered   string
+    requested string
+}
+
+func (m *pluginMismatchError) Error() string {
+    if m.offered == "" {
+        return fmt.Sprintf("server requested auth plugin %s, which is disabled", m.requested)
+    }
+    return fmt.Sprintf("server requested auth plugin %s instead of %s", m.requested, m.offered)
+}
+
+// validAuthPlugin reports whether --auth-plugin names a plugin sqlblaster can force
+func validAuthPlugin(plugin string) bool {
+    for _, p := range authPlugins {
+        if p == plugin {
+            return true
+        }
+    }
+    return false
+}
+
+// asPluginMismatch reports whether err means the server asked for an auth plugin that was not
+// used, either refused while --auth-plugin forced another or disabled in the driver
+func asPluginMismatch(err error) (*pluginMismatchError, bool) {
+    var mismatch *pluginMismatchError
+    switch {
+    case errors.As(err, &mismatch):
+        return mismatch, true
+    case errors.Is(err, mysql.ErrNativePassword):
+        return &pluginMismatchError{requested: "mysql_native_password"}, true
+    case errors.Is(err, mysql.ErrCleartextPassword):
+        return &pluginMismatchError{requested: "mysql_clear_password"}, true
+    case errors.Is(err, mysql.ErrOldPassword):
+        return &pluginMismatchError{requested: "mysql_old_password"}, true
+    case errors.Is(err, mysql.ErrUnknownPlugin):
+        return &pluginMismatchError{requested: "an unsupported plugin"}, true
+    }
+    return nil, false
+}
+
+// authPluginDSNParams are the driver parameters for --allow-cleartext and --auth-plugin: the
+// regular connections after a forced caching_sha2_password login must not fall back to
+// mysql_native_password either
+func (e *Engine) authPluginDSNParams() string {
+    var params string
+    if e.opts.AllowCleartext {
+        params += "&allowCleartextPasswords=true"
+    }
+    if e.opts.AuthPlugin == "caching_sha2_password" {
+        params += "&allowNativePasswords=false"
+    }
+    return params
+}
+
+// pingRaw logs in once over the raw protocol and disconnects, for what the driver cannot do:
+// offering --auth-plugin in the handshake, where an auth switch to another plugin is a
+// pluginMismatchError, and pre-4.1 servers.
+func (e *Engine) pingRaw(ctx context.Context, user, pass string) error {
+    deadline, ok := ctx.Deadline()
+    if !ok {
+        deadline = time.Now().Add(e.attemptTimeout)
+    }
+    conn, err := e.dialMySQL(ctx, net.JoinHostPort(e.opts.Host, strconv.Itoa(e.opts.Port)))
+    if err != nil {
+        return err
+    }
+    c := &authConn{engine: e, conn: conn}
+    if err := c.handshake(deadline, user, pass); err != nil {
+        c.conn.Close()
+        return err
+    }
+    c.close()
+    return nil
+}
+
+// notePluginMismatch reports an attempt that was not tested because of its auth plugin, once
+// per user
+func (e *Engine) notePluginMismatch(user string, mismatch *pluginMismatchError, log *os.File) {
+    e.pluginMismatches.Add(1)
+    if _, seen := e.pluginMismatchWarned.LoadOrStore(user, true); seen {
+        return
+    }
+    msg := fmt.Sprintf("Could not test %s: %v (see --auth-plugin and --allow-cleartext)", user, mismatch)
+    e.printWarning("%s", msg)
+    if log != nil {
+        log.WriteString(msg + "\n")
+    }
+}
+
+// reportPluginMismatches prints how many attempts were not tested because of auth plugin
+// negotiation
+func (e *Engine) reportPluginMismatches(log *os.File) {
+    count := e.pluginMismatches.Load()
+    if count == 0 {
+        return
+    }
+    msg := fmt.Sprintf("%d attempt(s) were not tested because the server requested another auth plugin, see the warnings above.", count)
+    e.printWarning("\n%s", msg)
+    if log != nil {
+        log.WriteString(msg + "\n")
+    }
+}
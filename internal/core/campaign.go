@@ -0,0 +1,297 @@
+package core
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "slices"
+    "sort"
+    "strings"
+    "time"
+)
+
+// campaignRoot is the directory every campaign's tree lives under
+const campaignRoot = "campaigns"
+
+// campaignFile holds a campaign's metadata and run history
+const campaignFile = "campaign.json"
+
+// Campaign records the runs made under one --campaign name
+type Campaign struct {
+    Name       string        `json:"name"`
+    Created    time.Time     `json:"created"`
+    Runs       []CampaignRun `json:"runs"`
+    ShredAfter string        `json:"shred_after,omitempty"` // retention period after the last run
+    ShredBy    *time.Time    `json:"shred_by,omitempty"`
+    Artifacts  []string      `json:"artifacts,omitempty"` // files written outside the campaign directory
+}
+
+// CampaignRun summarizes one sqlblaster invocation in a campaign
+type CampaignRun struct {
+    Host      string            `json:"host"`
+    Port      int               `json:"port"`
+    Mode      string            `json:"mode"`
+    Started   time.Time         `json:"started"`
+    Finished  time.Time         `json:"finished"`
+    Users     int               `json:"users_tested"`
+    Successes int               `json:"successes"`
+    Data      map[string]string `json:"data_versions,omitempty"`
+}
+
+// validCampaignName reports whether name can be used as a single directory name
+func validCampaignName(name string) bool {
+    return name != "" && name != "." && name != ".." && sanitizeFilename(name) == name
+}
+
+// applyCampaign organizes the run's state, results database, log, dumps, loot and reports under
+// campaigns/<name>. Paths set explicitly on the command line or in the config are kept.
+func (e *Engine) applyCampaign(name string) error {
+    if !validCampaignName(name) {
+        return fmt.Errorf("invalid campaign name '%s': use letters, digits, '-', '_' or '.'", name)
+    }
+    e.campaignDir = filepath.Join(campaignRoot, name)
+    target := sanitizeFilename(fmt.Sprintf("%s_%d", e.opts.Host, e.opts.Port))
+
+    // A dry run only reports where the files would go
+    if !e.dryRun {
+        for _, dir := range []string{"state", "logs", "dumps", "loot", "reports"} {
+            if err := os.MkdirAll(filepath.Join(e.campaignDir, dir), 0755); err != nil {
+                return err
+            }
+        }
+        if !e.fileExists(filepath.Join(e.campaignDir, campaignFile)) {
+            if err := e.saveCampaign(&Campaign{Name: name, Created: time.Now()}); err != nil {
+                return err
+            }
+        }
+    }
+
+    if e.opts.SessionDir == "sessions" {
+        e.opts.SessionDir = filepath.Join(e.campaignDir, "state")
+    }
+    if e.opts.ResultsDB == "" {
+        e.opts.ResultsDB = filepath.Join(e.campaignDir, "results.db")
+    }
+    if e.opts.LogFile == "" {
+        e.opts.LogFile = filepath.Join(e.campaignDir, "logs", target+".log")
+    }
+    if e.opts.DumpDir == "mysql_dump" {
+        e.opts.DumpDir = filepath.Join(e.campaignDir, "dumps", target)
+    }
+    if e.opts.LootDir == "loot" {
+        e.opts.LootDir = filepath.Join(e.campaignDir, "loot")
+    }
+
+    // Report names without a directory go to the campaign's reports directory, an audit log
+    // name to its logs directory
+    if e.opts.SARIFFile != "" && filepath.Base(e.opts.SARIFFile) == e.opts.SARIFFile {
+        e.opts.SARIFFile = filepath.Join(e.campaignDir, "reports", e.opts.SARIFFile)
+    }
+    if e.opts.JUnitFile != "" && filepath.Base(e.opts.JUnitFile) == e.opts.JUnitFile {
+        e.opts.JUnitFile = filepath.Join(e.campaignDir, "reports", e.opts.JUnitFile)
+    }
+    if e.opts.InventoryFile != "" && filepath.Base(e.opts.InventoryFile) == e.opts.InventoryFile {
+        e.opts.InventoryFile = filepath.Join(e.campaignDir, "reports", e.opts.InventoryFile)
+    }
+    if e.opts.AuditLog != "" && filepath.Base(e.opts.AuditLog) == e.opts.AuditLog {
+        e.opts.AuditLog = filepath.Join(e.campaignDir, "logs", e.opts.AuditLog)
+    }
+
+    e.verbosePrintln("Campaign directory:", e.campaignDir)
+    return nil
+}
+
+// loadCampaign reads a campaign's metadata from dir
+func loadCampaign(dir string) (*Campaign, error) {
+    data, err := os.ReadFile(filepath.Join(dir, campaignFile))
+    if err != nil {
+        return nil, err
+    }
+    var c Campaign
+    if err := json.Unmarshal(data, &c); err != nil {
+        return nil, fmt.Errorf("%s: %v", campaignFile, err)
+    }
+    return &c, nil
+}
+
+// saveCampaign writes a campaign's metadata to the active campaign directory
+func (e *Engine) saveCampaign(c *Campaign) error {
+    data, err := json.MarshalIndent(c, "", "  ")
+    if err != nil {
+        return err
+    }
+    return os.WriteFile(filepath.Join(e.campaignDir, campaignFile), data, 0644)
+}
+
+// recordCampaignRun appends the finished run to the active campaign's history
+func (e *Engine) recordCampaignRun(mode string, started time.Time) {
+    if e.campaignDir == "" {
+        return
+    }
+    c, err := loadCampaign(e.campaignDir)
+    if err != nil {
+        e.printError("Error reading campaign: %v", err)
+        return
+    }
+    c.Runs = append(c.Runs, CampaignRun{
+        Host:      e.opts.Host,
+        Port:      e.opts.Port,
+        Mode:      mode,
+        Started:   started,
+        Finished:  time.Now(),
+        Users:     len(e.getTestedUsers()),
+        Successes: len(e.getSuccesses()),
+        Data:      e.dataVersions,
+    })
+
+    // Every run restarts the retention period, which a run without --shred-after keeps
+    if e.opts.ShredAfter != "" {
+        c.ShredAfter = e.opts.ShredAfter
+    }
+    if ttl, err := parseRetention(c.ShredAfter); c.ShredAfter != "" && err == nil {
+        shredBy := time.Now().Add(ttl)
+        c.ShredBy = &shredBy
+    }
+    for _, path := range e.campaignArtifacts() {
+        if !slices.Contains(c.Artifacts, path) {
+            c.Artifacts = append(c.Artifacts, path)
+        }
+    }
+    if err := e.saveCampaign(c); err != nil {
+        e.printError("Error saving campaign: %v", err)
+    }
+}
+
+// runCampaignCommand implements "sqlblaster campaign list" and "sqlblaster campaign summary <name>"
+func (e *Engine) runCampaignCommand(args []string) {
+    switch {
+    case len(args) == 1 && args[0] == "list":
+        listCampaigns()
+    case len(args) == 2 && args[0] == "summary":
+        if err := e.summarizeCampaign(args[1]); err != nil {
+            e.printError("Error: %v", err)
+            os.Exit(1)
+        }
+    default:
+        e.printError("Error: unknown campaign command.")
+        fmt.Fprintln(e.out, "Usage: sqlblaster campaign list")
+        fmt.Fprintln(e.out, "       sqlblaster campaign summary <name>")
+        os.Exit(1)
+    }
+}
+
+// listCampaigns prints every campaign with its run count and last activity
+func listCampaigns() {
+    entries, err := os.ReadDir(campaignRoot)
+    if err != nil && !os.IsNotExist(err) {
+        printError("Error: %v", err)
+        os.Exit(1)
+    }
+
+    found := 0
+    for _, entry := range entries {
+        if !entry.IsDir() {
+            continue
+        }
+        c, err := loadCampaign(filepath.Join(campaignRoot, entry.Name()))
+        if err != nil {
+            continue
+        }
+        found++
+        last := c.Created
+        if len(c.Runs) > 0 {
+            last = c.Runs[len(c.Runs)-1].Finished
+        }
+        line := fmt.Sprintf("%-24s %3d run(s), last activity %s", c.Name, len(c.Runs), last.Format("2006-01-02 15:04"))
+        if c.ShredBy != nil {
+            line += ", shred by " + c.ShredBy.Format("2006-01-02")
+        }
+        fmt.Println(line)
+    }
+    if found == 0 {
+        fmt.Println("No campaigns found in", campaignRoot)
+    }
+}
+
+// summarizeCampaign prints a campaign's runs, the credentials found per target and the
+// dumps, loot and reports collected
+func (e *Engine) summarizeCampaign(name string) error {
+    if !validCampaignName(name) {
+        return fmt.Errorf("invalid campaign name '%s'", name)
+    }
+    dir := filepath.Join(campaignRoot, name)
+    c, err := loadCampaign(dir)
+    if err != nil {
+        return fmt.Errorf("campaign '%s' not found: %v", name, err)
+    }
+
+    e.theme.heading.Fprintf(e.out, "Campaign %s (created %s)\n", c.Name, c.Created.Format("2006-01-02 15:04"))
+    if c.ShredBy != nil {
+        fmt.Fprintf(e.out, "Shred by %s (%s after the last run), with 'sqlblaster shred --campaign %s'\n",
+            c.ShredBy.Format("2006-01-02 15:04"), c.ShredAfter, c.Name)
+    }
+
+    e.theme.heading.Fprintf(e.out, "\nRuns (%d):\n", len(c.Runs))
+    for _, r := range c.Runs {
+        fmt.Fprintf(e.out, "  %s  %-28s %-8s %6d user(s) %3d found  %s\n",
+            r.Started.Format("2006-01-02 15:04"), fmt.Sprintf("%s:%d", r.Host, r.Port), r.Mode,
+            r.Users, r.Successes, r.Finished.Sub(r.Started).Round(time.Second))
+    }
+
+    resultsPath := filepath.Join(dir, "results.db")
+    if e.fileExists(resultsPath) {
+        records, err := e.loadResults(resultsPath)
+        if err != nil {
+            return err
+        }
+        byTarget := make(map[string][]ResultRecord)
+        var targets []string
+        for _, r := range records {
+            if byTarget[r.hostKey()] == nil {
+                targets = append(targets, r.hostKey())
+            }
+            byTarget[r.hostKey()] = append(byTarget[r.hostKey()], r)
+        }
+        sort.Strings(targets)
+
+        e.theme.heading.Fprintf(e.out, "\nCredentials found (%d on %d target(s)):\n", len(records), len(targets))
+        for _, target := range targets {
+            fmt.Fprintf(e.out, "  %s\n", target)
+            for _, r := range byTarget[target] {
+                line := fmt.Sprintf("    %s:%s", r.User, r.Pass)
+                if status := r.accountStatus(); status != "" {
+                    line += " (" + status + ")"
+                }
+                e.printSuccess("%s", line)
+                if !isPasswordProof(r.Pass) {
+                    fmt.Fprintf(e.out, "      %s\n", e.scorePassword(r.Pass))
+                }
+            }
+        }
+
+        list, err := loadNotes(resultsPath)
+        if err != nil {
+            return err
+        }
+        if len(list) > 0 {
+            e.theme.heading.Fprintf(e.out, "\nNotes (%d):\n", len(list))
+            for _, n := range list {
+                fmt.Fprintf(e.out, "  %s  %s: %s\n", n.AddedAt, n.subject(), n.Text)
+            }
+        }
+    }
+
+    for _, sub := range []string{"dumps", "loot", "reports"} {
+        entries, _ := os.ReadDir(filepath.Join(dir, sub))
+        var names []string
+        for _, entry := range entries {
+            names = append(names, entry.Name())
+        }
+        e.theme.heading.Fprintf(e.out, "\n%s%s (%d):\n", strings.ToUpper(sub[:1]), sub[1:], len(names))
+        for _, n := range names {
+            fmt.Fprintf(e.out, "  %s\n", filepath.Join(dir, sub, n))
+        }
+    }
+    return nil
+}
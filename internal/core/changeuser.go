@@ -0,0 +1,522 @@
+package core
+
+import (
+    "bytes"
+    "context"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha1"
+    "crypto/sha256"
+    "crypto/tls"
+    "crypto/x509"
+    "encoding/binary"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "os"
+    "strconv"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// MySQL protocol constants used by the change-user batching client
+const (
+    clientLongPassword     = 0x00000001
+    clientProtocol41       = 0x00000200
+    clientSSL              = 0x00000800
+    clientTransactions     = 0x00002000
+    clientSecureConn       = 0x00008000
+    clientPluginAuth       = 0x00080000
+    clientConnectAttrs     = 0x00100000
+    clientPluginAuthLenenc = 0x00200000
+
+    comQuit       = 0x01
+    comChangeUser = 0x11
+
+    charsetUTF8MB4 = 45
+    maxPacketSize  = 1<<24 - 1
+)
+
+// errBatchFallback marks attempts the batching client cannot decide, which are retried with a
+// regular connection
+var errBatchFallback = errors.New("attempt needs a regular connection")
+
+// authConn is a raw MySQL protocol session used to test credentials with COM_CHANGE_USER
+type authConn struct {
+    engine   *Engine
+    conn     net.Conn
+    seq      byte
+    caps     uint32
+    plugin   string
+    scramble []byte
+    secure   bool
+    source   string
+    attempts int
+    failures int
+}
+
+// batchTestLogin tests a credential over the worker's batched connection, opening one with the
+// bootstrap credential (--monitor-creds or the first success) when needed. It reports false when
+// the credential has no verdict yet and still has to be tested with testLogin.
+func (e *Engine) batchTestLogin(ctx context.Context, batch **authConn, user, pass string, log *os.File) (string, bool) {
+    if e.opts.BatchAuth <= 0 || e.batchUnsupported.Load() {
+        return "", false
+    }
+
+    if *batch != nil && (*batch).attempts >= e.opts.BatchAuth {
+        (*batch).close()
+        *batch = nil
+    }
+    if *batch == nil {
+        bootUser, bootPass, ok := e.monitorCredential()
+        if !ok {
+            return "", false
+        }
+        c, err := e.openAuthConn(ctx, bootUser, bootPass)
+        if err != nil {
+            e.verbosePrintln("Batch auth: cannot open session:", err)
+            if errors.Is(err, errBatchFallback) {
+                e.disableBatching(err.Error())
+            }
+            return "", false
+        }
+        *batch = c
+    }
+
+    c := *batch
+    e.recordAttempt(user)
+    e.verbosePrintf("Testing %s:%s over batched connection... ", user, pass)
+    err := c.changeUser(ctx, user, pass)
+    c.attempts++
+
+    var myErr *mysql.MySQLError
+    var mismatch *pluginMismatchError
+    switch {
+    case err == nil:
+        e.verbosePrintln("success")
+        // Run the command, enumeration and recording for the hit over a regular connection, without
+        // counting the attempt a second time
+        return e.safeLogin(ctx, user, pass, log)
+    case errors.As(err, &mismatch):
+        // The session is mid-handshake: test the user over a regular connection, which reports it
+        c.close()
+        *batch = nil
+        e.verbosePrintln("inconclusive:", err)
+        return "", false
+    case errors.As(err, &myErr) && isTransientError(err):
+        // Out of connections or shutting down: retry over a regular connection
+        c.close()
+        *batch = nil
+        e.verbosePrintln("inconclusive:", err)
+        return "", false
+    case errors.As(err, &myErr):
+        e.verbosePrintln("failed:", err)
+        if isAuthFailure(err) {
+            e.recordTested(e.opts.Host, e.opts.Port)
+        }
+        c.failures++
+        e.noteLockout(user, c.source, err, log)
+        e.emit(Event{Type: EventFailure, User: user, Pass: pass, Err: err})
+        return "", true
+    default:
+        c.close()
+        *batch = nil
+        if c.failures > 0 && !errors.Is(err, errBatchFallback) {
+            e.disableBatching("the server closes the connection after a failed COM_CHANGE_USER")
+        }
+        e.verbosePrintln("inconclusive:", err)
+        return "", false
+    }
+}
+
+// disableBatching switches every worker back to one connection per attempt
+func (e *Engine) disableBatching(reason string) {
+    if !e.batchUnsupported.Swap(true) {
+        e.printWarning("\nBatch auth disabled: %s, using one connection per attempt", reason)
+    }
+}
+
+// openAuthConn connects to the target and logs in with the bootstrap credential
+func (e *Engine) openAuthConn(ctx context.Context, user, pass string) (*authConn, error) {
+    addr := net.JoinHostPort(e.opts.Host, strconv.Itoa(e.opts.Port))
+
+    // Dial, TLS and authentication share one --attempt-timeout
+    deadline := time.Now().Add(e.attemptTimeout)
+    attemptCtx, cancel := context.WithDeadline(ctx, deadline)
+    defer cancel()
+    dialCtx, usedSource := withSourceTracking(attemptCtx)
+
+    conn, err := e.dialMySQL(dialCtx, addr)
+    if err != nil {
+        return nil, err
+    }
+
+    c := &authConn{engine: e, conn: conn, source: *usedSource}
+    if err := c.handshake(deadline, user, pass); err != nil {
+        c.conn.Close()
+        return nil, err
+    }
+    return c, nil
+}
+
+// handshake reads the server greeting, negotiates TLS when configured and authenticates by deadline
+func (c *authConn) handshake(deadline time.Time, user, pass string) error {
+    c.conn.SetDeadline(deadline)
+
+    data, err := c.readPacket()
+    if err != nil {
+        return err
+    }
+    if data[0] == 0xff {
+        return parseErrPacket(data)
+    }
+    if err := c.parseGreeting(data); err != nil {
+        return err
+    }
+    if c.engine.opts.AuthPlugin != "" {
+        c.plugin = c.engine.opts.AuthPlugin
+    }
+
+    if c.caps&clientProtocol41 == 0 || c.caps&clientSecureConn == 0 {
+        return c.handshake320(user, pass)
+    }
+
+    serverCaps := c.caps
+    c.caps = clientLongPassword | clientProtocol41 | clientSecureConn | clientTransactions | clientPluginAuth
+    if serverCaps&clientPluginAuthLenenc != 0 {
+        c.caps |= clientPluginAuthLenenc
+    }
+    if len(c.engine.connAttrs) > 0 && serverCaps&clientConnectAttrs != 0 {
+        c.caps |= clientConnectAttrs
+    }
+
+    // Match the driver's TLS behaviour: required with --use-ssl, skip-verify by default
+    if !c.engine.opts.SkipSSL && serverCaps&clientSSL != 0 {
+        c.caps |= clientSSL
+        if err := c.writePacket(c.handshakePrefix()); err != nil {
+            return err
+        }
+        tlsConn := tls.Client(c.conn, c.engine.tlsConfigFor(c.engine.opts.Host))
+        if err := tlsConn.Handshake(); err != nil {
+            return err
+        }
+        c.conn = tlsConn
+        c.secure = true
+    } else if c.engine.opts.UseSSL {
+        return fmt.Errorf("%w: server does not support TLS", errBatchFallback)
+    }
+
+    auth, err := authResponse(c.plugin, c.scramble, pass, c.secure, c.engine.opts.AllowCleartext)
+    if err != nil {
+        return err
+    }
+
+    packet := c.handshakePrefix()
+    packet = append(packet, user...)
+    packet = append(packet, 0)
+    packet = append(packet, byte(len(auth)))
+    packet = append(packet, auth...)
+    packet = append(packet, c.plugin...)
+    packet = append(packet, 0)
+    if c.caps&clientConnectAttrs != 0 {
+        packet = append(packet, c.engine.encodeConnAttrs()...)
+    }
+    if err := c.writePacket(packet); err != nil {
+        return err
+    }
+    return c.readAuthResult(pass)
+}
+
+// parseGreeting extracts the capabilities, scramble and auth plugin from the server greeting
+func (c *authConn) parseGreeting(data []byte) error {
+    pos := bytes.IndexByte(data[1:], 0) + 2 // protocol version and server version
+    if pos < 2 || len(data) < pos+4+9+2 {
+        return fmt.Errorf("%w: malformed server greeting", errBatchFallback)
+    }
+    pos += 4 // connection id
+    c.scramble = append([]byte{}, data[pos:pos+8]...)
+    pos += 9 // scramble part 1 and filler
+    c.caps = uint32(binary.LittleEndian.Uint16(data[pos:]))
+    pos += 2
+    c.plugin = "mysql_native_password"
+    if len(data) < pos+16 {
+        return nil
+    }
+    pos += 3 // charset and status
+    c.caps |= uint32(binary.LittleEndian.Uint16(data[pos:])) << 16
+    pos += 2
+    authLen := int(data[pos])
+    pos += 11 // auth data length and reserved bytes
+    if c.caps&clientSecureConn != 0 {
+        part2 := authLen - 8
+        if part2 < 13 {
+            part2 = 13
+        }
+        if len(data) < pos+part2 {
+            return fmt.Errorf("%w: malformed server greeting", errBatchFallback)
+        }
+        c.scramble = append(c.scramble, data[pos:pos+12]...)
+        pos += part2
+    }
+    if c.caps&clientPluginAuth != 0 && pos < len(data) {
+        c.plugin = string(bytes.TrimRight(data[pos:], "\x00"))
+    }
+    return nil
+}
+
+// handshakePrefix builds the fixed start of the handshake response, also used as SSLRequest
+func (c *authConn) handshakePrefix() []byte {
+    packet := make([]byte, 32)
+    binary.LittleEndian.PutUint32(packet, c.caps)
+    binary.LittleEndian.PutUint32(packet[4:], maxPacketSize)
+    packet[8] = charsetUTF8MB4
+    return packet
+}
+
+// changeUser re-authenticates the session as user with COM_CHANGE_USER
+func (c *authConn) changeUser(ctx context.Context, user, pass string) error {
+    if deadline, ok := ctx.Deadline(); ok {
+        c.conn.SetDeadline(deadline)
+    } else {
+        c.conn.SetDeadline(time.Now().Add(c.engine.attemptTimeout))
+    }
+
+    auth, err := authResponse(c.plugin, c.scramble, pass, c.secure, c.engine.opts.AllowCleartext)
+    if err != nil {
+        return err
+    }
+
+    packet := []byte{comChangeUser}
+    packet = append(packet, user...)
+    packet = append(packet, 0)
+    packet = append(packet, byte(len(auth)))
+    packet = append(packet, auth...)
+    packet = append(packet, 0) // no default schema
+    packet = append(packet, charsetUTF8MB4, 0)
+    packet = append(packet, c.plugin...)
+    packet = append(packet, 0)
+    if c.caps&clientConnectAttrs != 0 {
+        packet = append(packet, c.engine.encodeConnAttrs()...)
+    }
+
+    c.seq = 0
+    if err := c.writePacket(packet); err != nil {
+        return err
+    }
+    return c.readAuthResult(pass)
+}
+
+// readAuthResult follows auth switch and caching_sha2_password exchanges until OK or ERR
+func (c *authConn) readAuthResult(pass string) error {
+    for {
+        data, err := c.readPacket()
+        if err != nil {
+            return err
+        }
+
+        switch data[0] {
+        case 0x00:
+            return nil
+        case 0xff:
+            return parseErrPacket(data)
+        case 0xfe:
+            if len(data) == 1 {
+                // Servers before auth plugins ask for the pre-4.1 scramble of the greeting's
+                // first 8 bytes for accounts with old password hashes
+                if forced := c.engine.opts.AuthPlugin; forced != "" {
+                    return &pluginMismatchError{offered: forced, requested: "mysql_old_password"}
+                }
+                c.plugin = "mysql_old_password"
+                if err := c.writePacket(append(scrambleOldPassword(c.scramble, pass), 0)); err != nil {
+                    return err
+                }
+                continue
+            }
+            // Auth switch: the server wants another plugin, with a fresh scramble
+            end := bytes.IndexByte(data[1:], 0)
+            if end < 0 {
+                return fmt.Errorf("%w: malformed auth switch request", errBatchFallback)
+            }
+            requested := string(data[1 : end+1])
+            if forced := c.engine.opts.AuthPlugin; forced != "" && requested != forced {
+                return &pluginMismatchError{offered: forced, requested: requested}
+            }
+            c.plugin = requested
+            c.scramble = bytes.TrimRight(data[end+2:], "\x00")
+            auth, err := authResponse(c.plugin, c.scramble, pass, c.secure, c.engine.opts.AllowCleartext)
+            if err != nil {
+                return err
+            }
+            if err := c.writePacket(auth); err != nil {
+                return err
+            }
+        case 0x01:
+            if c.plugin != "caching_sha2_password" || len(data) < 2 {
+                return fmt.Errorf("%w: unexpected auth data for %s", errBatchFallback, c.plugin)
+            }
+            switch data[1] {
+            case 3: // fast auth succeeded, OK follows
+            case 4: // full auth: cleartext over TLS, otherwise RSA-encrypted with the server key
+                if c.secure {
+                    if err := c.writePacket(append([]byte(pass), 0)); err != nil {
+                        return err
+                    }
+                    continue
+                }
+                if err := c.writePacket([]byte{2}); err != nil {
+                    return err
+                }
+                keyData, err := c.readPacket()
+                if err != nil {
+                    return err
+                }
+                enc, err := encryptPassword(keyData[1:], c.scramble, pass)
+                if err != nil {
+                    return err
+                }
+                if err := c.writePacket(enc); err != nil {
+                    return err
+                }
+            default:
+                return fmt.Errorf("%w: unexpected caching_sha2_password state %d", errBatchFallback, data[1])
+            }
+        default:
+            return fmt.Errorf("%w: unexpected packet 0x%02x during auth", errBatchFallback, data[0])
+        }
+    }
+}
+
+// close sends COM_QUIT and closes the connection
+func (c *authConn) close() {
+    c.conn.SetDeadline(time.Now().Add(time.Second))
+    c.seq = 0
+    c.writePacket([]byte{comQuit})
+    c.conn.Close()
+}
+
+// readPacket reads one protocol packet and advances the sequence id
+func (c *authConn) readPacket() ([]byte, error) {
+    var header [4]byte
+    if _, err := io.ReadFull(c.conn, header[:]); err != nil {
+        return nil, err
+    }
+    length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+    c.seq = header[3] + 1
+    data := make([]byte, length)
+    if _, err := io.ReadFull(c.conn, data); err != nil {
+        return nil, err
+    }
+    if length == 0 {
+        return nil, io.ErrUnexpectedEOF
+    }
+    return data, nil
+}
+
+// writePacket writes one protocol packet with the next sequence id
+func (c *authConn) writePacket(data []byte) error {
+    packet := make([]byte, 4+len(data))
+    packet[0] = byte(len(data))
+    packet[1] = byte(len(data) >> 8)
+    packet[2] = byte(len(data) >> 16)
+    packet[3] = c.seq
+    copy(packet[4:], data)
+    c.seq++
+    _, err := c.conn.Write(packet)
+    return err
+}
+
+// parseErrPacket converts an ERR packet into the driver's error type so lockout detection works
+func parseErrPacket(data []byte) error {
+    if len(data) < 3 {
+        return &mysql.MySQLError{Message: "malformed error packet"}
+    }
+    myErr := &mysql.MySQLError{Number: binary.LittleEndian.Uint16(data[1:3])}
+    msg := data[3:]
+    if len(msg) > 0 && msg[0] == '#' && len(msg) >= 6 {
+        copy(myErr.SQLState[:], msg[1:6])
+        msg = msg[6:]
+    }
+    myErr.Message = string(msg)
+    return myErr
+}
+
+// authResponse computes the auth data for a plugin. The password is only sent in clear over
+// TLS, or with mysql_clear_password when --allow-cleartext permits it.
+func authResponse(plugin string, scramble []byte, pass string, secure, allowCleartext bool) ([]byte, error) {
+    switch plugin {
+    case "mysql_native_password":
+        return scrambleNativePassword(scramble, pass), nil
+    case "caching_sha2_password":
+        return scrambleSHA256Password(scramble, pass), nil
+    case "mysql_old_password":
+        return append(scrambleOldPassword(scramble, pass), 0), nil
+    case "mysql_clear_password":
+        if secure || allowCleartext {
+            return append([]byte(pass), 0), nil
+        }
+    case "sha256_password":
+        if secure {
+            return append([]byte(pass), 0), nil
+        }
+    }
+    return nil, fmt.Errorf("%w: unsupported auth plugin %s", errBatchFallback, plugin)
+}
+
+// scrambleNativePassword computes SHA1(pass) XOR SHA1(scramble + SHA1(SHA1(pass)))
+func scrambleNativePassword(scramble []byte, pass string) []byte {
+    if pass == "" {
+        return nil
+    }
+    stage1 := sha1.Sum([]byte(pass))
+    stage2 := sha1.Sum(stage1[:])
+    h := sha1.New()
+    h.Write(scramble)
+    h.Write(stage2[:])
+    result := h.Sum(nil)
+    for i := range result {
+        result[i] ^= stage1[i]
+    }
+    return result
+}
+
+// scrambleSHA256Password computes SHA256(pass) XOR SHA256(SHA256(SHA256(pass)) + scramble)
+func scrambleSHA256Password(scramble []byte, pass string) []byte {
+    if pass == "" {
+        return nil
+    }
+    m1 := sha256.Sum256([]byte(pass))
+    m2 := sha256.Sum256(m1[:])
+    h := sha256.New()
+    h.Write(m2[:])
+    h.Write(scramble)
+    result := h.Sum(nil)
+    for i := range result {
+        result[i] ^= m1[i]
+    }
+    return result
+}
+
+// encryptPassword encrypts the scrambled password with the server's RSA public key
+func encryptPassword(keyPEM, scramble []byte, pass string) ([]byte, error) {
+    block, _ := pem.Decode(keyPEM)
+    if block == nil {
+        return nil, fmt.Errorf("%w: invalid server public key", errBatchFallback)
+    }
+    key, err := x509.ParsePKIXPublicKey(block.Bytes)
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", errBatchFallback, err)
+    }
+    rsaKey, ok := key.(*rsa.PublicKey)
+    if !ok {
+        return nil, fmt.Errorf("%w: server public key is not RSA", errBatchFallback)
+    }
+
+    plain := append([]byte(pass), 0)
+    for i := range plain {
+        plain[i] ^= scramble[i%len(scramble)]
+    }
+    return rsa.EncryptOAEP(sha1.New(), rand.Reader, rsaKey, plain, nil)
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+    "context"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// TestBatchedHitCountedOnce checks that a credential found over a batched connection and replayed
+// over a regular one counts as a single attempt
+func TestBatchedHitCountedOnce(t *testing.T) {
+    server, err := newFakeServer(selftestVersion)
+    if err != nil {
+        t.Fatalf("starting fake server: %v", err)
+    }
+    defer server.close()
+    server.addUser("root", "toor")
+    server.addUser("app", "secret")
+
+    host, port, _ := strings.Cut(server.addr(), ":")
+    opts := DefaultOptions()
+    opts.Host = host
+    opts.Port, _ = strconv.Atoi(port)
+    opts.SkipSSL = true
+    opts.BatchAuth = 10
+    opts.MonitorCreds = "root:toor"
+    e, err := beginRun(opts, nil)
+    if err != nil {
+        t.Fatalf("setting up run: %v", err)
+    }
+
+    var batch *authConn
+    result, concluded := e.batchTestLogin(context.Background(), &batch, "app", "secret", nil)
+    if batch != nil {
+        batch.close()
+    }
+    if result == "" || !concluded {
+        t.Fatalf("app:secret was not found over the batched connection (concluded %v)", concluded)
+    }
+    if n := e.getTargetAttempts()[server.addr()]; n != 1 {
+        t.Errorf("the hit counted as %d attempts, want 1", n)
+    }
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strings"
+)
+
+// Backends sqlblaster recognizes behind the MySQL wire protocol
+const (
+    backendMySQL       = "MySQL"
+    backendMariaDB     = "MariaDB"
+    backendTiDB        = "TiDB"
+    backendVitess      = "Vitess"
+    backendPlanetScale = "PlanetScale"
+    backendSingleStore = "SingleStore"
+    backendClickHouse  = "ClickHouse"
+)
+
+// backendSystemDBs lists the extra internal schemas of each compatible backend, skipped during dumps
+var backendSystemDBs = map[string][]string{
+    backendTiDB:        {"metrics_schema", "inspection_schema"},
+    backendVitess:      {"_vt"},
+    backendPlanetScale: {"_vt"},
+    backendSingleStore: {"memsql", "cluster"},
+    backendClickHouse:  {"system"},
+}
+
+// detectBackend names the system behind a server from its VERSION() and @@version_comment
+func detectBackend(version, comment string) string {
+    v := strings.ToLower(version + " " + comment)
+    switch {
+    case strings.Contains(v, "tidb"):
+        return backendTiDB
+    case strings.Contains(v, "planetscale"):
+        return backendPlanetScale
+    case strings.Contains(v, "vitess"):
+        return backendVitess
+    case strings.Contains(v, "singlestore"), strings.Contains(v, "memsql"):
+        return backendSingleStore
+    case strings.Contains(v, "clickhouse"), isClickHouseVersion(version):
+        return backendClickHouse
+    case isMariaDB(v):
+        return backendMariaDB
+    }
+    return backendMySQL
+}
+
+// isClickHouseVersion reports whether a bare VERSION() looks like ClickHouse's year.month.patch.build
+// numbering (e.g. 23.8.2.7) rather than a MySQL version
+func isClickHouseVersion(version string) bool {
+    parts := strings.Split(strings.TrimSpace(version), ".")
+    if len(parts) != 4 {
+        return false
+    }
+    for _, p := range parts {
+        if p == "" || strings.Trim(p, "0123456789") != "" {
+            return false
+        }
+    }
+    return true
+}
+
+// queryBackend asks the server for its version strings and names the backend, returning an
+// empty string when the version cannot be read
+func (e *Engine) queryBackend(ctx context.Context, db *sql.DB) string {
+    var version string
+    var comment sql.NullString
+    if err := db.QueryRowContext(ctx, "SELECT VERSION(), @@version_comment").Scan(&version, &comment); err != nil {
+        // Vitess and ClickHouse reject some system variables, so fall back to the version alone
+        if err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+            e.verbosePrintln("Error detecting backend:", err)
+            return ""
+        }
+    }
+    return detectBackend(version, comment.String)
+}
+
+// isVitessBackend reports whether the backend routes queries through vtgate
+func isVitessBackend(backend string) bool {
+    return backend == backendVitess || backend == backendPlanetScale
+}
+
+// supportsShowGrants reports whether SHOW GRANTS returns the account's privileges on the backend
+func supportsShowGrants(backend string) bool {
+    return !isVitessBackend(backend)
+}
+
+// isBackendSystemDB checks if a database is an internal schema of the backend
+func isBackendSystemDB(backend, name string) bool {
+    name = strings.ToLower(name)
+    for _, sysDB := range backendSystemDBs[backend] {
+        if name == sysDB {
+            return true
+        }
+    }
+    return false
+}
+
+// databasesQuery returns the query listing databases on the backend
+func databasesQuery(backend string) string {
+    if backend == backendClickHouse {
+        return "SELECT name FROM system.databases"
+    }
+    return "SHOW DATABASES"
+}
+
+// tablesQuery returns the query listing the tables of a database on the backend
+func tablesQuery(backend, dbName string) string {
+    if backend == backendClickHouse {
+        return "SELECT name FROM system.tables WHERE database = " + quoteSQLString(dbName)
+    }
+    return fmt.Sprintf("SHOW TABLES FROM `%s`", dbName)
+}
+
+// currentUserQuery returns the query for the session and effective user on the backend
+func currentUserQuery(backend string) string {
+    if backend == backendClickHouse {
+        return "SELECT currentUser(), currentUser()"
+    }
+    return "SELECT USER(), CURRENT_USER()"
+}
+
+// showCreateTable fetches a table's CREATE statement on the backend
+func showCreateTable(ctx context.Context, db *sql.DB, backend, dbName, tableName string) (string, error) {
+    var createStmt string
+    if backend == backendClickHouse {
+        // ClickHouse's MySQL interface has no prepared statements, so quote the names inline
+        err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT create_table_query FROM system.tables WHERE database = %s AND name = %s",
+            quoteSQLString(dbName), quoteSQLString(tableName))).Scan(&createStmt)
+        return createStmt, err
+    }
+    var name string
+    err := db.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE TABLE `%s`.`%s`", dbName, tableName)).Scan(&name, &createStmt)
+    return createStmt, err
+}
+
+// backendDSNParams returns extra DSN parameters the dump connection needs on the backend
+func backendDSNParams(backend string) string {
+    if isVitessBackend(backend) {
+        // vtgate caps OLTP result sets, OLAP mode streams whole tables
+        return "&workload=%27olap%27"
+    }
+    return ""
+}
+
+// enumerateBackend adds checks specific to MySQL-compatible distributed backends
+func (e *Engine) enumerateBackend(ctx context.Context, db *sql.DB, backend string, output *strings.Builder) {
+    switch backend {
+    case backendTiDB:
+        e.verbosePrintln("TiDB detected, running TiDB-specific enumeration")
+        output.WriteString("\nTiDB:\n")
+        var tidbVersion string
+        if err := db.QueryRowContext(ctx, "SELECT tidb_version()").Scan(&tidbVersion); err == nil {
+            output.WriteString("  " + strings.ReplaceAll(strings.TrimSpace(tidbVersion), "\n", "\n  ") + "\n")
+        }
+        e.enumRows(ctx, db, output, "Cluster Members", "tidb_cluster_member",
+            "SELECT TYPE, INSTANCE, STATUS_ADDRESS, VERSION FROM information_schema.CLUSTER_INFO",
+            []string{"type", "instance", "status_address", "version"})
+    case backendVitess, backendPlanetScale:
+        e.verbosePrintln(backend + " detected, running Vitess-specific enumeration")
+        output.WriteString("\n" + backend + ":\n")
+        output.WriteString("  Queries are routed by vtgate: databases are keyspaces and may be sharded\n")
+        e.enumRows(ctx, db, output, "Shards", "vitess_shard", "SHOW VITESS_SHARDS", []string{"shard"})
+        e.enumRows(ctx, db, output, "Tablets", "vitess_tablet",
+            "SHOW VITESS_TABLETS",
+            []string{"cell", "keyspace", "shard", "type", "state", "alias", "hostname", "primary_term_start"})
+    case backendSingleStore:
+        e.verbosePrintln("SingleStore detected, running SingleStore-specific enumeration")
+        output.WriteString("\nSingleStore:\n")
+        var memsqlVersion string
+        if err := db.QueryRowContext(ctx, "SELECT @@memsql_version").Scan(&memsqlVersion); err == nil {
+            output.WriteString("  Engine Version: " + memsqlVersion + "\n")
+        }
+        e.enumRows(ctx, db, output, "Cluster Nodes", "singlestore_node",
+            "SELECT IP_ADDR, PORT, TYPE, STATE FROM information_schema.MV_NODES",
+            []string{"host", "port", "type", "state"})
+    case backendClickHouse:
+        e.verbosePrintln("ClickHouse detected, running ClickHouse-specific enumeration")
+        output.WriteString("\nClickHouse:\n")
+        output.WriteString("  Queries run as ClickHouse SQL through its MySQL interface\n")
+        e.enumRows(ctx, db, output, "Users", "clickhouse_user",
+            "SELECT name, toString(auth_type), arrayStringConcat(host_ip, ',') FROM system.users",
+            []string{"user", "auth_type", "host_ip"})
+        e.enumRows(ctx, db, output, "Clusters", "clickhouse_cluster",
+            "SELECT cluster, host_name, toString(port) FROM system.clusters",
+            []string{"cluster", "host", "port"})
+        // These engines reach other servers, object storage or the filesystem
+        e.enumRows(ctx, db, output, "External Tables", "clickhouse_external_table",
+            "SELECT database, name, engine FROM system.tables WHERE engine IN ('MySQL', 'PostgreSQL', 'MongoDB', 'URL', 'S3', 'HDFS', 'File', 'Distributed')",
+            []string{"database", "table", "engine"})
+    }
+}
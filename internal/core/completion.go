@@ -0,0 +1,202 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// completionTimeout bounds each schema lookup made while completing, so Tab never hangs the shell
+const completionTimeout = 2 * time.Second
+
+// sqlKeywords are completed in interactive mode alongside schema names
+var sqlKeywords = []string{
+    "ALTER", "AND", "AS", "ASC", "BETWEEN", "BY", "CREATE", "DATABASE", "DATABASES", "DELETE",
+    "DESC", "DESCRIBE", "DISTINCT", "DROP", "EXPLAIN", "FROM", "FUNCTION", "GRANT", "GRANTS", "GROUP",
+    "HAVING", "IN", "INDEX", "INFILE", "INNER", "INSERT", "INTO", "IS", "JOIN", "LEFT", "LIKE",
+    "LIMIT", "LOAD_FILE", "NOT", "NULL", "OFFSET", "ON", "OR", "ORDER", "OUTFILE", "PROCEDURE",
+    "PROCESSLIST", "REVOKE", "RIGHT", "SELECT", "SET", "SHOW", "STATUS", "TABLE", "TABLES",
+    "TRIGGER", "UNION", "UPDATE", "USE", "USER", "VALUES", "VARIABLES", "VIEW", "WHERE",
+}
+
+// sqlCompleter completes SQL keywords and the database, table and column names of the
+// connection, caching what it fetches from information_schema
+type sqlCompleter struct {
+    engine    *Engine
+    ctx       context.Context
+    db        *sql.DB
+    currentDB *string
+
+    mu        sync.Mutex
+    databases []string
+    tables    map[string][]string
+    columns   map[string]map[string][]string
+}
+
+// newSQLCompleter creates a completer for db; currentDB tracks the shell's USE database
+func (e *Engine) newSQLCompleter(ctx context.Context, db *sql.DB, currentDB *string) *sqlCompleter {
+    return &sqlCompleter{
+        engine:    e,
+        ctx:       ctx,
+        db:        db,
+        currentDB: currentDB,
+        tables:    make(map[string][]string),
+        columns:   make(map[string]map[string][]string),
+    }
+}
+
+// invalidate drops the cached schema, e.g. after CREATE or DROP
+func (c *sqlCompleter) invalidate() {
+    c.mu.Lock()
+    c.databases = nil
+    c.tables = make(map[string][]string)
+    c.columns = make(map[string]map[string][]string)
+    c.mu.Unlock()
+}
+
+// complete is a liner word completer: it replaces the identifier before pos with each candidate
+func (c *sqlCompleter) complete(line string, pos int) (string, []string, string) {
+    start := pos
+    for start > 0 && isIdentChar(line[start-1]) {
+        start--
+    }
+    head, word, tail := line[:start], line[start:pos], line[pos:]
+    if word == "" {
+        return head, nil, tail
+    }
+
+    var candidates []string
+    if dot := strings.LastIndex(word, "."); dot >= 0 {
+        // db.table or table.column
+        qualifier, prefix := word[:dot], word[dot+1:]
+        names := c.tablesOf(qualifier)
+        names = append(names, c.columnsOf(*c.currentDB)[qualifier]...)
+        for _, name := range matchPrefix(names, prefix) {
+            candidates = append(candidates, qualifier+"."+name)
+        }
+    } else {
+        lower := strings.ToLower(word) == word
+        for _, kw := range matchPrefix(sqlKeywords, word) {
+            if lower {
+                kw = strings.ToLower(kw)
+            }
+            candidates = append(candidates, kw)
+        }
+        candidates = append(candidates, matchPrefix(c.databaseNames(), word)...)
+        if *c.currentDB != "" {
+            candidates = append(candidates, matchPrefix(c.tablesOf(*c.currentDB), word)...)
+            for _, cols := range c.columnsOf(*c.currentDB) {
+                candidates = append(candidates, matchPrefix(cols, word)...)
+            }
+        }
+    }
+
+    return head, uniqueSorted(candidates), tail
+}
+
+// databaseNames returns the cached database list, fetching it on first use
+func (c *sqlCompleter) databaseNames() []string {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.databases == nil {
+        c.databases = c.queryNames("SELECT SCHEMA_NAME FROM information_schema.SCHEMATA")
+    }
+    return c.databases
+}
+
+// tablesOf returns the cached tables of dbName, fetching them on first use
+func (c *sqlCompleter) tablesOf(dbName string) []string {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    tables, ok := c.tables[dbName]
+    if !ok {
+        tables = c.queryNames("SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ?", dbName)
+        c.tables[dbName] = tables
+    }
+    return tables
+}
+
+// columnsOf returns the cached columns of every table in dbName, keyed by table
+func (c *sqlCompleter) columnsOf(dbName string) map[string][]string {
+    if dbName == "" {
+        return nil
+    }
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if cols, ok := c.columns[dbName]; ok {
+        return cols
+    }
+
+    cols := make(map[string][]string)
+    ctx, cancel := context.WithTimeout(c.ctx, completionTimeout)
+    defer cancel()
+    rows, err := c.db.QueryContext(ctx, "SELECT TABLE_NAME, COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ?", dbName)
+    if err == nil {
+        defer rows.Close()
+        for rows.Next() {
+            var table, column string
+            if rows.Scan(&table, &column) == nil {
+                cols[table] = append(cols[table], column)
+            }
+        }
+    } else {
+        c.engine.verbosePrintln("Completion: failed to fetch columns:", err)
+    }
+    c.columns[dbName] = cols
+    return cols
+}
+
+// queryNames runs a single-column query and returns its values, or nil on error
+func (c *sqlCompleter) queryNames(query string, args ...interface{}) []string {
+    ctx, cancel := context.WithTimeout(c.ctx, completionTimeout)
+    defer cancel()
+
+    rows, err := c.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        c.engine.verbosePrintln("Completion: schema lookup failed:", err)
+        return []string{}
+    }
+    defer rows.Close()
+
+    names := []string{}
+    for rows.Next() {
+        var name string
+        if rows.Scan(&name) == nil {
+            names = append(names, name)
+        }
+    }
+    return names
+}
+
+// isIdentChar reports whether b can be part of a (possibly qualified) SQL identifier
+func isIdentChar(b byte) bool {
+    return b == '_' || b == '$' || b == '.' ||
+        (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// matchPrefix returns the names starting with prefix, ignoring case
+func matchPrefix(names []string, prefix string) []string {
+    var matches []string
+    lower := strings.ToLower(prefix)
+    for _, name := range names {
+        if strings.HasPrefix(strings.ToLower(name), lower) {
+            matches = append(matches, name)
+        }
+    }
+    return matches
+}
+
+// uniqueSorted sorts names and removes duplicates
+func uniqueSorted(names []string) []string {
+    sort.Strings(names)
+    out := names[:0]
+    for i, name := range names {
+        if i == 0 || name != names[i-1] {
+            out = append(out, name)
+        }
+    }
+    return out
+}
@@ -0,0 +1,651 @@
+package core
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+
+    "github.com/mitchellh/mapstructure"
+)
+
+// createSampleConfig generates a sample config.json file
+func (e *Engine) createSampleConfig() {
+    e.verbosePrintln("Creating sample configuration file")
+    sampleConfig := Options{
+        Host:            "mysql.server.com",
+        Port:            3306,
+        SingleUser:      "admin",
+        UserList:        "users.txt",
+        SinglePass:      "pass123",
+        PassList:        "pass.txt",
+        Verbose:         true,
+        FirstOnly:       false,
+        UserFirst:       false,
+        ExecCmd:         "SHOW DATABASES;",
+        AllowDangerous:  false,
+        LogFile:         "results.log",
+        UseSSL:          false,
+        Workers:         10,
+        Sources:         "",
+        MaxConnFraction: 0,
+        MonitorCreds:    "",
+        Enum:            false,
+        EnumOutputFile:  "enum_results.txt",
+        Dump:            false,
+        DumpDir:         "mysql_dump",
+        QuietDump:       false,
+        MaxRowsPerFile:  10000,
+        ResultsDB:       "results.sqlite",
+        Lang:            "en",
+        Theme:           "default",
+        ThemeColors: map[string]string{
+            "success": "green",
+            "warning": "yellow",
+            "error":   "red",
+        },
+        SARIFFile:          "findings.sarif",
+        PushDefectDojo:     "",
+        PushFaraday:        "",
+        APIKey:             "",
+        PushEngagement:     "",
+        JUnitFile:          "",
+        DebugCrash:         false,
+        MaxMemory:          "",
+        BatchAuth:          0,
+        NoTLSResume:        false,
+        Resolver:           "",
+        DNSTTL:             300,
+        Output:             "text",
+        HostsFile:          "",
+        Rate:               0,
+        Delay:              0,
+        OnSuccess:          "",
+        HookTimeout:        30,
+        OnErrorThreshold:   "",
+        ErrorThreshold:     0.5,
+        DumpFormat:         "csv",
+        DumpCompress:       false,
+        LootHashes:         false,
+        LootDir:            "loot",
+        EnumFull:           false,
+        VerifyQuery:        "",
+        DumpInclude:        "",
+        DumpExclude:        "",
+        Spray:              false,
+        SprayInterval:      1800,
+        MaxAttemptsPerUser: 0,
+        Campaign:           "",
+        Mutate:             false,
+        Company:            "",
+        Rules:              "",
+        AttemptTimeout:     "10s",
+        ReadTimeout:        "",
+        WriteTimeout:       "",
+        KeepAlive:          60,
+        ConnectTimeout:     "10s",
+        QueryTimeout:       "30s",
+        SessionVars:        "sql_mode='NO_ENGINE_SUBSTITUTION',net_read_timeout=600,net_write_timeout=600,max_execution_time=0,max_statement_time=0,group_concat_max_len=1048576",
+        CommonPasswords:    "",
+        DeployUDF:          false,
+        UDFDir:             "udf",
+        PrivescCheck:       false,
+        InventoryFile:      "",
+        VaultPasswordFile:  "",
+        FindSecrets:        false,
+        SecretDetectors:    "",
+        DumpBatchSize:      10000,
+        CredCSV:            "",
+        ConnAttrs:          "",
+        SummaryOnly:        false,
+        Discover:           "",
+        DiscoverPorts:      defaultDiscoverPorts,
+        ProveOnly:          false,
+        IPVersion:          0,
+        Follow:             false,
+        TLSCert:            "",
+        TLSKey:             "",
+        TLSCA:              "",
+        Defaults:           false,
+        DefaultsFile:       "",
+        ShredAfter:         "",
+        DataVersion:        "",
+        SkipKnown:          false,
+        Retries:            2,
+        BlockPause:         "",
+        CSVDelimiter:       ",",
+        CSVNullString:      "NULL",
+        SessionDir:         "sessions",
+        Pager:              "",
+        RowLimit:           1000,
+        MaxColWidth:        0,
+        ExecParams:         false,
+        Params:             nil,
+        Policy:             "",
+        AuditLog:           "",
+        ReadOnly:           false,
+        AuthPlugin:         "",
+        AllowCleartext:     false,
+        DumpSchemaOnly:     false,
+        DumpDataOnly:       false,
+        DumpWhere:          nil,
+        DumpEncrypt:        "",
+        MaxBytes:           "",
+        MaxBytesPerTable:   "",
+        BlobMode:           "raw",
+    }
+
+    file, err := os.Create("config.json")
+    if err != nil {
+        e.printError("Error creating config file: %v", err)
+        os.Exit(1)
+    }
+    defer file.Close()
+
+    encoder := json.NewEncoder(file)
+    encoder.SetIndent("", "  ")
+    if err := encoder.Encode(sampleConfig); err != nil {
+        e.printError("Error encoding config file: %v", err)
+        os.Exit(1)
+    }
+
+    fmt.Fprintln(e.out, "Sample config file 'config.json' created. Please adjust the values and remove this message.")
+    e.verbosePrintln("Sample config file created successfully")
+}
+
+// loadConfig loads settings from a JSON file
+func (e *Engine) loadConfig(filename string) {
+    e.verbosePrintln("Loading configuration from file:", filename)
+    file, err := os.Open(filename)
+    if err != nil {
+        e.printError("Error opening config file: %v", err)
+        os.Exit(1)
+    }
+    defer file.Close()
+
+    var fileConfig map[string]interface{}
+    decoder := json.NewDecoder(file)
+    if err := decoder.Decode(&fileConfig); err != nil {
+        e.printError("Error decoding config file: %v", err)
+        os.Exit(1)
+    }
+
+    // Use mapstructure to convert map to struct
+    // Only overwrite values that aren't set by command line
+    var newCfg Options
+    if err := mapstructure.Decode(fileConfig, &newCfg); err != nil {
+        e.printError("Error mapping config values: %v", err)
+        os.Exit(1)
+    }
+
+    // Only apply values from config file that weren't set via command line
+    if e.opts.Host == "" {
+        e.opts.Host = newCfg.Host
+        e.verbosePrintln("Using host from config:", e.opts.Host)
+    }
+    if e.opts.Port == 3306 && newCfg.Port != 0 {
+        e.opts.Port = newCfg.Port
+        e.verbosePrintln("Using port from config:", e.opts.Port)
+    }
+    if e.opts.SingleUser == "" && newCfg.SingleUser != "" {
+        e.opts.SingleUser = newCfg.SingleUser
+        e.verbosePrintln("Using single user from config:", e.opts.SingleUser)
+    }
+    if e.opts.UserList == "" && newCfg.UserList != "" {
+        e.opts.UserList = newCfg.UserList
+        e.verbosePrintln("Using user list from config:", e.opts.UserList)
+    }
+    if e.opts.SinglePass == "" && newCfg.SinglePass != "" {
+        e.opts.SinglePass = newCfg.SinglePass
+        e.verbosePrintln("Using single password from config:", e.opts.SinglePass)
+    }
+    if e.opts.PassList == "" && newCfg.PassList != "" {
+        e.opts.PassList = newCfg.PassList
+        e.verbosePrintln("Using password list from config:", e.opts.PassList)
+    }
+    if !e.opts.Verbose && newCfg.Verbose {
+        e.opts.Verbose = newCfg.Verbose
+        e.verbosePrintln("Enabling verbose mode from config")
+    }
+    if !e.opts.FirstOnly && newCfg.FirstOnly {
+        e.opts.FirstOnly = newCfg.FirstOnly
+        e.verbosePrintln("Enabling first-only mode from config")
+    }
+    if !e.opts.UserFirst && newCfg.UserFirst {
+        e.opts.UserFirst = newCfg.UserFirst
+        e.verbosePrintln("Enabling user-first strategy from config")
+    }
+    if e.opts.ExecCmd == "SHOW DATABASES;" && newCfg.ExecCmd != "" {
+        e.opts.ExecCmd = sanitizeCommand(newCfg.ExecCmd)
+        e.verbosePrintln("Using command from config:", e.opts.ExecCmd)
+    }
+    if !e.opts.AllowDangerous && newCfg.AllowDangerous {
+        e.opts.AllowDangerous = newCfg.AllowDangerous
+        e.verbosePrintln("Enabling dangerous command execution from config")
+    }
+    if e.opts.LogFile == "" && newCfg.LogFile != "" {
+        e.opts.LogFile = newCfg.LogFile
+        e.verbosePrintln("Using log file from config:", e.opts.LogFile)
+    }
+    if !e.opts.UseSSL && newCfg.UseSSL {
+        e.opts.UseSSL = newCfg.UseSSL
+        e.verbosePrintln("Enabling SSL from config")
+    }
+    if !e.opts.SkipSSL && newCfg.SkipSSL {
+        e.opts.SkipSSL = newCfg.SkipSSL
+        e.verbosePrintln("Skipping SSL from config")
+    }
+    if e.opts.Workers == 10 && newCfg.Workers != 0 {
+        e.opts.Workers = newCfg.Workers
+        e.verbosePrintln("Using worker count from config:", e.opts.Workers)
+    }
+    if e.opts.MaxConnFraction == 0 && newCfg.MaxConnFraction != 0 {
+        e.opts.MaxConnFraction = newCfg.MaxConnFraction
+        e.verbosePrintln("Using max connection fraction from config:", e.opts.MaxConnFraction)
+    }
+    if e.opts.MonitorCreds == "" && newCfg.MonitorCreds != "" {
+        e.opts.MonitorCreds = newCfg.MonitorCreds
+        e.verbosePrintln("Using monitor credentials from config")
+    }
+    if e.opts.Sources == "" && newCfg.Sources != "" {
+        e.opts.Sources = newCfg.Sources
+        e.verbosePrintln("Using sources from config:", e.opts.Sources)
+    }
+    if !e.opts.Enum && newCfg.Enum {
+        e.opts.Enum = newCfg.Enum
+        e.verbosePrintln("Enabling enumeration from config")
+    }
+    if e.opts.EnumOutputFile == "" && newCfg.EnumOutputFile != "" {
+        e.opts.EnumOutputFile = newCfg.EnumOutputFile
+        e.verbosePrintln("Using enumeration output file from config:", e.opts.EnumOutputFile)
+    }
+    if !e.opts.Dump && newCfg.Dump {
+        e.opts.Dump = newCfg.Dump
+        e.verbosePrintln("Enabling database dump from config")
+    }
+    if e.opts.DumpDir == "mysql_dump" && newCfg.DumpDir != "" {
+        e.opts.DumpDir = newCfg.DumpDir
+        e.verbosePrintln("Using dump directory from config:", e.opts.DumpDir)
+    }
+    if !e.opts.QuietDump && newCfg.QuietDump {
+        e.opts.QuietDump = newCfg.QuietDump
+        e.verbosePrintln("Enabling quiet dump mode from config")
+    }
+    if e.opts.MaxRowsPerFile == 10000 && newCfg.MaxRowsPerFile != 0 {
+        e.opts.MaxRowsPerFile = newCfg.MaxRowsPerFile
+        e.verbosePrintln("Using max rows per file from config:", e.opts.MaxRowsPerFile)
+    }
+    if e.opts.Lang == "" && newCfg.Lang != "" {
+        e.opts.Lang = newCfg.Lang
+        e.verbosePrintln("Using language from config:", e.opts.Lang)
+    }
+    if e.opts.Theme == "" && newCfg.Theme != "" {
+        e.opts.Theme = newCfg.Theme
+        e.verbosePrintln("Using color theme from config:", e.opts.Theme)
+    }
+    if e.opts.ThemeColors == nil && newCfg.ThemeColors != nil {
+        e.opts.ThemeColors = newCfg.ThemeColors
+        e.verbosePrintln("Using theme color overrides from config")
+    }
+    if e.opts.ResultsDB == "" && newCfg.ResultsDB != "" {
+        e.opts.ResultsDB = newCfg.ResultsDB
+        e.verbosePrintln("Using results database from config:", e.opts.ResultsDB)
+    }
+    if e.opts.SARIFFile == "" && newCfg.SARIFFile != "" {
+        e.opts.SARIFFile = newCfg.SARIFFile
+        e.verbosePrintln("Using SARIF export file from config:", e.opts.SARIFFile)
+    }
+    if e.opts.PushDefectDojo == "" && newCfg.PushDefectDojo != "" {
+        e.opts.PushDefectDojo = newCfg.PushDefectDojo
+        e.verbosePrintln("Using DefectDojo URL from config:", e.opts.PushDefectDojo)
+    }
+    if e.opts.PushFaraday == "" && newCfg.PushFaraday != "" {
+        e.opts.PushFaraday = newCfg.PushFaraday
+        e.verbosePrintln("Using Faraday URL from config:", e.opts.PushFaraday)
+    }
+    if e.opts.APIKey == "" && newCfg.APIKey != "" {
+        e.opts.APIKey = newCfg.APIKey
+        e.verbosePrintln("Using API key from config")
+    }
+    if e.opts.PushEngagement == "" && newCfg.PushEngagement != "" {
+        e.opts.PushEngagement = newCfg.PushEngagement
+        e.verbosePrintln("Using push engagement from config:", e.opts.PushEngagement)
+    }
+    if e.opts.JUnitFile == "" && newCfg.JUnitFile != "" {
+        e.opts.JUnitFile = newCfg.JUnitFile
+        e.verbosePrintln("Using JUnit report file from config:", e.opts.JUnitFile)
+    }
+
+    if !e.opts.DebugCrash && newCfg.DebugCrash {
+        e.opts.DebugCrash = newCfg.DebugCrash
+        e.verbosePrintln("Using debug crash setting from config:", e.opts.DebugCrash)
+    }
+    if e.opts.MaxMemory == "" && newCfg.MaxMemory != "" {
+        e.opts.MaxMemory = newCfg.MaxMemory
+        e.verbosePrintln("Using memory limit from config:", e.opts.MaxMemory)
+    }
+    if e.opts.BatchAuth == 0 && newCfg.BatchAuth != 0 {
+        e.opts.BatchAuth = newCfg.BatchAuth
+        e.verbosePrintln("Using batch auth size from config:", e.opts.BatchAuth)
+    }
+    if !e.opts.NoTLSResume && newCfg.NoTLSResume {
+        e.opts.NoTLSResume = newCfg.NoTLSResume
+        e.verbosePrintln("Using TLS resumption setting from config:", !e.opts.NoTLSResume)
+    }
+    if e.opts.Resolver == "" && newCfg.Resolver != "" {
+        e.opts.Resolver = newCfg.Resolver
+        e.verbosePrintln("Using DNS resolver from config:", e.opts.Resolver)
+    }
+    if e.opts.DNSTTL == 300 && newCfg.DNSTTL != 0 {
+        e.opts.DNSTTL = newCfg.DNSTTL
+        e.verbosePrintln("Using DNS cache TTL from config:", e.opts.DNSTTL)
+    }
+    if e.opts.Output == "" && newCfg.Output != "" {
+        e.opts.Output = newCfg.Output
+        e.verbosePrintln("Using output format from config:", e.opts.Output)
+    }
+    if e.opts.HostsFile == "" && newCfg.HostsFile != "" {
+        e.opts.HostsFile = newCfg.HostsFile
+        e.verbosePrintln("Using hosts file from config:", e.opts.HostsFile)
+    }
+    if e.opts.Rate == 0 && newCfg.Rate != 0 {
+        e.opts.Rate = newCfg.Rate
+        e.verbosePrintln("Using rate limit from config:", e.opts.Rate)
+    }
+    if e.opts.Delay == 0 && newCfg.Delay != 0 {
+        e.opts.Delay = newCfg.Delay
+        e.verbosePrintln("Using attempt delay from config:", e.opts.Delay)
+    }
+    if e.opts.OnSuccess == "" && newCfg.OnSuccess != "" {
+        e.opts.OnSuccess = newCfg.OnSuccess
+        e.verbosePrintln("Using on-success command from config:", e.opts.OnSuccess)
+    }
+    if e.opts.HookTimeout == 30 && newCfg.HookTimeout != 0 {
+        e.opts.HookTimeout = newCfg.HookTimeout
+        e.verbosePrintln("Using hook timeout from config:", e.opts.HookTimeout)
+    }
+    if e.opts.OnErrorThreshold == "" && newCfg.OnErrorThreshold != "" {
+        e.opts.OnErrorThreshold = newCfg.OnErrorThreshold
+        e.verbosePrintln("Using on-error-threshold command from config:", e.opts.OnErrorThreshold)
+    }
+    if e.opts.ErrorThreshold == 0.5 && newCfg.ErrorThreshold != 0 {
+        e.opts.ErrorThreshold = newCfg.ErrorThreshold
+        e.verbosePrintln("Using error threshold from config:", e.opts.ErrorThreshold)
+    }
+    if e.opts.DumpFormat == "csv" && newCfg.DumpFormat != "" {
+        e.opts.DumpFormat = newCfg.DumpFormat
+        e.verbosePrintln("Using dump format from config:", e.opts.DumpFormat)
+    }
+    if !e.opts.DumpCompress && newCfg.DumpCompress {
+        e.opts.DumpCompress = newCfg.DumpCompress
+        e.verbosePrintln("Using dump compression from config:", e.opts.DumpCompress)
+    }
+    if !e.opts.LootHashes && newCfg.LootHashes {
+        e.opts.LootHashes = newCfg.LootHashes
+        e.verbosePrintln("Using hash extraction from config:", e.opts.LootHashes)
+    }
+    if e.opts.LootDir == "loot" && newCfg.LootDir != "" {
+        e.opts.LootDir = newCfg.LootDir
+        e.verbosePrintln("Using loot directory from config:", e.opts.LootDir)
+    }
+    if !e.opts.EnumFull && newCfg.EnumFull {
+        e.opts.EnumFull = newCfg.EnumFull
+        e.verbosePrintln("Using full enumeration output from config:", e.opts.EnumFull)
+    }
+    if e.opts.VerifyQuery == "" && newCfg.VerifyQuery != "" {
+        e.opts.VerifyQuery = newCfg.VerifyQuery
+        e.verbosePrintln("Using verification query from config:", e.opts.VerifyQuery)
+    }
+    if e.opts.DumpInclude == "" && newCfg.DumpInclude != "" {
+        e.opts.DumpInclude = newCfg.DumpInclude
+        e.verbosePrintln("Using dump include patterns from config:", e.opts.DumpInclude)
+    }
+    if e.opts.DumpExclude == "" && newCfg.DumpExclude != "" {
+        e.opts.DumpExclude = newCfg.DumpExclude
+        e.verbosePrintln("Using dump exclude patterns from config:", e.opts.DumpExclude)
+    }
+    if !e.opts.Spray && newCfg.Spray {
+        e.opts.Spray = newCfg.Spray
+        e.verbosePrintln("Using spray mode from config:", e.opts.Spray)
+    }
+    if e.opts.SprayInterval == 1800 && newCfg.SprayInterval != 0 {
+        e.opts.SprayInterval = newCfg.SprayInterval
+        e.verbosePrintln("Using spray interval from config:", e.opts.SprayInterval)
+    }
+    if e.opts.MaxAttemptsPerUser == 0 && newCfg.MaxAttemptsPerUser != 0 {
+        e.opts.MaxAttemptsPerUser = newCfg.MaxAttemptsPerUser
+        e.verbosePrintln("Using max attempts per user from config:", e.opts.MaxAttemptsPerUser)
+    }
+    if e.opts.Campaign == "" && newCfg.Campaign != "" {
+        e.opts.Campaign = newCfg.Campaign
+        e.verbosePrintln("Using campaign from config:", e.opts.Campaign)
+    }
+    if !e.opts.Mutate && newCfg.Mutate {
+        e.opts.Mutate = newCfg.Mutate
+        e.verbosePrintln("Using password mutation from config:", e.opts.Mutate)
+    }
+    if e.opts.Company == "" && newCfg.Company != "" {
+        e.opts.Company = newCfg.Company
+        e.verbosePrintln("Using company name from config:", e.opts.Company)
+    }
+    if e.opts.Rules == "" && newCfg.Rules != "" {
+        e.opts.Rules = newCfg.Rules
+        e.verbosePrintln("Using rules file from config:", e.opts.Rules)
+    }
+    if e.opts.AttemptTimeout == "10s" && newCfg.AttemptTimeout != "" {
+        e.opts.AttemptTimeout = newCfg.AttemptTimeout
+        e.verbosePrintln("Using attempt timeout from config:", e.opts.AttemptTimeout)
+    }
+    if e.opts.ReadTimeout == "" && newCfg.ReadTimeout != "" {
+        e.opts.ReadTimeout = newCfg.ReadTimeout
+        e.verbosePrintln("Using read timeout from config:", e.opts.ReadTimeout)
+    }
+    if e.opts.WriteTimeout == "" && newCfg.WriteTimeout != "" {
+        e.opts.WriteTimeout = newCfg.WriteTimeout
+        e.verbosePrintln("Using write timeout from config:", e.opts.WriteTimeout)
+    }
+    if e.opts.KeepAlive == 60 && newCfg.KeepAlive != 0 {
+        e.opts.KeepAlive = newCfg.KeepAlive
+        e.verbosePrintln("Using keep-alive interval from config:", e.opts.KeepAlive)
+    }
+    if e.opts.ConnectTimeout == "10s" && newCfg.ConnectTimeout != "" {
+        e.opts.ConnectTimeout = newCfg.ConnectTimeout
+        e.verbosePrintln("Using connect timeout from config:", e.opts.ConnectTimeout)
+    }
+    if e.opts.QueryTimeout == "30s" && newCfg.QueryTimeout != "" {
+        e.opts.QueryTimeout = newCfg.QueryTimeout
+        e.verbosePrintln("Using query timeout from config:", e.opts.QueryTimeout)
+    }
+    if e.opts.SessionVars == defaultSessionVars && newCfg.SessionVars != "" {
+        e.opts.SessionVars = newCfg.SessionVars
+        e.verbosePrintln("Using session variables from config:", e.opts.SessionVars)
+    }
+    if e.opts.CommonPasswords == "" && newCfg.CommonPasswords != "" {
+        e.opts.CommonPasswords = newCfg.CommonPasswords
+        e.verbosePrintln("Using common passwords list from config:", e.opts.CommonPasswords)
+    }
+    if !e.opts.DeployUDF && newCfg.DeployUDF {
+        e.opts.DeployUDF = newCfg.DeployUDF
+        e.verbosePrintln("UDF deployment enabled from config")
+    }
+    if e.opts.UDFDir == "udf" && newCfg.UDFDir != "" {
+        e.opts.UDFDir = newCfg.UDFDir
+        e.verbosePrintln("Using UDF library directory from config:", e.opts.UDFDir)
+    }
+    if !e.opts.PrivescCheck && newCfg.PrivescCheck {
+        e.opts.PrivescCheck = newCfg.PrivescCheck
+        e.verbosePrintln("Privilege escalation audit enabled from config")
+    }
+    if e.opts.InventoryFile == "" && newCfg.InventoryFile != "" {
+        e.opts.InventoryFile = newCfg.InventoryFile
+        e.verbosePrintln("Using Ansible inventory file from config:", e.opts.InventoryFile)
+    }
+    if e.opts.VaultPasswordFile == "" && newCfg.VaultPasswordFile != "" {
+        e.opts.VaultPasswordFile = newCfg.VaultPasswordFile
+        e.verbosePrintln("Using vault password file from config:", e.opts.VaultPasswordFile)
+    }
+    if !e.opts.FindSecrets && newCfg.FindSecrets {
+        e.opts.FindSecrets = newCfg.FindSecrets
+        e.verbosePrintln("Secret scanning enabled from config")
+    }
+    if e.opts.SecretDetectors == "" && newCfg.SecretDetectors != "" {
+        e.opts.SecretDetectors = newCfg.SecretDetectors
+        e.verbosePrintln("Using secret detectors from config:", e.opts.SecretDetectors)
+    }
+    if e.opts.DumpBatchSize == 10000 && newCfg.DumpBatchSize != 0 {
+        e.opts.DumpBatchSize = newCfg.DumpBatchSize
+        e.verbosePrintln("Using dump batch size from config:", e.opts.DumpBatchSize)
+    }
+    if e.opts.CredCSV == "" && newCfg.CredCSV != "" {
+        e.opts.CredCSV = newCfg.CredCSV
+        e.verbosePrintln("Using credential CSV from config:", e.opts.CredCSV)
+    }
+    if e.opts.ConnAttrs == "" && newCfg.ConnAttrs != "" {
+        e.opts.ConnAttrs = newCfg.ConnAttrs
+        e.verbosePrintln("Using connection attributes from config:", e.opts.ConnAttrs)
+    }
+    if !e.opts.SummaryOnly && newCfg.SummaryOnly {
+        e.opts.SummaryOnly = newCfg.SummaryOnly
+        e.verbosePrintln("Using summary-only output from config:", e.opts.SummaryOnly)
+    }
+    if e.opts.Discover == "" && newCfg.Discover != "" {
+        e.opts.Discover = newCfg.Discover
+        e.verbosePrintln("Using discovery targets from config:", e.opts.Discover)
+    }
+    if e.opts.DiscoverPorts == defaultDiscoverPorts && newCfg.DiscoverPorts != "" {
+        e.opts.DiscoverPorts = newCfg.DiscoverPorts
+        e.verbosePrintln("Using discovery ports from config:", e.opts.DiscoverPorts)
+    }
+    if !e.opts.ProveOnly && newCfg.ProveOnly {
+        e.opts.ProveOnly = newCfg.ProveOnly
+        e.verbosePrintln("Using prove-only mode from config:", e.opts.ProveOnly)
+    }
+    if e.opts.IPVersion == 0 && newCfg.IPVersion != 0 {
+        e.opts.IPVersion = newCfg.IPVersion
+        e.verbosePrintln("Using IP version from config:", e.opts.IPVersion)
+    }
+    if !e.opts.Follow && newCfg.Follow {
+        e.opts.Follow = newCfg.Follow
+        e.verbosePrintln("Using follow mode from config:", e.opts.Follow)
+    }
+    if e.opts.TLSCert == "" && newCfg.TLSCert != "" {
+        e.opts.TLSCert = newCfg.TLSCert
+        e.verbosePrintln("Using TLS client certificate from config:", e.opts.TLSCert)
+    }
+    if e.opts.TLSKey == "" && newCfg.TLSKey != "" {
+        e.opts.TLSKey = newCfg.TLSKey
+        e.verbosePrintln("Using TLS client key from config:", e.opts.TLSKey)
+    }
+    if e.opts.TLSCA == "" && newCfg.TLSCA != "" {
+        e.opts.TLSCA = newCfg.TLSCA
+        e.verbosePrintln("Using TLS CA file from config:", e.opts.TLSCA)
+    }
+    if !e.opts.Defaults && newCfg.Defaults {
+        e.opts.Defaults = newCfg.Defaults
+        e.verbosePrintln("Using default credentials mode from config:", e.opts.Defaults)
+    }
+    if e.opts.DefaultsFile == "" && newCfg.DefaultsFile != "" {
+        e.opts.DefaultsFile = newCfg.DefaultsFile
+        e.verbosePrintln("Using defaults file from config:", e.opts.DefaultsFile)
+    }
+    if e.opts.ShredAfter == "" && newCfg.ShredAfter != "" {
+        e.opts.ShredAfter = newCfg.ShredAfter
+        e.verbosePrintln("Using shred period from config:", e.opts.ShredAfter)
+    }
+    if e.opts.DataVersion == "" && newCfg.DataVersion != "" {
+        e.opts.DataVersion = newCfg.DataVersion
+        e.verbosePrintln("Using pinned data version from config:", e.opts.DataVersion)
+    }
+    if !e.opts.SkipKnown && newCfg.SkipKnown {
+        e.opts.SkipKnown = newCfg.SkipKnown
+        e.verbosePrintln("Using skip-known setting from config:", e.opts.SkipKnown)
+    }
+    if e.opts.Retries == 2 && newCfg.Retries != 0 {
+        e.opts.Retries = newCfg.Retries
+        e.verbosePrintln("Using retries from config:", e.opts.Retries)
+    }
+    if e.opts.BlockPause == "" && newCfg.BlockPause != "" {
+        e.opts.BlockPause = newCfg.BlockPause
+        e.verbosePrintln("Using block pause from config:", e.opts.BlockPause)
+    }
+    if e.opts.CSVDelimiter == "," && newCfg.CSVDelimiter != "" {
+        e.opts.CSVDelimiter = newCfg.CSVDelimiter
+        e.verbosePrintln("Using CSV delimiter from config:", e.opts.CSVDelimiter)
+    }
+    if e.opts.CSVNullString == "NULL" && newCfg.CSVNullString != "" {
+        e.opts.CSVNullString = newCfg.CSVNullString
+        e.verbosePrintln("Using CSV NULL string from config:", e.opts.CSVNullString)
+    }
+    if e.opts.SessionDir == "sessions" && newCfg.SessionDir != "" {
+        e.opts.SessionDir = newCfg.SessionDir
+        e.verbosePrintln("Using session directory from config:", e.opts.SessionDir)
+    }
+    if e.opts.Pager == "" && newCfg.Pager != "" {
+        e.opts.Pager = newCfg.Pager
+        e.verbosePrintln("Using pager from config:", e.opts.Pager)
+    }
+    if e.opts.RowLimit == 1000 && newCfg.RowLimit != 0 {
+        e.opts.RowLimit = newCfg.RowLimit
+        e.verbosePrintln("Using row limit from config:", e.opts.RowLimit)
+    }
+    if e.opts.MaxColWidth == 0 && newCfg.MaxColWidth != 0 {
+        e.opts.MaxColWidth = newCfg.MaxColWidth
+        e.verbosePrintln("Using max column width from config:", e.opts.MaxColWidth)
+    }
+    if newCfg.ExecParams {
+        e.opts.ExecParams = true
+        e.verbosePrintln("Running -e as a prepared statement from config")
+    }
+    if len(e.opts.Params) == 0 && len(newCfg.Params) > 0 {
+        e.opts.Params = newCfg.Params
+        e.verbosePrintln("Using -e parameters from config:", len(e.opts.Params))
+    }
+    if e.opts.Policy == "" && newCfg.Policy != "" {
+        e.opts.Policy = newCfg.Policy
+        e.verbosePrintln("Using statement policy from config:", e.opts.Policy)
+    }
+    if e.opts.AuditLog == "" && newCfg.AuditLog != "" {
+        e.opts.AuditLog = newCfg.AuditLog
+        e.verbosePrintln("Using audit log from config:", e.opts.AuditLog)
+    }
+    if !e.opts.ReadOnly && newCfg.ReadOnly {
+        e.opts.ReadOnly = newCfg.ReadOnly
+        e.verbosePrintln("Read-only mode enabled from config")
+    }
+    if e.opts.AuthPlugin == "" && newCfg.AuthPlugin != "" {
+        e.opts.AuthPlugin = newCfg.AuthPlugin
+        e.verbosePrintln("Using auth plugin from config:", e.opts.AuthPlugin)
+    }
+    if !e.opts.AllowCleartext && newCfg.AllowCleartext {
+        e.opts.AllowCleartext = newCfg.AllowCleartext
+        e.verbosePrintln("Cleartext passwords allowed from config")
+    }
+    if !e.opts.DumpSchemaOnly && newCfg.DumpSchemaOnly {
+        e.opts.DumpSchemaOnly = newCfg.DumpSchemaOnly
+        e.verbosePrintln("Schema-only dump enabled from config")
+    }
+    if !e.opts.DumpDataOnly && newCfg.DumpDataOnly {
+        e.opts.DumpDataOnly = newCfg.DumpDataOnly
+        e.verbosePrintln("Data-only dump enabled from config")
+    }
+    if len(e.opts.DumpWhere) == 0 && len(newCfg.DumpWhere) > 0 {
+        e.opts.DumpWhere = newCfg.DumpWhere
+        e.verbosePrintln("Using dump row filters from config:", len(e.opts.DumpWhere))
+    }
+    if e.opts.DumpEncrypt == "" && newCfg.DumpEncrypt != "" {
+        e.opts.DumpEncrypt = newCfg.DumpEncrypt
+        e.verbosePrintln("Using dump encryption from config:", e.opts.DumpEncrypt)
+    }
+    if e.opts.MaxBytes == "" && newCfg.MaxBytes != "" {
+        e.opts.MaxBytes = newCfg.MaxBytes
+        e.verbosePrintln("Using max dump size from config:", e.opts.MaxBytes)
+    }
+    if e.opts.MaxBytesPerTable == "" && newCfg.MaxBytesPerTable != "" {
+        e.opts.MaxBytesPerTable = newCfg.MaxBytesPerTable
+        e.verbosePrintln("Using max size per table from config:", e.opts.MaxBytesPerTable)
+    }
+    if e.opts.BlobMode == "raw" && newCfg.BlobMode != "" {
+        e.opts.BlobMode = newCfg.BlobMode
+        e.verbosePrintln("Using blob mode from config:", e.opts.BlobMode)
+    }
+    e.verbosePrintln("Configuration loaded successfully")
+}
@@ -0,0 +1,413 @@
+package core
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "reflect"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/mitchellh/mapstructure"
+)
+
+// defaultOptions returns the options used when neither flags nor a config file set a value
+func defaultOptions() Options {
+    return Options{
+        Port:           3306,
+        ExecCmd:        "SHOW DATABASES;",
+        Workers:        10,
+        DumpDir:        "mysql_dump",
+        MaxRowsPerFile: 10000,
+        DumpBatchSize:  10000,
+        DumpFormat:     "csv",
+        CSVDelimiter:   ",",
+        CSVNullString:  "NULL",
+        BlobMode:       "raw",
+        LootDir:        "loot",
+        UDFDir:         "udf",
+        DiscoverPorts:  defaultDiscoverPorts,
+        DNSTTL:         300,
+        HookTimeout:    30,
+        SprayInterval:  1800,
+        AttemptTimeout: "10s",
+        KeepAlive:      60,
+        RowLimit:       1000,
+        Retries:        2,
+        ConnectTimeout: "10s",
+        QueryTimeout:   "30s",
+        SessionVars:    defaultSessionVars,
+        ErrorThreshold: 0.5,
+    }
+}
+
+// knownConfigKeys returns the JSON keys understood in config files
+func knownConfigKeys() map[string]bool {
+    keys := make(map[string]bool)
+    t := reflect.TypeOf(Options{})
+    for i := 0; i < t.NumField(); i++ {
+        tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+        if tag != "" && tag != "-" {
+            keys[tag] = true
+        }
+    }
+    return keys
+}
+
+// runConfigCommand implements the 'config' subcommand
+func (e *Engine) runConfigCommand(args []string) {
+    if len(args) != 2 || args[0] != "validate" {
+        e.printError("Error: unknown config command.")
+        fmt.Fprintln(e.out, "Usage: sqlblaster config validate <file.json>")
+        os.Exit(1)
+    }
+
+    errs, warnings := e.validateConfigFile(args[1])
+    for _, w := range warnings {
+        e.printWarning("Warning: %s", w)
+    }
+    for _, msg := range errs {
+        e.printError("Error: %s", msg)
+    }
+
+    if len(errs) > 0 {
+        fmt.Fprintf(e.out, "\n%s is invalid: %d error(s), %d warning(s)\n", args[1], len(errs), len(warnings))
+        os.Exit(1)
+    }
+    e.printSuccess("\n%s is valid (%d warning(s))", args[1], len(warnings))
+}
+
+// validateConfigFile checks a config file for unknown keys, conflicting options, and missing
+// files, and prints the effective configuration it would produce
+func (e *Engine) validateConfigFile(filename string) (errs []string, warnings []string) {
+    file, err := os.Open(filename)
+    if err != nil {
+        return []string{fmt.Sprintf("cannot open config file: %v", err)}, nil
+    }
+    defer file.Close()
+
+    var fileConfig map[string]interface{}
+    if err := json.NewDecoder(file).Decode(&fileConfig); err != nil {
+        return []string{fmt.Sprintf("cannot decode config file: %v", err)}, nil
+    }
+
+    // Unknown keys are silently ignored by loadConfig, which hides typos
+    known := knownConfigKeys()
+    var unknown []string
+    for key := range fileConfig {
+        if !known[key] {
+            unknown = append(unknown, key)
+        }
+    }
+    sort.Strings(unknown)
+    for _, key := range unknown {
+        errs = append(errs, fmt.Sprintf("unknown key '%s'", key))
+    }
+
+    effective := defaultOptions()
+    if err := mapstructure.Decode(fileConfig, &effective); err != nil {
+        errs = append(errs, fmt.Sprintf("invalid value: %v", err))
+        return errs, warnings
+    }
+    effective.ExecCmd = sanitizeCommand(effective.ExecCmd)
+
+    // Required and conflicting options
+    if effective.Host == "" && effective.CredCSV == "" && effective.Discover == "" {
+        warnings = append(warnings, "no host set, -h must be given on the command line")
+    }
+    if effective.SingleUser != "" && effective.UserList != "" {
+        errs = append(errs, "singleUser and userList are mutually exclusive")
+    }
+    if effective.SinglePass != "" && effective.PassList != "" {
+        warnings = append(warnings, "singlePass and passList are both set, singlePass takes precedence")
+    }
+    if err := e.checkTLSFiles(effective.TLSCert, effective.TLSKey, effective.TLSCA, effective.SkipSSL); err != nil {
+        errs = append(errs, fmt.Sprintf("tlsCert/tlsKey/tlsCA: %v", err))
+    }
+    if effective.UseSSL && effective.SkipSSL {
+        errs = append(errs, "useSSL and skipSSL conflict (skipSSL overrides useSSL)")
+    }
+    if effective.Dump && (effective.UserList != "" || effective.PassList != "") {
+        errs = append(errs, "dump is not compatible with userList or passList")
+    }
+    if effective.Dump && (effective.SingleUser == "" || effective.SinglePass == "") {
+        errs = append(errs, "dump requires singleUser and singlePass")
+    }
+    if effective.CredCSV != "" && (effective.SingleUser != "" || effective.UserList != "" || effective.SinglePass != "" || effective.PassList != "") {
+        errs = append(errs, "credCSV supplies every credential and cannot be combined with singleUser, userList, singlePass or passList")
+    }
+    if effective.Discover != "" {
+        if effective.Host != "" || effective.CredCSV != "" {
+            errs = append(errs, "discover finds its own targets and cannot be combined with host or credCSV")
+        }
+        if _, err := expandDiscoverTargets(effective.Discover); err != nil {
+            errs = append(errs, fmt.Sprintf("discover: %v", err))
+        }
+        if _, err := parseDiscoverPorts(effective.DiscoverPorts); err != nil {
+            errs = append(errs, fmt.Sprintf("discoverPorts: %v", err))
+        }
+    }
+    if effective.CredCSV != "" && effective.Dump {
+        errs = append(errs, "dump is not compatible with credCSV")
+    }
+    if effective.IPVersion != 0 && effective.IPVersion != 4 && effective.IPVersion != 6 {
+        errs = append(errs, "ipVersion must be 0, 4 or 6")
+    }
+    if effective.Workers < 1 {
+        errs = append(errs, "workers must be at least 1")
+    }
+    if effective.MaxConnFraction < 0 || effective.MaxConnFraction > 1 {
+        errs = append(errs, "maxConnFraction must be between 0 and 1")
+    }
+    if effective.Output != "" && effective.Output != "text" && effective.Output != "json" {
+        errs = append(errs, fmt.Sprintf("unknown output mode '%s' (use text or json)", effective.Output))
+    }
+    if effective.SummaryOnly && effective.Output == "json" {
+        errs = append(errs, "summaryOnly cannot be combined with output json")
+    }
+    if effective.DNSTTL < 0 {
+        errs = append(errs, "dnsTTL must be 0 or more")
+    }
+    if effective.HostsFile != "" {
+        if _, err := loadHostsFile(effective.HostsFile); err != nil {
+            errs = append(errs, fmt.Sprintf("hostsFile: %v", err))
+        }
+    }
+    if effective.Resolver != "" {
+        if _, err := parseResolver(effective.Resolver); err != nil {
+            errs = append(errs, err.Error())
+        }
+    }
+    if effective.Rate < 0 || effective.Delay < 0 {
+        errs = append(errs, "rate and delay must be 0 or more")
+    }
+    if effective.OnSuccess != "" {
+        if _, err := parseHookCommand(effective.OnSuccess); err != nil {
+            errs = append(errs, fmt.Sprintf("onSuccess: %v", err))
+        }
+    }
+    if effective.OnErrorThreshold != "" {
+        if _, err := parseHookCommand(effective.OnErrorThreshold); err != nil {
+            errs = append(errs, fmt.Sprintf("onErrorThreshold: %v", err))
+        }
+    }
+    if effective.ErrorThreshold <= 0 || effective.ErrorThreshold > 1 {
+        errs = append(errs, "errorThreshold must be between 0 and 1")
+    }
+    if effective.HookTimeout < 1 {
+        errs = append(errs, "hookTimeout must be at least 1 second")
+    }
+    if !validDumpFormat(effective.DumpFormat) {
+        errs = append(errs, fmt.Sprintf("dumpFormat must be one of: %s", strings.Join(dumpFormats, ", ")))
+    }
+    if _, err := parseCSVDelimiter(effective.CSVDelimiter); err != nil {
+        errs = append(errs, fmt.Sprintf("csvDelimiter: %v", err))
+    }
+    if !validBlobMode(effective.BlobMode) {
+        errs = append(errs, fmt.Sprintf("blobMode must be one of: %s", strings.Join(blobModes, ", ")))
+    } else if effective.BlobMode != "raw" && (effective.DumpFormat == "sql" || effective.DumpFormat == "parquet") {
+        warnings = append(warnings, "blobMode only applies to csv and jsonl dumps")
+    }
+    if _, err := parseTableFilter(effective.DumpInclude, effective.DumpExclude); err != nil {
+        errs = append(errs, err.Error())
+    }
+    if _, err := parseDumpWhere(effective.DumpWhere); err != nil {
+        errs = append(errs, err.Error())
+    }
+    if _, err := parseDumpEncrypt(effective.DumpEncrypt); err != nil {
+        errs = append(errs, fmt.Sprintf("dumpEncrypt: %v", err))
+    }
+    if effective.Campaign != "" && !validCampaignName(effective.Campaign) {
+        errs = append(errs, fmt.Sprintf("campaign '%s' is not a valid directory name", effective.Campaign))
+    }
+    if effective.ShredAfter != "" {
+        if effective.Campaign == "" {
+            errs = append(errs, "shredAfter requires campaign")
+        } else if _, err := parseRetention(effective.ShredAfter); err != nil {
+            errs = append(errs, fmt.Sprintf("shredAfter: %v", err))
+        }
+    }
+    if err := NewEngine(&effective).setupTimeouts(effective.AttemptTimeout, effective.ConnectTimeout, effective.ReadTimeout, effective.WriteTimeout, effective.QueryTimeout); err != nil {
+        errs = append(errs, err.Error())
+    }
+    if effective.CommonPasswords != "" && !e.fileExists(effective.CommonPasswords) {
+        errs = append(errs, fmt.Sprintf("commonPasswords: file '%s' not found", effective.CommonPasswords))
+    }
+    if _, err := parseSessionVars(effective.SessionVars); err != nil {
+        errs = append(errs, fmt.Sprintf("sessionVars: %v", err))
+    }
+    if effective.KeepAlive < 0 {
+        errs = append(errs, "keepAlive must be 0 or more")
+    }
+    if effective.RowLimit < 0 {
+        errs = append(errs, "rowLimit must be 0 or more")
+    }
+    if effective.MaxColWidth < 0 {
+        errs = append(errs, "maxColWidth must be 0 or more")
+    }
+    if effective.Retries < 0 {
+        errs = append(errs, "retries must be 0 or more")
+    }
+    if effective.BlockPause != "" {
+        if d, err := time.ParseDuration(effective.BlockPause); err != nil || d <= 0 {
+            errs = append(errs, fmt.Sprintf("blockPause: invalid duration '%s'", effective.BlockPause))
+        }
+    }
+    if _, err := parseConnAttrs(effective.ConnAttrs); err != nil {
+        errs = append(errs, fmt.Sprintf("connAttrs: %v", err))
+    }
+    if effective.Spray && effective.UserFirst {
+        errs = append(errs, "spray cannot be combined with userFirst")
+    }
+    if effective.SprayInterval < 0 || effective.MaxAttemptsPerUser < 0 {
+        errs = append(errs, "sprayInterval and maxAttemptsPerUser must be 0 or more")
+    }
+    if effective.BatchAuth < 0 {
+        errs = append(errs, "batchAuth must be 0 or more")
+    }
+    if (effective.PushDefectDojo != "" || effective.PushFaraday != "") && (effective.APIKey == "" || effective.PushEngagement == "") {
+        errs = append(errs, "pushDefectDojo and pushFaraday require apiKey and pushEngagement")
+    }
+    if effective.DumpBatchSize < 0 {
+        errs = append(errs, "dumpBatchSize must be 0 or more")
+    }
+    if effective.InventoryFile != "" && effective.VaultPasswordFile == "" {
+        errs = append(errs, "inventoryFile requires vaultPasswordFile")
+    }
+    if effective.VaultPasswordFile != "" && !e.fileExists(effective.VaultPasswordFile) {
+        errs = append(errs, fmt.Sprintf("vaultPasswordFile: file '%s' not found", effective.VaultPasswordFile))
+    }
+    if effective.SecretDetectors != "" {
+        if _, err := loadSecretDetectors(effective.SecretDetectors); err != nil {
+            errs = append(errs, fmt.Sprintf("secretDetectors: %v", err))
+        }
+    }
+    if effective.ProveOnly && (effective.Dump || effective.Enum || effective.LootHashes || effective.DeployUDF || effective.PrivescCheck || effective.InventoryFile != "") {
+        errs = append(errs, "proveOnly cannot be combined with dump, enum, lootHashes, deployUdf, privescCheck or inventoryFile")
+    }
+    if effective.DefaultsFile != "" {
+        if _, err := e.loadDefaultCreds(effective.DefaultsFile); err != nil {
+            errs = append(errs, fmt.Sprintf("defaultsFile: %v", err))
+        }
+    }
+    if (effective.Defaults || effective.DefaultsFile != "") && (effective.CredCSV != "" || effective.Discover != "" || effective.Dump) {
+        errs = append(errs, "defaults cannot be combined with credCSV, discover or dump")
+    }
+    if effective.SkipKnown && effective.ResultsDB == "" && effective.LogFile == "" && effective.Campaign == "" {
+        errs = append(errs, "skipKnown requires resultsDB, logFile or campaign")
+    }
+    if effective.SkipKnown && effective.Dump {
+        errs = append(errs, "skipKnown cannot be combined with dump")
+    }
+    if effective.Follow && effective.UserList == "" && effective.PassList == "" && effective.CredCSV == "" {
+        errs = append(errs, "follow requires userList, passList or credCSV")
+    }
+    if effective.Follow && (effective.UserFirst || effective.Spray || effective.Dump || effective.Discover != "") {
+        errs = append(errs, "follow cannot be combined with userFirst, spray, dump or discover")
+    }
+    if effective.DeployUDF && !effective.AllowDangerous {
+        errs = append(errs, "deployUdf requires allowDangerous")
+    }
+    if effective.DumpSchemaOnly && effective.DumpDataOnly {
+        errs = append(errs, "dumpSchemaOnly and dumpDataOnly cannot be combined")
+    }
+    if effective.AuthPlugin != "" && !validAuthPlugin(effective.AuthPlugin) {
+        errs = append(errs, fmt.Sprintf("authPlugin must be one of: %s", strings.Join(authPlugins, ", ")))
+    } else if effective.AuthPlugin == "mysql_clear_password" && !effective.AllowCleartext {
+        errs = append(errs, "authPlugin mysql_clear_password requires allowCleartext")
+    }
+    if effective.AllowCleartext && effective.SkipSSL {
+        warnings = append(warnings, "allowCleartext with skipSsl sends mysql_clear_password passwords unencrypted")
+    }
+    if effective.ReadOnly && (effective.AllowDangerous || effective.DeployUDF) {
+        errs = append(errs, "readOnly cannot be combined with allowDangerous or deployUdf")
+    }
+    if effective.ReadOnly && effective.ExecCmd != "" {
+        if verdict := readOnlyPolicy.check(effective.ExecCmd); verdict.action != "" {
+            errs = append(errs, fmt.Sprintf("execCmd '%s' is not allowed in read-only mode (%s)", effective.ExecCmd, verdict.reason))
+        }
+    }
+    if len(effective.Params) > 0 && !effective.ExecParams {
+        errs = append(errs, "params requires execParams")
+    }
+    if n := countPlaceholders(effective.ExecCmd); effective.ExecParams && n != len(effective.Params) {
+        errs = append(errs, fmt.Sprintf("execCmd has %d ? placeholder(s) but params has %d value(s)", n, len(effective.Params)))
+    }
+    policy := defaultPolicy()
+    if effective.Policy != "" {
+        loaded, err := loadPolicy(effective.Policy)
+        if err != nil {
+            errs = append(errs, fmt.Sprintf("policy: %v", err))
+        } else {
+            policy = loaded
+        }
+    }
+    if verdict := policy.check(effective.ExecCmd); verdict.action != "" && !effective.AllowDangerous {
+        if verdict.action == policyWarn {
+            warnings = append(warnings, fmt.Sprintf("execCmd '%s' is flagged by the policy (%s)", effective.ExecCmd, verdict.reason))
+        } else {
+            warnings = append(warnings, fmt.Sprintf("execCmd '%s' is dangerous (%s) and will be blocked without allowDangerous", effective.ExecCmd, verdict.reason))
+        }
+    }
+
+    // Input files must exist before a long run starts
+    if effective.UserList != "" && !e.fileExists(effective.UserList) {
+        errs = append(errs, fmt.Sprintf("userList file '%s' not found", effective.UserList))
+    }
+    if effective.PassList != "" && !e.fileExists(effective.PassList) {
+        errs = append(errs, fmt.Sprintf("passList file '%s' not found", effective.PassList))
+    }
+    if effective.CredCSV != "" && !e.fileExists(effective.CredCSV) {
+        errs = append(errs, fmt.Sprintf("credCSV file '%s' not found", effective.CredCSV))
+    }
+    if effective.Rules != "" {
+        if _, err := loadRules(effective.Rules); err != nil {
+            errs = append(errs, fmt.Sprintf("rules: %v", err))
+        }
+    }
+    if effective.Company != "" && !effective.Mutate {
+        warnings = append(warnings, "company is only used together with mutate")
+    }
+    if _, err := parseDumpBudget(effective.MaxBytes, effective.MaxBytesPerTable); err != nil {
+        errs = append(errs, err.Error())
+    }
+    if effective.MaxMemory != "" {
+        if _, err := parseByteSize(effective.MaxMemory); err != nil {
+            errs = append(errs, fmt.Sprintf("maxMemory: %v", err))
+        }
+    }
+    if err := validateTheme(effective.Theme, effective.ThemeColors); err != nil {
+        errs = append(errs, err.Error())
+    }
+    if effective.Sources != "" {
+        if _, err := parseSources(effective.Sources); err != nil {
+            errs = append(errs, err.Error())
+        }
+    }
+
+    // Never echo passwords, prepared statement values or API secrets back to the terminal
+    shown := effective
+    if shown.APIKey != "" {
+        shown.APIKey = "********"
+    }
+    if shown.SinglePass != "" {
+        shown.SinglePass = "********"
+    }
+    if user, _, ok := strings.Cut(shown.MonitorCreds, ":"); ok {
+        shown.MonitorCreds = user + ":********"
+    }
+    if len(shown.Params) > 0 {
+        shown.Params = make([]string, len(effective.Params))
+        for i := range shown.Params {
+            shown.Params[i] = "********"
+        }
+    }
+    fmt.Fprintln(e.out, "Effective configuration:")
+    encoder := json.NewEncoder(e.out)
+    encoder.SetIndent("", "  ")
+    encoder.Encode(shown)
+    fmt.Fprintln(e.out)
+
+    return errs, warnings
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// TestEffectiveConfigMasksSecrets checks that config check prints its effective configuration
+// to the run's output without the passwords and parameter values in it
+func TestEffectiveConfigMasksSecrets(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "config.json")
+    config := `{"host": "db1", "singleUser": "root", "singlePass": "hunter2", "monitorCreds": "mon:monpass", "apiKey": "key123", "params": ["p@ram"]}`
+    if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+        t.Fatal(err)
+    }
+
+    opts := DefaultOptions()
+    e := NewEngine(&opts)
+    var out bytes.Buffer
+    e.out = &out
+    e.validateConfigFile(path)
+
+    shown := out.String()
+    if !strings.Contains(shown, `"host": "db1"`) {
+        t.Fatalf("the effective configuration is not in the run's output:\n%s", shown)
+    }
+    for _, secret := range []string{"hunter2", "monpass", "key123", "p@ram"} {
+        if strings.Contains(shown, secret) {
+            t.Errorf("%s is shown in the effective configuration:\n%s", secret, shown)
+        }
+    }
+}
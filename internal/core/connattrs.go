@@ -0,0 +1,80 @@
+package core
+
+import (
+    "fmt"
+    "net/url"
+    "regexp"
+    "strings"
+)
+
+// connAttrName matches the attribute names --conn-attrs accepts
+var connAttrName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// connAttr is one name=value pair from --conn-attrs
+type connAttr struct {
+    name  string
+    value string
+}
+
+// parseConnAttrs parses a comma-separated name=value list. The driver separates attributes with
+// commas and names from values with colons, so values cannot contain either.
+func parseConnAttrs(list string) ([]connAttr, error) {
+    var attrs []connAttr
+    for _, part := range strings.Split(list, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        name, value, ok := strings.Cut(part, "=")
+        name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+        if !ok {
+            return nil, fmt.Errorf("'%s' is not name=value", part)
+        }
+        if !connAttrName.MatchString(name) {
+            return nil, fmt.Errorf("invalid attribute name '%s'", name)
+        }
+        if strings.Contains(value, ":") {
+            return nil, fmt.Errorf("value of '%s' cannot contain ':'", name)
+        }
+        // The server truncates longer attribute names and values
+        if len(name) > 32 || len(value) > 1024 {
+            return nil, fmt.Errorf("attribute '%s' is too long (names up to 32 bytes, values up to 1024)", name)
+        }
+        attrs = append(attrs, connAttr{name: name, value: value})
+    }
+    return attrs, nil
+}
+
+// setupConnAttrs parses --conn-attrs into the attributes sent with every connection attempt,
+// which show up in performance_schema.session_connect_attrs on the target
+func (e *Engine) setupConnAttrs(list string) error {
+    attrs, err := parseConnAttrs(list)
+    if err != nil {
+        return err
+    }
+    e.connAttrs = attrs
+    return nil
+}
+
+// connAttrsDSNParam returns the DSN parameter that makes the driver send the attributes, after
+// its own _client_name, _os, _pid and similar ones
+func (e *Engine) connAttrsDSNParam() string {
+    if len(e.connAttrs) == 0 {
+        return ""
+    }
+    pairs := make([]string, len(e.connAttrs))
+    for i, a := range e.connAttrs {
+        pairs[i] = a.name + ":" + a.value
+    }
+    return "&connectionAttributes=" + url.QueryEscape(strings.Join(pairs, ","))
+}
+
+// encodeConnAttrs encodes the attributes for a handshake response or COM_CHANGE_USER packet
+func (e *Engine) encodeConnAttrs() []byte {
+    var attrs []byte
+    for _, a := range e.connAttrs {
+        attrs = appendLenEncString(attrs, []byte(a.name))
+        attrs = appendLenEncString(attrs, []byte(a.value))
+    }
+    return appendLenEncString(nil, attrs)
+}
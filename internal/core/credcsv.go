@@ -0,0 +1,135 @@
+package core
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+)
+
+// loadCredCSVTargets reads a --cred-csv file and groups its rows by target
+func (e *Engine) loadCredCSVTargets(filename string) ([][]ResultRecord, error) {
+    records, err := e.loadCredentialCSV(filename)
+    if err != nil {
+        return nil, err
+    }
+    return groupByTarget(records)
+}
+
+// groupByTarget groups credentials by target, keeping the order in which targets first appear
+// and the order of rows within each
+func groupByTarget(records []ResultRecord) ([][]ResultRecord, error) {
+    index := make(map[string]int)
+    var targets [][]ResultRecord
+    for _, r := range records {
+        if r.Host == "" {
+            return nil, fmt.Errorf("no host for %s, give every row host,port columns or specify one with -h", r.User)
+        }
+        i, ok := index[r.hostKey()]
+        if !ok {
+            i = len(targets)
+            index[r.hostKey()] = i
+            targets = append(targets, nil)
+        }
+        targets[i] = append(targets[i], r)
+    }
+    return targets, nil
+}
+
+// credReplay is the state of a --cred-csv run carried from one target to the next
+type credReplay struct {
+    engine  *Engine
+    note    string
+    stats   *runStats
+    logFile *os.File
+    tested  int // targets started so far
+    found   int
+}
+
+// runCredCSV tries every row of a --cred-csv file as one attempt. Targets are tested one after
+// another, each with the full worker pool and its own lockout state, so a host that blocks this
+// client only ends the attempts against that host. A non-empty note is attached to every target.
+// With --follow, rows appended to the file are replayed as they come in.
+func (e *Engine) runCredCSV(ctx context.Context, filename, note string, logFile *os.File) {
+    replay := &credReplay{engine: e, note: note, logFile: logFile}
+    if e.opts.Follow {
+        total, found := e.followCredCSV(ctx, filename, replay)
+        fmt.Fprintln(e.out, e.tr("replay.complete", found, total))
+        e.printInfo("%s", replay.stats.summary())
+        return
+    }
+
+    targets, err := e.loadCredCSVTargets(filename)
+    if err != nil {
+        e.printError("%s", e.tr("err.credfile_read", err))
+        os.Exit(1)
+    }
+    total := 0
+    for _, rows := range targets {
+        total += len(rows)
+    }
+    fmt.Fprintln(e.out, e.tr("replay.starting", total, filename, len(targets)))
+
+    replay.stats = e.newRunStats("Replaying credentials", total, 0, 0)
+    statusCtx, stopStatus := context.WithCancel(ctx)
+    startStatusLine(statusCtx, replay.stats)
+    replay.run(ctx, targets)
+    stopStatus()
+
+    fmt.Fprintln(e.out, e.tr("replay.complete", replay.found, total))
+    e.printInfo("%s", replay.stats.summary())
+}
+
+// run tests targets one after another. errFirstSuccess means -f stopped the run.
+func (r *credReplay) run(ctx context.Context, targets [][]ResultRecord) error {
+    for _, rows := range targets {
+        if ctx.Err() != nil {
+            return nil
+        }
+        // Report the previous target's lockouts before its state is cleared; the last target's
+        // are reported with the rest of the run
+        if r.tested > 0 {
+            r.engine.reportLockouts(r.logFile)
+            r.engine.resetLockouts()
+        }
+        r.tested++
+
+        // Attempts, lockouts and successes all read the target from the configuration
+        r.engine.opts.Host, r.engine.opts.Port = rows[0].Host, rows[0].Port
+        if err := r.engine.preResolve(ctx, r.engine.opts.Host); err != nil {
+            r.engine.printError("%s", r.engine.tr("replay.unresolved", r.engine.opts.Host, len(rows), err))
+            r.stats.skip(len(rows))
+            continue
+        }
+        if r.note != "" {
+            r.engine.addNote(r.engine.opts.Host, r.engine.opts.Port, "", r.note)
+        }
+
+        r.engine.verbosePrintf("\nTesting %d credential(s) against %s:%d\n", len(rows), r.engine.opts.Host, r.engine.opts.Port)
+        found, err := r.engine.replayTarget(ctx, rows, r.stats, r.logFile)
+        r.found += found
+        if errors.Is(err, errFirstSuccess) {
+            return err
+        }
+    }
+    return nil
+}
+
+// replayTarget tests the rows of one target with the worker pool and returns how many succeeded.
+// errFirstSuccess means -f stopped the run.
+func (e *Engine) replayTarget(ctx context.Context, rows []ResultRecord, stats *runStats, logFile *os.File) (int, error) {
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+    pairs := make(chan Credential)
+    go func() {
+        defer close(pairs)
+        for i, r := range rows {
+            select {
+            case <-ctx.Done():
+                return
+            case pairs <- Credential{user: r.User, pass: r.Pass, seq: int64(i)}:
+            }
+        }
+    }()
+    return e.testPairs(ctx, pairs, stats, nil, logFile)
+}
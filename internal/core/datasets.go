@@ -0,0 +1,327 @@
+package core
+
+import (
+    "crypto/ed25519"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// dataBundlePublicKey is the ed25519 key (hex) that data bundles must be signed with
+const dataBundlePublicKey = "55d9a5d970dab00be0c62a56c25267f56d208b265f41b407807c5873b9e8de86"
+
+// dataFileName is the installed data bundle kept in the user's home directory
+const dataFileName = ".sqlblaster_data.json"
+
+// dataDownloadTimeout bounds fetching a bundle given as an https URL
+const dataDownloadTimeout = 60 * time.Second
+
+// Datasets a bundle can refresh
+const (
+    datasetDefaultCreds    = "default-creds"    // --defaults logins, user:password entries
+    datasetCommonPasswords = "common-passwords" // top passwords for password scoring
+)
+
+// builtinDataVersion is the version reported for data compiled into the binary
+const builtinDataVersion = "builtin"
+
+// dataBundle is the signed payload of a data update
+type dataBundle struct {
+    Version  string             `json:"version"`
+    Created  time.Time          `json:"created"`
+    Datasets map[string]dataset `json:"datasets"`
+}
+
+// dataset is one list in a bundle
+type dataset struct {
+    Version string   `json:"version"`
+    Entries []string `json:"entries"`
+}
+
+// signedBundle is the file 'update-data' installs: the bundle's JSON and an ed25519 signature
+// over it, both base64-encoded so the signed bytes survive any reformatting
+type signedBundle struct {
+    Bundle    string `json:"bundle"`
+    Signature string `json:"signature"`
+}
+
+// builtinDataVersions returns the versions of the data compiled into the binary, which a run
+// reports in its metadata until a bundle replaces them
+func builtinDataVersions() map[string]string {
+    return map[string]string{
+        "bundle":               builtinDataVersion,
+        datasetDefaultCreds:    builtinDataVersion,
+        datasetCommonPasswords: builtinDataVersion,
+    }
+}
+
+// dataPath returns the path of the installed data bundle, or "" without a home directory
+func dataPath() string {
+    home, err := os.UserHomeDir()
+    if err != nil {
+        return ""
+    }
+    return filepath.Join(home, dataFileName)
+}
+
+// verifyBundle checks a signed bundle against the built-in public key and decodes it
+func verifyBundle(data []byte) (*dataBundle, error) {
+    var signed signedBundle
+    if err := json.Unmarshal(data, &signed); err != nil {
+        return nil, fmt.Errorf("not a signed data bundle: %v", err)
+    }
+    payload, err := base64.StdEncoding.DecodeString(signed.Bundle)
+    if err != nil {
+        return nil, fmt.Errorf("invalid bundle encoding: %v", err)
+    }
+    sig, err := base64.StdEncoding.DecodeString(signed.Signature)
+    if err != nil {
+        return nil, fmt.Errorf("invalid signature encoding: %v", err)
+    }
+    key, _ := hex.DecodeString(dataBundlePublicKey)
+    if !ed25519.Verify(ed25519.PublicKey(key), payload, sig) {
+        return nil, errors.New("signature does not match, the bundle is corrupt or not from the sqlblaster maintainers")
+    }
+
+    var b dataBundle
+    if err := json.Unmarshal(payload, &b); err != nil {
+        return nil, fmt.Errorf("invalid bundle: %v", err)
+    }
+    if b.Version == "" {
+        return nil, errors.New("bundle has no version")
+    }
+    return &b, nil
+}
+
+// applyBundle replaces the run's built-in datasets with the bundle's. Datasets this build does
+// not know are returned rather than applied.
+func (e *Engine) applyBundle(b *dataBundle) (unknown []string, err error) {
+    for name, ds := range b.Datasets {
+        switch name {
+        case datasetDefaultCreds:
+            creds := make([]defaultCred, 0, len(ds.Entries))
+            for _, entry := range ds.Entries {
+                c, err := parseDefaultCred(entry)
+                if err != nil {
+                    return nil, fmt.Errorf("%s: %v", name, err)
+                }
+                creds = append(creds, c)
+            }
+            e.defaultCredList = creds
+        case datasetCommonPasswords:
+            e.commonPasswordList = ds.Entries
+        default:
+            unknown = append(unknown, name)
+            continue
+        }
+        e.dataVersions[name] = ds.Version
+    }
+    e.dataVersions["bundle"] = b.Version
+    sort.Strings(unknown)
+    return unknown, nil
+}
+
+// loadInstalledData applies the bundle installed by 'update-data', if any. The signature is
+// checked again, so a bundle edited after installation is refused and the built-in data kept.
+func (e *Engine) loadInstalledData() error {
+    path := dataPath()
+    if path == "" || !e.fileExists(path) {
+        return nil
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return err
+    }
+    b, err := verifyBundle(data)
+    if err != nil {
+        return fmt.Errorf("%s: %v", path, err)
+    }
+    if _, err := e.applyBundle(b); err != nil {
+        return fmt.Errorf("%s: %v", path, err)
+    }
+    e.verbosePrintln("Using data bundle", b.Version, "from", path)
+    return nil
+}
+
+// formatDataVersions renders the data versions as "bundle=x, common-passwords=y, ..."
+func (e *Engine) formatDataVersions() string {
+    names := make([]string, 0, len(e.dataVersions))
+    for name := range e.dataVersions {
+        if name != "bundle" {
+            names = append(names, name)
+        }
+    }
+    sort.Strings(names)
+    parts := []string{"bundle=" + e.dataVersions["bundle"]}
+    for _, name := range names {
+        parts = append(parts, name+"="+e.dataVersions[name])
+    }
+    return strings.Join(parts, ", ")
+}
+
+// compareVersions compares dotted version strings numerically where both parts are numbers
+func compareVersions(a, b string) int {
+    as, bs := strings.Split(a, "."), strings.Split(b, ".")
+    for i := 0; i < max(len(as), len(bs)); i++ {
+        var x, y string
+        if i < len(as) {
+            x = as[i]
+        }
+        if i < len(bs) {
+            y = bs[i]
+        }
+        xn, xerr := strconv.Atoi(x)
+        yn, yerr := strconv.Atoi(y)
+        switch {
+        case xerr == nil && yerr == nil && xn != yn:
+            if xn < yn {
+                return -1
+            }
+            return 1
+        case (xerr != nil || yerr != nil) && x != y:
+            return strings.Compare(x, y)
+        }
+    }
+    return 0
+}
+
+// readBundleSource reads a bundle from a file, or downloads it from an https URL
+func readBundleSource(source string) ([]byte, error) {
+    if !strings.HasPrefix(source, "https://") {
+        return os.ReadFile(source)
+    }
+    client := &http.Client{Timeout: dataDownloadTimeout}
+    resp, err := client.Get(source)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("%s: %s", source, resp.Status)
+    }
+    return io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+}
+
+// signBundle wraps a bundle's JSON in a signed bundle with the ed25519 seed (hex) in keyFile
+func signBundle(payloadFile, keyFile string) ([]byte, error) {
+    payload, err := os.ReadFile(payloadFile)
+    if err != nil {
+        return nil, err
+    }
+    var b dataBundle
+    if err := json.Unmarshal(payload, &b); err != nil || b.Version == "" {
+        return nil, fmt.Errorf("%s is not a bundle with a version", payloadFile)
+    }
+    seedHex, err := os.ReadFile(keyFile)
+    if err != nil {
+        return nil, err
+    }
+    seed, err := hex.DecodeString(strings.TrimSpace(string(seedHex)))
+    if err != nil || len(seed) != ed25519.SeedSize {
+        return nil, fmt.Errorf("%s is not a hex ed25519 seed", keyFile)
+    }
+    sig := ed25519.Sign(ed25519.NewKeyFromSeed(seed), payload)
+    return json.MarshalIndent(signedBundle{
+        Bundle:    base64.StdEncoding.EncodeToString(payload),
+        Signature: base64.StdEncoding.EncodeToString(sig),
+    }, "", "  ")
+}
+
+// runUpdateData implements 'sqlblaster update-data': it verifies a signed bundle from a file
+// or https URL and installs it, refusing bundles older than the installed one unless told to
+func (e *Engine) runUpdateData(args []string) {
+    flags := flag.NewFlagSet("update-data", flag.ExitOnError)
+    status := flags.Bool("status", false, "Show the data versions in use")
+    downgrade := flags.Bool("allow-downgrade", false, "Install a bundle older than the installed one")
+    signKey := flags.String("sign", "", "Sign the bundle JSON given as argument with this ed25519 seed file and print it")
+    flags.Parse(args)
+
+    if err := e.loadInstalledData(); err != nil {
+        e.printWarning("Installed data bundle ignored: %v", err)
+    }
+    if *status {
+        fmt.Fprintln(e.out, "Data versions:", e.formatDataVersions())
+        return
+    }
+    if flags.NArg() != 1 {
+        e.printError("Error: update-data requires a bundle file or https URL.")
+        fmt.Fprintln(e.out, "Usage: sqlblaster update-data [--allow-downgrade] <bundle.json|https://...>")
+        fmt.Fprintln(e.out, "       sqlblaster update-data --status")
+        fmt.Fprintln(e.out, "       sqlblaster update-data --sign <seed.hex> <payload.json>")
+        os.Exit(1)
+    }
+
+    if *signKey != "" {
+        signed, err := signBundle(flags.Arg(0), *signKey)
+        if err != nil {
+            e.printError("Error signing bundle: %v", err)
+            os.Exit(1)
+        }
+        fmt.Fprintln(e.out, string(signed))
+        return
+    }
+
+    data, err := readBundleSource(flags.Arg(0))
+    if err != nil {
+        e.printError("Error reading bundle: %v", err)
+        os.Exit(1)
+    }
+    b, err := verifyBundle(data)
+    if err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+
+    installed := e.dataVersions["bundle"]
+    if installed != builtinDataVersion {
+        switch cmp := compareVersions(b.Version, installed); {
+        case cmp == 0:
+            e.printInfo("Data bundle %s is already installed.", installed)
+            return
+        case cmp < 0 && !*downgrade:
+            e.printError("Error: bundle %s is older than the installed %s, use --allow-downgrade to install it anyway.", b.Version, installed)
+            os.Exit(1)
+        }
+    }
+    // Report only what the new bundle provides, as the next run will see it
+    for name := range e.dataVersions {
+        e.dataVersions[name] = builtinDataVersion
+    }
+    unknown, err := e.applyBundle(b)
+    if err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    for _, name := range unknown {
+        e.printWarning("Dataset '%s' is not used by this version of sqlblaster and was skipped.", name)
+    }
+
+    path := dataPath()
+    if path == "" {
+        e.printError("Error: no home directory to install the bundle in.")
+        os.Exit(1)
+    }
+    tmp := path + ".tmp"
+    if err := os.WriteFile(tmp, data, 0644); err != nil {
+        e.printError("Error installing bundle: %v", err)
+        os.Exit(1)
+    }
+    if err := os.Rename(tmp, path); err != nil {
+        os.Remove(tmp)
+        e.printError("Error installing bundle: %v", err)
+        os.Exit(1)
+    }
+    e.printSuccess("Installed data bundle %s to %s", b.Version, path)
+    fmt.Fprintln(e.out, "Data versions:", e.formatDataVersions())
+}
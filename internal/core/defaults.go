@@ -0,0 +1,118 @@
+package core
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "os"
+    "strings"
+)
+
+// defaultCred is a vendor or packaging default login
+type defaultCred struct {
+    user string
+    pass string
+}
+
+// builtinDefaultCreds are the logins --defaults tries: blank and well-known root passwords,
+// accounts created by distribution packages and application installers, and the example
+// passwords of container images and development boxes. An installed data bundle replaces them.
+var builtinDefaultCreds = []defaultCred{
+    // Fresh installs and common root choices
+    {"root", ""}, {"root", "root"}, {"root", "toor"}, {"root", "mysql"}, {"root", "mariadb"},
+    {"root", "password"}, {"root", "admin"}, {"root", "123456"}, {"root", "changeme"},
+    {"root", "secret"}, {"admin", "admin"}, {"admin", ""}, {"admin", "password"},
+    {"mysql", "mysql"}, {"mysql", ""}, {"test", "test"}, {"test", ""}, {"guest", "guest"},
+    {"user", "user"}, {"", ""},
+    // Debian and Ubuntu maintenance account, normally random but blank or copied on old images
+    {"debian-sys-maint", ""}, {"debian-sys-maint", "debian-sys-maint"},
+    // Container images and development boxes
+    {"root", "my-secret-pw"}, {"root", "example"}, {"root", "docker"}, {"root", "vagrant"},
+    {"vagrant", "vagrant"}, {"homestead", "secret"}, {"root", "bitnami"}, {"bitnami", "bitnami"},
+    {"root", "usbw"}, {"root", "vertrigo"},
+    // Application installers
+    {"zabbix", "zabbix"}, {"cacti", "cacti"}, {"cactiuser", "cactiuser"}, {"nagios", "nagios"},
+    {"phpmyadmin", "phpmyadmin"}, {"pma", ""}, {"wordpress", "wordpress"}, {"wp", "wp"},
+    {"drupal", "drupal"}, {"joomla", "joomla"}, {"magento", "magento"}, {"grafana", "grafana"},
+    {"observium", "observium"}, {"librenms", "librenms"}, {"openfire", "openfire"},
+    {"bugzilla", "bugzilla"}, {"mantis", "mantis"}, {"owncloud", "owncloud"},
+    {"nextcloud", "nextcloud"}, {"redmine", "redmine"}, {"gitea", "gitea"}, {"keystone", "keystone"},
+}
+
+// loadDefaultCreds returns the run's default logins followed by those of filename, which has one
+// user:password per line (a blank password is "user:"), skipping blank lines, # comments and
+// repeats
+func (e *Engine) loadDefaultCreds(filename string) ([]defaultCred, error) {
+    seen := make(map[defaultCred]bool)
+    var creds []defaultCred
+    add := func(c defaultCred) {
+        if !seen[c] {
+            seen[c] = true
+            creds = append(creds, c)
+        }
+    }
+    for _, c := range e.defaultCredList {
+        add(c)
+    }
+    if filename == "" {
+        return creds, nil
+    }
+
+    file, err := os.Open(filename)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    line := 0
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        line++
+        text := strings.TrimSpace(scanner.Text())
+        if text == "" || strings.HasPrefix(text, "#") {
+            continue
+        }
+        c, err := parseDefaultCred(text)
+        if err != nil {
+            return nil, fmt.Errorf("line %d: %v", line, err)
+        }
+        add(c)
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+    return creds, nil
+}
+
+// parseDefaultCred parses a user:password entry; "user:" has a blank password
+func parseDefaultCred(text string) (defaultCred, error) {
+    user, pass, ok := strings.Cut(text, ":")
+    if !ok {
+        return defaultCred{}, fmt.Errorf("expected user:password, got '%s'", text)
+    }
+    return defaultCred{user: user, pass: pass}, nil
+}
+
+// runDefaults tries the default credentials against the target with the worker pool, before
+// any wordlist. errFirstSuccess and errBlockedHost mean the run should not go on to them.
+func (e *Engine) runDefaults(ctx context.Context, logFile *os.File) error {
+    creds, err := e.loadDefaultCreds(e.opts.DefaultsFile)
+    if err != nil {
+        e.printError("Error reading defaults file: %v", err)
+        os.Exit(1)
+    }
+    rows := make([]ResultRecord, len(creds))
+    for i, c := range creds {
+        rows[i] = ResultRecord{Host: e.opts.Host, Port: e.opts.Port, User: c.user, Pass: c.pass}
+    }
+    fmt.Fprintf(e.out, "Trying %d default credential(s)\n", len(rows))
+
+    stats := e.newRunStats("Default credentials", len(rows), 0, 0)
+    statusCtx, stopStatus := context.WithCancel(ctx)
+    startStatusLine(statusCtx, stats)
+    found, err := e.replayTarget(ctx, rows, stats, logFile)
+    stopStatus()
+    fmt.Fprintf(e.out, "\nDefault credentials: %d valid out of %d\n", found, len(rows))
+    e.printInfo("%s", stats.summary())
+    return err
+}
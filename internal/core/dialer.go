@@ -0,0 +1,194 @@
+package core
+
+import (
+    "context"
+    "database/sql/driver"
+    "fmt"
+    "net"
+    "net/url"
+    "strings"
+    "sync"
+
+    "github.com/go-sql-driver/mysql"
+    "golang.org/x/net/proxy"
+)
+
+// dialNetwork is the DSN network name served by dialEngine
+const dialNetwork = "sqlblaster"
+
+// registerDialer registers dialEngine with the mysql driver, once for the process
+var registerDialer sync.Once
+
+// sourceKey is the context key under which an attempt's dialer records its source
+type sourceKey struct{}
+
+// engineKey is the context key under which engineConnector passes its engine to dialEngine
+type engineKey struct{}
+
+// parseSources validates a comma-separated list of source IPs and proxy URLs
+func parseSources(list string) ([]string, error) {
+    var parsed []string
+    for _, src := range strings.Split(list, ",") {
+        src = strings.TrimSpace(src)
+        if src == "" {
+            continue
+        }
+        if strings.Contains(src, "://") {
+            u, err := url.Parse(src)
+            if err != nil {
+                return nil, fmt.Errorf("invalid proxy '%s': %v", src, err)
+            }
+            if _, err := proxy.FromURL(u, proxy.Direct); err != nil {
+                return nil, fmt.Errorf("unsupported proxy '%s': %v", src, err)
+            }
+        } else if net.ParseIP(src) == nil {
+            return nil, fmt.Errorf("invalid source IP '%s'", src)
+        }
+        parsed = append(parsed, src)
+    }
+    return parsed, nil
+}
+
+// setupSources parses the --sources list the engine's connections fail over between
+func (e *Engine) setupSources(list string) error {
+    parsed, err := parseSources(list)
+    if err != nil {
+        return err
+    }
+    e.sources = parsed
+
+    if len(e.sources) > 0 {
+        e.verbosePrintln("Dialing from sources:", strings.Join(e.sources, ", "))
+    }
+    return nil
+}
+
+// dialEngine is the dialer registered for dialNetwork. Every engine's pools go through it, so it
+// dials with the engine engineConnector put in the context.
+func dialEngine(ctx context.Context, addr string) (net.Conn, error) {
+    e, ok := ctx.Value(engineKey{}).(*Engine)
+    if !ok {
+        return nil, fmt.Errorf("connection to %s was not opened by an engine", addr)
+    }
+    return e.dialMySQL(ctx, addr)
+}
+
+// engineConnector opens the connections of an engine's pool, adding the engine to the context
+// dialEngine receives
+type engineConnector struct {
+    base driver.Connector
+    e    *Engine
+}
+
+// Connect opens a connection with the underlying connector
+func (c *engineConnector) Connect(ctx context.Context) (driver.Conn, error) {
+    return c.base.Connect(context.WithValue(ctx, engineKey{}, c.e))
+}
+
+// Driver returns the underlying connector's driver
+func (c *engineConnector) Driver() driver.Driver {
+    return c.base.Driver()
+}
+
+// connector returns the connector of a pool for dsn, which dials through the engine and uses its
+// TLS config
+func (e *Engine) connector(dsn string) (driver.Connector, *mysql.Config, error) {
+    registerDialer.Do(func() {
+        mysql.RegisterDialContext(dialNetwork, dialEngine)
+    })
+    cfg, err := mysql.ParseDSN(dsn)
+    if err != nil {
+        return nil, nil, err
+    }
+    if cfg.TLS != nil && e.tlsConfig != nil {
+        host, _, err := net.SplitHostPort(cfg.Addr)
+        if err != nil {
+            host = cfg.Addr
+        }
+        cfg.TLS = e.tlsConfigFor(host)
+    }
+    base, err := mysql.NewConnector(cfg)
+    if err != nil {
+        return nil, nil, err
+    }
+    return &engineConnector{base: base, e: e}, cfg, nil
+}
+
+// currentSource returns the source new connections are made from
+func (e *Engine) currentSource() string {
+    e.sourceMu.Lock()
+    defer e.sourceMu.Unlock()
+    if e.sourceIndex >= len(e.sources) {
+        return ""
+    }
+    return e.sources[e.sourceIndex]
+}
+
+// withSourceTracking returns a context the dialer records the used source into
+func withSourceTracking(ctx context.Context) (context.Context, *string) {
+    used := new(string)
+    return context.WithValue(ctx, sourceKey{}, used), used
+}
+
+// dialMySQL connects to the target from the current source IP or through the current proxy,
+// resolving the target through the DNS cache
+func (e *Engine) dialMySQL(ctx context.Context, addr string) (net.Conn, error) {
+    var src string
+    if len(e.sources) > 0 {
+        src = e.currentSource()
+        if src == "" {
+            return nil, fmt.Errorf("all source addresses are blocked by the target")
+        }
+        if used, ok := ctx.Value(sourceKey{}).(*string); ok {
+            *used = src
+        }
+    }
+
+    if strings.Contains(src, "://") {
+        // The proxy resolves the name itself unless --hosts-file maps it
+        addr = e.mapHostsAddr(addr)
+        u, _ := url.Parse(src)
+        d, err := proxy.FromURL(u, proxy.Direct)
+        if err != nil {
+            return nil, err
+        }
+        if cd, ok := d.(proxy.ContextDialer); ok {
+            return cd.DialContext(ctx, "tcp", addr)
+        }
+        return d.Dial("tcp", addr)
+    }
+
+    resolved, err := e.resolveAddr(ctx, addr)
+    if err != nil {
+        return nil, err
+    }
+    d := net.Dialer{Timeout: e.connectTimeout}
+    if src != "" {
+        d.LocalAddr = &net.TCPAddr{IP: net.ParseIP(src)}
+    }
+    return d.DialContext(ctx, "tcp", resolved)
+}
+
+// failoverSource marks a source as burned for the target and switches to the next unburned one.
+// It reports false when there is nothing left to fail over to.
+func (e *Engine) failoverSource(burned string) (string, bool) {
+    e.sourceMu.Lock()
+    defer e.sourceMu.Unlock()
+
+    if len(e.sources) == 0 || burned == "" {
+        return "", false
+    }
+    e.burnedSources[burned] = true
+
+    // Another worker may have already moved on from this source
+    if e.sourceIndex < len(e.sources) && !e.burnedSources[e.sources[e.sourceIndex]] {
+        return e.sources[e.sourceIndex], true
+    }
+    for e.sourceIndex < len(e.sources) && e.burnedSources[e.sources[e.sourceIndex]] {
+        e.sourceIndex++
+    }
+    if e.sourceIndex >= len(e.sources) {
+        return "", false
+    }
+    return e.sources[e.sourceIndex], true
+}
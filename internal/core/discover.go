@@ -0,0 +1,303 @@
+package core
+
+import (
+    "context"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+    "github.com/schollz/progressbar/v3"
+)
+
+// defaultDiscoverPorts are the classic protocol's usual ports and the X Protocol's
+const defaultDiscoverPorts = "3306,3307,33060"
+
+// discoverWorkers is how many probes --discover runs at once
+const discoverWorkers = 256
+
+// maxDiscoverAddresses caps the addresses one --discover run expands to (a /16)
+const maxDiscoverAddresses = 1 << 16
+
+// discoverGreetingWait is how long a probe waits for a classic protocol greeting before trying
+// the X Protocol, whose servers wait for the client to speak first
+const discoverGreetingWait = 2 * time.Second
+
+// X Protocol framing: a CapabilitiesGet request and the server message types that answer it
+var xCapabilitiesGet = []byte{1, 0, 0, 0, 1}
+
+const (
+    xServerError        = 1
+    xServerCapabilities = 2
+    xServerNotice       = 11
+)
+
+// discoveredService is a port that answered like MySQL
+type discoveredService struct {
+    Host    string
+    Port    int
+    XProto  bool   // MySQL X Protocol rather than the classic protocol
+    Version string // from the classic greeting
+    Backend string
+    Plugin  string
+    TLS     bool
+    Refusal string // error sent instead of a greeting, e.g. host not allowed
+}
+
+// addr returns the service's host:port
+func (s discoveredService) addr() string {
+    return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// describe summarizes what the probe learned
+func (s discoveredService) describe() string {
+    switch {
+    case s.XProto:
+        return "MySQL X Protocol"
+    case s.Refusal != "":
+        return "MySQL, refused this source: " + s.Refusal
+    }
+    desc := fmt.Sprintf("%s %s, %s", s.Backend, s.Version, s.Plugin)
+    if s.TLS {
+        desc += ", TLS"
+    }
+    return desc
+}
+
+// expandDiscoverTargets turns a comma-separated list of hosts, addresses and CIDR ranges into
+// addresses to probe. Network and broadcast addresses of IPv4 ranges are left out.
+func expandDiscoverTargets(list string) ([]string, error) {
+    var hosts []string
+    for _, entry := range strings.Split(list, ",") {
+        entry = strings.TrimSpace(entry)
+        if entry == "" {
+            continue
+        }
+        if !strings.Contains(entry, "/") {
+            hosts = append(hosts, normalizeHost(entry))
+            continue
+        }
+
+        _, network, err := net.ParseCIDR(entry)
+        if err != nil {
+            return nil, fmt.Errorf("invalid range '%s'", entry)
+        }
+        ones, bits := network.Mask.Size()
+        if bits-ones > 16 || len(hosts)+(1<<(bits-ones)) > maxDiscoverAddresses {
+            return nil, fmt.Errorf("'%s' is too large, at most %d addresses can be swept", entry, maxDiscoverAddresses)
+        }
+        count := 1 << (bits - ones)
+        ip := network.IP
+        for i := 0; i < count; i++ {
+            if bits == 32 && bits-ones > 1 && (i == 0 || i == count-1) {
+                ip = nextIP(ip)
+                continue
+            }
+            hosts = append(hosts, ip.String())
+            ip = nextIP(ip)
+        }
+    }
+    if len(hosts) > maxDiscoverAddresses {
+        return nil, fmt.Errorf("%d addresses to sweep, at most %d are allowed", len(hosts), maxDiscoverAddresses)
+    }
+    return hosts, nil
+}
+
+// nextIP returns the address after ip
+func nextIP(ip net.IP) net.IP {
+    next := append(net.IP(nil), ip...)
+    for i := len(next) - 1; i >= 0; i-- {
+        next[i]++
+        if next[i] != 0 {
+            break
+        }
+    }
+    return next
+}
+
+// parseDiscoverPorts parses the comma-separated --discover-ports list
+func parseDiscoverPorts(list string) ([]int, error) {
+    var ports []int
+    for _, p := range strings.Split(list, ",") {
+        p = strings.TrimSpace(p)
+        if p == "" {
+            continue
+        }
+        port, err := strconv.Atoi(p)
+        if err != nil || port < 1 || port > 65535 {
+            return nil, fmt.Errorf("invalid port '%s'", p)
+        }
+        ports = append(ports, port)
+    }
+    if len(ports) == 0 {
+        return nil, errors.New("no ports to sweep")
+    }
+    return ports, nil
+}
+
+// probeMySQL connects to host:port and checks that a MySQL server answers: a classic greeting
+// (or an error packet sent in its place), or an X Protocol reply to CapabilitiesGet. Ports that
+// are closed or speak anything else report false.
+func (e *Engine) probeMySQL(ctx context.Context, host string, port int) (discoveredService, bool) {
+    svc := discoveredService{Host: host, Port: port}
+    conn, err := e.dialMySQL(ctx, svc.addr())
+    if err != nil {
+        return svc, false
+    }
+    defer conn.Close()
+
+    c := &authConn{engine: e, conn: conn}
+    c.conn.SetDeadline(time.Now().Add(min(discoverGreetingWait, e.attemptTimeout)))
+    data, err := c.readPacket()
+    if err == nil {
+        if data[0] == 0xff {
+            // Only a MySQL server refuses with a MySQL error packet
+            var myErr *mysql.MySQLError
+            if errors.As(parseErrPacket(data), &myErr) {
+                svc.Refusal = myErr.Message
+                return svc, true
+            }
+            return svc, false
+        }
+        fp, err := parseFingerprint(data)
+        if err != nil || fp.Protocol != 10 {
+            return svc, false
+        }
+        svc.Version, svc.Backend, svc.Plugin, svc.TLS = fp.Version, fp.flavor(), fp.AuthPlugin, fp.tls()
+        return svc, true
+    }
+    var netErr net.Error
+    if !errors.As(err, &netErr) || !netErr.Timeout() {
+        return svc, false
+    }
+
+    // Silence: ask for the X Protocol capabilities
+    c.conn.SetDeadline(time.Now().Add(e.attemptTimeout))
+    if _, err := c.conn.Write(xCapabilitiesGet); err != nil {
+        return svc, false
+    }
+    header := make([]byte, 5)
+    if _, err := io.ReadFull(c.conn, header); err != nil {
+        return svc, false
+    }
+    length := binary.LittleEndian.Uint32(header)
+    switch header[4] {
+    case xServerError, xServerCapabilities, xServerNotice:
+        svc.XProto = length >= 1 && length < 1<<20
+    }
+    return svc, svc.XProto
+}
+
+// sweepMySQL probes every host and port concurrently and returns the MySQL services found, in
+// the order of the target list
+func (e *Engine) sweepMySQL(ctx context.Context, hosts []string, ports []int) []discoveredService {
+    total := len(hosts) * len(ports)
+    bar := progressbar.NewOptions(total,
+        progressbar.OptionSetDescription("Discovering MySQL"),
+        progressbar.OptionSetWidth(30),
+        progressbar.OptionShowCount(),
+        progressbar.OptionSetWriter(e.out),
+    )
+
+    found := make([]*discoveredService, total)
+    jobs := make(chan int)
+    var wg sync.WaitGroup
+    for i := 0; i < min(discoverWorkers, total); i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for job := range jobs {
+                if svc, ok := e.probeMySQL(ctx, hosts[job/len(ports)], ports[job%len(ports)]); ok {
+                    found[job] = &svc
+                }
+                bar.Add(1)
+            }
+        }()
+    }
+    for job := 0; job < total && ctx.Err() == nil; job++ {
+        jobs <- job
+    }
+    close(jobs)
+    wg.Wait()
+    fmt.Fprintln(e.out)
+
+    var services []discoveredService
+    for _, svc := range found {
+        if svc != nil {
+            services = append(services, *svc)
+        }
+    }
+    return services
+}
+
+// runDiscover sweeps the --discover targets for MySQL services and lists them. When usernames
+// were given, each classic protocol service found is then tested like a -h target, one after
+// another with its own lockout state.
+func (e *Engine) runDiscover(ctx context.Context, log *os.File) {
+    hosts, err := expandDiscoverTargets(e.opts.Discover)
+    if err != nil {
+        e.printError("Error: --discover: %v", err)
+        os.Exit(1)
+    }
+    ports, err := parseDiscoverPorts(e.opts.DiscoverPorts)
+    if err != nil {
+        e.printError("Error: --discover-ports: %v", err)
+        os.Exit(1)
+    }
+
+    fmt.Fprintf(e.out, "Sweeping %d host(s) on port(s) %s\n", len(hosts), e.opts.DiscoverPorts)
+    services := e.sweepMySQL(ctx, hosts, ports)
+
+    e.theme.heading.Fprintf(e.out, "\nMySQL services found: %d\n", len(services))
+    for _, svc := range services {
+        line := fmt.Sprintf("  %-22s %s", svc.addr(), svc.describe())
+        fmt.Fprintln(e.out, line)
+        if log != nil {
+            log.WriteString("Discovered " + strings.TrimSpace(line) + "\n")
+        }
+        e.emitRecord("discovered", map[string]interface{}{"host": svc.Host, "port": svc.Port, "x_protocol": svc.XProto,
+            "version": svc.Version, "backend": svc.Backend, "plugin": svc.Plugin, "tls": svc.TLS, "refusal": svc.Refusal})
+    }
+
+    if e.opts.SingleUser == "" && e.opts.UserList == "" {
+        return
+    }
+
+    dumpDir := e.opts.DumpDir
+    tested := 0
+    for _, svc := range services {
+        if ctx.Err() != nil {
+            return
+        }
+        if svc.XProto || svc.Refusal != "" {
+            e.verbosePrintf("Skipping %s: %s\n", svc.addr(), svc.describe())
+            continue
+        }
+        if e.opts.FirstOnly && len(e.getSuccesses()) > 0 {
+            return
+        }
+
+        // Report the previous target's lockouts before its state is cleared; the last target's
+        // are reported with the rest of the run
+        if tested > 0 {
+            e.reportLockouts(log)
+            e.resetLockouts()
+        }
+        tested++
+        e.opts.Host, e.opts.Port = svc.Host, svc.Port
+        if e.opts.Dump {
+            // Keep each server's databases apart
+            e.opts.DumpDir = filepath.Join(dumpDir, sanitizeFilename(fmt.Sprintf("%s_%d", svc.Host, svc.Port)))
+        }
+        e.theme.heading.Fprintf(e.out, "\nTesting %s (%s)\n", svc.addr(), svc.describe())
+        e.performTesting(ctx, false, log)
+    }
+}
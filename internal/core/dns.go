@@ -0,0 +1,204 @@
+package core
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "net"
+    "net/netip"
+    "os"
+    "strings"
+    "time"
+)
+
+// dnsEntry is a cached lookup result
+type dnsEntry struct {
+    addrs   []string
+    expires time.Time
+}
+
+// parseResolver validates a DNS server address, adding the default port 53
+func parseResolver(server string) (string, error) {
+    if _, _, err := net.SplitHostPort(server); err != nil {
+        server = net.JoinHostPort(server, "53")
+    }
+    host, _, _ := net.SplitHostPort(server)
+    if net.ParseIP(host) == nil {
+        return "", fmt.Errorf("invalid resolver address '%s'", server)
+    }
+    return server, nil
+}
+
+// setupResolver sends lookups to the given DNS server (ip or ip:port) instead of the system resolver
+func (e *Engine) setupResolver(server string) error {
+    if server == "" {
+        return nil
+    }
+    server, err := parseResolver(server)
+    if err != nil {
+        return err
+    }
+
+    e.verbosePrintln("Using DNS resolver", server)
+    e.resolver = &net.Resolver{
+        PreferGo: true,
+        Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+            d := net.Dialer{Timeout: 5 * time.Second}
+            return d.DialContext(ctx, network, server)
+        },
+    }
+    return nil
+}
+
+// loadHostsFile reads an /etc/hosts-style file ("ip name [name...]", # comments) whose names
+// are resolved only by sqlblaster's dialer
+func loadHostsFile(filename string) (map[string][]string, error) {
+    file, err := os.Open(filename)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    hosts := make(map[string][]string)
+    scanner := bufio.NewScanner(file)
+    lineNum := 0
+    for scanner.Scan() {
+        lineNum++
+        line := scanner.Text()
+        if i := strings.Index(line, "#"); i >= 0 {
+            line = line[:i]
+        }
+        fields := strings.Fields(line)
+        if len(fields) == 0 {
+            continue
+        }
+        if len(fields) < 2 || net.ParseIP(fields[0]) == nil {
+            return nil, fmt.Errorf("%s:%d: expected 'ip name [name...]'", filename, lineNum)
+        }
+        for _, name := range fields[1:] {
+            name = strings.ToLower(name)
+            hosts[name] = append(hosts[name], fields[0])
+        }
+    }
+    return hosts, scanner.Err()
+}
+
+// setupHostsFile loads the --hosts-file mapping
+func (e *Engine) setupHostsFile(filename string) error {
+    if filename == "" {
+        return nil
+    }
+    hosts, err := loadHostsFile(filename)
+    if err != nil {
+        return err
+    }
+    e.verbosePrintf("Loaded %d host mappings from %s\n", len(hosts), filename)
+    e.hostsMap = hosts
+    return nil
+}
+
+// mapHostsAddr replaces the host in a host:port address when --hosts-file maps it
+func (e *Engine) mapHostsAddr(addr string) string {
+    host, port, err := net.SplitHostPort(addr)
+    if err != nil {
+        return addr
+    }
+    if addrs, ok := e.hostsMap[strings.ToLower(host)]; ok {
+        return net.JoinHostPort(addrs[0], port)
+    }
+    return addr
+}
+
+// normalizeHost strips the brackets from an IPv6 literal such as [::1] or [fe80::1%eth0], which
+// are added back wherever a port is joined to it
+func normalizeHost(host string) string {
+    if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+        return host[1 : len(host)-1]
+    }
+    return host
+}
+
+// isIPLiteral reports whether host is an IP address, including IPv6 addresses with a zone
+func isIPLiteral(host string) bool {
+    _, err := netip.ParseAddr(host)
+    return err == nil
+}
+
+// filterIPVersion keeps the addresses of the --ip-version family, failing when none is left
+func (e *Engine) filterIPVersion(host string, addrs []string) ([]string, error) {
+    if e.opts.IPVersion == 0 {
+        return addrs, nil
+    }
+    var kept []string
+    for _, a := range addrs {
+        ip, err := netip.ParseAddr(a)
+        if err != nil {
+            continue
+        }
+        if (e.opts.IPVersion == 4) == ip.Unmap().Is4() {
+            kept = append(kept, a)
+        }
+    }
+    if len(kept) == 0 {
+        return nil, fmt.Errorf("%s has no IPv%d address", host, e.opts.IPVersion)
+    }
+    return kept, nil
+}
+
+// lookupHost resolves host through the --hosts-file mapping and the cache, querying the
+// resolver only when the entry expired, and keeps the addresses of the --ip-version family
+func (e *Engine) lookupHost(ctx context.Context, host string) ([]string, error) {
+    if isIPLiteral(host) {
+        return e.filterIPVersion(host, []string{host})
+    }
+    if addrs, ok := e.hostsMap[strings.ToLower(host)]; ok {
+        return e.filterIPVersion(host, addrs)
+    }
+
+    e.dnsMu.Lock()
+    entry, ok := e.dnsCache[host]
+    e.dnsMu.Unlock()
+    if ok && time.Now().Before(entry.expires) {
+        return e.filterIPVersion(host, entry.addrs)
+    }
+
+    addrs, err := e.resolver.LookupHost(ctx, host)
+    if err != nil {
+        // Keep using a stale answer rather than failing attempts on a resolver hiccup
+        if ok {
+            e.verbosePrintln("DNS lookup failed, using cached addresses for", host)
+            return e.filterIPVersion(host, entry.addrs)
+        }
+        return nil, err
+    }
+
+    e.dnsMu.Lock()
+    e.dnsCache[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(time.Duration(e.opts.DNSTTL) * time.Second)}
+    e.dnsMu.Unlock()
+    return e.filterIPVersion(host, addrs)
+}
+
+// resolveAddr replaces the host in a host:port address with its cached IP
+func (e *Engine) resolveAddr(ctx context.Context, addr string) (string, error) {
+    host, port, err := net.SplitHostPort(addr)
+    if err != nil {
+        return addr, nil
+    }
+    addrs, err := e.lookupHost(ctx, host)
+    if err != nil {
+        return "", err
+    }
+    return net.JoinHostPort(addrs[0], port), nil
+}
+
+// preResolve looks up the target once before testing starts so failures surface early
+func (e *Engine) preResolve(ctx context.Context, host string) error {
+    addrs, err := e.lookupHost(ctx, host)
+    if err != nil {
+        return err
+    }
+    if !isIPLiteral(host) {
+        e.verbosePrintf("Resolved %s to %v (cached for %ds)\n", host, addrs, e.opts.DNSTTL)
+    }
+    return nil
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+    "fmt"
+    "path/filepath"
+    "time"
+)
+
+// credentialCounts returns the number of usernames and password candidates the run will pair up,
+// counting mutated candidates when --mutate or --rules is set
+func (e *Engine) credentialCounts() (users, passes int) {
+    users, passes = 1, 1
+    if e.opts.SingleUser == "" && e.opts.UserList != "" {
+        users = e.countLines(e.opts.UserList)
+    }
+    if e.opts.SinglePass == "" && e.opts.PassList != "" {
+        if e.passMutator != nil {
+            passes = e.passMutator.countFile(e.opts.PassList)
+        } else {
+            passes = e.countLines(e.opts.PassList)
+        }
+    } else if e.opts.SinglePass != "" && e.passMutator != nil {
+        passes = len(e.passMutator.mutate(e.opts.SinglePass))
+    }
+    return users, passes
+}
+
+// printPlan describes what the run would do: the target, the credential pipeline, the number of
+// attempts and how long they would take, and the files it would write. Nothing is resolved,
+// dialed or written.
+func (e *Engine) printPlan(resume bool, verifyOnly string) {
+    e.theme.heading.Fprintln(e.out, "Dry run - no connections will be opened")
+    e.useSession()
+
+    fmt.Fprintln(e.out, "\nTarget:")
+    if e.opts.CredCSV != "" {
+        fmt.Fprintf(e.out, "  Every host:port named in %s (not resolved)\n", e.opts.CredCSV)
+    } else if e.opts.Discover != "" {
+        hosts, _ := expandDiscoverTargets(e.opts.Discover)
+        fmt.Fprintf(e.out, "  Sweep of %d address(es) on port(s) %s, then every MySQL server found\n", len(hosts), e.opts.DiscoverPorts)
+    } else {
+        fmt.Fprintf(e.out, "  %s:%d (not resolved)\n", e.opts.Host, e.opts.Port)
+    }
+    switch {
+    case e.opts.SkipSSL:
+        fmt.Fprintln(e.out, "  TLS: disabled")
+    case e.opts.UseSSL:
+        fmt.Fprintln(e.out, "  TLS: required")
+    default:
+        fmt.Fprintln(e.out, "  TLS: preferred")
+    }
+    if e.opts.TLSCA != "" {
+        fmt.Fprintln(e.out, "  TLS CA:", e.opts.TLSCA)
+    }
+    if e.opts.TLSCert != "" {
+        fmt.Fprintln(e.out, "  TLS client certificate:", e.opts.TLSCert)
+    }
+    if e.opts.Sources != "" {
+        fmt.Fprintln(e.out, "  Sources:", e.opts.Sources)
+    }
+
+    attempts := 0
+    rounds := 0
+    fmt.Fprintln(e.out, "\nCredentials:")
+    switch {
+    case verifyOnly != "":
+        records, err := e.loadCredentialCSV(verifyOnly)
+        if err != nil {
+            e.printError("Error reading %s: %v", verifyOnly, err)
+            return
+        }
+        attempts = len(records)
+        fmt.Fprintf(e.out, "  %d credential(s) from %s re-validated (auth + SELECT 1)\n", attempts, verifyOnly)
+    case e.opts.CredCSV != "":
+        targets, err := e.loadCredCSVTargets(e.opts.CredCSV)
+        if err != nil {
+            e.printError("Error reading %s: %v", e.opts.CredCSV, err)
+            return
+        }
+        for _, rows := range targets {
+            attempts += len(rows)
+        }
+        fmt.Fprintf(e.out, "  %d credential(s) from %s, one attempt each, across %d target(s) tested one after another\n",
+            attempts, e.opts.CredCSV, len(targets))
+        if e.opts.Follow {
+            fmt.Fprintln(e.out, "  Rows appended to the file are replayed as they are written, until interrupted")
+        }
+        if e.opts.FirstOnly {
+            fmt.Fprintln(e.out, "  Stops at the first valid credential")
+        }
+    case e.opts.Defaults && e.opts.SingleUser == "" && e.opts.UserList == "":
+        creds, _ := e.loadDefaultCreds(e.opts.DefaultsFile)
+        attempts = len(creds)
+        fmt.Fprintf(e.out, "  %d default credential(s), one attempt each\n", attempts)
+        if e.opts.FirstOnly {
+            fmt.Fprintln(e.out, "  Stops at the first valid credential")
+        }
+    case e.opts.Dump:
+        attempts = 1
+        what := "a dump"
+        if e.opts.DumpSchemaOnly {
+            what = "a schema-only dump"
+        } else if e.opts.DumpDataOnly {
+            what = "a data-only dump"
+        }
+        fmt.Fprintf(e.out, "  Single login as %s, then %s of every database\n", e.opts.SingleUser, what)
+    default:
+        users, passes := e.credentialCounts()
+        userSource, passSource := "-u", "no password"
+        if e.opts.UserList != "" {
+            userSource = e.opts.UserList
+        }
+        if e.opts.PassList != "" {
+            passSource = e.opts.PassList
+        } else if e.opts.SinglePass != "" {
+            passSource = "-p"
+        }
+        if e.passMutator != nil {
+            passSource += " (mutated"
+            if e.opts.Rules != "" {
+                passSource += " with " + e.opts.Rules
+            }
+            passSource += ")"
+        }
+        fmt.Fprintf(e.out, "  Usernames: %d from %s\n", users, userSource)
+        fmt.Fprintf(e.out, "  Passwords: %d from %s\n", passes, passSource)
+
+        perUser := passes
+        if e.opts.MaxAttemptsPerUser > 0 && e.opts.MaxAttemptsPerUser < perUser {
+            perUser = e.opts.MaxAttemptsPerUser
+            fmt.Fprintf(e.out, "  Capped at %d attempt(s) per user by --max-attempts-per-user\n", perUser)
+        }
+        attempts = users * perUser
+        if e.opts.Defaults {
+            creds, _ := e.loadDefaultCreds(e.opts.DefaultsFile)
+            attempts += len(creds)
+            fmt.Fprintf(e.out, "  First: %d default credential(s), one attempt each\n", len(creds))
+        }
+
+        switch {
+        case e.opts.Follow:
+            fmt.Fprintln(e.out, "  Ordering: as lines arrive, the followed wordlists are read until interrupted")
+        case e.opts.Spray:
+            rounds = perUser
+            fmt.Fprintf(e.out, "  Ordering: spray, %d round(s) of one password across all users, %d seconds apart\n", rounds, e.opts.SprayInterval)
+        case e.opts.UserFirst:
+            fmt.Fprintln(e.out, "  Ordering: user-first, every password for one user before the next")
+        default:
+            fmt.Fprintln(e.out, "  Ordering: password-first, every user for one password before the next")
+        }
+        if resume && e.fileExists(e.statePath) {
+            e.hashWordlists()
+            state := e.loadState()
+            fmt.Fprintf(e.out, "  Resuming at pair %d of %d from %s; pairs workers had in flight past it are tested again\n",
+                state.Completed, state.Total, e.statePath)
+            if err := e.checkResumeState(state); err != nil {
+                e.printWarning("  %v", err)
+            }
+        }
+        if e.opts.FirstOnly {
+            fmt.Fprintln(e.out, "  Stops at the first valid credential")
+        }
+    }
+
+    fmt.Fprintln(e.out, "\nAttempts:")
+    fmt.Fprintf(e.out, "  Total: %d with %d worker(s)\n", attempts, e.opts.Workers)
+    var pauses time.Duration
+    if rounds > 1 {
+        pauses = time.Duration(rounds-1) * time.Duration(e.opts.SprayInterval) * time.Second
+    }
+    if e.attemptLimiter != nil {
+        duration := time.Duration(attempts)*e.attemptLimiter.interval + pauses
+        fmt.Fprintf(e.out, "  Estimated duration: %s at one attempt every %s\n", duration.Round(time.Second), e.attemptLimiter.interval)
+    } else {
+        workers := e.opts.Workers
+        if workers < 1 {
+            workers = 1
+        }
+        worst := time.Duration((attempts+workers-1)/workers)*e.attemptTimeout + pauses
+        fmt.Fprintf(e.out, "  Estimated duration: not rate limited, depends on server latency (at most %s if every attempt hits --attempt-timeout)\n",
+            worst.Round(time.Second))
+    }
+    if pauses > 0 {
+        fmt.Fprintf(e.out, "  Includes %s of pauses between spray rounds\n", pauses.Round(time.Second))
+    }
+    if e.opts.Retries > 0 {
+        fmt.Fprintf(e.out, "  Attempts failing with network errors are retried up to %d time(s), which adds to the estimate\n", e.opts.Retries)
+    }
+    if e.blockPause > 0 {
+        fmt.Fprintf(e.out, "  A host block pauses testing for %s and resumes instead of stopping\n", e.blockPause)
+    }
+
+    fmt.Fprintln(e.out, "\nData:", e.formatDataVersions())
+
+    fmt.Fprintln(e.out, "\nFiles that would be written:")
+    var files []string
+    if e.campaignDir != "" {
+        history := filepath.Join(e.campaignDir, campaignFile) + " (campaign run history"
+        if e.opts.ShredAfter != "" {
+            history += ", shred date " + e.opts.ShredAfter + " after this run"
+        }
+        files = append(files, history+")")
+    }
+    if e.opts.LogFile != "" {
+        files = append(files, e.opts.LogFile+" (log, appended)")
+    }
+    if e.opts.ResultsDB != "" {
+        files = append(files, e.opts.ResultsDB+" (results database)")
+    }
+    if e.opts.AuditLog != "" {
+        files = append(files, e.opts.AuditLog+" (statement audit log, appended)")
+    }
+    if verifyOnly == "" && !e.opts.Dump {
+        files = append(files, e.statePath+" (resume state)")
+    }
+    if e.opts.Enum && e.opts.EnumOutputFile != "" {
+        files = append(files, e.opts.EnumOutputFile+" (enumeration results, on success)")
+    }
+    if e.opts.Dump {
+        files = append(files, e.opts.DumpDir+"/ (database dump, on success)")
+    }
+    if e.opts.LootHashes {
+        files = append(files, e.opts.LootDir+"/ (password hashes, on success)")
+    }
+    if e.opts.SARIFFile != "" {
+        files = append(files, e.opts.SARIFFile+" (SARIF findings)")
+    }
+    if e.opts.JUnitFile != "" {
+        files = append(files, e.opts.JUnitFile+" (JUnit report)")
+    }
+    if len(files) == 0 {
+        fmt.Fprintln(e.out, "  None")
+    }
+    for _, f := range files {
+        fmt.Fprintln(e.out, " ", f)
+    }
+
+    if e.opts.PushDefectDojo != "" || e.opts.PushFaraday != "" {
+        fmt.Fprintln(e.out, "\nFindings would be uploaded after the run to:")
+        if e.opts.PushDefectDojo != "" {
+            fmt.Fprintf(e.out, "  DefectDojo %s (engagement %s)\n", e.opts.PushDefectDojo, e.opts.PushEngagement)
+        }
+        if e.opts.PushFaraday != "" {
+            fmt.Fprintf(e.out, "  Faraday %s (workspace %s)\n", e.opts.PushFaraday, e.opts.PushEngagement)
+        }
+    }
+}
@@ -0,0 +1,376 @@
+package core
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/schollz/progressbar/v3"
+)
+
+// dumpBufferSize is the write buffer used for each dumped table file
+const dumpBufferSize = 1 << 20
+
+// dumpAllDatabases extracts all data from all accessible databases
+func (e *Engine) dumpAllDatabases(ctx context.Context, sess *session, backend string) string {
+    // Metadata queries can use any pooled connection, table data goes through the session
+    db := sess.db
+    var summary strings.Builder
+    summary.WriteString("Database Dump Summary:\n")
+
+    // Create dump directory if it doesn't exist
+    if err := e.makeRunDir(e.opts.DumpDir, 0755); err != nil {
+        errMsg := fmt.Sprintf("Failed to create dump directory: %v", err)
+        e.printError(errMsg)
+        return errMsg
+    }
+
+    // Every file is checksummed into manifest.json as it is finished
+    manifest := e.newDumpManifest(e.opts.DumpDir)
+
+    // --max-bytes and --max-bytes-per-table cap the table data written
+    budget, err := parseDumpBudget(e.opts.MaxBytes, e.opts.MaxBytesPerTable)
+    if err != nil {
+        e.printError("%v", err)
+        return err.Error()
+    }
+    skippedTables := 0
+
+    // Create an index file for the dump
+    indexPath := e.dumpFileName(filepath.Join(e.opts.DumpDir, "dump_index.txt"))
+    indexFile, err := e.createDumpFile(indexPath)
+    if err != nil {
+        errMsg := fmt.Sprintf("Failed to create dump index file: %v", err)
+        e.printError(errMsg)
+        return errMsg
+    }
+    defer indexFile.Close()
+
+    // Write header to index file
+    hostname, _ := os.Hostname()
+    indexFile.WriteString(fmt.Sprintf("MySQL Dump from %s to %s:%d\n", hostname, e.opts.Host, e.opts.Port))
+    indexFile.WriteString(fmt.Sprintf("Date: %s\n", time.Now().Format(time.RFC1123)))
+    indexFile.WriteString(fmt.Sprintf("User: %s\n\n", e.opts.SingleUser))
+
+    // Get server version
+    var version string
+    err = db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version)
+    if err != nil {
+        summary.WriteString(fmt.Sprintf("Error getting server version: %v\n", err))
+    } else {
+        indexFile.WriteString(fmt.Sprintf("Server Version: %s\n", version))
+        summary.WriteString(fmt.Sprintf("Server Version: %s\n", version))
+    }
+    if backend != "" {
+        indexFile.WriteString(fmt.Sprintf("Backend: %s\n", backend))
+        summary.WriteString(fmt.Sprintf("Backend: %s\n", backend))
+    }
+    indexFile.WriteString("\n")
+
+    // Look for sensitive values while the rows pass through, instead of grepping the files later
+    var secrets *secretScanner
+    if e.opts.FindSecrets {
+        if detectors, err := e.secretDetectors(); err != nil {
+            e.printError("Error loading secret detectors: %v", err)
+        } else {
+            secrets = e.newSecretScanner(detectors)
+        }
+    }
+
+    // Get list of databases
+    dbRows, err := db.QueryContext(ctx, databasesQuery(backend))
+    if err != nil {
+        errMsg := fmt.Sprintf("Failed to list databases: %v", err)
+        e.printError(errMsg)
+        summary.WriteString(errMsg + "\n")
+        return summary.String()
+    }
+    defer dbRows.Close()
+
+    // Create a progress bar for databases
+    var databases []string
+    for dbRows.Next() {
+        var dbName string
+        if err := dbRows.Scan(&dbName); err != nil {
+            fmt.Fprintf(e.out, "Error reading database name: %v\n", err)
+            continue
+        }
+        databases = append(databases, dbName)
+    }
+
+    summary.WriteString(fmt.Sprintf("Found %d databases\n", len(databases)))
+    indexFile.WriteString(fmt.Sprintf("Databases: %d\n\n", len(databases)))
+
+    // Create database progress bar
+    dbBar := progressbar.NewOptions(len(databases),
+        progressbar.OptionSetDescription("Dumping databases"),
+        progressbar.OptionSetWidth(50),
+        progressbar.OptionShowCount(),
+        progressbar.OptionSetWriter(e.out),
+    )
+
+    // Process each database
+    for _, dbName := range databases {
+        // Skip system databases if they exist
+        if isSystemDB(dbName) || isBackendSystemDB(backend, dbName) {
+            summary.WriteString(fmt.Sprintf("Skipped system database: %s\n", dbName))
+            indexFile.WriteString(fmt.Sprintf("Database: %s (skipped - system database)\n", dbName))
+            dbBar.Add(1)
+            continue
+        }
+
+        // Create a directory for this database
+        dbDir := filepath.Join(e.opts.DumpDir, sanitizeFilename(dbName))
+        if err := e.makeRunDir(dbDir, 0755); err != nil {
+            summary.WriteString(fmt.Sprintf("Failed to create directory for %s: %v\n", dbName, err))
+            dbBar.Add(1)
+            continue
+        }
+
+        // Write database info to index
+        indexFile.WriteString(fmt.Sprintf("Database: %s\n", dbName))
+
+        // Get tables for this database
+        tableCtx, cancel := e.withQueryTimeout(ctx)
+        tableRows, err := db.QueryContext(tableCtx, tablesQuery(backend, dbName))
+
+        if err != nil {
+            cancel()
+            summary.WriteString(fmt.Sprintf("Failed to list tables in %s: %v\n", dbName, err))
+            indexFile.WriteString(fmt.Sprintf("  Error: %v\n", err))
+            dbBar.Add(1)
+            continue
+        }
+
+        // Collect table names
+        var tables []string
+        for tableRows.Next() {
+            var tableName string
+            if err := tableRows.Scan(&tableName); err != nil {
+                fmt.Fprintf(e.out, "Error reading table name: %v\n", err)
+                continue
+            }
+            tables = append(tables, tableName)
+        }
+        tableRows.Close()
+        cancel()
+
+        // SHOW TABLES lists views too; they go to schema.sql with the routines, triggers and
+        // events instead of getting data files
+        objCtx, objCancel := e.withQueryTimeout(ctx)
+        objects := e.listSchemaObjects(objCtx, db, backend, dbName)
+        objCancel()
+        views := make(map[string]bool)
+        keptObjects := objects[:0]
+        for _, obj := range objects {
+            if obj.kind == "VIEW" {
+                views[obj.name] = true
+                if !e.dumpTableFilter.allows(dbName, obj.name) {
+                    continue
+                }
+            }
+            keptObjects = append(keptObjects, obj)
+        }
+        objects = keptObjects
+
+        // Apply --dump-include and --dump-exclude
+        filtered := 0
+        kept := tables[:0]
+        for _, tableName := range tables {
+            if views[tableName] {
+                continue
+            }
+            if e.dumpTableFilter.allows(dbName, tableName) {
+                kept = append(kept, tableName)
+            } else {
+                filtered++
+            }
+        }
+        tables = kept
+        if filtered > 0 {
+            summary.WriteString(fmt.Sprintf("Filtered out %d tables in %s\n", filtered, dbName))
+            indexFile.WriteString(fmt.Sprintf("  Filtered out: %d\n", filtered))
+        }
+
+        // Write tables to index
+        indexFile.WriteString(fmt.Sprintf("  Tables: %d\n", len(tables)))
+        for _, tableName := range tables {
+            indexFile.WriteString(fmt.Sprintf("    - %s\n", tableName))
+        }
+
+        // Create the schema file for this database, keeping the table statements for SQL
+        // dumps. --dump-data-only writes neither.
+        createStmts := make(map[string]string)
+        if !e.opts.DumpDataOnly {
+            objectCounts, err := e.writeSchemaFile(ctx, db, backend, dbDir, dbName, tables, objects, createStmts)
+            if err != nil {
+                summary.WriteString(fmt.Sprintf("Failed to create schema file for %s: %v\n", dbName, err))
+            } else {
+                if err := manifest.add(e.dumpFileName(filepath.Join(dbDir, "schema.sql")), manifestEntry{Kind: "schema", Database: dbName}); err != nil {
+                    summary.WriteString(fmt.Sprintf("Failed to checksum schema file for %s: %v\n", dbName, err))
+                }
+                if desc := schemaObjectSummary(objectCounts); desc != "" {
+                    indexFile.WriteString(fmt.Sprintf("  Schema objects: %s\n", desc))
+                    summary.WriteString(fmt.Sprintf("Schema objects in %s: %s\n", dbName, desc))
+                }
+            }
+        }
+
+        // --dump-schema-only stops before the slow data phase
+        if e.opts.DumpSchemaOnly {
+            summary.WriteString(fmt.Sprintf("Database %s: schema of %d tables\n", dbName, len(tables)))
+            e.emitRecord("dump", map[string]interface{}{"user": e.opts.SingleUser, "database": dbName, "tables": len(tables), "rows": 0,
+                "dir": dbDir, "schemaOnly": true})
+            dbBar.Add(1)
+            continue
+        }
+
+        // Create a progress bar for tables
+        if !e.opts.QuietDump {
+            fmt.Fprintf(e.out, "\nDumping database: %s (%d tables)\n", dbName, len(tables))
+        }
+
+        tableBar := progressbar.NewOptions(len(tables),
+            progressbar.OptionSetDescription(fmt.Sprintf("Tables in %s", dbName)),
+            progressbar.OptionSetWidth(40),
+            progressbar.OptionShowCount(),
+            progressbar.OptionSetWriter(e.out),
+        )
+
+        tableCount := 0
+        rowCount := 0
+
+        // Process each table
+        for _, tableName := range tables {
+            // Once --max-bytes is spent the remaining tables are only counted
+            if budget.spent() {
+                skippedTables++
+                tableBar.Add(1)
+                continue
+            }
+
+            // Use database
+            useCtx, useCancel := e.withQueryTimeout(ctx)
+            _, err := sess.exec(useCtx, fmt.Sprintf("USE `%s`", dbName))
+            useCancel()
+
+            if err != nil {
+                summary.WriteString(fmt.Sprintf("Failed to use database %s: %v\n", dbName, err))
+                tableBar.Add(1)
+                continue
+            }
+
+            // Page through the table so no single query has to outlive --query-timeout
+            tableRows, parts, err := e.dumpTable(ctx, sess, dbDir, dbName, tableName, createStmts[tableName], secrets, manifest, budget)
+            rowCount += tableRows
+            tableBar.Add(1)
+            var truncated *dumpTruncatedError
+            if err != nil && !errors.As(err, &truncated) {
+                summary.WriteString(fmt.Sprintf("Failed to dump %s.%s after %d rows: %v\n", dbName, tableName, tableRows, err))
+                continue
+            }
+            tableCount++
+
+            // Note in summary
+            var matching string
+            if where := e.rowFilter(dbName, tableName); where != "" {
+                matching = " matching " + where
+            }
+            if parts > 1 {
+                matching += fmt.Sprintf(" in %d files", parts)
+            }
+            if truncated != nil {
+                matching += fmt.Sprintf(", truncated: %v", truncated)
+            }
+            summary.WriteString(fmt.Sprintf("Dumped %s.%s: %d rows%s\n", dbName, tableName, tableRows, matching))
+        }
+
+        // Add database summary
+        summary.WriteString(fmt.Sprintf("Database %s: %d tables, %d total rows\n", dbName, tableCount, rowCount))
+        e.emitRecord("dump", map[string]interface{}{"user": e.opts.SingleUser, "database": dbName, "tables": tableCount, "rows": rowCount, "dir": dbDir})
+        dbBar.Add(1)
+    }
+
+    if secrets != nil {
+        if path, err := secrets.writeReport(e.opts.DumpDir); err != nil {
+            summary.WriteString(fmt.Sprintf("Failed to write secrets report: %v\n", err))
+        } else {
+            summary.WriteString(fmt.Sprintf("Secrets: %d matches, report saved to %s\n", secrets.matches(), path))
+            if err := manifest.add(path, manifestEntry{Kind: "report"}); err != nil {
+                summary.WriteString(fmt.Sprintf("Failed to checksum secrets report: %v\n", err))
+            }
+        }
+    }
+
+    if skippedTables > 0 {
+        summary.WriteString(fmt.Sprintf("Stopped at --max-bytes %s: %d tables not dumped\n", formatByteSize(budget.total), skippedTables))
+    }
+
+    // Final summary
+    summary.WriteString(fmt.Sprintf("\nDump complete. Files saved to %s\n", e.opts.DumpDir))
+    if e.dumpRecipients != nil {
+        summary.WriteString("Dump files are encrypted with age, decrypt them with 'age -d'\n")
+    }
+    e.emitRecord("dump_summary", map[string]interface{}{"user": e.opts.SingleUser, "databases": len(databases), "dir": e.opts.DumpDir, "summary": summary.String()})
+
+    // Write summary to index file
+    indexFile.WriteString("\nSummary:\n")
+    indexFile.WriteString(summary.String())
+
+    // The index is complete now, and the manifest goes last so it covers everything else
+    if err := indexFile.Close(); err != nil {
+        summary.WriteString(fmt.Sprintf("Failed to write dump index: %v\n", err))
+    } else if err := manifest.add(indexPath, manifestEntry{Kind: "index"}); err != nil {
+        summary.WriteString(fmt.Sprintf("Failed to checksum dump index: %v\n", err))
+    }
+    if path, sum, err := manifest.write(); err != nil {
+        summary.WriteString(fmt.Sprintf("Failed to write manifest: %v\n", err))
+    } else {
+        summary.WriteString(fmt.Sprintf("Manifest: %s (sha256 %s), check with 'sqlblaster verify-dump %s'\n", path, sum, e.opts.DumpDir))
+    }
+
+    return summary.String()
+}
+
+// isSystemDB checks if a database is a system database that should be skipped
+func isSystemDB(name string) bool {
+    systemDBs := []string{"information_schema", "performance_schema", "mysql", "sys"}
+    name = strings.ToLower(name)
+    for _, sysDB := range systemDBs {
+        if name == sysDB {
+            return true
+        }
+    }
+    return false
+}
+
+// sanitizeFilename makes a string safe to use as a filename
+func sanitizeFilename(name string) string {
+    name = strings.ReplaceAll(name, "/", "_")
+    name = strings.ReplaceAll(name, "\\", "_")
+    name = strings.ReplaceAll(name, ":", "_")
+    name = strings.ReplaceAll(name, "*", "_")
+    name = strings.ReplaceAll(name, "?", "_")
+    name = strings.ReplaceAll(name, "\"", "_")
+    name = strings.ReplaceAll(name, "<", "_")
+    name = strings.ReplaceAll(name, ">", "_")
+    name = strings.ReplaceAll(name, "|", "_")
+    name = strings.ReplaceAll(name, " ", "_")
+    return name
+}
+
+// formatValueForCSV formats a value as a CSV field, NULL as --csv-null-string. Quoting, and
+// telling NULL from a string equal to the null string, is left to csvWriter.
+func (e *Engine) formatValueForCSV(val interface{}) string {
+    if val == nil {
+        return e.opts.CSVNullString
+    }
+    if b, ok := val.([]byte); ok {
+        return string(b)
+    }
+    return fmt.Sprintf("%v", val)
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+    "encoding/base64"
+    "encoding/hex"
+    "fmt"
+    "path/filepath"
+)
+
+// blobModes lists the supported --blob-mode values
+var blobModes = []string{"raw", "skip", "hex", "base64", "file"}
+
+// validBlobMode reports whether mode is a supported --blob-mode
+func validBlobMode(mode string) bool {
+    for _, m := range blobModes {
+        if m == mode {
+            return true
+        }
+    }
+    return false
+}
+
+// setupBlobs works out the columns a CSV or JSON Lines file is written with under --blob-mode:
+// binary columns become text, or are left out with skip. SQL and Parquet hold binary data as it
+// is, so they keep their columns.
+func (t *tableDumpWriter) setupBlobs() {
+    t.outColumns, t.outKinds = t.columns, t.kinds
+    if t.blobMode == "" || t.blobMode == "raw" || (t.format != "csv" && t.format != "jsonl") {
+        t.blobMode = ""
+        return
+    }
+    t.outColumns, t.outKinds = nil, nil
+    for i, col := range t.columns {
+        if t.kinds[i] == kindBytes {
+            if t.blobMode == "skip" {
+                continue
+            }
+            t.outColumns = append(t.outColumns, col)
+            t.outKinds = append(t.outKinds, kindString)
+            continue
+        }
+        t.outColumns = append(t.outColumns, col)
+        t.outKinds = append(t.outKinds, t.kinds[i])
+    }
+}
+
+// blobRow returns a row's values in the order of outColumns, binary values encoded, written to
+// their own file or left out. NULL stays NULL.
+func (t *tableDumpWriter) blobRow(values []interface{}) []interface{} {
+    if t.blobMode == "" {
+        return values
+    }
+    t.rowNum++
+    out := make([]interface{}, 0, len(t.outColumns))
+    for i, val := range values {
+        if t.kinds[i] != kindBytes {
+            out = append(out, val)
+            continue
+        }
+        if t.blobMode == "skip" {
+            continue
+        }
+        b, ok := val.([]byte)
+        if !ok {
+            out = append(out, val)
+            continue
+        }
+        switch t.blobMode {
+        case "hex":
+            out = append(out, hex.EncodeToString(b))
+        case "base64":
+            out = append(out, base64.StdEncoding.EncodeToString(b))
+        case "file":
+            path, err := t.writeBlob(t.columns[i], b)
+            if err != nil {
+                if t.err == nil {
+                    t.err = err
+                }
+                out = append(out, nil)
+                continue
+            }
+            out = append(out, path)
+        }
+    }
+    return out
+}
+
+// writeBlob saves one binary value for --blob-mode file as <table>.blobs/<row>_<column>.bin
+// next to the table's files, and returns that path relative to them
+func (t *tableDumpWriter) writeBlob(column string, b []byte) (string, error) {
+    dir := filepath.Join(t.dir, sanitizeFilename(t.tableName)+".blobs")
+    if !t.blobDir {
+        if err := t.engine.makeRunDir(dir, 0755); err != nil {
+            return "", err
+        }
+        t.blobDir = true
+    }
+    path := t.engine.dumpFileName(filepath.Join(dir, fmt.Sprintf("%d_%s.bin", t.rowNum, sanitizeFilename(column))))
+    file, err := t.engine.createDumpFile(path)
+    if err != nil {
+        return "", err
+    }
+    _, err = file.Write(b)
+    if closeErr := file.Close(); err == nil {
+        err = closeErr
+    }
+    if err != nil {
+        return "", err
+    }
+    t.written += file.size()
+    if err := t.manifest.add(path, manifestEntry{Kind: "blob", Database: t.dbName, Table: t.tableName}); err != nil {
+        return "", err
+    }
+    rel, _ := filepath.Rel(t.dir, path)
+    return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,66 @@
+package core
+
+import "fmt"
+
+// dumpBudget enforces --max-bytes and --max-bytes-per-table on the table data files, as
+// written to disk. Output still buffered by gzip or a Parquet row group is counted once it is
+// flushed, so those formats can overshoot a limit by up to one buffer.
+type dumpBudget struct {
+    total    uint64
+    perTable uint64
+    used     uint64
+}
+
+// dumpTruncatedError ends a table's dump early because a byte limit was reached. The rows
+// written before it are complete.
+type dumpTruncatedError struct {
+    option string
+    limit  uint64
+}
+
+func (t *dumpTruncatedError) Error() string {
+    return fmt.Sprintf("%s %s reached", t.option, formatByteSize(t.limit))
+}
+
+// parseDumpBudget parses the --max-bytes and --max-bytes-per-table sizes, either of which may
+// be empty for no limit
+func parseDumpBudget(total, perTable string) (*dumpBudget, error) {
+    b := &dumpBudget{}
+    var err error
+    if total != "" {
+        if b.total, err = parseByteSize(total); err != nil {
+            return nil, fmt.Errorf("max bytes: %v", err)
+        }
+    }
+    if perTable != "" {
+        if b.perTable, err = parseByteSize(perTable); err != nil {
+            return nil, fmt.Errorf("max bytes per table: %v", err)
+        }
+    }
+    return b, nil
+}
+
+// tableLimit returns how many bytes the next table may write and the error that truncates it
+// there, or nil when the table is not limited
+func (b *dumpBudget) tableLimit() (uint64, *dumpTruncatedError) {
+    var allowed uint64
+    var reason *dumpTruncatedError
+    if b.perTable > 0 {
+        allowed, reason = b.perTable, &dumpTruncatedError{option: "--max-bytes-per-table", limit: b.perTable}
+    }
+    if b.total > 0 {
+        var left uint64
+        if b.used < b.total {
+            left = b.total - b.used
+        }
+        if reason == nil || left < allowed {
+            allowed, reason = left, &dumpTruncatedError{option: "--max-bytes", limit: b.total}
+        }
+    }
+    return allowed, reason
+}
+
+// spent reports whether --max-bytes leaves nothing for another table
+func (b *dumpBudget) spent() bool {
+    return b.total > 0 && b.used >= b.total
+}
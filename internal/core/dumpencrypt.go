@@ -0,0 +1,135 @@
+package core
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "strings"
+
+    "filippo.io/age"
+)
+
+// parseDumpEncrypt parses --dump-encrypt: 'age:' and comma-separated X25519 recipients
+// (age1...), or 'passphrase:' and a file whose first line is the passphrase
+func parseDumpEncrypt(spec string) ([]age.Recipient, error) {
+    if spec == "" {
+        return nil, nil
+    }
+    mode, value, _ := strings.Cut(spec, ":")
+    switch mode {
+    case "age":
+        var recipients []age.Recipient
+        for _, s := range strings.Split(value, ",") {
+            r, err := age.ParseX25519Recipient(strings.TrimSpace(s))
+            if err != nil {
+                return nil, fmt.Errorf("invalid age recipient '%s': %v", strings.TrimSpace(s), err)
+            }
+            recipients = append(recipients, r)
+        }
+        return recipients, nil
+    case "passphrase":
+        data, err := os.ReadFile(value)
+        if err != nil {
+            return nil, err
+        }
+        passphrase, _, _ := strings.Cut(strings.TrimRight(string(data), "\r\n"), "\n")
+        if passphrase == "" {
+            return nil, fmt.Errorf("passphrase file '%s' is empty", value)
+        }
+        r, err := age.NewScryptRecipient(passphrase)
+        if err != nil {
+            return nil, err
+        }
+        return []age.Recipient{r}, nil
+    }
+    return nil, fmt.Errorf("dump encryption must be 'age:<recipient>' or 'passphrase:<file>', not '%s'", spec)
+}
+
+// setupDumpEncrypt parses --dump-encrypt into who the dump's files are encrypted to, none to
+// write them in plaintext
+func (e *Engine) setupDumpEncrypt(spec string) error {
+    recipients, err := parseDumpEncrypt(spec)
+    if err != nil {
+        return err
+    }
+    e.dumpRecipients = recipients
+    return nil
+}
+
+// dumpFileName adds the .age suffix to a dump file's path when the dump is encrypted
+func (e *Engine) dumpFileName(path string) string {
+    if e.dumpRecipients == nil {
+        return path
+    }
+    return path + ".age"
+}
+
+// dumpFile is a dump file that, with --dump-encrypt, is encrypted on its way to disk, so none
+// of its plaintext is ever written. It can be closed more than once.
+type dumpFile struct {
+    file   *os.File
+    disk   *countingWriter
+    enc    io.WriteCloser
+    closed bool
+    err    error
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+    w io.Writer
+    n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+    n, err := c.w.Write(p)
+    c.n += int64(n)
+    return n, err
+}
+
+// createDumpFile creates the file at path, a name from dumpFileName
+func (e *Engine) createDumpFile(path string) (*dumpFile, error) {
+    file, err := os.Create(path)
+    if err != nil {
+        return nil, err
+    }
+    e.noteCreated(path)
+    f := &dumpFile{file: file, disk: &countingWriter{w: file}}
+    if e.dumpRecipients != nil {
+        if f.enc, err = age.Encrypt(f.disk, e.dumpRecipients...); err != nil {
+            file.Close()
+            return nil, err
+        }
+    }
+    return f, nil
+}
+
+func (f *dumpFile) Write(p []byte) (int, error) {
+    if f.enc != nil {
+        return f.enc.Write(p)
+    }
+    return f.disk.Write(p)
+}
+
+func (f *dumpFile) WriteString(s string) (int, error) {
+    return f.Write([]byte(s))
+}
+
+// size returns the bytes written to disk so far
+func (f *dumpFile) size() int64 {
+    return f.disk.n
+}
+
+// Close writes the last encrypted chunk and closes the file
+func (f *dumpFile) Close() error {
+    if f.closed {
+        return f.err
+    }
+    f.closed = true
+    if f.enc != nil {
+        f.err = f.enc.Close()
+    }
+    if err := f.file.Close(); f.err == nil {
+        f.err = err
+    }
+    return f.err
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+    "fmt"
+    "path"
+    "regexp"
+    "strings"
+)
+
+// tablePattern matches "db.table" names by glob, or by regular expression when written as /regex/
+type tablePattern struct {
+    glob string
+    re   *regexp.Regexp
+}
+
+// tableFilter keeps tables matching any include pattern (all when there are none) and no exclude pattern
+type tableFilter struct {
+    include []tablePattern
+    exclude []tablePattern
+}
+
+// parseTablePatterns parses a comma-separated list of glob or /regex/ patterns
+func parseTablePatterns(list string) ([]tablePattern, error) {
+    var patterns []tablePattern
+    for _, p := range strings.Split(list, ",") {
+        p = strings.TrimSpace(p)
+        if p == "" {
+            continue
+        }
+        if len(p) > 1 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+            re, err := regexp.Compile(p[1 : len(p)-1])
+            if err != nil {
+                return nil, fmt.Errorf("invalid regex %s: %v", p, err)
+            }
+            patterns = append(patterns, tablePattern{re: re})
+            continue
+        }
+        if _, err := path.Match(p, ""); err != nil {
+            return nil, fmt.Errorf("invalid glob '%s': %v", p, err)
+        }
+        patterns = append(patterns, tablePattern{glob: p})
+    }
+    return patterns, nil
+}
+
+// parseTableFilter builds a filter from include and exclude pattern lists, returning nil when both are empty
+func parseTableFilter(include, exclude string) (*tableFilter, error) {
+    inc, err := parseTablePatterns(include)
+    if err != nil {
+        return nil, fmt.Errorf("--dump-include: %v", err)
+    }
+    exc, err := parseTablePatterns(exclude)
+    if err != nil {
+        return nil, fmt.Errorf("--dump-exclude: %v", err)
+    }
+    if len(inc) == 0 && len(exc) == 0 {
+        return nil, nil
+    }
+    return &tableFilter{include: inc, exclude: exc}, nil
+}
+
+// setupDumpFilter parses --dump-include and --dump-exclude into the filter selecting the tables
+// dumped, nil to dump everything
+func (e *Engine) setupDumpFilter(include, exclude string) error {
+    filter, err := parseTableFilter(include, exclude)
+    if err != nil {
+        return err
+    }
+    e.dumpTableFilter = filter
+    return nil
+}
+
+// matches reports whether the pattern matches the qualified table name
+func (p tablePattern) matches(name string) bool {
+    if p.re != nil {
+        return p.re.MatchString(name)
+    }
+    ok, _ := path.Match(p.glob, name)
+    return ok
+}
+
+// allows reports whether dbName.tableName should be dumped. A nil filter allows every table.
+func (f *tableFilter) allows(dbName, tableName string) bool {
+    if f == nil {
+        return true
+    }
+    name := dbName + "." + tableName
+    included := len(f.include) == 0
+    for _, p := range f.include {
+        if p.matches(name) {
+            included = true
+            break
+        }
+    }
+    if !included {
+        return false
+    }
+    for _, p := range f.exclude {
+        if p.matches(name) {
+            return false
+        }
+    }
+    return true
+}
+
+// wherePredicate limits the rows dumped from tables matching a db.table glob
+type wherePredicate struct {
+    table     tablePattern
+    predicate string
+}
+
+// parseDumpWhere parses 'db.table:predicate' filters. The name ends at the first colon, so the
+// predicate may contain more.
+func parseDumpWhere(list []string) ([]wherePredicate, error) {
+    var predicates []wherePredicate
+    for _, item := range list {
+        name, predicate, ok := strings.Cut(item, ":")
+        name, predicate = strings.TrimSpace(name), strings.TrimSpace(predicate)
+        if !ok || name == "" || predicate == "" {
+            return nil, fmt.Errorf("--dump-where '%s': use db.table:predicate", item)
+        }
+        // The dump session runs multiple statements, so a ; must not end the condition early
+        if stmts, _ := splitStatements(predicate); len(stmts) > 0 {
+            return nil, fmt.Errorf("--dump-where '%s': the predicate must be one condition without ;", item)
+        }
+        if !strings.Contains(name, ".") {
+            return nil, fmt.Errorf("--dump-where '%s': table must be qualified as db.table", item)
+        }
+        if _, err := path.Match(name, ""); err != nil {
+            return nil, fmt.Errorf("--dump-where '%s': invalid glob: %v", item, err)
+        }
+        predicates = append(predicates, wherePredicate{table: tablePattern{glob: name}, predicate: predicate})
+    }
+    return predicates, nil
+}
+
+// setupDumpWhere parses --dump-where into the dump's row filters, nil to dump every row
+func (e *Engine) setupDumpWhere(list []string) error {
+    predicates, err := parseDumpWhere(list)
+    if err != nil {
+        return err
+    }
+    e.dumpWhere = predicates
+    return nil
+}
+
+// rowFilter returns the WHERE condition for dbName.tableName, every matching predicate joined
+// with AND, or "" to dump all of its rows
+func (e *Engine) rowFilter(dbName, tableName string) string {
+    name := dbName + "." + tableName
+    var conds []string
+    for _, w := range e.dumpWhere {
+        if w.table.matches(name) {
+            conds = append(conds, "("+w.predicate+")")
+        }
+    }
+    return strings.Join(conds, " AND ")
+}
@@ -0,0 +1,455 @@
+package core
+
+import (
+    "bufio"
+    "compress/gzip"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "path/filepath"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/parquet-go/parquet-go"
+)
+
+// sqlInsertBatch is the number of rows per extended INSERT statement in SQL dumps
+const sqlInsertBatch = 100
+
+// dumpFormats lists the supported --dump-format values
+var dumpFormats = []string{"csv", "sql", "jsonl", "parquet"}
+
+// validDumpFormat reports whether format is a supported --dump-format
+func validDumpFormat(format string) bool {
+    for _, f := range dumpFormats {
+        if f == format {
+            return true
+        }
+    }
+    return false
+}
+
+// parseCSVDelimiter parses --csv-delimiter: a single character, or "tab" or \t for a tab
+func parseCSVDelimiter(s string) (rune, error) {
+    if s == "tab" || s == `\t` {
+        return '\t', nil
+    }
+    r, size := utf8.DecodeRuneInString(s)
+    if size == 0 || size != len(s) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+        return 0, fmt.Errorf("invalid CSV delimiter '%s': use a single character other than a quote or newline", s)
+    }
+    return r, nil
+}
+
+// csvWriter writes RFC 4180 records as encoding/csv does, except that a value that reads the
+// same as --csv-null-string is quoted. An unquoted null string then always means NULL: with the
+// default NULL, the string 'NULL' is written "NULL", and with --csv-null-string '' a NULL is an
+// empty field while the empty string is "".
+type csvWriter struct {
+    w     *bufio.Writer
+    comma rune
+    null  string
+    err   error
+}
+
+// newCSVWriter returns a csvWriter with the run's --csv-delimiter and --csv-null-string
+func (e *Engine) newCSVWriter(w *bufio.Writer) *csvWriter {
+    comma, _ := parseCSVDelimiter(e.opts.CSVDelimiter)
+    return &csvWriter{w: w, comma: comma, null: e.opts.CSVNullString}
+}
+
+// Write writes a record of strings, such as the header
+func (c *csvWriter) Write(record []string) error {
+    return c.write(record, nil)
+}
+
+// WriteValues writes a row of scanned values, NULLs as the null string
+func (c *csvWriter) WriteValues(values []interface{}, format func(interface{}) string) error {
+    fields := make([]string, len(values))
+    nulls := make([]bool, len(values))
+    for i, val := range values {
+        fields[i] = format(val)
+        nulls[i] = val == nil
+    }
+    return c.write(fields, nulls)
+}
+
+// write writes one record. Fields flagged in nulls are NULLs and stay unquoted unless the null
+// string itself would break the row.
+func (c *csvWriter) write(fields []string, nulls []bool) error {
+    for i, field := range fields {
+        if i > 0 {
+            c.w.WriteRune(c.comma)
+        }
+        isNull := nulls != nil && nulls[i]
+        if !c.needsQuotes(field) && (isNull || field != c.null) {
+            c.w.WriteString(field)
+            continue
+        }
+        c.w.WriteByte('"')
+        c.w.WriteString(strings.ReplaceAll(field, `"`, `""`))
+        c.w.WriteByte('"')
+    }
+    _, err := c.w.WriteString("\n")
+    return err
+}
+
+// needsQuotes reports whether field has to be quoted to be read back as it is, following
+// encoding/csv: it holds the delimiter, a quote or a newline, starts with a space, or is \.
+func (c *csvWriter) needsQuotes(field string) bool {
+    if field == "" {
+        return false
+    }
+    if field == `\.` || strings.ContainsRune(field, c.comma) || strings.ContainsAny(field, "\"\r\n") {
+        return true
+    }
+    r, _ := utf8.DecodeRuneInString(field)
+    return unicode.IsSpace(r)
+}
+
+// Flush writes the buffered records to the underlying writer
+func (c *csvWriter) Flush() {
+    c.err = c.w.Flush()
+}
+
+// Error reports an error from a previous Write or Flush
+func (c *csvWriter) Error() error {
+    return c.err
+}
+
+// tableDumpWriter writes one table's rows in the --dump-format, starting a new part file
+// whenever --max-rows is reached and compressing the output with --dump-compress: gzip around
+// the file, or zstd inside it for Parquet
+type tableDumpWriter struct {
+    engine     *Engine
+    format     string
+    dir        string
+    dbName     string
+    tableName  string
+    columns    []string
+    types      []string
+    createStmt string
+    kinds      []string
+    compress   bool
+
+    file    *dumpFile
+    gz      *gzip.Writer
+    buf     *bufio.Writer
+    csv     *csvWriter
+    pq      *parquet.Writer
+    leaves  []int
+    err     error
+    part    int
+    pending int
+    written int64
+
+    manifest *dumpManifest
+    query    string
+    queries  []string
+    rows     int
+
+    blobMode   string
+    outColumns []string
+    outKinds   []string
+    rowNum     int
+    blobDir    bool
+}
+
+// newTableDumpWriter opens the first file for a table. types are the columns' database type
+// names and createStmt the table's CREATE TABLE, both used by the SQL format. Every finished
+// part is recorded in manifest, which may be nil.
+func (e *Engine) newTableDumpWriter(format, dir, dbName, tableName string, columns, types []string, createStmt string, manifest *dumpManifest) (*tableDumpWriter, error) {
+    t := &tableDumpWriter{
+        engine:     e,
+        format:     format,
+        dir:        dir,
+        dbName:     dbName,
+        tableName:  tableName,
+        columns:    columns,
+        types:      types,
+        createStmt: createStmt,
+        compress:   e.opts.DumpCompress,
+        manifest:   manifest,
+        blobMode:   e.opts.BlobMode,
+    }
+    t.kinds = make([]string, len(types))
+    for i, typ := range types {
+        t.kinds[i] = columnKind(typ)
+    }
+    t.setupBlobs()
+    if err := t.open(); err != nil {
+        return nil, err
+    }
+    return t, nil
+}
+
+// filename returns the path of the current part, e.g. users.csv, users.part2.sql, users.csv.gz
+// or users.csv.gz.age
+func (t *tableDumpWriter) filename() string {
+    name := t.tableName
+    if t.part > 1 {
+        name = fmt.Sprintf("%s.part%d", t.tableName, t.part)
+    }
+    name += "." + t.format
+    if t.compress && t.format != "parquet" {
+        name += ".gz"
+    }
+    return t.engine.dumpFileName(filepath.Join(t.dir, name))
+}
+
+// open starts the next part file and writes its header. A part opened in the middle of a page
+// was read by that page's query too.
+func (t *tableDumpWriter) open() error {
+    if t.file != nil {
+        t.written += t.file.size()
+    }
+    t.part++
+    t.rows, t.queries = 0, nil
+    if t.query != "" {
+        t.queries = []string{t.query}
+    }
+    file, err := t.engine.createDumpFile(t.filename())
+    if err != nil {
+        return err
+    }
+    t.file = file
+
+    // Stream straight through gzip so uncompressed data never touches the disk, compressing
+    // before the encryption
+    var out io.Writer = file
+    if t.compress && t.format != "parquet" {
+        if t.gz == nil {
+            t.gz = gzip.NewWriter(file)
+        } else {
+            t.gz.Reset(file)
+        }
+        out = t.gz
+    }
+    if t.buf == nil {
+        t.buf = bufio.NewWriterSize(out, dumpBufferSize)
+    } else {
+        t.buf.Reset(out)
+    }
+
+    switch t.format {
+    case "jsonl":
+        return nil
+    case "parquet":
+        schema, leaves := parquetSchema(t.tableName, t.columns, t.kinds)
+        options := []parquet.WriterOption{schema, parquet.MaxRowsPerRowGroup(parquetRowGroupRows)}
+        if t.compress {
+            options = append(options, parquet.Compression(&parquet.Zstd))
+        }
+        t.pq = parquet.NewWriter(t.buf, options...)
+        t.leaves = leaves
+        return nil
+    case "csv":
+        // RFC 4180 quoting keeps delimiters, quotes and newlines inside values from breaking rows
+        if t.csv == nil {
+            t.csv = t.engine.newCSVWriter(t.buf)
+        }
+        return t.csv.Write(t.outColumns)
+    }
+
+    // Every part can be restored on its own with 'mysql < file.sql'
+    t.buf.WriteString(fmt.Sprintf("-- sqlblaster dump of %s.%s from %s:%d (part %d)\n", t.dbName, t.tableName, t.engine.opts.Host, t.engine.opts.Port, t.part))
+    t.buf.WriteString("SET NAMES utf8mb4;\nSET FOREIGN_KEY_CHECKS=0;\n")
+    t.buf.WriteString(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s;\nUSE %s;\n\n", quoteIdent(t.dbName), quoteIdent(t.dbName)))
+    if t.part == 1 && t.createStmt != "" {
+        t.buf.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS %s;\n%s;\n\n", quoteIdent(t.tableName), t.createStmt))
+    }
+    return nil
+}
+
+// nextPart finishes the current file and opens the next one
+func (t *tableDumpWriter) nextPart() error {
+    if err := t.finish(); err != nil {
+        return err
+    }
+    return t.open()
+}
+
+// startPage notes the query the next rows are read with, for the manifest
+func (t *tableDumpWriter) startPage(query string) {
+    t.query = query
+    t.queries = append(t.queries, query)
+}
+
+// writeRow writes one scanned row
+func (t *tableDumpWriter) writeRow(values []interface{}) {
+    t.rows++
+    values = t.blobRow(values)
+    switch t.format {
+    case "csv":
+        t.csv.WriteValues(values, t.engine.formatValueForCSV)
+        return
+    case "jsonl":
+        t.buf.Write(jsonlRow(t.outColumns, t.outKinds, values))
+        return
+    case "parquet":
+        if _, err := t.pq.WriteRows([]parquet.Row{parquetRow(t.kinds, t.leaves, values)}); err != nil && t.err == nil {
+            t.err = err
+        }
+        return
+    }
+
+    if t.pending == 0 {
+        quoted := make([]string, len(t.columns))
+        for i, col := range t.columns {
+            quoted[i] = quoteIdent(col)
+        }
+        t.buf.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", quoteIdent(t.tableName), strings.Join(quoted, ",")))
+    } else {
+        t.buf.WriteString(",\n")
+    }
+
+    sqlValues := make([]string, len(values))
+    for i, val := range values {
+        sqlValues[i] = formatValueForSQL(val, t.types[i])
+    }
+    t.buf.WriteString("(" + strings.Join(sqlValues, ",") + ")")
+
+    t.pending++
+    if t.pending >= sqlInsertBatch {
+        t.buf.WriteString(";\n")
+        t.pending = 0
+    }
+}
+
+// size returns the bytes of all parts so far, counting output still in the write buffer
+func (t *tableDumpWriter) size() uint64 {
+    return uint64(t.written + t.file.size() + int64(t.buf.Buffered()))
+}
+
+// flush pushes buffered output to disk
+func (t *tableDumpWriter) flush() {
+    if t.csv != nil {
+        t.csv.Flush()
+    }
+    if t.pq != nil {
+        // Ends the row group, so the rows buffered so far can be let go
+        if err := t.pq.Flush(); err != nil && t.err == nil {
+            t.err = err
+        }
+    }
+    t.buf.Flush()
+}
+
+// finish closes the current file and records it in the manifest
+func (t *tableDumpWriter) finish() error {
+    if err := t.closeFile(); err != nil {
+        return err
+    }
+    rows := t.rows
+    return t.manifest.add(t.filename(), manifestEntry{Kind: "data", Database: t.dbName, Table: t.tableName, Rows: &rows, Queries: t.queries})
+}
+
+// closeFile terminates any open INSERT and closes the current file
+func (t *tableDumpWriter) closeFile() error {
+    if t.format == "sql" {
+        if t.pending > 0 {
+            t.buf.WriteString(";\n")
+            t.pending = 0
+        }
+        t.buf.WriteString("\nSET FOREIGN_KEY_CHECKS=1;\n")
+    }
+    if t.csv != nil {
+        t.csv.Flush()
+        if err := t.csv.Error(); err != nil {
+            t.file.Close()
+            return err
+        }
+    }
+    if t.pq != nil {
+        // Writes the last row group and the footer
+        err := t.pq.Close()
+        if t.err != nil {
+            err = t.err
+        }
+        t.pq, t.err = nil, nil
+        if err != nil {
+            t.file.Close()
+            return err
+        }
+    }
+    if err := t.buf.Flush(); err != nil {
+        t.file.Close()
+        return err
+    }
+    if t.gz != nil {
+        if err := t.gz.Close(); err != nil {
+            t.file.Close()
+            return err
+        }
+    }
+    err := t.file.Close()
+    if t.err != nil {
+        // A blob file that could not be written
+        err, t.err = t.err, nil
+    }
+    return err
+}
+
+// close finishes the last part file
+func (t *tableDumpWriter) close() error {
+    return t.finish()
+}
+
+// quoteIdent quotes a MySQL identifier with backticks
+func quoteIdent(name string) string {
+    return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// formatValueForSQL formats a value as a SQL literal for the column's database type
+func formatValueForSQL(val interface{}, dbType string) string {
+    if val == nil {
+        return "NULL"
+    }
+
+    b, ok := val.([]byte)
+    if !ok {
+        b = []byte(fmt.Sprintf("%v", val))
+    }
+
+    switch strings.ToUpper(dbType) {
+    case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "DECIMAL", "FLOAT", "DOUBLE", "YEAR",
+        "UNSIGNED TINYINT", "UNSIGNED SMALLINT", "UNSIGNED MEDIUMINT", "UNSIGNED INT", "UNSIGNED BIGINT":
+        return string(b)
+    case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BIT", "GEOMETRY":
+        if len(b) == 0 {
+            return "''"
+        }
+        return "0x" + hex.EncodeToString(b)
+    }
+    return quoteSQLString(string(b))
+}
+
+// quoteSQLString escapes a string the way mysqldump does and wraps it in single quotes
+func quoteSQLString(s string) string {
+    var sb strings.Builder
+    sb.Grow(len(s) + 2)
+    sb.WriteByte('\'')
+    for i := 0; i < len(s); i++ {
+        switch c := s[i]; c {
+        case 0:
+            sb.WriteString(`\0`)
+        case '\n':
+            sb.WriteString(`\n`)
+        case '\r':
+            sb.WriteString(`\r`)
+        case '\\':
+            sb.WriteString(`\\`)
+        case '\'':
+            sb.WriteString(`\'`)
+        case '"':
+            sb.WriteString(`\"`)
+        case 0x1a:
+            sb.WriteString(`\Z`)
+        default:
+            sb.WriteByte(c)
+        }
+    }
+    sb.WriteByte('\'')
+    return sb.String()
+}
@@ -0,0 +1,300 @@
+package core
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    "github.com/schollz/progressbar/v3"
+)
+
+// tablePager builds the queries that read a table in pages of --dump-batch-size rows. Tables
+// with a primary key are read in key order, each page starting after the last key seen, so a
+// page costs the same however deep into the table it is. Tables without one fall back to
+// LIMIT/OFFSET. A --dump-where condition applies to every page.
+type tablePager struct {
+    engine  *Engine
+    table   string
+    where   string
+    batch   int
+    keys    []string
+    keyIdx  []int
+    types   []string
+    lastKey []string
+    offset  int
+}
+
+// primaryKeyColumns returns the primary key columns of a table in key order, or none when it
+// has no primary key or the backend cannot say. The names are sent as literals so the query
+// stays in the text protocol like the rest of the dump.
+func (e *Engine) primaryKeyColumns(ctx context.Context, sess *session, dbName, tableName string) []string {
+    rows, err := sess.query(ctx, fmt.Sprintf("SELECT COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE "+
+        "WHERE TABLE_SCHEMA = %s AND TABLE_NAME = %s AND CONSTRAINT_NAME = 'PRIMARY' ORDER BY ORDINAL_POSITION",
+        quoteSQLString(dbName), quoteSQLString(tableName)))
+    if err != nil {
+        e.verbosePrintln("Cannot read primary key of", tableName+", paging with LIMIT/OFFSET:", err)
+        return nil
+    }
+    defer rows.Close()
+
+    var keys []string
+    for rows.Next() {
+        var key string
+        if err := rows.Scan(&key); err != nil {
+            return nil
+        }
+        keys = append(keys, key)
+    }
+    if rows.Err() != nil {
+        return nil
+    }
+    return keys
+}
+
+// newTablePager prepares paging through tableName in the session's current database
+func (e *Engine) newTablePager(ctx context.Context, sess *session, dbName, tableName string) *tablePager {
+    p := &tablePager{engine: e, table: tableName, where: e.rowFilter(dbName, tableName), batch: e.opts.DumpBatchSize}
+    if p.batch > 0 {
+        keyCtx, cancel := e.withQueryTimeout(ctx)
+        p.keys = e.primaryKeyColumns(keyCtx, sess, dbName, tableName)
+        cancel()
+    }
+    return p
+}
+
+// setColumns finds the key columns in the result set, falling back to LIMIT/OFFSET when one is
+// missing
+func (p *tablePager) setColumns(columns, types []string) {
+    p.types = types
+    for _, key := range p.keys {
+        idx := -1
+        for i, col := range columns {
+            if strings.EqualFold(col, key) {
+                idx = i
+                break
+            }
+        }
+        if idx < 0 {
+            p.keys, p.keyIdx = nil, nil
+            return
+        }
+        p.keyIdx = append(p.keyIdx, idx)
+    }
+}
+
+// from returns the FROM clause with the --dump-where condition and the extra condition cond,
+// either of which may be empty
+func (p *tablePager) from(cond string) string {
+    clause := "FROM " + quoteIdent(p.table)
+    switch {
+    case p.where != "" && cond != "":
+        return clause + " WHERE " + p.where + " AND " + cond
+    case p.where != "":
+        return clause + " WHERE " + p.where
+    case cond != "":
+        return clause + " WHERE " + cond
+    }
+    return clause
+}
+
+// query returns the statement for the next page, or the whole table when paging is off
+func (p *tablePager) query() string {
+    if p.batch <= 0 {
+        return "SELECT * " + p.from("")
+    }
+    if len(p.keys) == 0 {
+        // Without a key InnoDB still returns rows in a stable (clustered) order
+        return fmt.Sprintf("SELECT * %s LIMIT %d OFFSET %d", p.from(""), p.batch, p.offset)
+    }
+
+    quoted := make([]string, len(p.keys))
+    for i, key := range p.keys {
+        quoted[i] = quoteIdent(key)
+    }
+    order := strings.Join(quoted, ", ")
+    if p.lastKey == nil {
+        return fmt.Sprintf("SELECT * %s ORDER BY %s LIMIT %d", p.from(""), order, p.batch)
+    }
+    if len(quoted) == 1 {
+        return fmt.Sprintf("SELECT * %s ORDER BY %s LIMIT %d", p.from(quoted[0]+" > "+p.lastKey[0]), order, p.batch)
+    }
+    return fmt.Sprintf("SELECT * %s ORDER BY %s LIMIT %d",
+        p.from(fmt.Sprintf("(%s) > (%s)", order, strings.Join(p.lastKey, ", "))), order, p.batch)
+}
+
+// context bounds one page by --query-timeout; a whole-table stream is only cancelled with ctx
+func (p *tablePager) context(ctx context.Context) (context.Context, context.CancelFunc) {
+    if p.batch <= 0 {
+        return context.WithCancel(ctx)
+    }
+    return p.engine.withQueryTimeout(ctx)
+}
+
+// advance moves past a page of n rows whose last row is values, reporting whether another page
+// may follow
+func (p *tablePager) advance(values []interface{}, n int) bool {
+    if p.batch <= 0 || n < p.batch {
+        return false
+    }
+    p.offset += n
+    if len(p.keyIdx) > 0 {
+        p.lastKey = make([]string, len(p.keyIdx))
+        for i, idx := range p.keyIdx {
+            p.lastKey[i] = formatValueForSQL(values[idx], p.types[idx])
+        }
+    }
+    return true
+}
+
+// dumpTable writes one table of the session's current database to dir, page by page. It returns
+// the rows written and the number of part files, which are also meaningful after an error. A
+// table cut short by budget returns a *dumpTruncatedError.
+func (e *Engine) dumpTable(ctx context.Context, sess *session, dir, dbName, tableName, createStmt string, secrets *secretScanner, manifest *dumpManifest, budget *dumpBudget) (int, int, error) {
+    pager := e.newTablePager(ctx, sess, dbName, tableName)
+
+    // Get total rows (approximate) for the progress bar
+    var rowCountApprox int
+    countCtx, countCancel := e.withQueryTimeout(ctx)
+    err := sess.queryRow(countCtx, "SELECT COUNT(*) "+pager.from(""), &rowCountApprox)
+    countCancel()
+    if err != nil && !e.opts.QuietDump {
+        fmt.Fprintf(e.out, "  Failed to count rows in %s: %v\n", tableName, err)
+    }
+    var tableWriter *tableDumpWriter
+    var rowsBar *progressbar.ProgressBar
+    var values, scanArgs []interface{}
+    total, partRows := 0, 0
+    parts := func() int {
+        if tableWriter == nil {
+            return 0
+        }
+        return tableWriter.part
+    }
+    fail := func(err error) (int, int, error) {
+        if tableWriter != nil {
+            tableWriter.close()
+        }
+        return total, parts(), err
+    }
+    allowed, limit := budget.tableLimit()
+    defer func() {
+        if tableWriter != nil {
+            budget.used += tableWriter.size()
+        }
+    }()
+
+    for {
+        pageCtx, pageCancel := pager.context(ctx)
+        query := pager.query()
+        rows, err := sess.query(pageCtx, query)
+        if err != nil {
+            pageCancel()
+            return fail(fmt.Errorf("query failed: %v", err))
+        }
+
+        // The first page describes the columns and opens the output file
+        if tableWriter == nil {
+            columns, err := rows.Columns()
+            if err != nil {
+                rows.Close()
+                pageCancel()
+                return fail(fmt.Errorf("failed to get columns: %v", err))
+            }
+            colTypes, err := rows.ColumnTypes()
+            if err != nil {
+                rows.Close()
+                pageCancel()
+                return fail(fmt.Errorf("failed to get column types: %v", err))
+            }
+            typeNames := make([]string, len(colTypes))
+            for i, ct := range colTypes {
+                typeNames[i] = ct.DatabaseTypeName()
+            }
+            pager.setColumns(columns, typeNames)
+
+            tableWriter, err = e.newTableDumpWriter(e.opts.DumpFormat, dir, dbName, tableName, columns, typeNames, createStmt, manifest)
+            if err != nil {
+                rows.Close()
+                pageCancel()
+                return fail(fmt.Errorf("failed to create file: %v", err))
+            }
+            if secrets != nil {
+                secrets.startTable(dbName, tableName, columns)
+            }
+
+            values = make([]interface{}, len(columns))
+            scanArgs = make([]interface{}, len(columns))
+            for i := range values {
+                scanArgs[i] = &values[i]
+            }
+            if !e.opts.QuietDump && rowCountApprox > 0 {
+                rowsBar = progressbar.NewOptions(rowCountApprox,
+                    progressbar.OptionSetDescription(fmt.Sprintf("Rows in %s", tableName)),
+                    progressbar.OptionSetWidth(30),
+                    progressbar.OptionSetWriter(e.out),
+                )
+            }
+        }
+
+        tableWriter.startPage(query)
+
+        pageRows := 0
+        for rows.Next() {
+            // Another row is only dumped while the byte budget lasts
+            if limit != nil && tableWriter.size() >= allowed {
+                rows.Close()
+                pageCancel()
+                if err := tableWriter.close(); err != nil {
+                    return total, parts(), fmt.Errorf("error writing file: %v", err)
+                }
+                return total, parts(), limit
+            }
+
+            // If max rows per file is reached, open a new file
+            if e.opts.MaxRowsPerFile > 0 && partRows >= e.opts.MaxRowsPerFile {
+                if err := tableWriter.nextPart(); err != nil {
+                    rows.Close()
+                    pageCancel()
+                    return total, parts(), fmt.Errorf("failed to create part file: %v", err)
+                }
+                partRows = 0
+            }
+
+            // A row that cannot be read would leave a gap the next page could not start after
+            if err := rows.Scan(scanArgs...); err != nil {
+                rows.Close()
+                pageCancel()
+                return fail(fmt.Errorf("error scanning row %d: %v", total+1, err))
+            }
+            if secrets != nil {
+                secrets.scanRow(values)
+            }
+
+            // Write row to file, flushing straight through while memory is tight
+            tableWriter.writeRow(values)
+            if e.overMemoryLimit() {
+                tableWriter.flush()
+            }
+            partRows++
+            pageRows++
+            total++
+            if rowsBar != nil {
+                rowsBar.Add(1)
+            }
+        }
+        err = rows.Err()
+        rows.Close()
+        pageCancel()
+        if err != nil {
+            return fail(fmt.Errorf("reading rows failed: %v", err))
+        }
+        if !pager.advance(values, pageRows) {
+            break
+        }
+    }
+
+    if err := tableWriter.close(); err != nil {
+        return total, parts(), fmt.Errorf("error writing file: %v", err)
+    }
+    return total, parts(), nil
+}
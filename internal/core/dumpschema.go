@@ -0,0 +1,151 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "io"
+    "path/filepath"
+    "strings"
+)
+
+// schemaObject is a view, routine, trigger or event of a database. Kind is what SHOW CREATE
+// takes: VIEW, FUNCTION, PROCEDURE, TRIGGER or EVENT.
+type schemaObject struct {
+    kind string
+    name string
+}
+
+// schemaObjectQueries list a database's objects besides tables, in the order schema.sql gets
+// them: functions and procedures first since views, triggers and events may call them
+var schemaObjectQueries = []string{
+    "SELECT ROUTINE_TYPE, ROUTINE_NAME FROM information_schema.ROUTINES WHERE ROUTINE_SCHEMA = ? ORDER BY ROUTINE_TYPE, ROUTINE_NAME",
+    "SELECT 'VIEW', TABLE_NAME FROM information_schema.VIEWS WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME",
+    "SELECT 'TRIGGER', TRIGGER_NAME FROM information_schema.TRIGGERS WHERE TRIGGER_SCHEMA = ? ORDER BY EVENT_OBJECT_TABLE, ACTION_ORDER",
+    "SELECT 'EVENT', EVENT_NAME FROM information_schema.EVENTS WHERE EVENT_SCHEMA = ? ORDER BY EVENT_NAME",
+}
+
+// listSchemaObjects returns the views, routines, triggers and events of a database. ClickHouse
+// has none of these, and a backend that cannot list one kind just has none of it.
+func (e *Engine) listSchemaObjects(ctx context.Context, db *sql.DB, backend, dbName string) []schemaObject {
+    if backend == backendClickHouse {
+        return nil
+    }
+    var objects []schemaObject
+    for _, query := range schemaObjectQueries {
+        rows, err := db.QueryContext(ctx, query, dbName)
+        if err != nil {
+            e.verbosePrintf("Listing schema objects of %s: %v\n", dbName, err)
+            continue
+        }
+        for rows.Next() {
+            var obj schemaObject
+            if err := rows.Scan(&obj.kind, &obj.name); err == nil {
+                objects = append(objects, obj)
+            }
+        }
+        rows.Close()
+    }
+    return objects
+}
+
+// showCreateObject fetches a view's, routine's, trigger's or event's CREATE statement. Its
+// column differs by kind and server, so it is found by name.
+func showCreateObject(ctx context.Context, db *sql.DB, dbName string, obj schemaObject) (string, error) {
+    rows, err := db.QueryContext(ctx, fmt.Sprintf("SHOW CREATE %s `%s`.`%s`", obj.kind, dbName, strings.ReplaceAll(obj.name, "`", "``")))
+    if err != nil {
+        return "", err
+    }
+    defer rows.Close()
+    columns, err := rows.Columns()
+    if err != nil {
+        return "", err
+    }
+    if !rows.Next() {
+        if err := rows.Err(); err != nil {
+            return "", err
+        }
+        return "", sql.ErrNoRows
+    }
+    values := make([]sql.NullString, len(columns))
+    ptrs := make([]interface{}, len(columns))
+    for i := range values {
+        ptrs[i] = &values[i]
+    }
+    if err := rows.Scan(ptrs...); err != nil {
+        return "", err
+    }
+    for i, col := range columns {
+        if strings.HasPrefix(col, "Create ") || col == "SQL Original Statement" {
+            if !values[i].Valid {
+                return "", fmt.Errorf("definition not visible to this account")
+            }
+            return values[i].String, nil
+        }
+    }
+    return "", fmt.Errorf("no CREATE statement in SHOW CREATE %s", obj.kind)
+}
+
+// writeSchemaObjects appends the objects' CREATE statements to schema.sql, routines, triggers and
+// events inside DELIMITER ;; since their bodies contain semicolons. It returns how many of each
+// kind were written.
+func (e *Engine) writeSchemaObjects(ctx context.Context, db *sql.DB, dbName string, objects []schemaObject, out io.Writer) map[string]int {
+    counts := make(map[string]int)
+    for _, obj := range objects {
+        objCtx, cancel := e.withQueryTimeout(ctx)
+        createStmt, err := showCreateObject(objCtx, db, dbName, obj)
+        cancel()
+        if err != nil {
+            fmt.Fprintf(out, "-- Failed to get %s %s: %v\n\n", strings.ToLower(obj.kind), obj.name, err)
+            continue
+        }
+        if obj.kind == "VIEW" {
+            fmt.Fprintf(out, "%s;\n\n", createStmt)
+        } else {
+            fmt.Fprintf(out, "DELIMITER ;;\n%s ;;\nDELIMITER ;\n\n", createStmt)
+        }
+        counts[obj.kind]++
+    }
+    return counts
+}
+
+// schemaObjectSummary describes the counts from writeSchemaObjects, e.g. "2 views, 1 trigger"
+func schemaObjectSummary(counts map[string]int) string {
+    var parts []string
+    for _, kind := range []string{"VIEW", "FUNCTION", "PROCEDURE", "TRIGGER", "EVENT"} {
+        if n := counts[kind]; n > 0 {
+            name := strings.ToLower(kind)
+            if n > 1 {
+                name += "s"
+            }
+            parts = append(parts, fmt.Sprintf("%d %s", n, name))
+        }
+    }
+    return strings.Join(parts, ", ")
+}
+
+// writeSchemaFile writes schema.sql (encrypted as schema.sql.age with --dump-encrypt) for a database: the tables' CREATE statements, which are
+// also stored in createStmts, followed by the other schema objects. It returns how many of
+// each object kind were written.
+func (e *Engine) writeSchemaFile(ctx context.Context, db *sql.DB, backend, dbDir, dbName string, tables []string, objects []schemaObject, createStmts map[string]string) (map[string]int, error) {
+    schemaFile, err := e.createDumpFile(e.dumpFileName(filepath.Join(dbDir, "schema.sql")))
+    if err != nil {
+        return nil, err
+    }
+    defer schemaFile.Close()
+
+    for _, tableName := range tables {
+        schemaCtx, schemaCancel := e.withQueryTimeout(ctx)
+        createStmt, err := showCreateTable(schemaCtx, db, backend, dbName, tableName)
+        schemaCancel()
+
+        if err != nil {
+            fmt.Fprintf(schemaFile, "-- Failed to get schema for %s: %v\n", tableName, err)
+        } else {
+            io.WriteString(schemaFile, createStmt+";\n\n")
+            createStmts[tableName] = createStmt
+        }
+    }
+    counts := e.writeSchemaObjects(ctx, db, dbName, objects, schemaFile)
+    return counts, schemaFile.Close()
+}
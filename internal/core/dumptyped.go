@@ -0,0 +1,222 @@
+package core
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/parquet-go/parquet-go"
+)
+
+// parquetRowGroupRows bounds the rows a Parquet writer buffers before writing a row group
+const parquetRowGroupRows = 100000
+
+// Kinds of dumped column, which decide how jsonl and parquet dumps type their values
+const (
+    kindInt      = "int"
+    kindUint     = "uint"
+    kindFloat    = "float"
+    kindDate     = "date"
+    kindDatetime = "datetime"
+    kindBit      = "bit"
+    kindBytes    = "bytes"
+    kindJSON     = "json"
+    kindString   = "string"
+)
+
+// columnKind maps a column's database type name to the kind of value it holds. DECIMAL stays
+// a string so no digits are lost, and TIME one since it can exceed a day.
+func columnKind(dbType string) string {
+    switch strings.ToUpper(dbType) {
+    case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR",
+        "UNSIGNED TINYINT", "UNSIGNED SMALLINT", "UNSIGNED MEDIUMINT", "UNSIGNED INT":
+        return kindInt
+    case "UNSIGNED BIGINT":
+        return kindUint
+    case "FLOAT", "DOUBLE":
+        return kindFloat
+    case "DATE":
+        return kindDate
+    case "DATETIME", "TIMESTAMP":
+        return kindDatetime
+    case "BIT":
+        return kindBit
+    case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "GEOMETRY":
+        return kindBytes
+    case "JSON":
+        return kindJSON
+    }
+    return kindString
+}
+
+// typedValue converts a scanned value to the Go type of its column kind: int64, uint64,
+// float64, time.Time, []byte or string. It returns nil for NULL and for values that do not
+// parse as their kind, such as the zero date 0000-00-00.
+func typedValue(val interface{}, kind string) interface{} {
+    if val == nil {
+        return nil
+    }
+    if t, ok := val.(time.Time); ok && (kind == kindDate || kind == kindDatetime) {
+        return t
+    }
+    b, ok := val.([]byte)
+    if !ok {
+        b = []byte(fmt.Sprintf("%v", val))
+    }
+    s := string(b)
+
+    switch kind {
+    case kindInt:
+        if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+            return n
+        }
+    case kindUint:
+        if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+            return n
+        }
+    case kindFloat:
+        if f, err := strconv.ParseFloat(s, 64); err == nil {
+            return f
+        }
+    case kindDate:
+        if t, err := time.Parse("2006-01-02", s); err == nil {
+            return t
+        }
+    case kindDatetime:
+        if t, err := time.Parse("2006-01-02 15:04:05.999999", s); err == nil {
+            return t
+        }
+    case kindBit:
+        // BIT(n) arrives as big-endian bytes, or as digits from the binary protocol
+        if _, isBytes := val.([]byte); !isBytes {
+            if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+                return n
+            }
+            return nil
+        }
+        var n uint64
+        for _, c := range b {
+            n = n<<8 | uint64(c)
+        }
+        return n
+    case kindBytes:
+        return b
+    default:
+        return s
+    }
+    return nil
+}
+
+// jsonlRow renders a row as one JSON object with the columns in table order. Numbers are JSON
+// numbers, dates and DECIMAL strings, binary data base64 and JSON columns embedded as is.
+func jsonlRow(columns, kinds []string, values []interface{}) []byte {
+    var buf bytes.Buffer
+    buf.WriteByte('{')
+    for i, col := range columns {
+        if i > 0 {
+            buf.WriteByte(',')
+        }
+        name, _ := json.Marshal(col)
+        buf.Write(name)
+        buf.WriteByte(':')
+
+        var v interface{}
+        switch kinds[i] {
+        case kindDate, kindDatetime:
+            // Keep the text the server sent, zero dates included
+            v = typedValue(values[i], kindString)
+        case kindJSON:
+            if b, ok := values[i].([]byte); ok && json.Valid(b) {
+                v = json.RawMessage(b)
+            } else {
+                v = typedValue(values[i], kindString)
+            }
+        default:
+            v = typedValue(values[i], kinds[i])
+        }
+        data, err := json.Marshal(v)
+        if err != nil {
+            // Floats such as NaN have no JSON form
+            data, _ = json.Marshal(fmt.Sprint(v))
+        }
+        buf.Write(data)
+    }
+    buf.WriteString("}\n")
+    return buf.Bytes()
+}
+
+// parquetNode returns the optional Parquet column for a column kind
+func parquetNode(kind string) parquet.Node {
+    switch kind {
+    case kindInt:
+        return parquet.Optional(parquet.Int(64))
+    case kindUint, kindBit:
+        return parquet.Optional(parquet.Uint(64))
+    case kindFloat:
+        return parquet.Optional(parquet.Leaf(parquet.DoubleType))
+    case kindDate:
+        return parquet.Optional(parquet.Date())
+    case kindDatetime:
+        return parquet.Optional(parquet.TimestampAdjusted(parquet.Microsecond, false))
+    case kindBytes:
+        return parquet.Optional(parquet.Leaf(parquet.ByteArrayType))
+    case kindJSON:
+        return parquet.Optional(parquet.JSON())
+    }
+    return parquet.Optional(parquet.String())
+}
+
+// parquetSchema builds the schema of a table dump. Parquet groups order their columns by name,
+// so it also returns each table column's index in the file.
+func parquetSchema(tableName string, columns, kinds []string) (*parquet.Schema, []int) {
+    group := make(parquet.Group, len(columns))
+    for i, col := range columns {
+        group[col] = parquetNode(kinds[i])
+    }
+    schema := parquet.NewSchema(tableName, group)
+
+    index := make(map[string]int, len(columns))
+    for i, leaf := range schema.Columns() {
+        index[leaf[0]] = i
+    }
+    leaves := make([]int, len(columns))
+    for i, col := range columns {
+        leaves[i] = index[col]
+    }
+    return schema, leaves
+}
+
+// parquetRow converts a scanned row into a Parquet row in the schema's column order
+func parquetRow(kinds []string, leaves []int, values []interface{}) parquet.Row {
+    row := make(parquet.Row, len(values))
+    for i, val := range values {
+        var v parquet.Value
+        switch typed := typedValue(val, kinds[i]).(type) {
+        case int64:
+            v = parquet.Int64Value(typed)
+        case uint64:
+            v = parquet.Int64Value(int64(typed))
+        case float64:
+            v = parquet.DoubleValue(typed)
+        case time.Time:
+            if kinds[i] == kindDate {
+                v = parquet.Int32Value(int32(typed.Unix() / 86400))
+            } else {
+                v = parquet.Int64Value(typed.UnixMicro())
+            }
+        case []byte:
+            v = parquet.ByteArrayValue(typed)
+        case string:
+            v = parquet.ByteArrayValue([]byte(typed))
+        }
+        definition := 1
+        if v.IsNull() {
+            definition = 0
+        }
+        row[leaves[i]] = v.Level(0, definition, leaves[i])
+    }
+    return row
+}
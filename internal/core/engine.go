@@ -0,0 +1,313 @@
+package core
+
+import (
+    "context"
+    "crypto/tls"
+    "database/sql"
+    "io"
+    "net"
+    "os"
+    "sync"
+    "sync/atomic"
+    "text/template"
+    "time"
+
+    "filippo.io/age"
+    "github.com/fatih/color"
+)
+
+// EventType identifies what happened in a run
+type EventType int
+
+const (
+    // EventSuccess is a credential that logged in
+    EventSuccess EventType = iota
+    // EventFailure is a credential that was rejected or could not be tested
+    EventFailure
+    // EventProgress follows every finished or skipped attempt of the worker pool
+    EventProgress
+)
+
+// Event is delivered to the handlers registered for its type. Credential fields are set for
+// success and failure events, the counters for progress events.
+type Event struct {
+    Type         EventType
+    Time         time.Time
+    Host         string
+    Port         int
+    User         string
+    Pass         string
+    Backend      string
+    Plugin       string
+    Restrictions []string
+    Err          error
+
+    Attempts  int
+    Total     int
+    Successes int
+}
+
+// EventHandler receives events. Handlers run on the goroutine that produced the event, so they
+// must be safe for concurrent use and hand slow work off to their own goroutines.
+type EventHandler func(Event)
+
+// Engine runs one configuration: the testing, dump and shell code read their options from it,
+// and it is the run's event bus, so output, hooks and alerts consume the same events instead of
+// each being called from the testing code. Cancellation comes from the context passed to each
+// part of the run.
+type Engine struct {
+    opts   *Options
+    policy *Policy
+    audit  *auditLog
+
+    // out receives the run's human-readable output: messages, results and progress bars. in
+    // is what interactive mode reads instead of the process's terminal, nil for the terminal.
+    out io.Writer
+    in  io.Reader
+
+    // Presentation of that output: the message language (--lang), the theme's colors, and the
+    // size of the terminal out is, 0 when it is not one. tableWrap makes cells wider than their
+    // column wrap onto extra lines instead of being truncated, toggled with \W in interactive
+    // mode.
+    lang       string
+    theme      themeColors
+    termWidth  atomic.Int32
+    termHeight atomic.Int32
+    tableWrap  atomic.Bool
+
+    // JSON Lines records of --output json and --summary-only go to jsonOut and a copy to jsonLog
+    // when jsonOutput is set. summaryOut receives the end-of-run summary of --summary-only and is
+    // nil otherwise.
+    jsonOutput bool
+    jsonOut    io.Writer
+    jsonLog    *os.File
+    jsonMu     sync.Mutex
+    summaryOut io.Writer
+
+    // Set from the fingerprint of an ancient server, see legacyAuth
+    oldProtocol  bool
+    oldPasswords bool
+
+    mu       sync.RWMutex
+    handlers map[EventType][]EventHandler
+
+    // Connection setup, see setupSources, setupTimeouts, setupTLS, setupSessionVars,
+    // setupConnAttrs and setupRateLimit
+    sources        []string
+    sourceIndex    int
+    burnedSources  map[string]bool
+    sourceMu       sync.Mutex
+    attemptTimeout time.Duration
+    connectTimeout time.Duration
+    readTimeout    time.Duration
+    writeTimeout   time.Duration
+    queryTimeout   time.Duration
+    tlsConfig      *tls.Config
+    sessionVars    []sessionVar
+    connAttrs      []connAttr
+    attemptLimiter *rateLimiter
+
+    // Lockout state of the current target shared by all workers, see noteLockout. blockPause is
+    // how long --block-pause waits out a host block instead of stopping (0 to stop), and while
+    // pausedUntil is in the future a host block has paused all testing.
+    lockedUsers   map[string]string
+    hostBlocked   string
+    pausedUntil   time.Time
+    guidanceShown bool
+    lockoutMu     sync.Mutex
+    blockPause    time.Duration
+
+    // Resume state: statePath is the session file the last tested credentials of the current
+    // target are saved to for --resume, set by useSession, and the checksums of the wordlists in
+    // use are saved with it. forceResume resumes even when they no longer match.
+    statePath   string
+    forceResume bool
+    userListSum string
+    passListSum string
+
+    // Dump settings, see setupDumpFilter, setupDumpWhere and setupDumpEncrypt
+    dumpTableFilter *tableFilter
+    dumpWhere       []wherePredicate
+    dumpRecipients  []age.Recipient
+
+    // Modes chosen on the command line: connectMode enters interactive mode after a successful
+    // login (--connect), and dryRun prints the run's plan and exits before any connection is
+    // opened (--dry-run)
+    connectMode bool
+    dryRun      bool
+
+    // Parsed --on-success and --on-error-threshold commands, one template per argument, the
+    // hook commands still running, and the error rate tracking of --on-error-threshold
+    successHook  []*template.Template
+    errorHook    []*template.Template
+    hooksWG      sync.WaitGroup
+    errorEvents  []attemptEvent
+    errorAlerted bool
+    errorMu      sync.Mutex
+
+    // foregroundQuery cancels the statement running in interactive mode, so Ctrl-C stops the
+    // statement instead of shutting down
+    foregroundQuery atomic.Pointer[context.CancelFunc]
+
+    // pluginMismatches counts attempts the server answered by asking for another auth plugin, so
+    // the password was never checked, and pluginMismatchWarned holds the users whose mismatch
+    // was already printed
+    pluginMismatches     atomic.Int64
+    pluginMismatchWarned sync.Map
+
+    // DNS: the resolver lookups go to (--resolver), the --hosts-file names and the cache of
+    // answers shared by every connection attempt
+    resolver *net.Resolver
+    hostsMap map[string][]string
+    dnsCache map[string]dnsEntry
+    dnsMu    sync.Mutex
+
+    // passMutator expands each base password into candidates, or nil when neither --mutate nor
+    // --rules is set
+    passMutator *mutator
+
+    // knownCreds maps host:port to the users already cracked there and their passwords, for
+    // --skip-known; nil when the option is off. knownSkipped counts the attempts skipped
+    // because the user was already cracked.
+    knownCreds   map[string]map[string]string
+    knownCredsMu sync.Mutex
+    knownSkipped atomic.Int64
+
+    // Attempts made per user during the run, for --max-attempts-per-user
+    userAttempts   map[string]int
+    userCapNoticed map[string]bool
+    userAttemptsMu sync.Mutex
+
+    // throttlePaused is set while the connection monitor pauses new attempts, sprayWaiting while
+    // the run deliberately pauses between spray rounds so the watchdog does not mistake the pause
+    // for a hang, and memoryExceeded while heap use is above the --max-memory limit
+    throttlePaused bool
+    throttleMu     sync.Mutex
+    sprayWaiting   atomic.Bool
+    memoryExceeded atomic.Bool
+
+    // spoolFiles are the result files written once --max-memory was exceeded, listed in the
+    // run's summary
+    spoolFiles []string
+    spoolMu    sync.Mutex
+
+    // createdPaths are the files and outermost directories the run created, the only paths
+    // outside the campaign directory that shred may later delete
+    createdPaths []string
+    createdMu    sync.Mutex
+
+    // batchUnsupported is set once the target turns out to close connections after a failed
+    // COM_CHANGE_USER, making batching pointless for the rest of the run
+    batchUnsupported atomic.Bool
+
+    // hashesLooted and udfDeployed are set once the target's password hashes have been written
+    // and the UDFs installed, so later successes don't repeat either
+    hashesLooted atomic.Bool
+    udfDeployed  atomic.Bool
+
+    // inconclusiveAttempts counts attempts that still failed with a transient error after
+    // --retries, so they are reported instead of passing for wrong passwords, and
+    // recoveredPanics the attempts lost to recovered panics
+    inconclusiveAttempts atomic.Int64
+    recoveredPanics      int
+    panicMu              sync.Mutex
+
+    // resultsDB is the optional SQLite database successful logins are recorded to. successes
+    // holds every successful login of the run for end-of-run exports, notes the notes taken
+    // during it, testedUsers every username an attempt was made for, testedAccounts every
+    // username per host:port, targetAttempts the number of attempts per host:port and
+    // testedTargets the host:port targets that answered an attempt.
+    resultsDB      *sql.DB
+    successes      []ResultRecord
+    notes          []targetNote
+    testedUsers    map[string]bool
+    testedAccounts map[string]ResultRecord
+    targetAttempts map[string]int
+    testedTargets  map[string]bool
+    successesMu    sync.Mutex
+
+    // campaignDir is the active campaign's directory, empty when --campaign is not set
+    campaignDir string
+
+    // commonPasswords holds the lowercase top-10k entries passwords are checked against, loaded
+    // once by commonPasswordsOnce when none were given
+    commonPasswords     map[string]bool
+    commonPasswordsOnce sync.Once
+
+    // Data the run uses, built in or from the installed data bundle, and its versions
+    defaultCredList    []defaultCred
+    commonPasswordList []string
+    dataVersions       map[string]string
+}
+
+// NewEngine creates an engine with no handlers that runs with opts
+func NewEngine(opts *Options) *Engine {
+    return &Engine{
+        opts:           opts,
+        out:            color.Output,
+        lang:           defaultLang,
+        theme:          newThemeColors(themes["default"]),
+        handlers:       make(map[EventType][]EventHandler),
+        burnedSources:  make(map[string]bool),
+        lockedUsers:    make(map[string]string),
+        resolver:       net.DefaultResolver,
+        hostsMap:       make(map[string][]string),
+        dnsCache:       make(map[string]dnsEntry),
+        userAttempts:   make(map[string]int),
+        userCapNoticed: make(map[string]bool),
+        testedUsers:    make(map[string]bool),
+        testedAccounts: make(map[string]ResultRecord),
+        targetAttempts: make(map[string]int),
+        testedTargets:  make(map[string]bool),
+        attemptTimeout: defaultAttemptTimeout,
+        connectTimeout: defaultConnectTimeout,
+        queryTimeout:   defaultQueryTimeout,
+
+        defaultCredList:    builtinDefaultCreds,
+        commonPasswordList: builtinCommonPasswords,
+        dataVersions:       builtinDataVersions(),
+    }
+}
+
+// RegisterHandler adds a handler for one event type; handlers run in registration order
+func (e *Engine) RegisterHandler(t EventType, h EventHandler) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    e.handlers[t] = append(e.handlers[t], h)
+}
+
+// emit stamps the event with the target and time and delivers it to its handlers
+func (e *Engine) emit(ev Event) {
+    if ev.Time.IsZero() {
+        ev.Time = time.Now()
+    }
+    if ev.Host == "" {
+        ev.Host, ev.Port = e.opts.Host, e.opts.Port
+    }
+
+    e.mu.RLock()
+    handlers := e.handlers[ev.Type]
+    e.mu.RUnlock()
+    for _, h := range handlers {
+        h(ev)
+    }
+}
+
+// registerBuiltinHandlers connects the JSON records, the --on-success hook and the
+// --on-error-threshold alert to the engine's events
+func (e *Engine) registerBuiltinHandlers(log *os.File) {
+    e.RegisterHandler(EventSuccess, func(ev Event) {
+        e.noteAttemptResult(nil, log)
+        e.runSuccessHook(ev.User, ev.Pass, log)
+        e.emitRecord("attempt", map[string]interface{}{"user": ev.User, "pass": ev.Pass, "success": true, "backend": ev.Backend,
+            "plugin": ev.Plugin, "restrictions": ev.Restrictions})
+    })
+    e.RegisterHandler(EventFailure, func(ev Event) {
+        e.noteAttemptResult(ev.Err, log)
+        record := map[string]interface{}{"user": ev.User, "pass": ev.Pass, "success": false, "error": ev.Err.Error()}
+        if mismatch, ok := asPluginMismatch(ev.Err); ok {
+            record["pluginMismatch"] = mismatch.requested
+        }
+        e.emitRecord("attempt", record)
+    })
+}
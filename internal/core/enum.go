@@ -0,0 +1,213 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strings"
+
+    "golang.org/x/sync/errgroup"
+)
+
+// enumTableWorkers is how many databases have their tables listed at once during
+// enumeration, kept below the connection pool size set in testLogin
+const enumTableWorkers = 8
+
+// enumTableThreshold is the most tables a database can have and still be listed in full
+// without --enum-full
+const enumTableThreshold = 50
+
+// enumerateMySQL gathers information about privileges, databases, and tables
+func (e *Engine) enumerateMySQL(ctx context.Context, db *sql.DB) string {
+    var output strings.Builder
+    var queryError bool
+    var serverVersion string
+
+    // Compatible backends need some queries adjusted
+    backend := e.queryBackend(ctx, db)
+
+    // Enumerate privileges
+    e.verbosePrintln("Enumerating user privileges")
+    output.WriteString("User Privileges:\n")
+    if !supportsShowGrants(backend) {
+        output.WriteString(fmt.Sprintf("  SHOW GRANTS is not supported on %s, privileges are enforced by vtgate table ACLs\n", backend))
+    } else if rows, err := db.QueryContext(ctx, "SHOW GRANTS"); err != nil {
+        e.verbosePrintln("Error fetching grants:", err)
+        output.WriteString(fmt.Sprintf("Error fetching grants: %v\n", err))
+        queryError = true
+    } else {
+        defer rows.Close()
+        grantCount := 0
+        for rows.Next() {
+            var grant string
+            if err := rows.Scan(&grant); err != nil {
+                e.verbosePrintln("Error scanning grant:", err)
+                output.WriteString(fmt.Sprintf("Error scanning grant: %v\n", err))
+            } else {
+                grantCount++
+                output.WriteString("  " + grant + "\n")
+                e.emitRecord("enum", map[string]interface{}{"kind": "grant", "value": grant})
+            }
+        }
+        e.verbosePrintf("Found %d privilege records\n", grantCount)
+        if err := rows.Err(); err != nil {
+            e.verbosePrintln("Error iterating grants:", err)
+            output.WriteString(fmt.Sprintf("Error iterating grants: %v\n", err))
+        }
+    }
+
+    // Get MySQL/MariaDB version
+    e.verbosePrintln("Checking database version")
+    output.WriteString("\nDatabase Version:\n")
+    verRows, err := db.QueryContext(ctx, "SELECT VERSION()")
+    if err != nil {
+        e.verbosePrintln("Error getting version:", err)
+        output.WriteString(fmt.Sprintf("  Error fetching version: %v\n", err))
+    } else {
+        defer verRows.Close()
+        if verRows.Next() {
+            var version string
+            if err := verRows.Scan(&version); err != nil {
+                e.verbosePrintln("Error scanning version:", err)
+                output.WriteString(fmt.Sprintf("  Error scanning version: %v\n", err))
+            } else {
+                output.WriteString("  " + version + "\n")
+                if backend != "" {
+                    output.WriteString("  Backend: " + backend + "\n")
+                }
+                e.emitRecord("enum", map[string]interface{}{"kind": "version", "value": version, "backend": backend})
+                serverVersion = version
+            }
+        }
+    }
+
+    // Get current user
+    e.verbosePrintln("Checking current user")
+    output.WriteString("\nCurrent User:\n")
+    userRows, err := db.QueryContext(ctx, currentUserQuery(backend))
+    if err != nil {
+        e.verbosePrintln("Error getting user info:", err)
+        output.WriteString(fmt.Sprintf("  Error fetching user info: %v\n", err))
+    } else {
+        defer userRows.Close()
+        if userRows.Next() {
+            var sessionUser, currentUser string
+            if err := userRows.Scan(&sessionUser, &currentUser); err != nil {
+                e.verbosePrintln("Error scanning user info:", err)
+                output.WriteString(fmt.Sprintf("  Error scanning user info: %v\n", err))
+            } else {
+                output.WriteString("  Session User: " + sessionUser + "\n")
+                output.WriteString("  Effective User: " + currentUser + "\n")
+                e.emitRecord("enum", map[string]interface{}{"kind": "user", "session": sessionUser, "effective": currentUser})
+            }
+        }
+    }
+
+    // Enumerate databases
+    e.verbosePrintln("Enumerating databases")
+    output.WriteString("\nDatabases:\n")
+    dbRows, err := db.QueryContext(ctx, databasesQuery(backend))
+    if err != nil {
+        e.verbosePrintln("Error fetching databases:", err)
+        output.WriteString(fmt.Sprintf("  Error fetching databases: %v\n", err))
+        queryError = true
+    } else {
+        var dbNames []string
+        for dbRows.Next() {
+            var dbName string
+            if err := dbRows.Scan(&dbName); err != nil {
+                e.verbosePrintln("Error scanning database:", err)
+                output.WriteString(fmt.Sprintf("  Error scanning database: %v\n", err))
+            } else {
+                dbNames = append(dbNames, dbName)
+            }
+        }
+        if err := dbRows.Err(); err != nil {
+            e.verbosePrintln("Error iterating databases:", err)
+            output.WriteString(fmt.Sprintf("  Error iterating databases: %v\n", err))
+        }
+        dbRows.Close()
+
+        // List the tables of several databases at once, servers can have hundreds
+        tables := make([][]string, len(dbNames))
+        tableErrs := make([]error, len(dbNames))
+        var g errgroup.Group
+        g.SetLimit(enumTableWorkers)
+        for i, dbName := range dbNames {
+            i, dbName := i, dbName
+            g.Go(func() error {
+                e.verbosePrintf("Enumerating tables in database: %s\n", dbName)
+                tables[i], tableErrs[i] = e.listTables(ctx, db, backend, dbName)
+                return nil
+            })
+        }
+        g.Wait()
+
+        failed := 0
+        for i, dbName := range dbNames {
+            e.emitRecord("enum", map[string]interface{}{"kind": "database", "database": dbName, "tables": len(tables[i])})
+
+            // Large databases are summarized to keep the report readable, JSON output keeps every table
+            expand := e.opts.EnumFull || len(tables[i]) <= enumTableThreshold
+            if expand {
+                output.WriteString("  " + dbName + "\n")
+            } else {
+                output.WriteString(fmt.Sprintf("  %s (%d tables, use --enum-full to list them)\n", dbName, len(tables[i])))
+            }
+            for _, tableName := range tables[i] {
+                if expand {
+                    output.WriteString("    " + tableName + "\n")
+                }
+                e.emitRecord("enum", map[string]interface{}{"kind": "table", "database": dbName, "table": tableName})
+            }
+            if tableErrs[i] != nil {
+                failed++
+                e.verbosePrintln("Error fetching tables:", tableErrs[i])
+                output.WriteString(fmt.Sprintf("    Error fetching tables: %v\n", tableErrs[i]))
+            } else {
+                e.verbosePrintf("Found %d tables in database %s\n", len(tables[i]), dbName)
+            }
+        }
+        e.verbosePrintf("Found %d databases\n", len(dbNames))
+        if failed > 0 {
+            output.WriteString(fmt.Sprintf("  Failed to list tables in %d of %d databases\n", failed, len(dbNames)))
+        }
+    }
+
+    // MariaDB differs enough from MySQL to need its own checks
+    if isMariaDB(serverVersion) {
+        e.enumerateMariaDB(ctx, db, serverVersion, &output)
+    }
+    e.enumerateBackend(ctx, db, backend, &output)
+
+    // If all queries failed, add a note about insufficient privileges
+    if queryError {
+        output.WriteString("\nNote: Some enumeration queries failed. This may be due to insufficient privileges.\n")
+        output.WriteString("Try running specific queries with the -e flag to get more information.\n")
+    }
+
+    e.verbosePrintln("Database enumeration completed")
+    return output.String()
+}
+
+// listTables returns the tables of one database, along with any error that cut the listing short
+func (e *Engine) listTables(ctx context.Context, db *sql.DB, backend, dbName string) ([]string, error) {
+    tableCtx, tableCancel := e.withQueryTimeout(ctx)
+    defer tableCancel()
+
+    tableRows, err := db.QueryContext(tableCtx, tablesQuery(backend, dbName))
+    if err != nil {
+        return nil, err
+    }
+    defer tableRows.Close()
+
+    var tables []string
+    for tableRows.Next() {
+        var tableName string
+        if err := tableRows.Scan(&tableName); err != nil {
+            return tables, err
+        }
+        tables = append(tables, tableName)
+    }
+    return tables, tableRows.Err()
+}
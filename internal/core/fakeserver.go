@@ -0,0 +1,445 @@
+package core
+
+import (
+    "bytes"
+    "crypto/rand"
+    "crypto/sha1"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "net"
+    "regexp"
+    "strings"
+    "sync"
+)
+
+// MySQL column types and flags used by fake server result sets
+const (
+    fakeTypeDecimal  = 0xf6
+    fakeTypeLong     = 0x03
+    fakeTypeLongLong = 0x08
+    fakeTypeDouble   = 0x05
+    fakeTypeDateTime = 0x0c
+    fakeTypeVarchar  = 0xfd
+    fakeTypeBlob     = 0xfc
+
+    fakeFlagUnsigned = 0x0020
+    fakeFlagBinary   = 0x0080
+
+    fakeCharsetUTF8MB4 = 255
+    fakeCharsetBinary  = 63
+)
+
+// fakeCapabilities is what the fake server offers: protocol 4.1 with secure auth, plugin auth,
+// multi statements and multi results, but no TLS or deprecated-EOF
+const fakeCapabilities = 0x00000001 | 0x00000004 | 0x00000008 | 0x00000200 | 0x00002000 |
+    0x00008000 | 0x00010000 | 0x00020000 | 0x00080000
+
+// fakeColumn describes one column of a scripted result set
+type fakeColumn struct {
+    name    string
+    typ     byte
+    flags   uint16
+    charset uint16
+}
+
+// fakeResult is a scripted result set. Row values are sent in the text protocol: nil is NULL,
+// strings and byte slices are sent as they are.
+type fakeResult struct {
+    columns []fakeColumn
+    rows    [][]interface{}
+}
+
+// fakeReply is the scripted answer to the queries matching pattern
+type fakeReply struct {
+    pattern *regexp.Regexp
+    result  *fakeResult
+    errCode uint16
+    errMsg  string
+}
+
+// fakeServer is a scriptable in-process MySQL server for self-tests. It speaks enough of the
+// protocol for the driver to log in with mysql_native_password, change user, ping, and run queries whose
+// result sets or errors are scripted with reply and fail. Other statements get an OK packet.
+type fakeServer struct {
+    listener net.Listener
+    version  string
+
+    mu      sync.Mutex
+    users   map[string]string
+    busy    map[string]bool
+    blocked bool
+    logins  map[string]int
+    replies []fakeReply
+    queries []string
+}
+
+// newFakeServer starts a fake server on a random loopback port
+func newFakeServer(version string) (*fakeServer, error) {
+    listener, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        return nil, err
+    }
+    s := &fakeServer{listener: listener, version: version, users: make(map[string]string),
+        busy: make(map[string]bool), logins: make(map[string]int)}
+    go s.serve()
+    return s, nil
+}
+
+// addr returns the host:port the server listens on
+func (s *fakeServer) addr() string {
+    return s.listener.Addr().String()
+}
+
+// close stops accepting connections
+func (s *fakeServer) close() {
+    s.listener.Close()
+}
+
+// addUser lets user log in with pass
+func (s *fakeServer) addUser(user, pass string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.users[user] = pass
+}
+
+// setBusy makes logins as user fail with "Too many connections", the transient error of a server
+// out of connections, until it is cleared
+func (s *fakeServer) setBusy(user string, busy bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.busy[user] = busy
+}
+
+// setBlocked makes every login fail with ER_HOST_IS_BLOCKED, as a server does once
+// max_connect_errors is reached for the client
+func (s *fakeServer) setBlocked(blocked bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.blocked = blocked
+}
+
+// loginCount returns how many times a client tried to log in as user
+func (s *fakeServer) loginCount(user string) int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.logins[user]
+}
+
+// reply answers queries matching the case-insensitive regexp pattern with result
+func (s *fakeServer) reply(pattern string, result *fakeResult) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.replies = append(s.replies, fakeReply{pattern: regexp.MustCompile("(?i)" + pattern), result: result})
+}
+
+// fail answers queries matching pattern with a MySQL error
+func (s *fakeServer) fail(pattern string, code uint16, msg string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.replies = append(s.replies, fakeReply{pattern: regexp.MustCompile("(?i)" + pattern), errCode: code, errMsg: msg})
+}
+
+// receivedQueries returns every query the server has received, in order
+func (s *fakeServer) receivedQueries() []string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]string(nil), s.queries...)
+}
+
+// serve accepts connections until the listener is closed
+func (s *fakeServer) serve() {
+    var connID uint32
+    for {
+        conn, err := s.listener.Accept()
+        if err != nil {
+            return
+        }
+        connID++
+        go s.handle(&fakeConn{conn: conn}, connID)
+    }
+}
+
+// fakeConn frames packets on one client connection
+type fakeConn struct {
+    conn net.Conn
+    seq  byte
+}
+
+// readPacket reads one packet, remembering its sequence number for the reply
+func (c *fakeConn) readPacket() ([]byte, error) {
+    var header [4]byte
+    if _, err := io.ReadFull(c.conn, header[:]); err != nil {
+        return nil, err
+    }
+    size := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+    c.seq = header[3] + 1
+    payload := make([]byte, size)
+    if _, err := io.ReadFull(c.conn, payload); err != nil {
+        return nil, err
+    }
+    return payload, nil
+}
+
+// writePacket writes one packet with the next sequence number
+func (c *fakeConn) writePacket(payload []byte) error {
+    header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), c.seq}
+    c.seq++
+    _, err := c.conn.Write(append(header, payload...))
+    return err
+}
+
+// writeOK sends an OK packet with autocommit status
+func (c *fakeConn) writeOK() error {
+    return c.writePacket([]byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00})
+}
+
+// writeEOF sends an EOF packet with autocommit status
+func (c *fakeConn) writeEOF() error {
+    return c.writePacket([]byte{0xfe, 0x00, 0x00, 0x02, 0x00})
+}
+
+// writeError sends an error packet
+func (c *fakeConn) writeError(code uint16, msg string) error {
+    payload := []byte{0xff, byte(code), byte(code >> 8)}
+    payload = append(payload, "#HY000"...)
+    payload = append(payload, msg...)
+    return c.writePacket(payload)
+}
+
+// appendLenEnc appends a length-encoded integer
+func appendLenEnc(b []byte, n uint64) []byte {
+    switch {
+    case n < 251:
+        return append(b, byte(n))
+    case n < 1<<16:
+        return append(b, 0xfc, byte(n), byte(n>>8))
+    case n < 1<<24:
+        return append(b, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+    }
+    b = append(b, 0xfe)
+    return binary.LittleEndian.AppendUint64(b, n)
+}
+
+// appendLenEncString appends a length-encoded string
+func appendLenEncString(b []byte, s []byte) []byte {
+    return append(appendLenEnc(b, uint64(len(s))), s...)
+}
+
+// handle runs one client connection: handshake, authentication, then the command loop
+func (s *fakeServer) handle(c *fakeConn, connID uint32) {
+    defer c.conn.Close()
+
+    scramble := make([]byte, 20)
+    rand.Read(scramble)
+    for i := range scramble {
+        // The scramble is sent NUL-terminated, so it must not contain NUL
+        scramble[i] = scramble[i]%94 + 33
+    }
+
+    greeting := []byte{10}
+    greeting = append(greeting, s.version...)
+    greeting = append(greeting, 0)
+    greeting = binary.LittleEndian.AppendUint32(greeting, connID)
+    greeting = append(greeting, scramble[:8]...)
+    greeting = append(greeting, 0)
+    greeting = binary.LittleEndian.AppendUint16(greeting, uint16(fakeCapabilities&0xffff))
+    greeting = append(greeting, fakeCharsetUTF8MB4, 0x02, 0x00)
+    greeting = binary.LittleEndian.AppendUint16(greeting, uint16(fakeCapabilities>>16))
+    greeting = append(greeting, 21)
+    greeting = append(greeting, make([]byte, 10)...)
+    greeting = append(greeting, scramble[8:]...)
+    greeting = append(greeting, 0)
+    greeting = append(greeting, "mysql_native_password"...)
+    greeting = append(greeting, 0)
+    c.seq = 0
+    if err := c.writePacket(greeting); err != nil {
+        return
+    }
+
+    response, err := c.readPacket()
+    if err != nil {
+        return
+    }
+    user, auth, ok := parseHandshakeResponse(response)
+    if !ok {
+        c.writeError(1043, "Bad handshake")
+        return
+    }
+    s.mu.Lock()
+    s.logins[user]++
+    pass, known := s.users[user]
+    busy := s.busy[user]
+    blocked := s.blocked
+    s.mu.Unlock()
+    if blocked {
+        c.writeError(errHostBlocked, "Host '127.0.0.1' is blocked because of many connection errors; unblock with 'mysqladmin flush-hosts'")
+        return
+    }
+    if busy {
+        c.writeError(1040, "Too many connections")
+        return
+    }
+    if !known || !bytes.Equal(auth, nativePasswordAuth(scramble, pass)) {
+        c.writeError(1045, fmt.Sprintf("Access denied for user '%s'@'localhost' (using password: %s)",
+            user, map[bool]string{true: "YES", false: "NO"}[len(auth) > 0]))
+        return
+    }
+    if err := c.writeOK(); err != nil {
+        return
+    }
+
+    for {
+        packet, err := c.readPacket()
+        if err != nil || len(packet) == 0 {
+            return
+        }
+        switch packet[0] {
+        case 0x01: // COM_QUIT
+            return
+        case 0x02, 0x0e, 0x1f: // COM_INIT_DB, COM_PING, COM_RESET_CONNECTION
+            err = c.writeOK()
+        case 0x03: // COM_QUERY
+            err = s.query(c, string(packet[1:]))
+        case 0x11: // COM_CHANGE_USER
+            err = s.changeUser(c, scramble, packet[1:])
+        default:
+            err = c.writeError(1047, "Unknown command")
+        }
+        if err != nil {
+            return
+        }
+    }
+}
+
+// changeUser answers a COM_CHANGE_USER against the connection's scramble, keeping the
+// connection open either way
+func (s *fakeServer) changeUser(c *fakeConn, scramble, p []byte) error {
+    end := bytes.IndexByte(p, 0)
+    if end < 0 || len(p) < end+2 || len(p) < end+2+int(p[end+1]) {
+        return c.writeError(1043, "Bad handshake")
+    }
+    user := string(p[:end])
+    auth := p[end+2 : end+2+int(p[end+1])]
+    s.mu.Lock()
+    s.logins[user]++
+    pass, known := s.users[user]
+    s.mu.Unlock()
+    if !known || !bytes.Equal(auth, nativePasswordAuth(scramble, pass)) {
+        return c.writeError(1045, fmt.Sprintf("Access denied for user '%s'@'localhost' (using password: %s)",
+            user, map[bool]string{true: "YES", false: "NO"}[len(auth) > 0]))
+    }
+    return c.writeOK()
+}
+
+// parseHandshakeResponse extracts the user and auth response from a protocol 4.1 handshake response
+func parseHandshakeResponse(p []byte) (user string, auth []byte, ok bool) {
+    // capabilities(4) max packet(4) charset(1) filler(23)
+    if len(p) < 32 {
+        return "", nil, false
+    }
+    rest := p[32:]
+    end := bytes.IndexByte(rest, 0)
+    if end < 0 {
+        return "", nil, false
+    }
+    user = string(rest[:end])
+    rest = rest[end+1:]
+    if len(rest) == 0 {
+        return user, nil, true
+    }
+    // One length byte (or a length-encoded integer, identical below 251)
+    n := int(rest[0])
+    if len(rest) < 1+n {
+        return "", nil, false
+    }
+    return user, rest[1 : 1+n], true
+}
+
+// nativePasswordAuth computes the mysql_native_password response for pass:
+// SHA1(pass) XOR SHA1(scramble + SHA1(SHA1(pass))), empty for an empty password
+func nativePasswordAuth(scramble []byte, pass string) []byte {
+    if pass == "" {
+        return nil
+    }
+    stage1 := sha1.Sum([]byte(pass))
+    stage2 := sha1.Sum(stage1[:])
+    h := sha1.New()
+    h.Write(scramble)
+    h.Write(stage2[:])
+    out := h.Sum(nil)
+    for i := range out {
+        out[i] ^= stage1[i]
+    }
+    return out
+}
+
+// query answers one COM_QUERY from the script
+func (s *fakeServer) query(c *fakeConn, q string) error {
+    s.mu.Lock()
+    s.queries = append(s.queries, q)
+    var match *fakeReply
+    for i := range s.replies {
+        if s.replies[i].pattern.MatchString(strings.TrimSpace(q)) {
+            match = &s.replies[i]
+            break
+        }
+    }
+    s.mu.Unlock()
+
+    switch {
+    case match == nil:
+        return c.writeOK()
+    case match.result == nil:
+        return c.writeError(match.errCode, match.errMsg)
+    }
+    return c.writeResult(match.result)
+}
+
+// writeResult sends a text protocol result set
+func (c *fakeConn) writeResult(r *fakeResult) error {
+    if err := c.writePacket(appendLenEnc(nil, uint64(len(r.columns)))); err != nil {
+        return err
+    }
+    for _, col := range r.columns {
+        charset := col.charset
+        if charset == 0 {
+            charset = fakeCharsetUTF8MB4
+        }
+        def := appendLenEncString(nil, []byte("def"))
+        def = appendLenEncString(def, nil)
+        def = appendLenEncString(def, nil)
+        def = appendLenEncString(def, nil)
+        def = appendLenEncString(def, []byte(col.name))
+        def = appendLenEncString(def, []byte(col.name))
+        def = append(def, 0x0c)
+        def = binary.LittleEndian.AppendUint16(def, charset)
+        def = binary.LittleEndian.AppendUint32(def, 1<<24)
+        def = append(def, col.typ)
+        def = binary.LittleEndian.AppendUint16(def, col.flags)
+        def = append(def, 0, 0, 0)
+        if err := c.writePacket(def); err != nil {
+            return err
+        }
+    }
+    if err := c.writeEOF(); err != nil {
+        return err
+    }
+    for _, row := range r.rows {
+        var payload []byte
+        for _, v := range row {
+            switch v := v.(type) {
+            case nil:
+                payload = append(payload, 0xfb)
+            case []byte:
+                payload = appendLenEncString(payload, v)
+            default:
+                payload = appendLenEncString(payload, []byte(fmt.Sprint(v)))
+            }
+        }
+        if err := c.writePacket(payload); err != nil {
+            return err
+        }
+    }
+    return c.writeEOF()
+}
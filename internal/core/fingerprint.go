@@ -0,0 +1,274 @@
+package core
+
+import (
+    "bytes"
+    "context"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "net"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// errHostNotAllowed is sent instead of the greeting to addresses no account may connect from
+const errHostNotAllowed = 1130 // ER_HOST_NOT_PRIVILEGED
+
+// capabilityNames names the server capability flags shown in the fingerprint
+var capabilityNames = []struct {
+    flag uint32
+    name string
+}{
+    {0x00000001, "LONG_PASSWORD"},
+    {0x00000004, "LONG_FLAG"},
+    {0x00000008, "CONNECT_WITH_DB"},
+    {0x00000020, "COMPRESS"},
+    {0x00000080, "LOCAL_FILES"},
+    {clientProtocol41, "PROTOCOL_41"},
+    {clientSSL, "SSL"},
+    {clientTransactions, "TRANSACTIONS"},
+    {clientSecureConn, "SECURE_CONNECTION"},
+    {0x00010000, "MULTI_STATEMENTS"},
+    {0x00020000, "MULTI_RESULTS"},
+    {clientPluginAuth, "PLUGIN_AUTH"},
+    {0x00100000, "CONNECT_ATTRS"},
+    {clientPluginAuthLenenc, "PLUGIN_AUTH_LENENC_CLIENT_DATA"},
+    {0x00800000, "SESSION_TRACK"},
+    {0x01000000, "DEPRECATE_EOF"},
+    {0x02000000, "OPTIONAL_RESULTSET_METADATA"},
+    {0x04000000, "ZSTD_COMPRESSION"},
+    {0x40000000, "SSL_VERIFY_SERVER_CERT"},
+}
+
+// charsetNames names the common default collations sent in the greeting
+var charsetNames = map[byte]string{
+    8:   "latin1_swedish_ci",
+    33:  "utf8_general_ci",
+    45:  "utf8mb4_general_ci",
+    224: "utf8mb4_unicode_ci",
+    255: "utf8mb4_0900_ai_ci",
+}
+
+// perconaVersion matches Percona Server's version-build numbering, e.g. 8.0.35-27
+var perconaVersion = regexp.MustCompile(`^\d+\.\d+\.\d+-\d+`)
+
+// serverFingerprint is what the server discloses in its greeting, before any authentication
+type serverFingerprint struct {
+    Protocol     byte
+    Version      string
+    ConnectionID uint32
+    Capabilities uint32
+    Charset      byte
+    Status       uint16
+    AuthPlugin   string
+    scramble     []byte
+}
+
+// grabGreeting connects to the target and reads the server greeting without authenticating
+func (e *Engine) grabGreeting(ctx context.Context) (*serverFingerprint, error) {
+    addr := net.JoinHostPort(e.opts.Host, strconv.Itoa(e.opts.Port))
+    conn, err := e.dialMySQL(ctx, addr)
+    if err != nil {
+        return nil, err
+    }
+    defer conn.Close()
+
+    c := &authConn{engine: e, conn: conn}
+    c.conn.SetDeadline(time.Now().Add(e.attemptTimeout))
+    data, err := c.readPacket()
+    if err != nil {
+        return nil, err
+    }
+    if data[0] == 0xff {
+        return nil, parseErrPacket(data)
+    }
+    return parseFingerprint(data)
+}
+
+// parseFingerprint decodes a HandshakeV10 greeting
+func parseFingerprint(data []byte) (*serverFingerprint, error) {
+    fp := &serverFingerprint{Protocol: data[0], AuthPlugin: "mysql_native_password"}
+    end := bytes.IndexByte(data[1:], 0)
+    if end < 0 || len(data) < end+2+4+9+2 {
+        return nil, errors.New("malformed server greeting")
+    }
+    fp.Version = string(data[1 : end+1])
+    pos := end + 2
+    fp.ConnectionID = binary.LittleEndian.Uint32(data[pos:])
+    pos += 4
+    fp.scramble = append([]byte{}, data[pos:pos+8]...)
+    pos += 9 // scramble part 1 and filler
+    fp.Capabilities = uint32(binary.LittleEndian.Uint16(data[pos:]))
+    pos += 2
+    if len(data) < pos+16 {
+        return fp, nil
+    }
+    fp.Charset = data[pos]
+    fp.Status = binary.LittleEndian.Uint16(data[pos+1:])
+    pos += 3
+    fp.Capabilities |= uint32(binary.LittleEndian.Uint16(data[pos:])) << 16
+    pos += 2
+    authLen := int(data[pos])
+    pos += 11 // auth data length and reserved bytes
+    if fp.Capabilities&clientSecureConn != 0 {
+        part2 := authLen - 8
+        if part2 < 13 {
+            part2 = 13
+        }
+        if len(data) < pos+part2 {
+            return nil, errors.New("malformed server greeting")
+        }
+        fp.scramble = append(fp.scramble, bytes.TrimRight(data[pos:pos+part2], "\x00")...)
+        pos += part2
+    }
+    if fp.Capabilities&clientPluginAuth != 0 && pos < len(data) {
+        fp.AuthPlugin = string(bytes.TrimRight(data[pos:], "\x00"))
+    }
+    return fp, nil
+}
+
+// flavor names the server software, telling Percona Server apart by its version numbering
+func (fp *serverFingerprint) flavor() string {
+    backend := detectBackend(fp.Version, "")
+    if backend == backendMySQL && perconaVersion.MatchString(fp.Version) {
+        return "Percona Server (likely)"
+    }
+    return backend
+}
+
+// tls reports whether the server offers TLS
+func (fp *serverFingerprint) tls() bool {
+    return fp.Capabilities&clientSSL != 0
+}
+
+// capabilities lists the names of the advertised capability flags
+func (fp *serverFingerprint) capabilities() []string {
+    var names []string
+    for _, c := range capabilityNames {
+        if fp.Capabilities&c.flag != 0 {
+            names = append(names, c.name)
+        }
+    }
+    return names
+}
+
+// anomalies lists greeting details no real MySQL-compatible server produces, comparing a second
+// greeting when one could be read. Honeypots and protocol emulators commonly trip these.
+func (fp *serverFingerprint) anomalies(second *serverFingerprint) []string {
+    var found []string
+    if fp.Protocol != 10 {
+        found = append(found, fmt.Sprintf("unexpected protocol version %d", fp.Protocol))
+    }
+    if fp.Version == "" || fp.Version[0] < '0' || fp.Version[0] > '9' {
+        found = append(found, fmt.Sprintf("version string %q does not start with a version number", fp.Version))
+    }
+    if major, minor, _ := versionNumbers(fp.Version); fp.Capabilities&clientProtocol41 == 0 && (major > 4 || major == 4 && minor >= 1) {
+        found = append(found, "PROTOCOL_41 capability missing")
+    }
+    if fp.ConnectionID == 0 {
+        found = append(found, "connection id is 0")
+    }
+    if fp.Capabilities&clientSecureConn != 0 && len(fp.scramble) != 20 {
+        found = append(found, fmt.Sprintf("auth scramble is %d bytes instead of 20", len(fp.scramble)))
+    }
+    for _, b := range fp.scramble {
+        if b == 0 || b > 0x7f {
+            found = append(found, "auth scramble contains bytes MySQL never generates")
+            break
+        }
+    }
+    if second != nil {
+        if bytes.Equal(fp.scramble, second.scramble) {
+            found = append(found, "auth scramble is identical across connections")
+        }
+        if fp.ConnectionID == second.ConnectionID {
+            found = append(found, "connection id is identical across connections")
+        }
+    }
+    return found
+}
+
+// runFingerprint reads the server greeting, prints and logs what it discloses, and warns about
+// signs of a honeypot. It reports false when no greeting could be read.
+func (e *Engine) runFingerprint(ctx context.Context, log *os.File) bool {
+    e.verbosePrintln("Fingerprinting server from its greeting")
+    fp, err := e.grabGreeting(ctx)
+    if err != nil {
+        var myErr *mysql.MySQLError
+        if errors.As(err, &myErr) && (myErr.Number == errHostBlocked || myErr.Number == errHostNotAllowed) {
+            e.printWarning("Fingerprint: server refused this source before authentication: %v", err)
+            e.printWarning("Every credential attempt from this source will fail the same way")
+        } else {
+            e.printWarning("Fingerprint: could not read server greeting: %v", err)
+        }
+        e.emitRecord("fingerprint", map[string]interface{}{"error": err.Error()})
+        return false
+    }
+
+    // A second greeting shows static scrambles and connection ids that only emulators produce
+    second, err := e.grabGreeting(ctx)
+    if err != nil {
+        e.verbosePrintln("Fingerprint: second greeting failed:", err)
+        second = nil
+    }
+    anomalies := fp.anomalies(second)
+
+    tlsSupport := "not supported"
+    if fp.tls() {
+        tlsSupport = "supported"
+    }
+    charset := strconv.Itoa(int(fp.Charset))
+    if name, ok := charsetNames[fp.Charset]; ok {
+        charset += " (" + name + ")"
+    }
+
+    var out strings.Builder
+    out.WriteString(e.theme.heading.Sprintf("Server fingerprint for %s:%d", e.opts.Host, e.opts.Port) + "\n")
+    fmt.Fprintf(&out, "  Version: %s\n", fp.Version)
+    fmt.Fprintf(&out, "  Flavor: %s\n", fp.flavor())
+    fmt.Fprintf(&out, "  Protocol: %d\n", fp.Protocol)
+    fmt.Fprintf(&out, "  Connection ID: %d\n", fp.ConnectionID)
+    fmt.Fprintf(&out, "  Auth plugin: %s\n", fp.AuthPlugin)
+    fmt.Fprintf(&out, "  TLS: %s\n", tlsSupport)
+    fmt.Fprintf(&out, "  Charset: %s\n", charset)
+    fmt.Fprintf(&out, "  Status flags: 0x%04x\n", fp.Status)
+    fmt.Fprintf(&out, "  Capabilities: 0x%08x %s\n", fp.Capabilities, strings.Join(fp.capabilities(), " "))
+    fmt.Fprint(e.out, out.String())
+    e.oldProtocol, e.oldPasswords = fp.legacyAuth()
+    if e.oldProtocol {
+        e.printWarning("  Legacy auth: pre-4.1 protocol, logins are tested with the old 8-byte scramble and nothing runs after them")
+    } else if e.oldPasswords {
+        e.printInfo("  Legacy auth: server predates auth plugins, pre-4.1 password hashes are accepted")
+    }
+    for _, a := range anomalies {
+        e.printWarning("  Suspicious: %s", a)
+    }
+    if len(anomalies) > 0 {
+        e.printWarning("The greeting looks emulated; the target may be a honeypot")
+    }
+
+    if log != nil {
+        fmt.Fprintf(log, "Fingerprint %s:%d: version=%q flavor=%s plugin=%s tls=%t caps=0x%08x anomalies=%q\n",
+            e.opts.Host, e.opts.Port, fp.Version, fp.flavor(), fp.AuthPlugin, fp.tls(), fp.Capabilities, anomalies)
+    }
+    e.emitRecord("fingerprint", map[string]interface{}{
+        "version":       fp.Version,
+        "flavor":        fp.flavor(),
+        "protocol":      fp.Protocol,
+        "connection_id": fp.ConnectionID,
+        "auth_plugin":   fp.AuthPlugin,
+        "tls":           fp.tls(),
+        "charset":       fp.Charset,
+        "status":        fp.Status,
+        "capabilities":  fp.capabilities(),
+        "anomalies":     anomalies,
+        "old_protocol":  e.oldProtocol,
+        "old_passwords": e.oldPasswords,
+    })
+    return true
+}
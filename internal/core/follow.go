@@ -0,0 +1,184 @@
+package core
+
+import (
+    "bufio"
+    "context"
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+)
+
+// followPollInterval is how often a followed file is checked for new lines once its end is reached
+const followPollInterval = time.Second
+
+// followLinesFromFile reads lines from a file like streamLinesFromFile, then keeps waiting for
+// lines appended to it until ctx is cancelled (--follow). A line is only used once its newline
+// has been written, so a writer caught mid-line is never read as two entries.
+func (e *Engine) followLinesFromFile(ctx context.Context, filename string) <-chan string {
+    ch := make(chan string)
+
+    go func() {
+        defer close(ch)
+
+        e.verbosePrintln("Following", filename)
+        file, err := os.Open(filename)
+        if err != nil {
+            e.printError("Error opening file: %v", err)
+            return
+        }
+        defer file.Close()
+
+        reader := bufio.NewReader(file)
+        partial := ""
+        for {
+            chunk, err := reader.ReadString('\n')
+            partial += chunk
+            if err == io.EOF {
+                select {
+                case <-ctx.Done():
+                    return
+                case <-time.After(followPollInterval):
+                }
+                continue
+            }
+            if err != nil {
+                e.printError("Error reading file: %v", err)
+                return
+            }
+
+            line := strings.TrimSpace(partial)
+            partial = ""
+            if line == "" {
+                continue
+            }
+            select {
+            case ch <- line:
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+
+    return ch
+}
+
+// buildFollowedPairs pairs users and passwords while either list keeps growing: a new user is
+// paired with every password seen so far and a new password with every user, so each
+// combination is tested exactly once whatever order the lines arrive in
+func (e *Engine) buildFollowedPairs(ctx context.Context, userChan, passChan <-chan string) <-chan Credential {
+    credChan := make(chan Credential)
+
+    go func() {
+        defer close(credChan)
+        e.verbosePrintln("Building credential pairs as the lists grow")
+
+        var users, passwords []string
+        var seq int64
+        send := func(c Credential) bool {
+            c.seq, seq = seq, seq+1
+            select {
+            case credChan <- c:
+                return true
+            case <-ctx.Done():
+                return false
+            }
+        }
+        for userChan != nil || passChan != nil {
+            select {
+            case u, ok := <-userChan:
+                if !ok {
+                    userChan = nil
+                    continue
+                }
+                users = append(users, u)
+                for j, p := range passwords {
+                    if !send(Credential{user: u, pass: p, userIdx: len(users), passIdx: j + 1}) {
+                        return
+                    }
+                }
+            case p, ok := <-passChan:
+                if !ok {
+                    passChan = nil
+                    continue
+                }
+                passwords = append(passwords, p)
+                for i, u := range users {
+                    if !send(Credential{user: u, pass: p, userIdx: i + 1, passIdx: len(passwords)}) {
+                        return
+                    }
+                }
+            case <-ctx.Done():
+                return
+            }
+        }
+    }()
+
+    return credChan
+}
+
+// nextCredCSVBatch waits for a row of a followed --cred-csv file, then takes the rows that come
+// in right after it, so rows written together are grouped by target. It returns false once the
+// stream has ended.
+func (e *Engine) nextCredCSVBatch(ctx context.Context, lines <-chan string, lineNum *int) ([]ResultRecord, bool) {
+    var batch []ResultRecord
+    var timeout <-chan time.Time
+    for {
+        select {
+        case line, ok := <-lines:
+            if !ok {
+                return batch, len(batch) > 0
+            }
+            *lineNum++
+            row, err := csv.NewReader(strings.NewReader(line)).Read()
+            if err != nil {
+                e.printError("Error reading credentials file: line %d: %v", *lineNum, err)
+                continue
+            }
+            if *lineNum == 1 && isCredentialHeader(row) {
+                continue
+            }
+            r, err := e.parseCredentialRow(row, *lineNum)
+            if err != nil {
+                e.printError("Error reading credentials file: %v", err)
+                continue
+            }
+            batch = append(batch, r)
+            timeout = time.After(followPollInterval)
+        case <-timeout:
+            return batch, true
+        case <-ctx.Done():
+            return batch, len(batch) > 0
+        }
+    }
+}
+
+// followCredCSV replays a --cred-csv file, then every row appended to it, until ctx is
+// cancelled or -f stops the run. Returns how many credentials were tried and how many succeeded.
+func (e *Engine) followCredCSV(ctx context.Context, filename string, replay *credReplay) (int, int) {
+    fmt.Fprintf(e.out, "Replaying credentials from %s as they are written, Ctrl-C to stop\n", filename)
+    replay.stats = e.newRunStats("Replaying credentials", -1, 0, 0)
+    startStatusLine(ctx, replay.stats)
+
+    lines := e.followLinesFromFile(ctx, filename)
+    lineNum, total := 0, 0
+    for ctx.Err() == nil {
+        batch, ok := e.nextCredCSVBatch(ctx, lines, &lineNum)
+        if !ok {
+            break
+        }
+        targets, err := groupByTarget(batch)
+        if err != nil {
+            e.printError("Error reading credentials file: %v", err)
+            continue
+        }
+        total += len(batch)
+        if err := replay.run(ctx, targets); errors.Is(err, errFirstSuccess) {
+            break
+        }
+    }
+    return total, replay.found
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+    "bytes"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+)
+
+// goldenDir holds the expected outputs the self-test compares against
+const goldenDir = "testdata/golden"
+
+// goldenSet compares outputs against files under a golden directory, or records them with --update
+type goldenSet struct {
+    dir    string
+    update bool
+    failed []string
+}
+
+// normalizer replaces output that changes between runs (timestamps, durations) with a fixed token
+type normalizer struct {
+    pattern *regexp.Regexp
+    replace string
+}
+
+// check compares got, after applying the normalizers, with the golden file name. With update set
+// the golden file is (re)written instead.
+func (g *goldenSet) check(name string, got []byte, normalizers ...normalizer) {
+    for _, n := range normalizers {
+        got = n.pattern.ReplaceAll(got, []byte(n.replace))
+    }
+    path := filepath.Join(g.dir, filepath.FromSlash(name))
+
+    if g.update {
+        if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+            g.fail(name, err)
+            return
+        }
+        if err := os.WriteFile(path, got, 0644); err != nil {
+            g.fail(name, err)
+            return
+        }
+        printInfo("RECORDED %s", name)
+        return
+    }
+
+    want, err := os.ReadFile(path)
+    if os.IsNotExist(err) {
+        g.fail(name, fmt.Errorf("no golden file %s (run 'sqlblaster selftest --update' to record it)", path))
+        return
+    }
+    if err != nil {
+        g.fail(name, err)
+        return
+    }
+    if !bytes.Equal(got, want) {
+        // Keep the actual output next to the golden file for diffing
+        os.WriteFile(path+".actual", got, 0644)
+        g.fail(name, fmt.Errorf("output differs from %s at %s (actual output in %s.actual)", path, firstDifference(want, got), path))
+        return
+    }
+    os.Remove(path + ".actual")
+    printSuccess("PASS %s", name)
+}
+
+// fail records a failed check
+func (g *goldenSet) fail(name string, err error) {
+    g.failed = append(g.failed, name)
+    printError("FAIL %s: %v", name, err)
+}
+
+// firstDifference describes the first line where want and got differ
+func firstDifference(want, got []byte) string {
+    wantLines := strings.Split(string(want), "\n")
+    gotLines := strings.Split(string(got), "\n")
+    for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+        var w, g string
+        if i < len(wantLines) {
+            w = wantLines[i]
+        }
+        if i < len(gotLines) {
+            g = gotLines[i]
+        }
+        if w != g || i >= len(wantLines) || i >= len(gotLines) {
+            return fmt.Sprintf("line %d: want %q, got %q", i+1, w, g)
+        }
+    }
+    return "end of file"
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "os"
+    "os/exec"
+    "strings"
+    "text/template"
+    "time"
+)
+
+// HookEvent is the data available to --on-success templates, e.g. {{.Host}} {{.User}} {{.Pass}}
+type HookEvent struct {
+    Host string
+    Port int
+    User string
+    Pass string
+    Time string
+}
+
+// splitCommandLine splits a command into arguments, honouring single and double quotes
+func splitCommandLine(cmdLine string) ([]string, error) {
+    var args []string
+    var current strings.Builder
+    var quote rune
+    inArg := false
+
+    for _, r := range cmdLine {
+        switch {
+        case quote != 0:
+            if r == quote {
+                quote = 0
+            } else {
+                current.WriteRune(r)
+            }
+        case r == '\'' || r == '"':
+            quote = r
+            inArg = true
+        case r == ' ' || r == '\t':
+            if inArg {
+                args = append(args, current.String())
+                current.Reset()
+                inArg = false
+            }
+        default:
+            current.WriteRune(r)
+            inArg = true
+        }
+    }
+    if quote != 0 {
+        return nil, fmt.Errorf("unterminated quote in '%s'", cmdLine)
+    }
+    if inArg {
+        args = append(args, current.String())
+    }
+    if len(args) == 0 {
+        return nil, fmt.Errorf("empty command")
+    }
+    return args, nil
+}
+
+// parseHookCommand prepares a hook command. Each argument is a separate template and the
+// command runs without a shell, so credentials cannot inject shell syntax.
+func parseHookCommand(cmdLine string) ([]*template.Template, error) {
+    args, err := splitCommandLine(cmdLine)
+    if err != nil {
+        return nil, err
+    }
+    templates := make([]*template.Template, len(args))
+    for i, arg := range args {
+        t, err := template.New(fmt.Sprintf("arg%d", i)).Option("missingkey=error").Parse(arg)
+        if err != nil {
+            return nil, err
+        }
+        templates[i] = t
+    }
+    return templates, nil
+}
+
+// setupSuccessHook parses the --on-success command
+func (e *Engine) setupSuccessHook(cmdLine string) error {
+    if cmdLine == "" {
+        return nil
+    }
+    templates, err := parseHookCommand(cmdLine)
+    if err != nil {
+        return err
+    }
+    e.successHook = templates
+    return nil
+}
+
+// runSuccessHook starts the --on-success command for a found credential in the background
+func (e *Engine) runSuccessHook(user, pass string, log *os.File) {
+    if e.successHook == nil {
+        return
+    }
+    event := HookEvent{
+        Host: e.opts.Host,
+        Port: e.opts.Port,
+        User: user,
+        Pass: pass,
+        Time: time.Now().Format(time.RFC3339),
+    }
+
+    e.hooksWG.Add(1)
+    go func() {
+        defer e.hooksWG.Done()
+        e.runHook("on-success", e.successHook, event, log)
+    }()
+}
+
+// runHook renders the templates with data and runs the command with the --hook-timeout limit
+func (e *Engine) runHook(name string, templates []*template.Template, data interface{}, log *os.File) {
+    args := make([]string, len(templates))
+    for i, t := range templates {
+        var buf bytes.Buffer
+        if err := t.Execute(&buf, data); err != nil {
+            e.printWarning("Hook %s: %v", name, err)
+            return
+        }
+        args[i] = buf.String()
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.opts.HookTimeout)*time.Second)
+    defer cancel()
+
+    e.verbosePrintf("Running %s hook: %s\n", name, args[0])
+    cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+    output, err := cmd.CombinedOutput()
+    if len(output) > 0 {
+        e.verbosePrintf("Hook %s output:\n%s\n", name, output)
+    }
+
+    var msg string
+    if ctx.Err() == context.DeadlineExceeded {
+        msg = fmt.Sprintf("Hook %s timed out after %ds", name, e.opts.HookTimeout)
+    } else if err != nil {
+        msg = fmt.Sprintf("Hook %s failed: %v", name, err)
+    }
+    if msg != "" {
+        e.printWarning("\n%s", msg)
+        if log != nil {
+            log.WriteString(msg + "\n")
+        }
+    }
+
+    record := map[string]interface{}{"hook": name, "command": args[0], "output": string(output)}
+    if msg != "" {
+        record["error"] = msg
+    }
+    e.emitRecord("hook", record)
+}
+
+// waitForHooks waits for background hook commands to finish before exiting
+func (e *Engine) waitForHooks() {
+    e.hooksWG.Wait()
+}
@@ -0,0 +1,312 @@
+package core
+
+import (
+    "fmt"
+    "os"
+    "sort"
+    "strings"
+)
+
+// defaultLang is used for messages missing from the selected catalog
+const defaultLang = "en"
+
+// messages holds the user-facing strings for every supported language: run and lockout messages,
+// the reports and the interactive shell. Help texts and verbose output are English only.
+var messages = map[string]map[string]string{
+    "en": {
+        "err.host_required":     "Error: Hostname (-h) is required.",
+        "err.user_required":     "Error: Either single username (-u) or username file (-U) must be specified.",
+        "err.user_exclusive":    "Error: -u and -U are mutually exclusive.",
+        "err.userfile_missing":  "Error: Username file '%s' not found",
+        "err.passfile_missing":  "Error: Password file '%s' not found",
+        "err.connect_single":    "Error: --connect requires single username (-u) and password (-p).",
+        "err.connect_lists":     "Error: --connect is not compatible with -U or -P flags.",
+        "err.dump_single":       "Error: --dump requires single username (-u) and password (-p).",
+        "err.dump_lists":        "Error: --dump is not compatible with -U or -P flags.",
+        "err.credfile_missing":  "Error: Credentials file '%s' not found",
+        "err.credfile_read":     "Error reading credentials file: %v",
+        "err.log_file":          "Error opening log file: %v",
+        "err.unknown_lang":      "Warning: Language '%s' is not available, using English. Available: %s",
+        "run.starting":          "Starting MySQL testing on %s:%d...",
+        "run.shutdown":          "\nShutting down gracefully...",
+        "run.interrupted":       "\nTesting interrupted.",
+        "run.complete":          "\nTesting complete.",
+        "login.success_pass":    "Success: %s with password '%s'",
+        "login.success_nopass":  "Success: %s with no password",
+        "cmd.blocked":           "Warning: Command '%s' starts with a dangerous verb and is blocked. Use --allow-dangerous to execute.",
+        "cmd.policy_blocked":    "Warning: Command '%s' is blocked by the statement policy (%s). Use --allow-dangerous to execute.",
+        "cmd.policy_warn":       "Warning: Command '%s' is flagged by the statement policy (%s).",
+        "cmd.executing":         "Executing command: %s",
+        "cmd.success":           "Command executed successfully.",
+        "cmd.query_error":       "Error executing query: %v",
+        "cmd.exec_error":        "Error executing command: %v",
+        "shell.enter":           "Entering interactive mode. Type 'help' for commands, 'exit' to quit.",
+        "shell.exit":            "Exiting interactive mode.",
+        "shell.db_changed":      "Database changed to %s",
+        "shell.db_change_error": "Error switching to database %s: %v",
+        "shell.interrupted":     "Query interrupted.",
+        "shell.read_error":      "Error reading input: %v",
+        "shell.error":           "Error: %v",
+        "shell.export_off":      "Query results go to the screen again",
+        "shell.export_on":       "The next query's results will be written to %s as %s",
+        "shell.download_error":  "Error downloading %s: %v",
+        "shell.downloaded":      "Downloaded %s (%d bytes) to %s",
+        "shell.upload_error":    "Error uploading %s: %v",
+        "shell.uploaded":        "Uploaded %s (%d bytes) to %s",
+        "shell.udf_error":       "Error installing UDF: %v",
+        "shell.udf_drop_error":  "Error removing UDF: %v",
+        "shell.udf_dropped":     "Dropped sys_exec and sys_eval (the library stays in plugin_dir)",
+        "shell.os_usage":        "Error: usage: !<command>",
+        "shell.os_error":        "Error running command: %v",
+        "shell.note_usage":      "Error: usage: note <text>",
+        "shell.note_added":      "Note added to %s@%s:%d",
+        "shell.db_list_error":   "Error listing databases: %v",
+        "shell.db_list":         "Available databases:",
+        "shell.db_name_error":   "Error reading database name: %v",
+        "shell.db_system":       "  %s (system)",
+        "shell.db_none":         "  No databases found or insufficient privileges",
+        "shell.db_count":        "\n%d databases found",
+        "shell.into_local":      "Error: INTO LOCAL FILE only applies to statements that return rows",
+        "shell.write_error":     "Error writing %s: %v",
+        "shell.rows_written":    "%d rows written to %s",
+        "status.connection":     "Connection: %s@%s:%d",
+        "status.version":        "Server version: %s",
+        "status.version_error":  "Server version: Error retrieving version",
+        "status.user":           "Current user: %s",
+        "status.user_error":     "Current user: Error retrieving user",
+        "status.db":             "Current database: %s",
+        "status.db_error":       "Current database: Error retrieving database",
+        "status.db_none":        "Current database: None selected",
+        "lockout.host":          "Lockout: %s:%d reports %s, stopping all testing against this host",
+        "lockout.user":          "Lockout: %s (%s), skipping remaining passwords for this user",
+        "lockout.pause":         "Lockout: %s:%d reports %s, pausing all testing until %s",
+        "lockout.summary":       "\nLockouts detected:\n",
+        "lockout.failover":      "Lockout: %s:%d blocked source %s, failing over to %s",
+        "lockout.all_sources":   "Lockout: %s:%d reports %s for every source, stopping all testing against this host",
+        "lockout.burned":        "  source %s - burned for %s:%d\n",
+        "replay.starting":       "Replaying %d credential(s) from %s against %d target(s)",
+        "replay.complete":       "\nReplay complete: %d valid credential(s) out of %d",
+        "replay.unresolved":     "\nError: cannot resolve %s, skipping %d credential(s): %v",
+        "resume.order":          "pair order %s, now %s",
+        "resume.no_checksums":   "%s has no wordlist checksums, cannot tell whether the lists changed since it was saved",
+        "resume.forced":         "Wordlists or pair order changed since the state was saved (%v), resuming anyway because of --force-resume",
+        "resume.changed":        "wordlists or pair order changed since the state was saved: %v (use --force-resume to resume anyway, or start over without --resume)",
+        "resume.no_session":     "no session %s in %s (see --list-sessions)",
+        "resume.other_target":   "session %s was saved for %s:%d, the command line names another target or other wordlists",
+        "resume.skipping":       "Skipping %v",
+        "resume.none":           "No sessions in %s",
+        "resume.header":         "ID\tTARGET\tUSERS\tPASSWORDS\tCOMPLETED\tUPDATED",
+        "resume.single_pass":    "(single password)",
+        "resume.hint":           "\nResume one with --resume-session <id>",
+        "state.open_error":      "Error opening state file: %v",
+        "state.decode_error":    "Error decoding state file: %v",
+        "state.encode_error":    "Error encoding state file: %v",
+        "state.write_error":     "Error writing state file: %v",
+        "stats.tested":          "Tested %d credential pair(s)",
+        "stats.tested_of":       "Tested %d of %d credential pair(s)",
+        "stats.resumed":         " after resuming at %d",
+        "stats.skipped":         ", skipped %d",
+        "stats.summary":         "%s in %s (%.1f/s): %d found, %d errors",
+        "summary.target":        "Target",
+        "summary.attempts":      "Attempts",
+        "summary.successes":     "Successes",
+        "summary.total":         "Total (%d target(s))",
+        "summary.duration":      "Duration: %s",
+        "summary.details":       "Details",
+        "summary.results_db":    "Results database",
+        "summary.inventory":     "Inventory",
+        "summary.dump":          "Dump",
+        "summary.spooled":       "Spooled results",
+        "verify.starting":       "Verifying %d credentials from %s...",
+        "verify.interrupted":    "\nVerification interrupted.",
+        "verify.complete":       "\nVerification complete: %d passed, %d failed.",
+        "export.sarif":          "Findings exported to %s",
+        "export.junit":          "JUnit report written to %s",
+        "export.inventory":      "Ansible inventory written to %s",
+        "export.junit_failures": "%d account(s) failed the weak credential check.",
+        "compare.header":        "Comparing %s (%d successes) with %s (%d successes)",
+        "compare.new_hosts":     "Newly vulnerable hosts (%d):",
+        "compare.new_accounts":  "Newly vulnerable accounts on already vulnerable hosts (%d):",
+        "compare.remediated":    "Remediated accounts (%d):",
+        "compare.not_retested":  "Not re-tested, host not reached in the second run (%d):",
+        "compare.changed":       "Credential changes (%d):",
+        "compare.unchanged":     "Still vulnerable with same credentials (%d):",
+        "compare.undetermined":  "Still vulnerable, credential change unknown (--prove-only in both runs) (%d):",
+    },
+    "es": {
+        "err.host_required":     "Error: Se requiere el nombre del servidor (-h).",
+        "err.user_required":     "Error: Debe indicar un usuario (-u) o un archivo de usuarios (-U).",
+        "err.user_exclusive":    "Error: -u y -U son mutuamente excluyentes.",
+        "err.userfile_missing":  "Error: No se encontró el archivo de usuarios '%s'",
+        "err.passfile_missing":  "Error: No se encontró el archivo de contraseñas '%s'",
+        "err.connect_single":    "Error: --connect requiere un único usuario (-u) y contraseña (-p).",
+        "err.connect_lists":     "Error: --connect no es compatible con -U o -P.",
+        "err.dump_single":       "Error: --dump requiere un único usuario (-u) y contraseña (-p).",
+        "err.dump_lists":        "Error: --dump no es compatible con -U o -P.",
+        "err.credfile_missing":  "Error: No se encontró el archivo de credenciales '%s'",
+        "err.credfile_read":     "Error al leer el archivo de credenciales: %v",
+        "err.log_file":          "Error al abrir el archivo de registro: %v",
+        "err.unknown_lang":      "Aviso: El idioma '%s' no está disponible, se usará inglés. Disponibles: %s",
+        "run.starting":          "Iniciando pruebas de MySQL en %s:%d...",
+        "run.shutdown":          "\nDeteniendo de forma ordenada...",
+        "run.interrupted":       "\nPruebas interrumpidas.",
+        "run.complete":          "\nPruebas completadas.",
+        "login.success_pass":    "Éxito: %s con la contraseña '%s'",
+        "login.success_nopass":  "Éxito: %s sin contraseña",
+        "cmd.blocked":           "Aviso: El comando '%s' comienza con un verbo peligroso y se ha bloqueado. Use --allow-dangerous para ejecutarlo.",
+        "cmd.policy_blocked":    "Aviso: La política de sentencias bloquea el comando '%s' (%s). Use --allow-dangerous para ejecutarlo.",
+        "cmd.policy_warn":       "Aviso: La política de sentencias marca el comando '%s' (%s).",
+        "cmd.executing":         "Ejecutando comando: %s",
+        "cmd.success":           "Comando ejecutado correctamente.",
+        "cmd.query_error":       "Error al ejecutar la consulta: %v",
+        "cmd.exec_error":        "Error al ejecutar el comando: %v",
+        "shell.enter":           "Entrando en modo interactivo. Escriba 'help' para ver los comandos, 'exit' para salir.",
+        "shell.exit":            "Saliendo del modo interactivo.",
+        "shell.db_changed":      "Base de datos cambiada a %s",
+        "shell.db_change_error": "Error al cambiar a la base de datos %s: %v",
+        "shell.interrupted":     "Consulta interrumpida.",
+        "shell.read_error":      "Error al leer la entrada: %v",
+        "shell.error":           "Error: %v",
+        "shell.export_off":      "Los resultados de las consultas vuelven a la pantalla",
+        "shell.export_on":       "Los resultados de la próxima consulta se escribirán en %s como %s",
+        "shell.download_error":  "Error al descargar %s: %v",
+        "shell.downloaded":      "Descargado %s (%d bytes) en %s",
+        "shell.upload_error":    "Error al subir %s: %v",
+        "shell.uploaded":        "Subido %s (%d bytes) a %s",
+        "shell.udf_error":       "Error al instalar la UDF: %v",
+        "shell.udf_drop_error":  "Error al eliminar la UDF: %v",
+        "shell.udf_dropped":     "Eliminadas sys_exec y sys_eval (la biblioteca se queda en plugin_dir)",
+        "shell.os_usage":        "Error: uso: !<comando>",
+        "shell.os_error":        "Error al ejecutar el comando: %v",
+        "shell.note_usage":      "Error: uso: note <texto>",
+        "shell.note_added":      "Nota añadida a %s@%s:%d",
+        "shell.db_list_error":   "Error al listar las bases de datos: %v",
+        "shell.db_list":         "Bases de datos disponibles:",
+        "shell.db_name_error":   "Error al leer el nombre de la base de datos: %v",
+        "shell.db_system":       "  %s (sistema)",
+        "shell.db_none":         "  No se encontraron bases de datos o faltan privilegios",
+        "shell.db_count":        "\n%d bases de datos encontradas",
+        "shell.into_local":      "Error: INTO LOCAL FILE solo se aplica a sentencias que devuelven filas",
+        "shell.write_error":     "Error al escribir %s: %v",
+        "shell.rows_written":    "%d filas escritas en %s",
+        "status.connection":     "Conexión: %s@%s:%d",
+        "status.version":        "Versión del servidor: %s",
+        "status.version_error":  "Versión del servidor: Error al obtener la versión",
+        "status.user":           "Usuario actual: %s",
+        "status.user_error":     "Usuario actual: Error al obtener el usuario",
+        "status.db":             "Base de datos actual: %s",
+        "status.db_error":       "Base de datos actual: Error al obtener la base de datos",
+        "status.db_none":        "Base de datos actual: Ninguna seleccionada",
+        "lockout.host":          "Bloqueo: %s:%d informa %s, se detienen todas las pruebas contra este servidor",
+        "lockout.user":          "Bloqueo: %s (%s), se omiten las contraseñas restantes de este usuario",
+        "lockout.pause":         "Bloqueo: %s:%d informa %s, se pausan todas las pruebas hasta las %s",
+        "lockout.summary":       "\nBloqueos detectados:\n",
+        "lockout.failover":      "Bloqueo: %s:%d bloqueó el origen %s, se cambia a %s",
+        "lockout.all_sources":   "Bloqueo: %s:%d informa %s para todos los orígenes, se detienen todas las pruebas contra este servidor",
+        "lockout.burned":        "  origen %s - quemado para %s:%d\n",
+        "replay.starting":       "Reproduciendo %d credencial(es) de %s contra %d objetivo(s)",
+        "replay.complete":       "\nReproducción completada: %d credencial(es) válida(s) de %d",
+        "replay.unresolved":     "\nError: no se puede resolver %s, se omiten %d credencial(es): %v",
+        "resume.order":          "orden de pares %s, ahora %s",
+        "resume.no_checksums":   "%s no tiene sumas de comprobación de las listas, no se puede saber si cambiaron desde que se guardó",
+        "resume.forced":         "Las listas o el orden de pares cambiaron desde que se guardó el estado (%v), se reanuda igualmente por --force-resume",
+        "resume.changed":        "las listas o el orden de pares cambiaron desde que se guardó el estado: %v (use --force-resume para reanudar igualmente, o empiece de nuevo sin --resume)",
+        "resume.no_session":     "no hay ninguna sesión %s en %s (vea --list-sessions)",
+        "resume.other_target":   "la sesión %s se guardó para %s:%d, la línea de comandos indica otro objetivo u otras listas",
+        "resume.skipping":       "Se omite %v",
+        "resume.none":           "No hay sesiones en %s",
+        "resume.header":         "ID\tOBJETIVO\tUSUARIOS\tCONTRASEÑAS\tCOMPLETADOS\tACTUALIZADA",
+        "resume.single_pass":    "(contraseña única)",
+        "resume.hint":           "\nReanude una con --resume-session <id>",
+        "state.open_error":      "Error al abrir el archivo de estado: %v",
+        "state.decode_error":    "Error al decodificar el archivo de estado: %v",
+        "state.encode_error":    "Error al codificar el archivo de estado: %v",
+        "state.write_error":     "Error al escribir el archivo de estado: %v",
+        "stats.tested":          "Probados %d par(es) de credenciales",
+        "stats.tested_of":       "Probados %d de %d par(es) de credenciales",
+        "stats.resumed":         " tras reanudar en %d",
+        "stats.skipped":         ", omitidos %d",
+        "stats.summary":         "%s en %s (%.1f/s): %d encontradas, %d errores",
+        "summary.target":        "Objetivo",
+        "summary.attempts":      "Intentos",
+        "summary.successes":     "Éxitos",
+        "summary.total":         "Total (%d objetivo(s))",
+        "summary.duration":      "Duración: %s",
+        "summary.details":       "Detalles",
+        "summary.results_db":    "Base de datos de resultados",
+        "summary.inventory":     "Inventario",
+        "summary.dump":          "Volcado",
+        "summary.spooled":       "Resultados volcados a disco",
+        "verify.starting":       "Verificando %d credenciales de %s...",
+        "verify.interrupted":    "\nVerificación interrumpida.",
+        "verify.complete":       "\nVerificación completada: %d correctas, %d fallidas.",
+        "export.sarif":          "Hallazgos exportados a %s",
+        "export.junit":          "Informe JUnit escrito en %s",
+        "export.inventory":      "Inventario de Ansible escrito en %s",
+        "export.junit_failures": "%d cuenta(s) no superaron la comprobación de credenciales débiles.",
+        "compare.header":        "Comparando %s (%d éxitos) con %s (%d éxitos)",
+        "compare.new_hosts":     "Servidores vulnerables nuevos (%d):",
+        "compare.new_accounts":  "Cuentas vulnerables nuevas en servidores ya vulnerables (%d):",
+        "compare.remediated":    "Cuentas corregidas (%d):",
+        "compare.not_retested":  "Sin volver a probar, servidor no alcanzado en la segunda ejecución (%d):",
+        "compare.changed":       "Cambios de credenciales (%d):",
+        "compare.unchanged":     "Siguen vulnerables con las mismas credenciales (%d):",
+        "compare.undetermined":  "Siguen vulnerables, cambio de credenciales desconocido (--prove-only en ambas ejecuciones) (%d):",
+    },
+}
+
+// tr returns the localized message for key in the run's language formatted with args, falling
+// back to English
+func (e *Engine) tr(key string, args ...interface{}) string {
+    return translate(e.lang, key, args...)
+}
+
+// translate returns the message for key in lang formatted with args, falling back to English
+func translate(lang, key string, args ...interface{}) string {
+    format, ok := messages[lang][key]
+    if !ok {
+        format, ok = messages[defaultLang][key]
+        if !ok {
+            return key
+        }
+    }
+    if len(args) == 0 {
+        return format
+    }
+    return fmt.Sprintf(format, args...)
+}
+
+// availableLangs returns the sorted list of supported language codes
+func availableLangs() []string {
+    langs := make([]string, 0, len(messages))
+    for lang := range messages {
+        langs = append(langs, lang)
+    }
+    sort.Strings(langs)
+    return langs
+}
+
+// setLang selects the run's message catalog, reporting false when the language is unsupported
+func (e *Engine) setLang(lang string) bool {
+    lang = strings.ToLower(strings.TrimSpace(lang))
+    if _, ok := messages[lang]; !ok {
+        return false
+    }
+    e.lang = lang
+    return true
+}
+
+// detectLang picks the run's catalog from the environment (e.g. LANG=es_ES.UTF-8) before flags
+// are parsed
+func (e *Engine) detectLang() {
+    for _, env := range []string{"SQLBLASTER_LANG", "LC_ALL", "LANG"} {
+        value := os.Getenv(env)
+        if value == "" {
+            continue
+        }
+        code := strings.SplitN(strings.SplitN(value, ".", 2)[0], "_", 2)[0]
+        if e.setLang(code) {
+            return
+        }
+    }
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+    "regexp"
+    "strings"
+    "testing"
+)
+
+// TestCatalogsMatch checks that every catalog has the English keys, with the same format verbs
+// in the same order, so a translated message never misformats its arguments
+func TestCatalogsMatch(t *testing.T) {
+    verbs := regexp.MustCompile(`%[-+# 0-9.*]*[a-zA-Z%]`)
+    for lang, catalog := range messages {
+        for key, format := range messages[defaultLang] {
+            translated, ok := catalog[key]
+            if !ok {
+                t.Errorf("%s: missing %s", lang, key)
+                continue
+            }
+            want := strings.Join(verbs.FindAllString(format, -1), " ")
+            if got := strings.Join(verbs.FindAllString(translated, -1), " "); got != want {
+                t.Errorf("%s: %s has verbs %q, want %q", lang, key, got, want)
+            }
+        }
+    }
+}
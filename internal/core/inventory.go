@@ -0,0 +1,204 @@
+package core
+
+import (
+    "bytes"
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/binary"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// vaultIterations is the PBKDF2 iteration count of the Ansible Vault 1.1 AES256 format
+const vaultIterations = 10000
+
+// readVaultPassword reads the vault password from a file the way ansible-vault does, ignoring
+// the trailing newline
+func readVaultPassword(filename string) (string, error) {
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        return "", err
+    }
+    password := strings.TrimRight(string(data), "\r\n")
+    if password == "" {
+        return "", fmt.Errorf("vault password file '%s' is empty", filename)
+    }
+    return password, nil
+}
+
+// pbkdf2SHA256 derives keyLen bytes from password with PBKDF2-HMAC-SHA256 (RFC 8018)
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+    prf := hmac.New(sha256.New, password)
+    var key []byte
+    for block := uint32(1); len(key) < keyLen; block++ {
+        prf.Reset()
+        prf.Write(salt)
+        prf.Write(binary.BigEndian.AppendUint32(nil, block))
+        u := prf.Sum(nil)
+        t := append([]byte(nil), u...)
+        for i := 1; i < iterations; i++ {
+            prf.Reset()
+            prf.Write(u)
+            u = prf.Sum(u[:0])
+            for j := range t {
+                t[j] ^= u[j]
+            }
+        }
+        key = append(key, t...)
+    }
+    return key[:keyLen]
+}
+
+// vaultEncrypt encrypts plaintext in the Ansible Vault 1.1 AES256 format, so it can be read with
+// ansible-vault and used as an inline !vault value
+func vaultEncrypt(plaintext, password string) (string, error) {
+    salt := make([]byte, 32)
+    if _, err := rand.Read(salt); err != nil {
+        return "", err
+    }
+    // 32 bytes of cipher key, 32 of HMAC key and 16 of counter
+    keys := pbkdf2SHA256([]byte(password), salt, vaultIterations, 80)
+    cipherKey, hmacKey, iv := keys[:32], keys[32:64], keys[64:]
+
+    // PKCS#7 padding, although CTR does not need it, is part of the format
+    padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+    data := append([]byte(plaintext), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+    block, err := aes.NewCipher(cipherKey)
+    if err != nil {
+        return "", err
+    }
+    ciphertext := make([]byte, len(data))
+    cipher.NewCTR(block, iv).XORKeyStream(ciphertext, data)
+
+    mac := hmac.New(sha256.New, hmacKey)
+    mac.Write(ciphertext)
+
+    body := hex.EncodeToString([]byte(hex.EncodeToString(salt) + "\n" + hex.EncodeToString(mac.Sum(nil)) + "\n" + hex.EncodeToString(ciphertext)))
+    var out strings.Builder
+    out.WriteString("$ANSIBLE_VAULT;1.1;AES256")
+    for len(body) > 0 {
+        n := min(80, len(body))
+        out.WriteString("\n" + body[:n])
+        body = body[n:]
+    }
+    return out.String(), nil
+}
+
+// yamlString quotes s for YAML
+func yamlString(s string) string {
+    return strconv.Quote(s)
+}
+
+// writeVaultValue writes key with an inline vaulted value at the given indentation
+func writeVaultValue(b *strings.Builder, indent, key, plaintext, password string) error {
+    vaulted, err := vaultEncrypt(plaintext, password)
+    if err != nil {
+        return err
+    }
+    fmt.Fprintf(b, "%s%s: !vault |\n", indent, key)
+    for _, line := range strings.Split(vaulted, "\n") {
+        fmt.Fprintf(b, "%s  %s\n", indent, line)
+    }
+    return nil
+}
+
+// inventoryAlias names a target in the inventory; hosts with several ports get one entry each
+func inventoryAlias(r ResultRecord) string {
+    return sanitizeFilename(fmt.Sprintf("%s_%d", r.Host, r.Port))
+}
+
+// buildInventory renders the successful logins as an Ansible YAML inventory. Each target becomes
+// a host in the sqlblaster_mysql group with connection variables for the community.mysql
+// modules (login_host, login_port, login_user, login_password) taken from its first fully usable
+// account, and every account found listed under mysql_accounts. Passwords are vaulted.
+func buildInventory(records []ResultRecord, password string, generated time.Time) (string, error) {
+    byTarget := make(map[string][]ResultRecord)
+    var targets []string
+    for _, r := range records {
+        alias := inventoryAlias(r)
+        if byTarget[alias] == nil {
+            targets = append(targets, alias)
+        }
+        byTarget[alias] = append(byTarget[alias], r)
+    }
+    sort.Strings(targets)
+
+    var b strings.Builder
+    fmt.Fprintf(&b, "# MySQL access found by sqlblaster on %s\n", generated.Format(time.RFC3339))
+    b.WriteString("# Passwords are encrypted with ansible-vault; run playbooks with --vault-password-file\n")
+    if len(targets) == 0 {
+        // An empty group still parses, so playbooks against it simply match no hosts
+        b.WriteString("all:\n  children:\n    sqlblaster_mysql:\n      hosts: {}\n")
+        return b.String(), nil
+    }
+    b.WriteString("all:\n  children:\n    sqlblaster_mysql:\n      hosts:\n")
+
+    for _, alias := range targets {
+        accounts := byTarget[alias]
+        // Prefer an account that can run queries for the connection variables
+        login := accounts[0]
+        for _, r := range accounts {
+            if r.Restrictions == "" {
+                login = r
+                break
+            }
+        }
+
+        const indent = "        "
+        fmt.Fprintf(&b, "%s%s:\n", indent, alias)
+        fmt.Fprintf(&b, "%s  ansible_host: %s\n", indent, yamlString(login.Host))
+        fmt.Fprintf(&b, "%s  login_host: %s\n", indent, yamlString(login.Host))
+        fmt.Fprintf(&b, "%s  login_port: %d\n", indent, login.Port)
+        fmt.Fprintf(&b, "%s  login_user: %s\n", indent, yamlString(login.User))
+        if err := writeVaultValue(&b, indent+"  ", "login_password", login.Pass, password); err != nil {
+            return "", err
+        }
+        fmt.Fprintf(&b, "%s  mysql_server: %s\n", indent, yamlString(login.serverName()))
+        fmt.Fprintf(&b, "%s  mysql_accounts:\n", indent)
+        for _, r := range accounts {
+            fmt.Fprintf(&b, "%s    - user: %s\n", indent, yamlString(r.User))
+            if err := writeVaultValue(&b, indent+"      ", "password", r.Pass, password); err != nil {
+                return "", err
+            }
+            fmt.Fprintf(&b, "%s      empty_password: %t\n", indent, r.Pass == "")
+            fmt.Fprintf(&b, "%s      found_at: %s\n", indent, yamlString(r.FoundAt))
+            if r.Plugin != "" {
+                fmt.Fprintf(&b, "%s      plugin: %s\n", indent, yamlString(r.Plugin))
+            }
+            if r.Restrictions != "" {
+                fmt.Fprintf(&b, "%s      restrictions: %s\n", indent, yamlString(r.Restrictions))
+            }
+            if len(r.Notes) > 0 {
+                fmt.Fprintf(&b, "%s      notes:\n", indent)
+                for _, n := range r.Notes {
+                    fmt.Fprintf(&b, "%s        - %s\n", indent, yamlString(n))
+                }
+            }
+        }
+    }
+    return b.String(), nil
+}
+
+// exportInventory writes the successful logins of the run as a vaulted Ansible inventory
+func (e *Engine) exportInventory(filename, passwordFile string, records []ResultRecord) error {
+    e.verbosePrintln("Exporting Ansible inventory:", filename)
+    password, err := readVaultPassword(passwordFile)
+    if err != nil {
+        return err
+    }
+    inventory, err := buildInventory(records, password, time.Now())
+    if err != nil {
+        return err
+    }
+    // The file names every affected host even though the passwords are encrypted
+    return os.WriteFile(filename, []byte(inventory), 0600)
+}
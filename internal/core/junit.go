@@ -0,0 +1,117 @@
+package core
+
+import (
+    "encoding/xml"
+    "fmt"
+    "os"
+    "sort"
+    "time"
+)
+
+// JUnit XML report structure as understood by common CI systems
+type junitTestSuites struct {
+    XMLName xml.Name         `xml:"testsuites"`
+    Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+    Name      string          `xml:"name,attr"`
+    Tests     int             `xml:"tests,attr"`
+    Failures  int             `xml:"failures,attr"`
+    Time      string          `xml:"time,attr"`
+    Timestamp string          `xml:"timestamp,attr"`
+    Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+    Name      string        `xml:"name,attr"`
+    ClassName string        `xml:"classname,attr"`
+    Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+    Message string `xml:"message,attr"`
+    Type    string `xml:"type,attr"`
+    Text    string `xml:",chardata"`
+}
+
+// exportJUnit writes a JUnit report with one test suite per target and one test case per
+// account tested on it, failing every case where a credential succeeded
+func (e *Engine) exportJUnit(filename string, accounts []ResultRecord, records []ResultRecord, started time.Time) (int, error) {
+    e.verbosePrintln("Exporting results to JUnit file:", filename)
+
+    findings := make(map[string][]Finding)
+    for _, f := range e.buildFindings(records) {
+        findings[f.Record.accountKey()] = append(findings[f.Record.accountKey()], f)
+    }
+
+    // Successes reported without an attempt of their own, such as a --verify-only list, still
+    // get their case
+    cases := append([]ResultRecord(nil), accounts...)
+    tested := make(map[string]bool)
+    for _, account := range accounts {
+        tested[account.accountKey()] = true
+    }
+    for _, r := range records {
+        if !tested[r.accountKey()] {
+            tested[r.accountKey()] = true
+            cases = append(cases, ResultRecord{Host: r.Host, Port: r.Port, User: r.User})
+        }
+    }
+    sort.SliceStable(cases, func(i, j int) bool {
+        if cases[i].Host != cases[j].Host {
+            return cases[i].Host < cases[j].Host
+        }
+        return cases[i].Port < cases[j].Port
+    })
+
+    report := junitTestSuites{}
+    failures := 0
+    var suite *junitTestSuite
+    for _, account := range cases {
+        if suite == nil || suite.Name != fmt.Sprintf("sqlblaster %s", account.hostKey()) {
+            report.Suites = append(report.Suites, junitTestSuite{
+                Name:      fmt.Sprintf("sqlblaster %s", account.hostKey()),
+                Timestamp: started.Format("2006-01-02T15:04:05"),
+                Time:      fmt.Sprintf("%.3f", time.Since(started).Seconds()),
+            })
+            suite = &report.Suites[len(report.Suites)-1]
+        }
+        tc := junitTestCase{
+            Name:      fmt.Sprintf("%s has no weak password", account.User),
+            ClassName: fmt.Sprintf("sqlblaster.%s", account.Host),
+        }
+        if accountFindings, ok := findings[account.accountKey()]; ok {
+            var text string
+            for _, f := range accountFindings {
+                text += fmt.Sprintf("[%s] %s\n", f.Rule.ID, f.Message)
+            }
+            tc.Failure = &junitFailure{
+                Message: accountFindings[0].Rule.Description,
+                Type:    accountFindings[0].Rule.Name,
+                Text:    text,
+            }
+            suite.Failures++
+            failures++
+        }
+        suite.Cases = append(suite.Cases, tc)
+        suite.Tests = len(suite.Cases)
+    }
+
+    file, err := os.Create(filename)
+    if err != nil {
+        return 0, err
+    }
+    defer file.Close()
+
+    file.WriteString(xml.Header)
+    encoder := xml.NewEncoder(file)
+    encoder.Indent("", "  ")
+    if err := encoder.Encode(report); err != nil {
+        return 0, err
+    }
+    file.WriteString("\n")
+
+    e.verbosePrintf("Exported %d test cases (%d failures) to %s\n", len(cases), failures, filename)
+    return failures, nil
+}
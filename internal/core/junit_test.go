@@ -0,0 +1,48 @@
+package core
+
+import (
+    "encoding/xml"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+// TestJUnitSuitePerTarget checks that the same username tested on two hosts gets a case in
+// each host's suite, failing only where its credential succeeded
+func TestJUnitSuitePerTarget(t *testing.T) {
+    opts := DefaultOptions()
+    opts.Host, opts.Port = "db1", 3306
+    e := NewEngine(&opts)
+    accounts := []ResultRecord{{Host: "db1", Port: 3306, User: "root"}, {Host: "db2", Port: 3307, User: "root"}}
+    records := []ResultRecord{{Host: "db2", Port: 3307, User: "root", Pass: "toor", Plugin: "mysql_native_password"}}
+
+    path := filepath.Join(t.TempDir(), "junit.xml")
+    failures, err := e.exportJUnit(path, accounts, records, time.Now())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if failures != 1 {
+        t.Errorf("got %d failures, want 1", failures)
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var report junitTestSuites
+    if err := xml.Unmarshal(data, &report); err != nil {
+        t.Fatal(err)
+    }
+    if len(report.Suites) != 2 {
+        t.Fatalf("got %d suites, want one per target:\n%s", len(report.Suites), data)
+    }
+    for i, want := range []struct {
+        name, class string
+        failures    int
+    }{{"sqlblaster db1:3306", "sqlblaster.db1", 0}, {"sqlblaster db2:3307", "sqlblaster.db2", 1}} {
+        suite := report.Suites[i]
+        if suite.Name != want.name || suite.Failures != want.failures || len(suite.Cases) != 1 || suite.Cases[0].ClassName != want.class {
+            t.Errorf("suite %d is %s with %d failures, want %s (%s) with %d:\n%s", i, suite.Name, suite.Failures, want.name, want.class, want.failures, data)
+        }
+    }
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "os"
+    "regexp"
+    "strings"
+)
+
+// ansiEscape matches the color codes success lines carry into log files written from a terminal
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// successLinePatterns match the success lines of every message catalog, capturing the user and,
+// for "with password", the password
+func successLinePatterns() []*regexp.Regexp {
+    var patterns []*regexp.Regexp
+    for _, catalog := range messages {
+        for _, key := range []string{"login.success_pass", "login.success_nopass"} {
+            parts := strings.Split(catalog[key], "%s")
+            for i := range parts {
+                parts[i] = regexp.QuoteMeta(parts[i])
+            }
+            patterns = append(patterns, regexp.MustCompile("^"+strings.Join(parts, "(.*)")+"$"))
+        }
+    }
+    return patterns
+}
+
+// addKnownCred records that user's password on host:port is known, when --skip-known is on
+func (e *Engine) addKnownCred(host string, port int, user, pass string) {
+    e.knownCredsMu.Lock()
+    defer e.knownCredsMu.Unlock()
+    if e.knownCreds == nil {
+        return
+    }
+    key := fmt.Sprintf("%s:%d", host, port)
+    if e.knownCreds[key] == nil {
+        e.knownCreds[key] = make(map[string]string)
+    }
+    e.knownCreds[key][user] = pass
+}
+
+// isKnownUser reports whether user was already cracked on host:port, by an earlier run or
+// earlier in this one
+func (e *Engine) isKnownUser(host string, port int, user string) bool {
+    e.knownCredsMu.Lock()
+    defer e.knownCredsMu.Unlock()
+    _, ok := e.knownCreds[fmt.Sprintf("%s:%d", host, port)][user]
+    return ok
+}
+
+// skipKnownUser reports whether an attempt for user on the current target should be skipped
+// because the account is already cracked, counting the skip
+func (e *Engine) skipKnownUser(user string) bool {
+    if !e.isKnownUser(e.opts.Host, e.opts.Port, user) {
+        return false
+    }
+    e.knownSkipped.Add(1)
+    return true
+}
+
+// loadKnownCreds collects the credentials confirmed by earlier runs from a results database and
+// a log file, either of which may be missing. The log's JSON records name their target; its
+// text success lines do not and are taken to be about the current -h target. Returns how many
+// accounts are known.
+func (e *Engine) loadKnownCreds(resultsPath, logPath string) (int, error) {
+    e.knownCredsMu.Lock()
+    e.knownCreds = make(map[string]map[string]string)
+    e.knownCredsMu.Unlock()
+
+    if resultsPath != "" && e.fileExists(resultsPath) {
+        records, err := e.loadResults(resultsPath)
+        if err != nil {
+            return 0, err
+        }
+        for _, r := range records {
+            e.addKnownCred(r.Host, r.Port, r.User, r.Pass)
+        }
+    }
+
+    if logPath != "" && e.fileExists(logPath) {
+        if err := e.scanLogForKnownCreds(logPath); err != nil {
+            return 0, err
+        }
+    }
+
+    e.knownCredsMu.Lock()
+    defer e.knownCredsMu.Unlock()
+    count := 0
+    for _, users := range e.knownCreds {
+        count += len(users)
+    }
+    return count, nil
+}
+
+// scanLogForKnownCreds adds the successes recorded in a log file
+func (e *Engine) scanLogForKnownCreds(logPath string) error {
+    file, err := os.Open(logPath)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    patterns := successLinePatterns()
+    scanner := bufio.NewScanner(file)
+    scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+    for scanner.Scan() {
+        line := strings.TrimSpace(ansiEscape.ReplaceAllString(scanner.Text(), ""))
+        if strings.HasPrefix(line, "{") {
+            var record struct {
+                Type    string `json:"type"`
+                Host    string `json:"host"`
+                Port    int    `json:"port"`
+                User    string `json:"user"`
+                Pass    string `json:"pass"`
+                Success bool   `json:"success"`
+            }
+            if json.Unmarshal([]byte(line), &record) == nil && record.Type == "attempt" && record.Success {
+                e.addKnownCred(record.Host, record.Port, record.User, record.Pass)
+            }
+            continue
+        }
+        if e.opts.Host == "" {
+            continue
+        }
+        for _, p := range patterns {
+            if m := p.FindStringSubmatch(line); m != nil {
+                pass := ""
+                if len(m) > 2 {
+                    pass = m[2]
+                }
+                e.addKnownCred(e.opts.Host, e.opts.Port, m[1], pass)
+                break
+            }
+        }
+    }
+    return scanner.Err()
+}
@@ -0,0 +1,219 @@
+package core
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// MySQL/MariaDB error numbers that indicate further attempts only worsen a lockout
+const (
+    errHostBlocked          = 1129 // ER_HOST_IS_BLOCKED
+    errAccountLocked        = 3118 // ER_ACCOUNT_HAS_BEEN_LOCKED
+    errAccountBlockedByLock = 3955 // ER_USER_ACCESS_DENIED_FOR_USER_ACCOUNT_BLOCKED_BY_PASSWORD_LOCK
+    errMariaDBAccountLocked = 4151 // ER_ACCOUNT_HAS_BEEN_LOCKED (MariaDB)
+)
+
+// hostBlockedGuidance explains how to recover from a max_connect_errors block
+const hostBlockedGuidance = `The server counts failed handshakes per client IP and refuses it after max_connect_errors.
+Continuing from the same source IP is pointless. To recover:
+  - Ask the DBA to run 'FLUSH HOSTS;' (or 'mysqladmin flush-hosts') or TRUNCATE performance_schema.host_cache
+  - Wait for the host cache entry to expire or the server to restart
+  - Provide more source IPs or proxies with --sources to fail over automatically
+  - Lower --workers to produce fewer handshake errors`
+
+// classifyLockout reports whether an error is a lockout and if it affects the whole host
+func classifyLockout(err error) (reason string, wholeHost bool) {
+    var myErr *mysql.MySQLError
+    if errors.As(err, &myErr) {
+        switch myErr.Number {
+        case errHostBlocked:
+            return "host blocked by max_connect_errors", true
+        case errAccountLocked, errMariaDBAccountLocked:
+            return "account locked", false
+        case errAccountBlockedByLock:
+            return "account blocked after failed logins", false
+        }
+    }
+
+    // Some proxies and older servers only return the message text
+    msg := strings.ToLower(err.Error())
+    switch {
+    case strings.Contains(msg, "is blocked because of many connection errors"):
+        return "host blocked by max_connect_errors", true
+    case strings.Contains(msg, "blocked due to too many authentication failures"):
+        return "host blocked by the proxy after failed logins", true
+    case strings.Contains(msg, "account is locked"), strings.Contains(msg, "account has been locked"):
+        return "account locked", false
+    case strings.Contains(msg, "account is blocked"):
+        return "account blocked after failed logins", false
+    }
+
+    return "", false
+}
+
+// noteLockout records a lockout-style error so the user (or host) is no longer tested.
+// source is the address the failed attempt originated from.
+func (e *Engine) noteLockout(user, source string, err error, log *os.File) {
+    reason, wholeHost := classifyLockout(err)
+    if reason == "" {
+        return
+    }
+
+    e.lockoutMu.Lock()
+    defer e.lockoutMu.Unlock()
+
+    var msg string
+    if wholeHost {
+        if e.hostBlocked != "" {
+            return
+        }
+        if !e.guidanceShown {
+            e.guidanceShown = true
+            e.printWarning("\n" + hostBlockedGuidance)
+        }
+
+        // Burn the source for this target and keep going from the next one if possible
+        if next, ok := e.failoverSource(source); ok {
+            if next == source {
+                return
+            }
+            msg = e.warningString("%s", e.tr("lockout.failover", e.opts.Host, e.opts.Port, source, next))
+            fmt.Fprintln(e.out, "\n"+msg)
+            if log != nil {
+                log.WriteString(msg + "\n")
+            }
+            return
+        }
+
+        if e.blockPause > 0 {
+            if time.Now().Before(e.pausedUntil) {
+                return
+            }
+            e.pausedUntil = time.Now().Add(e.blockPause)
+            e.fireErrorAlert("paused: "+reason, 1, 0, 0, log)
+            msg = e.warningString("%s", e.tr("lockout.pause", e.opts.Host, e.opts.Port, reason, e.pausedUntil.Format("15:04:05")))
+            fmt.Fprintln(e.out, "\n"+msg)
+            if log != nil {
+                log.WriteString(msg + "\n")
+            }
+            return
+        }
+
+        e.hostBlocked = reason
+        e.fireErrorAlert("circuit broken: "+reason, 1, 0, 0, log)
+        if source != "" {
+            msg = e.warningString("%s", e.tr("lockout.all_sources", e.opts.Host, e.opts.Port, reason))
+        } else {
+            msg = e.warningString("%s", e.tr("lockout.host", e.opts.Host, e.opts.Port, reason))
+        }
+    } else {
+        if _, ok := e.lockedUsers[user]; ok {
+            return
+        }
+        e.lockedUsers[user] = reason
+        msg = e.warningString("%s", e.tr("lockout.user", user, reason))
+    }
+
+    fmt.Fprintln(e.out, "\n"+msg)
+    if log != nil {
+        log.WriteString(msg + "\n")
+    }
+}
+
+// isLockedOut reports whether attempts for the user should be skipped
+func (e *Engine) isLockedOut(user string) bool {
+    e.lockoutMu.Lock()
+    defer e.lockoutMu.Unlock()
+    _, locked := e.lockedUsers[user]
+    return locked || e.hostBlocked != ""
+}
+
+// isHostBlocked reports whether the target has blocked this client
+func (e *Engine) isHostBlocked() bool {
+    e.lockoutMu.Lock()
+    defer e.lockoutMu.Unlock()
+    return e.hostBlocked != ""
+}
+
+// isBlockPaused reports whether --block-pause is waiting out a host block
+func (e *Engine) isBlockPaused() bool {
+    e.lockoutMu.Lock()
+    defer e.lockoutMu.Unlock()
+    return time.Now().Before(e.pausedUntil)
+}
+
+// waitForBlockPause blocks until a --block-pause pause ends. It reports whether the attempt that
+// ran into the block should be tried again: a pause was under way and the run was not cancelled.
+func (e *Engine) waitForBlockPause(ctx context.Context) bool {
+    e.lockoutMu.Lock()
+    until := e.pausedUntil
+    e.lockoutMu.Unlock()
+    if !time.Now().Before(until) {
+        return false
+    }
+    return sleepContext(ctx, time.Until(until)) == nil
+}
+
+// resetLockouts forgets the locked users, host block, burned sources and --max-attempts-per-user
+// counts before testing another target. A source one host blocked may still reach the next.
+func (e *Engine) resetLockouts() {
+    e.lockoutMu.Lock()
+    e.lockedUsers = make(map[string]string)
+    e.hostBlocked = ""
+    e.pausedUntil = time.Time{}
+    e.lockoutMu.Unlock()
+
+    e.sourceMu.Lock()
+    e.burnedSources = make(map[string]bool)
+    e.sourceIndex = 0
+    e.sourceMu.Unlock()
+
+    e.userAttemptsMu.Lock()
+    e.userAttempts = make(map[string]int)
+    e.userCapNoticed = make(map[string]bool)
+    e.userAttemptsMu.Unlock()
+}
+
+// reportLockouts prints the users and hosts flagged as locked out during the run
+func (e *Engine) reportLockouts(log *os.File) {
+    e.lockoutMu.Lock()
+    defer e.lockoutMu.Unlock()
+
+    e.sourceMu.Lock()
+    burned := len(e.burnedSources)
+    e.sourceMu.Unlock()
+    if len(e.lockedUsers) == 0 && e.hostBlocked == "" && burned == 0 {
+        return
+    }
+
+    var report strings.Builder
+    report.WriteString(e.tr("lockout.summary"))
+    if e.hostBlocked != "" {
+        report.WriteString(fmt.Sprintf("  %s:%d - %s\n", e.opts.Host, e.opts.Port, e.hostBlocked))
+    }
+    e.sourceMu.Lock()
+    for src := range e.burnedSources {
+        report.WriteString(e.tr("lockout.burned", src, e.opts.Host, e.opts.Port))
+    }
+    e.sourceMu.Unlock()
+    users := make([]string, 0, len(e.lockedUsers))
+    for user := range e.lockedUsers {
+        users = append(users, user)
+    }
+    sort.Strings(users)
+    for _, user := range users {
+        report.WriteString(fmt.Sprintf("  %s - %s\n", user, e.lockedUsers[user]))
+    }
+
+    e.printWarning(report.String())
+    if log != nil {
+        log.WriteString(report.String())
+    }
+}
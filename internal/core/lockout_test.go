@@ -0,0 +1,54 @@
+package core
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// TestReplayResetsBurnedSources checks that a source burned by one --cred-csv target is tried
+// again against the next target instead of leaving it with no source to dial from
+func TestReplayResetsBurnedSources(t *testing.T) {
+    blocked, err := newFakeServer(selftestVersion)
+    if err != nil {
+        t.Fatalf("starting fake server: %v", err)
+    }
+    defer blocked.close()
+    blocked.setBlocked(true)
+    open, err := newFakeServer(selftestVersion)
+    if err != nil {
+        t.Fatalf("starting fake server: %v", err)
+    }
+    defer open.close()
+    open.addUser("root", "toor")
+
+    dir := t.TempDir()
+    credFile := filepath.Join(dir, "creds.csv")
+    var rows string
+    for _, s := range []*fakeServer{blocked, open} {
+        rows += fmt.Sprintf("127.0.0.1,%s,root,toor\n", s.addr()[len("127.0.0.1:"):])
+    }
+    if err := os.WriteFile(credFile, []byte(rows), 0600); err != nil {
+        t.Fatal(err)
+    }
+
+    opts := DefaultOptions()
+    opts.Host = "127.0.0.1"
+    opts.Sources = "127.0.0.1"
+    opts.SkipSSL = true
+    opts.Retries = 0
+    e, err := beginRun(opts, nil)
+    if err != nil {
+        t.Fatalf("setting up run: %v", err)
+    }
+    e.runCredCSV(context.Background(), credFile, "", nil)
+
+    if open.loginCount("root") == 0 {
+        t.Fatal("the second target was never tried after the first burned the only source")
+    }
+    if found := len(e.getSuccesses()); found != 1 {
+        t.Errorf("%d credential(s) found, want 1 on the second target", found)
+    }
+}
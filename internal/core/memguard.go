@@ -0,0 +1,152 @@
+package core
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+    "runtime"
+    "runtime/debug"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// memoryPollInterval is how often heap use is checked against --max-memory
+const memoryPollInterval = time.Second
+
+// parseByteSize converts sizes like "512MB", "2GB" or "1048576" into bytes
+func parseByteSize(size string) (uint64, error) {
+    s := strings.ToUpper(strings.TrimSpace(size))
+    units := []struct {
+        suffix string
+        mult   uint64
+    }{
+        {"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+        {"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+    }
+    mult := uint64(1)
+    for _, u := range units {
+        if strings.HasSuffix(s, u.suffix) {
+            s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+            mult = u.mult
+            break
+        }
+    }
+    n, err := strconv.ParseFloat(s, 64)
+    if err != nil || n <= 0 {
+        return 0, fmt.Errorf("invalid size '%s' (use e.g. 512MB or 2GB)", size)
+    }
+    return uint64(n * float64(mult)), nil
+}
+
+// startMemoryWatchdog polls heap use and flags when it exceeds limit, so result formatting and
+// dumps switch to spooling through temporary files instead of growing in memory
+func (e *Engine) startMemoryWatchdog(ctx context.Context, limit uint64) {
+    // Also ask the runtime to collect harder as the limit approaches
+    debug.SetMemoryLimit(int64(limit))
+
+    go func() {
+        ticker := time.NewTicker(memoryPollInterval)
+        defer ticker.Stop()
+
+        var stats runtime.MemStats
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+            }
+
+            runtime.ReadMemStats(&stats)
+            over := stats.HeapAlloc > limit
+            if over && !e.memoryExceeded.Load() {
+                e.printWarning("\nMemory: heap use %s exceeds --max-memory %s, spooling results to temporary files",
+                    formatByteSize(stats.HeapAlloc), formatByteSize(limit))
+                debug.FreeOSMemory()
+            } else if !over && e.memoryExceeded.Load() {
+                e.verbosePrintln("Memory: heap use back below limit:", formatByteSize(stats.HeapAlloc))
+            }
+            e.memoryExceeded.Store(over)
+        }
+    }()
+}
+
+// overMemoryLimit reports whether heap use is currently above --max-memory
+func (e *Engine) overMemoryLimit() bool {
+    return e.memoryExceeded.Load()
+}
+
+// formatByteSize formats a byte count for display, e.g. "1.5GB"
+func formatByteSize(n uint64) string {
+    switch {
+    case n >= 1<<30:
+        return fmt.Sprintf("%.1fGB", float64(n)/(1<<30))
+    case n >= 1<<20:
+        return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+    case n >= 1<<10:
+        return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+    }
+    return fmt.Sprintf("%dB", n)
+}
+
+// spoolBuffer collects output in memory until the memory limit is exceeded, then moves it to
+// a file under the target's loot directory and keeps writing there
+type spoolBuffer struct {
+    engine *Engine
+    mem    strings.Builder
+    file   *os.File
+}
+
+// createSpoolFile creates a file only the current user can read for results that exceeded
+// --max-memory. Query results hold whatever the target returned, so they are kept with the
+// target's loot rather than in the shared temporary directory.
+func (e *Engine) createSpoolFile() (*os.File, error) {
+    target := sanitizeFilename(fmt.Sprintf("%s_%d", e.opts.Host, e.opts.Port))
+    dir := filepath.Join(e.opts.LootDir, target, "results")
+    if err := e.makeRunDir(dir, 0700); err != nil {
+        return nil, err
+    }
+    // CreateTemp opens the file with mode 0600
+    file, err := os.CreateTemp(dir, "results-*.txt")
+    if err != nil {
+        return nil, err
+    }
+    e.noteCreated(file.Name())
+    e.spoolMu.Lock()
+    e.spoolFiles = append(e.spoolFiles, file.Name())
+    e.spoolMu.Unlock()
+    return file, nil
+}
+
+// WriteString appends s, spilling to a file once the memory limit is exceeded
+func (b *spoolBuffer) WriteString(s string) (int, error) {
+    if b.file == nil && b.engine.overMemoryLimit() {
+        file, err := b.engine.createSpoolFile()
+        if err != nil {
+            b.engine.verbosePrintln("Failed to create spool file, keeping results in memory:", err)
+        } else {
+            b.engine.verbosePrintln("Spooling results to", file.Name())
+            file.WriteString(b.mem.String())
+            b.mem.Reset()
+            b.file = file
+        }
+    }
+    if b.file != nil {
+        return b.file.WriteString(s)
+    }
+    return b.mem.WriteString(s)
+}
+
+// String returns the collected output, or where to find it if it was spooled to disk
+func (b *spoolBuffer) String() string {
+    if b.file == nil {
+        return b.mem.String()
+    }
+    name := b.file.Name()
+    b.file.Close()
+    b.file = nil
+    b.mem.Reset()
+    b.mem.WriteString(fmt.Sprintf("Results exceeded --max-memory and were written to %s\n", name))
+    return b.mem.String()
+}
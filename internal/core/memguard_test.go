@@ -0,0 +1,45 @@
+package core
+
+import (
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// TestSpoolFileKeptWithLoot checks that results spooled past --max-memory go to a private file
+// under the loot directory that the run lists among its artifacts
+func TestSpoolFileKeptWithLoot(t *testing.T) {
+    opts := defaultOptions()
+    opts.Host, opts.Port = "db1", 3306
+    opts.LootDir = filepath.Join(t.TempDir(), "loot")
+    e := NewEngine(&opts)
+    e.memoryExceeded.Store(true)
+
+    out := spoolBuffer{engine: e}
+    out.WriteString("secret row\n")
+    if out.file == nil {
+        t.Fatal("results were not spooled")
+    }
+    name := out.file.Name()
+    info, err := out.file.Stat()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if msg := out.String(); !strings.Contains(msg, name) {
+        t.Errorf("the output does not say where the results went: %q", msg)
+    }
+
+    if dir := filepath.Join(opts.LootDir, "db1_3306", "results"); filepath.Dir(name) != dir {
+        t.Errorf("spool file %s is not under %s", name, dir)
+    }
+    if mode := info.Mode().Perm(); mode != 0600 {
+        t.Errorf("spool file mode is %o, want 600", mode)
+    }
+    listed := false
+    for _, a := range e.summaryArtifacts() {
+        listed = listed || a[1] == name
+    }
+    if !listed {
+        t.Errorf("spool file %s is not among the run's artifacts", name)
+    }
+}
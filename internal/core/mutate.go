@@ -0,0 +1,443 @@
+package core
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// mutationYears is how many years back from the current one the built-in rules append
+const mutationYears = 5
+
+// mutator generates password candidates from a base word with the built-in rules and/or hashcat-style rules
+type mutator struct {
+    engine  *Engine
+    builtin bool
+    company string
+    years   []string
+    rules   []rule
+}
+
+// ruleOp is one hashcat rule function with its parsed arguments
+type ruleOp struct {
+    fn   byte
+    args []byte
+}
+
+// rule is a line of a rules file: functions applied left to right
+type rule []ruleOp
+
+// ruleArgs gives the number of arguments each supported rule function takes
+var ruleArgs = map[byte]int{
+    ':': 0, 'l': 0, 'u': 0, 'c': 0, 'C': 0, 't': 0, 'r': 0, 'd': 0, 'f': 0, '{': 0, '}': 0,
+    '[': 0, ']': 0, 'q': 0, 'k': 0, 'K': 0, 'E': 0,
+    '$': 1, '^': 1, '@': 1, '!': 1, '/': 1, 'T': 1, 'p': 1, 'D': 1, '\'': 1, 'z': 1, 'Z': 1,
+    '<': 1, '>': 1, '_': 1, '+': 1, '-': 1, '.': 1, ',': 1, 'y': 1, 'Y': 1, 'L': 1, 'R': 1,
+    's': 2, 'x': 2, 'O': 2, 'i': 2, 'o': 2, '*': 2,
+}
+
+// leetMap holds the substitutions of the built-in leetspeak rule
+var leetMap = strings.NewReplacer("a", "@", "A", "@", "e", "3", "E", "3", "i", "1", "I", "1", "o", "0", "O", "0", "s", "$", "S", "$")
+
+// setupMutator builds the password mutator from --mutate, --company and --rules
+func (e *Engine) setupMutator(builtin bool, company, rulesFile string) error {
+    if !builtin && rulesFile == "" {
+        return nil
+    }
+
+    m := &mutator{engine: e, builtin: builtin, company: company}
+    year := time.Now().Year()
+    for y := year + 1; y >= year-mutationYears; y-- {
+        m.years = append(m.years, strconv.Itoa(y), strconv.Itoa(y%100))
+    }
+    if rulesFile != "" {
+        rules, err := loadRules(rulesFile)
+        if err != nil {
+            return err
+        }
+        m.rules = rules
+        e.verbosePrintf("Loaded %d rules from %s\n", len(rules), rulesFile)
+    }
+    e.passMutator = m
+    return nil
+}
+
+// loadRules parses a hashcat-style rules file, skipping blank lines and # comments
+func loadRules(filename string) ([]rule, error) {
+    file, err := os.Open(filename)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    var rules []rule
+    lineNum := 0
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        lineNum++
+        line := scanner.Text()
+        if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        r, err := parseRule(line)
+        if err != nil {
+            return nil, fmt.Errorf("%s:%d: %v", filename, lineNum, err)
+        }
+        rules = append(rules, r)
+    }
+    return rules, scanner.Err()
+}
+
+// parseRule parses one rule line. Spaces between functions are ignored, but not as arguments.
+func parseRule(line string) (rule, error) {
+    var r rule
+    for i := 0; i < len(line); i++ {
+        fn := line[i]
+        if fn == ' ' || fn == '\t' {
+            continue
+        }
+        n, ok := ruleArgs[fn]
+        if !ok {
+            return nil, fmt.Errorf("unsupported rule function '%c'", fn)
+        }
+        if i+n >= len(line) {
+            return nil, fmt.Errorf("rule function '%c' needs %d argument(s)", fn, n)
+        }
+        r = append(r, ruleOp{fn: fn, args: []byte(line[i+1 : i+1+n])})
+        i += n
+    }
+    return r, nil
+}
+
+// rulePos converts a rule position argument (0-9, A-Z) to an index
+func rulePos(c byte) int {
+    switch {
+    case c >= '0' && c <= '9':
+        return int(c - '0')
+    case c >= 'A' && c <= 'Z':
+        return int(c-'A') + 10
+    }
+    return -1
+}
+
+// toggle flips the case of an ASCII letter
+func toggle(c byte) byte {
+    switch {
+    case c >= 'a' && c <= 'z':
+        return c - 32
+    case c >= 'A' && c <= 'Z':
+        return c + 32
+    }
+    return c
+}
+
+// apply runs the rule on word, reporting false when a rejection function rejects it
+func (r rule) apply(word string) (string, bool) {
+    w := []byte(word)
+    for _, op := range r {
+        var a, b byte
+        if len(op.args) > 0 {
+            a = op.args[0]
+        }
+        if len(op.args) > 1 {
+            b = op.args[1]
+        }
+        n := rulePos(a)
+
+        switch op.fn {
+        case ':':
+        case 'l':
+            w = bytes.ToLower(w)
+        case 'u':
+            w = bytes.ToUpper(w)
+        case 'c':
+            w = bytes.ToLower(w)
+            if len(w) > 0 {
+                w[0] = bytes.ToUpper(w[:1])[0]
+            }
+        case 'C':
+            w = bytes.ToUpper(w)
+            if len(w) > 0 {
+                w[0] = bytes.ToLower(w[:1])[0]
+            }
+        case 't':
+            for i := range w {
+                w[i] = toggle(w[i])
+            }
+        case 'E':
+            w = bytes.ToLower(w)
+            for i := range w {
+                if i == 0 || w[i-1] == ' ' {
+                    w[i] = toggle(w[i])
+                }
+            }
+        case 'r':
+            for i, j := 0, len(w)-1; i < j; i, j = i+1, j-1 {
+                w[i], w[j] = w[j], w[i]
+            }
+        case 'd':
+            w = append(w, w...)
+        case 'f':
+            rev := make([]byte, len(w))
+            for i := range w {
+                rev[len(w)-1-i] = w[i]
+            }
+            w = append(w, rev...)
+        case 'q':
+            dup := make([]byte, 0, len(w)*2)
+            for _, c := range w {
+                dup = append(dup, c, c)
+            }
+            w = dup
+        case '{':
+            if len(w) > 1 {
+                w = append(w[1:], w[0])
+            }
+        case '}':
+            if len(w) > 1 {
+                w = append([]byte{w[len(w)-1]}, w[:len(w)-1]...)
+            }
+        case '[':
+            if len(w) > 0 {
+                w = w[1:]
+            }
+        case ']':
+            if len(w) > 0 {
+                w = w[:len(w)-1]
+            }
+        case 'k':
+            if len(w) > 1 {
+                w[0], w[1] = w[1], w[0]
+            }
+        case 'K':
+            if len(w) > 1 {
+                w[len(w)-1], w[len(w)-2] = w[len(w)-2], w[len(w)-1]
+            }
+        case '$':
+            w = append(w, a)
+        case '^':
+            w = append([]byte{a}, w...)
+        case '@':
+            w = bytes.ReplaceAll(w, []byte{a}, nil)
+        case 's':
+            w = bytes.ReplaceAll(w, []byte{a}, []byte{b})
+        case 'T':
+            if n >= 0 && n < len(w) {
+                w[n] = toggle(w[n])
+            }
+        case 'p':
+            orig := append([]byte{}, w...)
+            for i := 0; i < n; i++ {
+                w = append(w, orig...)
+            }
+        case 'D':
+            if n >= 0 && n < len(w) {
+                w = append(w[:n], w[n+1:]...)
+            }
+        case '\'':
+            if n >= 0 && n < len(w) {
+                w = w[:n]
+            }
+        case 'z':
+            if n > 0 && len(w) > 0 {
+                w = append(bytes.Repeat(w[:1], n), w...)
+            }
+        case 'Z':
+            if n > 0 && len(w) > 0 {
+                w = append(w, bytes.Repeat(w[len(w)-1:], n)...)
+            }
+        case '+':
+            if n >= 0 && n < len(w) {
+                w[n]++
+            }
+        case '-':
+            if n >= 0 && n < len(w) {
+                w[n]--
+            }
+        case 'L':
+            if n >= 0 && n < len(w) {
+                w[n] <<= 1
+            }
+        case 'R':
+            if n >= 0 && n < len(w) {
+                w[n] >>= 1
+            }
+        case '.':
+            if n >= 0 && n+1 < len(w) {
+                w[n] = w[n+1]
+            }
+        case ',':
+            if n > 0 && n < len(w) {
+                w[n] = w[n-1]
+            }
+        case 'y':
+            if n > 0 && n <= len(w) {
+                w = append(append([]byte{}, w[:n]...), w...)
+            }
+        case 'Y':
+            if n > 0 && n <= len(w) {
+                w = append(w, append([]byte{}, w[len(w)-n:]...)...)
+            }
+        case 'x':
+            m := rulePos(b)
+            if n >= 0 && m >= 0 && n < len(w) {
+                end := n + m
+                if end > len(w) {
+                    end = len(w)
+                }
+                w = append([]byte{}, w[n:end]...)
+            }
+        case 'O':
+            m := rulePos(b)
+            if n >= 0 && m >= 0 && n < len(w) {
+                end := n + m
+                if end > len(w) {
+                    end = len(w)
+                }
+                w = append(w[:n], w[end:]...)
+            }
+        case 'i':
+            if n >= 0 && n <= len(w) {
+                w = append(w[:n], append([]byte{b}, w[n:]...)...)
+            }
+        case 'o':
+            if n >= 0 && n < len(w) {
+                w[n] = b
+            }
+        case '*':
+            m := rulePos(b)
+            if n >= 0 && m >= 0 && n < len(w) && m < len(w) {
+                w[n], w[m] = w[m], w[n]
+            }
+        case '<':
+            if len(w) >= n {
+                return "", false
+            }
+        case '>':
+            if len(w) <= n {
+                return "", false
+            }
+        case '_':
+            if len(w) != n {
+                return "", false
+            }
+        case '!':
+            if bytes.IndexByte(w, a) >= 0 {
+                return "", false
+            }
+        case '/':
+            if bytes.IndexByte(w, a) < 0 {
+                return "", false
+            }
+        }
+    }
+    return string(w), true
+}
+
+// capitalize uppercases the first letter and lowercases the rest
+func capitalize(s string) string {
+    if s == "" {
+        return s
+    }
+    return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+}
+
+// mutate returns the unique candidates for a base password, in generation order
+func (m *mutator) mutate(base string) []string {
+    seen := make(map[string]bool)
+    var out []string
+    add := func(s string) {
+        if s != "" && !seen[s] {
+            seen[s] = true
+            out = append(out, s)
+        }
+    }
+
+    if m.builtin {
+        // Capitalization and leetspeak variants
+        words := []string{base, capitalize(base), strings.ToLower(base), strings.ToUpper(base)}
+        words = append(words, leetMap.Replace(base), leetMap.Replace(capitalize(base)))
+        for _, w := range words {
+            add(w)
+        }
+
+        // Years and common suffixes on the plain and capitalized word
+        for _, w := range []string{base, capitalize(base)} {
+            for _, y := range m.years {
+                add(w + y)
+                add(w + y + "!")
+                add(w + "@" + y)
+            }
+            for _, suffix := range []string{"!", "1", "12", "123", "1234", "@123", "#1"} {
+                add(w + suffix)
+            }
+        }
+
+        // Company-name combinations
+        if m.company != "" {
+            for _, c := range []string{m.company, strings.ToLower(m.company), capitalize(m.company)} {
+                for _, w := range []string{base, capitalize(base)} {
+                    add(w + c)
+                    add(w + "@" + c)
+                    add(w + "_" + c)
+                    add(c + w)
+                }
+                for _, y := range m.years {
+                    add(c + y)
+                    add(c + "@" + y)
+                }
+            }
+        }
+    }
+
+    for _, r := range m.rules {
+        if s, ok := r.apply(base); ok {
+            add(s)
+        }
+    }
+    return out
+}
+
+// stream expands every base password read from in into its candidates
+func (m *mutator) stream(ctx context.Context, in <-chan string) <-chan string {
+    ch := make(chan string)
+    go func() {
+        defer close(ch)
+        for base := range in {
+            for _, candidate := range m.mutate(base) {
+                select {
+                case ch <- candidate:
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }
+    }()
+    return ch
+}
+
+// countFile counts the candidates the base passwords in filename expand to
+func (m *mutator) countFile(filename string) int {
+    m.engine.verbosePrintf("Counting mutated candidates for %s... ", filename)
+    file, err := os.Open(filename)
+    if err != nil {
+        m.engine.verbosePrintln("error:", err)
+        return 0
+    }
+    defer file.Close()
+
+    count := 0
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        if line := strings.TrimSpace(scanner.Text()); line != "" {
+            count += len(m.mutate(line))
+        }
+    }
+    m.engine.verbosePrintln("found", count, "candidates")
+    return count
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+    "database/sql"
+    "fmt"
+    "strings"
+    "time"
+)
+
+// targetNote is a free-text note on a target, or on one of its accounts when User is set
+type targetNote struct {
+    Host    string
+    Port    int
+    User    string
+    Text    string
+    AddedAt string
+}
+
+// addNote keeps a note for the end-of-run reports and stores it in the results database if one
+// is open. An empty user attaches the note to the whole target.
+func (e *Engine) addNote(host string, port int, user, text string) {
+    note := targetNote{
+        Host:    host,
+        Port:    port,
+        User:    user,
+        Text:    text,
+        AddedAt: time.Now().Format(time.RFC3339),
+    }
+
+    e.successesMu.Lock()
+    e.notes = append(e.notes, note)
+    e.successesMu.Unlock()
+    e.emitRecord("note", map[string]interface{}{"host": host, "port": port, "user": user, "note": text})
+
+    if e.resultsDB == nil {
+        return
+    }
+    _, err := e.resultsDB.Exec("INSERT INTO notes (host, port, user, note, added_at) VALUES (?, ?, ?, ?, ?)",
+        note.Host, note.Port, note.User, note.Text, note.AddedAt)
+    if err != nil {
+        e.printError("Error recording note: %v", err)
+    }
+}
+
+// queryNotes reads every note from a results database
+func queryNotes(db *sql.DB) ([]targetNote, error) {
+    rows, err := db.Query("SELECT host, port, user, note, added_at FROM notes ORDER BY added_at")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var list []targetNote
+    for rows.Next() {
+        var n targetNote
+        if err := rows.Scan(&n.Host, &n.Port, &n.User, &n.Text, &n.AddedAt); err != nil {
+            return nil, err
+        }
+        list = append(list, n)
+    }
+    return list, rows.Err()
+}
+
+// loadNotes reads every note from a results database file
+func loadNotes(filename string) ([]targetNote, error) {
+    db, err := openResultsDB(filename)
+    if err != nil {
+        return nil, err
+    }
+    defer db.Close()
+    return queryNotes(db)
+}
+
+// attachNotes fills in the notes of each record: those on its account and those on its target
+func attachNotes(records []ResultRecord, list []targetNote) {
+    for i := range records {
+        for _, n := range list {
+            if n.Host == records[i].Host && n.Port == records[i].Port && (n.User == "" || n.User == records[i].User) {
+                records[i].Notes = append(records[i].Notes, n.Text)
+            }
+        }
+    }
+}
+
+// subject names what the note is attached to
+func (n targetNote) subject() string {
+    if n.User == "" {
+        return fmt.Sprintf("%s:%d", n.Host, n.Port)
+    }
+    return fmt.Sprintf("%s@%s:%d", n.User, n.Host, n.Port)
+}
+
+// notesSuffix formats a record's notes for appending to a finding message
+func notesSuffix(r ResultRecord) string {
+    if len(r.Notes) == 0 {
+        return ""
+    }
+    return " [notes: " + strings.Join(r.Notes, "; ") + "]"
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+    "encoding/binary"
+    "fmt"
+    "os"
+)
+
+// oldRandMax is the modulus of the random generator behind the pre-4.1 scramble
+const oldRandMax = 0x3FFFFFFF
+
+// hashOldPassword is MySQL 3.23's 64-bit password hash, which skips spaces and tabs
+func hashOldPassword(password []byte) [2]uint32 {
+    nr, nr2, add := uint32(1345345333), uint32(0x12345671), uint32(7)
+    for _, c := range password {
+        if c == ' ' || c == '\t' {
+            continue
+        }
+        nr ^= ((nr&63)+add)*uint32(c) + nr<<8
+        nr2 += nr2<<8 ^ nr
+        add += uint32(c)
+    }
+    return [2]uint32{nr & (1<<31 - 1), nr2 & (1<<31 - 1)}
+}
+
+// scrambleOldPassword computes the 8-byte pre-4.1 auth response to the first 8 bytes of the
+// scramble. A blank password sends no response at all.
+func scrambleOldPassword(scramble []byte, pass string) []byte {
+    if pass == "" {
+        return nil
+    }
+    if len(scramble) > 8 {
+        scramble = scramble[:8]
+    }
+    hashPass := hashOldPassword([]byte(pass))
+    hashScramble := hashOldPassword(scramble)
+    seed1 := (hashPass[0] ^ hashScramble[0]) % oldRandMax
+    seed2 := (hashPass[1] ^ hashScramble[1]) % oldRandMax
+    next := func() byte {
+        seed1 = (seed1*3 + seed2) % oldRandMax
+        seed2 = (seed1 + seed2 + 33) % oldRandMax
+        return byte(uint64(seed1) * 31 / oldRandMax)
+    }
+
+    result := make([]byte, 8)
+    for i := range result {
+        result[i] = next() + 64
+    }
+    extra := next()
+    for i := range result {
+        result[i] ^= extra
+    }
+    return result
+}
+
+// legacyAuth tells from the greeting how old the server's authentication is. oldProtocol means
+// it cannot speak protocol 4.1 at all (MySQL 4.0 and older), which the driver does not support.
+// oldPasswords means it predates auth plugins, so accounts may still hold 16-byte pre-4.1 hashes.
+func (fp *serverFingerprint) legacyAuth() (oldProtocol, oldPasswords bool) {
+    oldProtocol = fp.Capabilities&clientProtocol41 == 0 || fp.Capabilities&clientSecureConn == 0
+    return oldProtocol, oldProtocol || fp.Capabilities&clientPluginAuth == 0
+}
+
+// oldAuthDSNParams lets the driver answer with the pre-4.1 scramble when the fingerprint shows a
+// server old enough to ask for it
+func (e *Engine) oldAuthDSNParams() string {
+    if !e.oldPasswords {
+        return ""
+    }
+    return "&allowOldPasswords=true&allowNativePasswords=true"
+}
+
+// handshake320 logs in to a server without protocol 4.1 using the pre-4.1 handshake response
+// and scramble. TLS is not attempted on these servers.
+func (c *authConn) handshake320(user, pass string) error {
+    if c.engine.opts.UseSSL {
+        return fmt.Errorf("%w: server does not support TLS", errBatchFallback)
+    }
+    c.plugin = "mysql_old_password"
+    c.caps = clientLongPassword | clientTransactions
+
+    packet := make([]byte, 5, 5+len(user)+10)
+    binary.LittleEndian.PutUint16(packet, uint16(c.caps))
+    packet[2], packet[3], packet[4] = 0xff, 0xff, 0xff // max packet size
+    packet = append(packet, user...)
+    packet = append(packet, 0)
+    packet = append(packet, scrambleOldPassword(c.scramble, pass)...)
+    packet = append(packet, 0)
+    if err := c.writePacket(packet); err != nil {
+        return err
+    }
+    return c.readAuthResult(pass)
+}
+
+// legacyLogin records a login to a pre-4.1 protocol server. The driver cannot open sessions on
+// these servers, so nothing runs after the login.
+func (e *Engine) legacyLogin(user, pass string, log *os.File) string {
+    e.recordSuccess(user, pass, backendMySQL, accountInfo{Plugin: "mysql_old_password"})
+    e.emit(Event{Type: EventSuccess, User: user, Pass: pass, Backend: backendMySQL, Plugin: "mysql_old_password"})
+
+    if e.opts.Verbose {
+        fmt.Fprintln(e.out) // Newline after "Testing..." message
+    }
+    var msg string
+    if pass != "" {
+        msg = e.successString("%s", e.tr("login.success_pass", user, pass))
+    } else {
+        msg = e.successString("%s", e.tr("login.success_nopass", user))
+    }
+    if log != nil {
+        log.WriteString(fmt.Sprintf("Login as %s verified with pre-4.1 authentication\n", user))
+    }
+    return msg + "\n" + e.warningString("Server speaks the pre-4.1 protocol: commands, enumeration, dumps and interactive mode are not available")
+}
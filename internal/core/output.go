@@ -0,0 +1,75 @@
+package core
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "strings"
+    "time"
+)
+
+// wantsJSONOutput checks the raw arguments for --output json, before flags are parsed, so the
+// banner can be kept off stdout
+func wantsJSONOutput(args []string) bool {
+    for i, arg := range args {
+        name := strings.TrimLeft(arg, "-")
+        if name == "output=json" {
+            return true
+        }
+        if name == "output" && arg != name && i+1 < len(args) && args[i+1] == "json" {
+            return true
+        }
+    }
+    return false
+}
+
+// setupOutput selects the run's output mode. In json mode stdout carries only JSON records: the
+// run's human output moves to stderr without colors, and the log file receives the JSON records
+// instead of text.
+func (e *Engine) setupOutput(mode string, logFile **os.File) error {
+    switch mode {
+    case "", "text":
+        return nil
+    case "json":
+    default:
+        return fmt.Errorf("unknown output mode '%s' (use text or json)", mode)
+    }
+
+    e.jsonOutput = true
+    e.jsonOut = os.Stdout
+    e.out = os.Stderr
+    e.theme.disable()
+    e.jsonLog = *logFile
+    *logFile = nil
+    return nil
+}
+
+// emitRecord writes one JSON Lines record of the given type when --output json is set
+func (e *Engine) emitRecord(kind string, fields map[string]interface{}) {
+    if !e.jsonOutput {
+        return
+    }
+
+    record := map[string]interface{}{
+        "type": kind,
+        "time": time.Now().UTC().Format(time.RFC3339),
+        "host": e.opts.Host,
+        "port": e.opts.Port,
+    }
+    for k, v := range fields {
+        record[k] = v
+    }
+    data, err := json.Marshal(record)
+    if err != nil {
+        e.verbosePrintln("Failed to encode JSON record:", err)
+        return
+    }
+    data = append(data, '\n')
+
+    e.jsonMu.Lock()
+    defer e.jsonMu.Unlock()
+    e.jsonOut.Write(data)
+    if e.jsonLog != nil {
+        e.jsonLog.Write(data)
+    }
+}
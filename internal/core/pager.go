@@ -0,0 +1,101 @@
+package core
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "os/exec"
+    "strings"
+)
+
+// interruptForeground cancels the running interactive statement and reports whether there was one
+func (e *Engine) interruptForeground() bool {
+    cancel := e.foregroundQuery.Load()
+    if cancel == nil {
+        return false
+    }
+    (*cancel)()
+    return true
+}
+
+// foregroundContext returns a --query-timeout context for an interactive statement that Ctrl-C
+// cancels without leaving interactive mode
+func (e *Engine) foregroundContext(ctx context.Context) (context.Context, context.CancelFunc) {
+    queryCtx, cancel := e.withQueryTimeout(ctx)
+    e.foregroundQuery.Store(&cancel)
+    return queryCtx, func() {
+        e.foregroundQuery.Store(nil)
+        cancel()
+    }
+}
+
+// rowLimitGuard asks once, before row --row-limit+1 is fetched, whether to read the rest
+func (e *Engine) rowLimitGuard(line lineEditor) func(fetched int) bool {
+    asked := false
+    return func(fetched int) bool {
+        if asked || e.opts.RowLimit <= 0 || fetched < e.opts.RowLimit {
+            return true
+        }
+        asked = true
+        e.printWarning("%d rows fetched and the result has more (--row-limit %d)", fetched, e.opts.RowLimit)
+        return askYesNo(line, "Fetch the rest? [y/N] ")
+    }
+}
+
+// page shows output one screen at a time: through the --pager command when set, otherwise
+// with the built-in pager when it is taller than the terminal
+func (e *Engine) page(line lineEditor, output string) {
+    switch e.opts.Pager {
+    case "off":
+        fmt.Fprintln(e.out, output)
+        return
+    case "":
+    default:
+        if err := e.runPager(e.opts.Pager, output); err != nil {
+            e.printWarning("Pager %q failed, printing directly: %v", e.opts.Pager, err)
+            fmt.Fprintln(e.out, output)
+        }
+        return
+    }
+
+    height := int(e.termHeight.Load())
+    lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+    if height <= 1 || len(lines) < height {
+        fmt.Fprintln(e.out, output)
+        return
+    }
+
+    // Leave the bottom line for the prompt
+    screen := height - 1
+    for start := 0; start < len(lines); start += screen {
+        end := start + screen
+        if end > len(lines) {
+            end = len(lines)
+        }
+        fmt.Fprintln(e.out, strings.Join(lines[start:end], "\n"))
+        if end == len(lines) {
+            return
+        }
+        prompt := fmt.Sprintf("-- More (%d%%) -- Enter: next page, q: quit ", end*100/len(lines))
+        answer, err := line.Prompt(prompt)
+        if err != nil || strings.EqualFold(strings.TrimSpace(answer), "q") {
+            // Ctrl-C at the prompt also stops paging
+            return
+        }
+    }
+}
+
+// runPager feeds output to an external pager command such as "less -S"
+func (e *Engine) runPager(command, output string) error {
+    // The pager handles Ctrl-C itself; it must not shut sqlblaster down
+    ignore := context.CancelFunc(func() {})
+    e.foregroundQuery.Store(&ignore)
+    defer e.foregroundQuery.Store(nil)
+
+    args := strings.Fields(command)
+    cmd := exec.Command(args[0], args[1:]...)
+    cmd.Stdin = strings.NewReader(output)
+    cmd.Stdout = e.out
+    cmd.Stderr = os.Stderr
+    return cmd.Run()
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+    "bufio"
+    "fmt"
+    "math"
+    "os"
+    "strings"
+    "unicode"
+)
+
+// builtinCommonPasswords are the most frequent entries of public top-10k password lists, used
+// when no --common-passwords file is given. An installed data bundle replaces them.
+var builtinCommonPasswords = []string{
+    "123456", "password", "12345678", "qwerty", "123456789", "12345", "1234", "111111", "1234567",
+    "dragon", "123123", "baseball", "abc123", "football", "monkey", "letmein", "696969", "shadow",
+    "master", "666666", "qwertyuiop", "123321", "mustang", "1234567890", "michael", "654321",
+    "superman", "1qaz2wsx", "7777777", "121212", "000000", "qazwsx", "123qwe", "killer", "trustno1",
+    "jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter", "buster", "soccer", "harley", "batman",
+    "andrew", "tigger", "sunshine", "iloveyou", "2000", "charlie", "robert", "thomas", "hockey",
+    "ranger", "daniel", "starwars", "112233", "george", "computer", "michelle", "jessica",
+    "pepper", "1111", "zxcvbn", "555555", "11111111", "131313", "freedom", "777777", "pass",
+    "maggie", "159753", "aaaaaa", "ginger", "princess", "joshua", "cheese", "amanda", "summer",
+    "love", "ashley", "nicole", "chelsea", "biteme", "matthew", "access", "yankees", "987654321",
+    "dallas", "austin", "thunder", "taylor", "matrix", "admin", "administrator", "root", "toor",
+    "mysql", "changeme", "default", "welcome", "test", "test123", "guest", "secret", "passw0rd",
+    "password1", "password123", "qwerty123", "admin123", "root123", "letmein1", "welcome1",
+    "P@ssw0rd", "Password1", "Welcome1", "1q2w3e4r", "1q2w3e", "q1w2e3r4", "zaq12wsx", "abcd1234",
+    "qwe123", "asdf", "asdfghjkl", "lovely", "flower", "hello", "hello123", "whatever", "666",
+}
+
+// passwordScore rates a discovered password for reports
+type passwordScore struct {
+    Score   int     // 0 (trivial) to 100 (strong)
+    Rating  string  // critical, weak, fair or strong
+    Length  int     // length in characters
+    Classes int     // character classes used: lower, upper, digit, symbol
+    Entropy float64 // estimated bits for a random password of this length and alphabet
+    Common  bool    // found in the top-10k list
+}
+
+// loadCommonPasswords builds the top-10k set from filename, or from the built-in list when no file is given
+func (e *Engine) loadCommonPasswords(filename string) error {
+    e.commonPasswords = make(map[string]bool)
+    if filename == "" {
+        for _, p := range e.commonPasswordList {
+            e.commonPasswords[strings.ToLower(p)] = true
+        }
+        return nil
+    }
+
+    file, err := os.Open(filename)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        if line := strings.TrimSpace(scanner.Text()); line != "" {
+            e.commonPasswords[strings.ToLower(line)] = true
+        }
+    }
+    if err := scanner.Err(); err != nil {
+        return err
+    }
+    e.verbosePrintf("Loaded %d common passwords from %s\n", len(e.commonPasswords), filename)
+    return nil
+}
+
+// isCommonPassword reports whether pass is in the top-10k list, ignoring case
+func (e *Engine) isCommonPassword(pass string) bool {
+    e.commonPasswordsOnce.Do(func() {
+        if e.commonPasswords == nil {
+            e.loadCommonPasswords("")
+        }
+    })
+    return e.commonPasswords[strings.ToLower(pass)]
+}
+
+// scorePassword estimates the strength of pass from its length and character classes. Passwords
+// found in the top-10k list are rated critical whatever their length, since they fall to any
+// dictionary attack.
+func (e *Engine) scorePassword(pass string) passwordScore {
+    var lower, upper, digit, symbol bool
+    length := 0
+    for _, r := range pass {
+        length++
+        switch {
+        case unicode.IsLower(r):
+            lower = true
+        case unicode.IsUpper(r):
+            upper = true
+        case unicode.IsDigit(r):
+            digit = true
+        default:
+            symbol = true
+        }
+    }
+
+    s := passwordScore{Length: length}
+    alphabet := 0
+    for _, class := range []struct {
+        used bool
+        size int
+    }{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}} {
+        if class.used {
+            s.Classes++
+            alphabet += class.size
+        }
+    }
+    if length > 0 {
+        s.Entropy = math.Round(float64(length)*math.Log2(float64(alphabet))*10) / 10
+    }
+    s.Common = pass != "" && e.isCommonPassword(pass)
+
+    // 80 bits of entropy or more counts as fully strong
+    s.Score = int(math.Min(100, s.Entropy*100/80))
+    switch {
+    case pass == "" || s.Common:
+        s.Score = 0
+        s.Rating = "critical"
+    case s.Entropy < 40:
+        s.Rating = "weak"
+    case s.Entropy < 60:
+        s.Rating = "fair"
+    default:
+        s.Rating = "strong"
+    }
+    return s
+}
+
+// String summarizes the score for report messages
+func (s passwordScore) String() string {
+    if s.Length == 0 {
+        return "password strength 0/100 (critical): empty password"
+    }
+    text := fmt.Sprintf("password strength %d/100 (%s): %d characters, %d character class(es), ~%.0f bits",
+        s.Score, s.Rating, s.Length, s.Classes, s.Entropy)
+    if s.Common {
+        text += ", in top-10k common password list"
+    }
+    return text
+}
@@ -0,0 +1,260 @@
+package core
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "os"
+    "regexp"
+    "strings"
+)
+
+// Policy modes: what happens to a statement the policy flags
+const (
+    policyBlock  = "block"
+    policyWarn   = "warn"
+    policyPrompt = "prompt"
+    policyAllow  = "allow"
+)
+
+// builtinDenyVerbs are the statement verbs flagged without a --policy file
+var builtinDenyVerbs = []string{"DROP", "DELETE", "TRUNCATE", "UPDATE", "INSERT", "ALTER", "GRANT", "REVOKE", "CREATE"}
+
+// builtinDenyFunctions are the functions and clauses flagged without a --policy file
+var builtinDenyFunctions = []string{
+    "SYS_EXEC", "SYSTEM_EXEC", "SHELL", "OUTFILE", "DUMPFILE",
+    "BENCHMARK", "SLEEP", "LOAD_FILE", "INTO OUTFILE", "INTO DUMPFILE",
+}
+
+// schemaRefPattern finds the schema of qualified table names after FROM, JOIN, INTO, UPDATE and
+// TABLE, and the schema of USE
+var schemaRefPattern = regexp.MustCompile("(?i)\\b(?:FROM|JOIN|INTO|UPDATE|TABLE)\\s+`?([\\w$]+)`?\\s*\\.|^\\s*USE\\s+`?([\\w$]+)")
+
+// PolicyRule flags or allows statements matching a regular expression. Action is block, warn,
+// prompt or allow; empty uses the policy's mode.
+type PolicyRule struct {
+    Pattern string `json:"pattern"`
+    Action  string `json:"action"`
+    Reason  string `json:"reason"`
+
+    re *regexp.Regexp
+}
+
+// Policy decides which statements are dangerous, loaded from the --policy JSON file. Rules are
+// tried first, in order, and the first match decides. Otherwise a statement is flagged when its
+// verb is missing from a non-empty AllowVerbs or listed in DenyVerbs, when it contains one of
+// DenyFunctions not exempted by AllowFunctions, or when it names a schema in DenySchemas or
+// missing from a non-empty AllowSchemas. DenyVerbs and DenyFunctions left out of the file keep
+// the built-in lists.
+type Policy struct {
+    Mode           string       `json:"mode"`
+    AllowVerbs     []string     `json:"allowVerbs"`
+    DenyVerbs      []string     `json:"denyVerbs"`
+    AllowFunctions []string     `json:"allowFunctions"`
+    DenyFunctions  []string     `json:"denyFunctions"`
+    AllowSchemas   []string     `json:"allowSchemas"`
+    DenySchemas    []string     `json:"denySchemas"`
+    Rules          []PolicyRule `json:"rules"`
+}
+
+// policyVerdict is the policy's decision on a statement: the action, empty when it may run
+// silently, and the reason it was flagged
+type policyVerdict struct {
+    action string
+    reason string
+}
+
+// defaultPolicy blocks the built-in dangerous verbs and functions
+func defaultPolicy() *Policy {
+    return &Policy{Mode: policyBlock, DenyVerbs: builtinDenyVerbs, DenyFunctions: builtinDenyFunctions}
+}
+
+// validPolicyAction reports whether action is a mode a flagged statement can get
+func validPolicyAction(action string) bool {
+    switch action {
+    case policyBlock, policyWarn, policyPrompt:
+        return true
+    }
+    return false
+}
+
+// loadPolicy reads and validates a --policy file
+func loadPolicy(path string) (*Policy, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var p Policy
+    decoder := json.NewDecoder(bytes.NewReader(data))
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&p); err != nil {
+        return nil, fmt.Errorf("%s: %v", path, err)
+    }
+
+    if p.Mode == "" {
+        p.Mode = policyBlock
+    }
+    p.Mode = strings.ToLower(p.Mode)
+    if !validPolicyAction(p.Mode) {
+        return nil, fmt.Errorf("%s: unknown mode '%s', use block, warn or prompt", path, p.Mode)
+    }
+    if p.DenyVerbs == nil {
+        p.DenyVerbs = builtinDenyVerbs
+    }
+    if p.DenyFunctions == nil {
+        p.DenyFunctions = builtinDenyFunctions
+    }
+    for i := range p.Rules {
+        r := &p.Rules[i]
+        r.Action = strings.ToLower(r.Action)
+        if r.Action != "" && r.Action != policyAllow && !validPolicyAction(r.Action) {
+            return nil, fmt.Errorf("%s: rule %d: unknown action '%s', use allow, block, warn or prompt", path, i+1, r.Action)
+        }
+        if r.re, err = regexp.Compile(r.Pattern); err != nil {
+            return nil, fmt.Errorf("%s: rule %d: %v", path, i+1, err)
+        }
+    }
+    return &p, nil
+}
+
+// containsFold reports whether list holds s, ignoring case
+func containsFold(list []string, s string) bool {
+    for _, item := range list {
+        if strings.EqualFold(item, s) {
+            return true
+        }
+    }
+    return false
+}
+
+// schemaRefs returns the schemas a statement names, outside quoted strings
+func schemaRefs(stmt string) []string {
+    var schemas []string
+    for _, m := range schemaRefPattern.FindAllStringSubmatch(stripStringLiterals(stmt), -1) {
+        if m[1] != "" {
+            schemas = append(schemas, m[1])
+        } else {
+            schemas = append(schemas, m[2])
+        }
+    }
+    return schemas
+}
+
+// stripStringLiterals blanks out the contents of quoted strings, so values that look like SQL
+// are not mistaken for it
+func stripStringLiterals(stmt string) string {
+    b := []byte(stmt)
+    for i := 0; i < len(b); i++ {
+        c := b[i]
+        if c != '\'' && c != '"' {
+            continue
+        }
+        for i++; i < len(b) && b[i] != c; i++ {
+            if b[i] == '\\' && i+1 < len(b) {
+                b[i] = ' '
+                i++
+            }
+            b[i] = ' '
+        }
+    }
+    return string(b)
+}
+
+// check decides what happens to a statement
+func (p *Policy) check(stmt string) policyVerdict {
+    for _, r := range p.Rules {
+        if !r.re.MatchString(stmt) {
+            continue
+        }
+        if r.Action == policyAllow {
+            return policyVerdict{}
+        }
+        verdict := policyVerdict{action: r.Action, reason: r.Reason}
+        if verdict.action == "" {
+            verdict.action = p.Mode
+        }
+        if verdict.reason == "" {
+            verdict.reason = fmt.Sprintf("matches rule %s", r.Pattern)
+        }
+        return verdict
+    }
+
+    verb := getSqlVerb(stmt)
+    if len(p.AllowVerbs) > 0 && !containsFold(p.AllowVerbs, verb) {
+        return policyVerdict{action: p.Mode, reason: fmt.Sprintf("verb %s is not allowed", verb)}
+    }
+    if containsFold(p.DenyVerbs, verb) {
+        return policyVerdict{action: p.Mode, reason: fmt.Sprintf("dangerous verb %s", verb)}
+    }
+
+    upper := strings.ToUpper(stmt)
+    for _, f := range p.DenyFunctions {
+        if strings.Contains(upper, strings.ToUpper(f)) && !containsFold(p.AllowFunctions, f) {
+            return policyVerdict{action: p.Mode, reason: fmt.Sprintf("contains %s", strings.ToUpper(f))}
+        }
+    }
+
+    for _, schema := range schemaRefs(stmt) {
+        if containsFold(p.DenySchemas, schema) {
+            return policyVerdict{action: p.Mode, reason: fmt.Sprintf("uses schema %s", schema)}
+        }
+        if len(p.AllowSchemas) > 0 && !containsFold(p.AllowSchemas, schema) {
+            return policyVerdict{action: p.Mode, reason: fmt.Sprintf("schema %s is not allowed", schema)}
+        }
+    }
+    return policyVerdict{}
+}
+
+// setupPolicy loads the --policy file, or the built-in policy without one
+func (e *Engine) setupPolicy(path string) error {
+    if path == "" {
+        e.policy = defaultPolicy()
+        return nil
+    }
+    p, err := loadPolicy(path)
+    if err != nil {
+        return err
+    }
+    e.policy = p
+    e.verbosePrintf("Loaded statement policy from %s (mode %s, %d rules)\n", path, p.Mode, len(p.Rules))
+    return nil
+}
+
+// checkStatement applies the read-only policy with --read-only, then the statement policy unless
+// --allow-dangerous is set
+func (e *Engine) checkStatement(stmt string) policyVerdict {
+    if verdict := e.checkReadOnly(stmt); verdict.action != "" {
+        e.verbosePrintf("Policy: %s (%s)\n", verdict.action, verdict.reason)
+        return verdict
+    }
+    if e.opts.AllowDangerous {
+        return policyVerdict{}
+    }
+    p := e.policy
+    if p == nil {
+        p = defaultPolicy()
+    }
+    verdict := p.check(stmt)
+    if verdict.action != "" {
+        e.verbosePrintf("Policy: %s (%s)\n", verdict.action, verdict.reason)
+    }
+    return verdict
+}
+
+// allowInteractive applies the statement policy to an interactive statement, asking first in
+// prompt mode, and reports whether it may run
+func (e *Engine) allowInteractive(line lineEditor, stmt string) bool {
+    verdict := e.checkStatement(stmt)
+    switch verdict.action {
+    case "":
+        return true
+    case policyWarn:
+        e.printWarning("%s", e.tr("cmd.policy_warn", stmt, verdict.reason))
+        return true
+    case policyPrompt:
+        e.printWarning("%s", e.tr("cmd.policy_warn", stmt, verdict.reason))
+        return askYesNo(line, "Run it anyway? [y/N] ")
+    }
+    e.printWarning("%s", e.tr("cmd.policy_blocked", stmt, verdict.reason))
+    return false
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "sync"
+
+    "golang.org/x/sync/errgroup"
+)
+
+// testPairs tests the credential pairs read from pairs against the run's target with the worker
+// pool until pairs is closed, and returns how many succeeded. Every run that tests credentials
+// goes through it: wordlists, --defaults, --cred-csv replays and the library's Bruteforce.
+// tracker records the finished pairs of a resumable run and is nil for the others.
+// errFirstSuccess means -f stopped the run and errBlockedHost that the host refused this client.
+func (e *Engine) testPairs(ctx context.Context, pairs <-chan Credential, stats *runStats, tracker *attemptTracker, logFile *os.File) (int, error) {
+    // Feeder and workers share one cancellation: the first worker to return an error or an
+    // interrupt stops the whole pool
+    g, gctx := errgroup.WithContext(ctx)
+    jobs := make(chan Credential, e.opts.Workers)
+
+    // Feed credential pairs to the workers
+    g.Go(func() error {
+        defer close(jobs)
+        processed := 0
+        for {
+            var cred Credential
+            var ok bool
+            select {
+            case <-gctx.Done():
+                e.verbosePrintln("\nContext cancelled, stopping credential processing")
+                return nil
+            case cred, ok = <-pairs:
+            }
+            if !ok {
+                e.verbosePrintln("\nAll credential pairs have been submitted to workers")
+                return nil
+            }
+
+            processed++
+            if processed%1000 == 0 {
+                e.verbosePrintf("\rProcessed %d credential pairs", processed)
+            }
+
+            e.waitForThrottle(gctx)
+            stats.position(cred.userIdx, cred.passIdx)
+
+            select {
+            case <-gctx.Done():
+                e.verbosePrintln("\nContext cancelled, stopping credential processing")
+                return nil
+            case jobs <- cred:
+            }
+        }
+    })
+
+    // finish records that worker is done with pair seq, so a resumed run does not test it again
+    finish := func(worker int, seq int64) {
+        if tracker != nil {
+            e.finishAttempt(tracker, worker, seq)
+        }
+    }
+
+    var mu sync.Mutex
+    found := 0
+    e.verbosePrintln("Setting up worker pool with", e.opts.Workers, "concurrent workers")
+    for i := 0; i < e.opts.Workers; i++ {
+        worker := i
+        g.Go(func() error {
+            // Batched COM_CHANGE_USER session owned by this worker, if enabled
+            var batch *authConn
+            defer func() {
+                if batch != nil {
+                    batch.close()
+                }
+            }()
+
+            for cred := range jobs {
+                if gctx.Err() != nil {
+                    return nil
+                }
+
+                // Skip users (or the whole host) that are locked out, at --max-attempts-per-user or
+                // already cracked under --skip-known
+                if e.isLockedOut(cred.user) || e.skipKnownUser(cred.user) || !e.reserveUserAttempt(cred.user) {
+                    stats.skip(1)
+                    finish(worker, cred.seq)
+                    e.emit(stats.progressEvent())
+                    continue
+                }
+
+                // Hold off while --block-pause waits out a host block, then honour --rate and
+                // --delay across the whole pool
+                e.waitForBlockPause(gctx)
+                if !e.attemptLimiter.wait(gctx) {
+                    return nil
+                }
+
+                result, concluded := e.batchTestLogin(gctx, &batch, cred.user, cred.pass, logFile)
+                if !concluded {
+                    result, concluded = e.safeTestLogin(gctx, cred.user, cred.pass, logFile)
+                }
+                if result != "" {
+                    stats.success()
+                }
+                stats.attempt()
+                // An attempt without a verdict, or cut short by cancellation, is not finished and
+                // is tested again on resume
+                if concluded && gctx.Err() == nil {
+                    finish(worker, cred.seq)
+                }
+                e.emit(stats.progressEvent())
+
+                if result != "" {
+                    mu.Lock()
+                    found++
+                    fmt.Fprintln(e.out, result)
+                    if logFile != nil {
+                        logFile.WriteString(result + "\n")
+                    }
+                    mu.Unlock()
+                    if e.opts.FirstOnly {
+                        e.verbosePrintln("First success found, cancelling remaining operations")
+                        return errFirstSuccess
+                    }
+                }
+                if e.isHostBlocked() {
+                    e.verbosePrintln("Host blocked, stopping the attempts against it")
+                    return errBlockedHost
+                }
+            }
+            return nil
+        })
+    }
+
+    err := g.Wait()
+    e.verbosePrintln("All workers have completed")
+    return found, err
+}
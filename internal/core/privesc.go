@@ -0,0 +1,318 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "os"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// Severities of privilege escalation findings, most urgent first
+const (
+    privescCritical = "critical"
+    privescHigh     = "high"
+    privescMedium   = "medium"
+    privescLow      = "low"
+)
+
+// privescRank orders severities for the report
+var privescRank = map[string]int{privescCritical: 0, privescHigh: 1, privescMedium: 2, privescLow: 3}
+
+// privescFinding is one way the logged-in account could gain more access than it was given
+type privescFinding struct {
+    Severity    string
+    Check       string
+    Title       string
+    Detail      string
+    Remediation string
+}
+
+// accountGrant is one SHOW GRANTS line split into its parts
+type accountGrant struct {
+    privs       map[string]bool
+    object      string
+    grantee     string
+    grantOption bool
+}
+
+// global reports whether the grant applies to every database
+func (g accountGrant) global() bool {
+    return g.object == "*.*"
+}
+
+// has reports whether the grant includes priv, directly or through ALL PRIVILEGES
+func (g accountGrant) has(priv string) bool {
+    return g.privs[priv] || g.privs["ALL PRIVILEGES"] || g.privs["ALL"]
+}
+
+// grantPattern splits "GRANT <privs> ON <object> TO <grantee>..."; role grants have no ON
+var grantPattern = regexp.MustCompile(`(?i)^GRANT (.+?) ON (\S+) TO (\S+)`)
+
+// parseGrant parses a SHOW GRANTS line, reporting false for role and proxy grants
+func parseGrant(line string) (accountGrant, bool) {
+    m := grantPattern.FindStringSubmatch(line)
+    if m == nil || strings.EqualFold(m[1], "PROXY") {
+        return accountGrant{}, false
+    }
+    g := accountGrant{
+        privs:       make(map[string]bool),
+        object:      m[2],
+        grantee:     m[3],
+        grantOption: strings.Contains(strings.ToUpper(line), "WITH GRANT OPTION"),
+    }
+    for _, p := range strings.Split(m[1], ",") {
+        // Column privileges look like "SELECT (col)"
+        p = strings.ToUpper(strings.TrimSpace(p))
+        if i := strings.Index(p, " ("); i >= 0 {
+            p = p[:i]
+        }
+        g.privs[p] = true
+    }
+    return g, true
+}
+
+// hasWildcard reports whether a database name or host pattern contains an unescaped % or _
+func hasWildcard(name string) bool {
+    name = strings.Trim(name, "`'\"")
+    for i := 0; i < len(name); i++ {
+        switch name[i] {
+        case '\\':
+            i++
+        case '%', '_':
+            return true
+        }
+    }
+    return false
+}
+
+// versionNumbers extracts major, minor and patch from a server version such as "5.0.51a-log"
+func versionNumbers(version string) (major, minor, patch int) {
+    parts := strings.SplitN(version, ".", 3)
+    nums := make([]int, 3)
+    for i := 0; i < len(parts) && i < 3; i++ {
+        digits := parts[i]
+        if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+            digits = digits[:end]
+        }
+        nums[i], _ = strconv.Atoi(digits)
+    }
+    return nums[0], nums[1], nums[2]
+}
+
+// udfAnyPath reports whether the server version loads UDF libraries from any path, as used by
+// raptor_udf: plugin_dir was only enforced from MySQL 5.0.67 and 5.1.19
+func udfAnyPath(version string) bool {
+    if isMariaDB(version) {
+        return false
+    }
+    major, minor, patch := versionNumbers(version)
+    switch {
+    case major == 0:
+        return false
+    case major < 5:
+        return true
+    case major == 5 && minor == 0:
+        return patch < 67
+    case major == 5 && minor == 1:
+        return patch < 19
+    }
+    return false
+}
+
+// runPrivescChecks audits what the logged-in account could escalate to and returns the
+// findings, most severe first
+func (e *Engine) runPrivescChecks(ctx context.Context, db *sql.DB) ([]privescFinding, error) {
+    rows, err := db.QueryContext(ctx, "SHOW GRANTS")
+    if err != nil {
+        return nil, fmt.Errorf("reading grants: %v", err)
+    }
+    var grants []accountGrant
+    for rows.Next() {
+        var line string
+        if err := rows.Scan(&line); err != nil {
+            rows.Close()
+            return nil, fmt.Errorf("reading grants: %v", err)
+        }
+        if g, ok := parseGrant(line); ok {
+            grants = append(grants, g)
+        }
+    }
+    rows.Close()
+
+    var findings []privescFinding
+    seen := make(map[string]bool)
+    add := func(f privescFinding) {
+        key := f.Check + "\x00" + f.Detail
+        if !seen[key] {
+            seen[key] = true
+            findings = append(findings, f)
+        }
+    }
+
+    // Privileges held through SHOW GRANTS
+    var hasFile, canCreateFunction bool
+    for _, g := range grants {
+        // Wildcards only apply to the database part, not table names
+        dbPart := strings.SplitN(g.object, ".", 2)[0]
+        mysqlDB := strings.EqualFold(strings.Trim(dbPart, "`'\""), "mysql")
+        if g.global() && (g.privs["ALL PRIVILEGES"] || g.privs["ALL"]) {
+            add(privescFinding{privescCritical, "all-privileges", "Account has ALL PRIVILEGES on *.*",
+                "Full administrative control of the server, including every check below",
+                "Grant only the privileges the application needs on its own schema"})
+        }
+        if g.global() && g.has("FILE") {
+            hasFile = true
+            add(privescFinding{privescHigh, "file-privilege", "Account has the FILE privilege",
+                "LOAD_FILE reads and INTO OUTFILE/DUMPFILE writes files as the mysqld user",
+                "REVOKE FILE ON *.* from the account"})
+        }
+        if g.global() && g.has("SUPER") {
+            add(privescFinding{privescHigh, "super-privilege", "Account has the SUPER privilege",
+                "SUPER can change global variables (such as general_log_file to write files), bypass read_only and kill other sessions",
+                "Replace SUPER with the specific dynamic privileges the account needs"})
+        }
+        if g.grantOption {
+            severity := privescMedium
+            if g.global() {
+                severity = privescHigh
+            }
+            add(privescFinding{severity, "grant-option", "Account can pass on its privileges (WITH GRANT OPTION)",
+                fmt.Sprintf("Privileges on %s can be granted to any other account", g.object),
+                "REVOKE GRANT OPTION unless the account administers users"})
+        }
+        if (g.global() || mysqlDB) && (g.has("INSERT") || g.has("UPDATE")) {
+            canCreateFunction = true
+            add(privescFinding{privescCritical, "grant-tables", "Account can write to the mysql system schema",
+                fmt.Sprintf("INSERT/UPDATE on %s allows editing grant tables and creating UDFs", g.object),
+                "Remove write privileges on the mysql schema"})
+        }
+        if g.global() && g.has("CREATE USER") {
+            add(privescFinding{privescHigh, "create-user", "Account has CREATE USER",
+                "New accounts can be created and existing ones renamed, dropped or have their passwords changed",
+                "Restrict CREATE USER to administrative accounts"})
+        }
+        if hasWildcard(dbPart) {
+            add(privescFinding{privescMedium, "wildcard-grant", "Grant uses a database name wildcard",
+                fmt.Sprintf("%s on %s also covers databases created later that match the pattern", g.grantee, g.object),
+                "Escape _ and % in database names or grant on exact names"})
+        }
+    }
+
+    // File access restrictions
+    var version string
+    var filePriv, pluginDir sql.NullString
+    if err := db.QueryRowContext(ctx, "SELECT VERSION(), @@secure_file_priv, @@plugin_dir").Scan(&version, &filePriv, &pluginDir); err != nil {
+        e.verbosePrintln("Error reading file restrictions:", err)
+    }
+    if filePriv.Valid && filePriv.String == "" {
+        severity, detail := privescLow, "Any account granted FILE could read and write files anywhere mysqld can"
+        if hasFile {
+            severity, detail = privescHigh, "The FILE privilege above is not limited to a directory"
+        }
+        add(privescFinding{severity, "secure-file-priv", "secure_file_priv is empty", detail,
+            "Set secure_file_priv to a dedicated directory, or NULL to disable file import and export"})
+    }
+    if hasFile && pluginDir.String != "" && filePriv.Valid && (filePriv.String == "" || underDirectory(pluginDir.String, filePriv.String)) {
+        detail := fmt.Sprintf("INTO DUMPFILE can reach plugin_dir (%s), the first step of UDF command execution; filesystem permissions were not tested", pluginDir.String)
+        if canCreateFunction {
+            detail += ", and the account can create functions"
+        }
+        add(privescFinding{privescCritical, "plugin-dir", "plugin_dir is writable through SQL", detail,
+            "Keep plugin_dir outside secure_file_priv and owned by root, not the mysqld user"})
+    }
+    if udfAnyPath(version) {
+        severity := privescMedium
+        if hasFile {
+            severity = privescCritical
+        }
+        add(privescFinding{severity, "raptor-udf", fmt.Sprintf("Server version %s loads UDFs from any path", version),
+            "Versions before 5.0.67 and 5.1.19 do not restrict CREATE FUNCTION to plugin_dir (raptor_udf)",
+            "Upgrade the server"})
+    }
+
+    // Accounts other than the current one need read access to mysql.user and mysql.db
+    if userRows, err := db.QueryContext(ctx, "SELECT user, host FROM mysql.user"); err != nil {
+        e.verbosePrintln("Skipping account checks, mysql.user not readable:", err)
+    } else {
+        for userRows.Next() {
+            var user, host string
+            if err := userRows.Scan(&user, &host); err != nil {
+                break
+            }
+            switch {
+            case user == "":
+                add(privescFinding{privescHigh, "anonymous-user", "Anonymous account exists",
+                    fmt.Sprintf("''@'%s' lets anyone log in without a user name", host),
+                    fmt.Sprintf("DROP USER ''@'%s'", host)})
+            case host == "%":
+                add(privescFinding{privescLow, "wildcard-host", "Account accepts logins from any host",
+                    fmt.Sprintf("'%s'@'%%' can log in from anywhere the port is reachable", user),
+                    "Restrict the account's host to the addresses that need it"})
+            }
+        }
+        userRows.Close()
+    }
+    if dbRows, err := db.QueryContext(ctx, "SELECT user, host, db FROM mysql.db"); err == nil {
+        for dbRows.Next() {
+            var user, host, dbName string
+            if err := dbRows.Scan(&user, &host, &dbName); err != nil {
+                break
+            }
+            if hasWildcard(dbName) {
+                add(privescFinding{privescMedium, "wildcard-grant", "Grant uses a database name wildcard",
+                    fmt.Sprintf("'%s'@'%s' on `%s`.* also covers databases created later that match the pattern", user, host, dbName),
+                    "Escape _ and % in database names or grant on exact names"})
+            }
+        }
+        dbRows.Close()
+    }
+
+    sort.SliceStable(findings, func(i, j int) bool {
+        return privescRank[findings[i].Severity] < privescRank[findings[j].Severity]
+    })
+    return findings, nil
+}
+
+// runPrivescAudit runs --privesc-check after a successful login and formats the findings
+func (e *Engine) runPrivescAudit(ctx context.Context, db *sql.DB, user, backend string, log *os.File) string {
+    if !supportsShowGrants(backend) || backend == backendClickHouse {
+        return e.warningString("Privilege escalation audit is not supported on %s", backend)
+    }
+    auditCtx, cancel := e.withQueryTimeout(ctx)
+    defer cancel()
+
+    findings, err := e.runPrivescChecks(auditCtx, db)
+    if err != nil {
+        e.emitRecord("privesc", map[string]interface{}{"user": user, "error": err.Error()})
+        return e.warningString("Privilege escalation audit failed: %v", err)
+    }
+
+    var output strings.Builder
+    output.WriteString(fmt.Sprintf("Privilege Escalation Audit (%s):\n", user))
+    if len(findings) == 0 {
+        output.WriteString("  No escalation paths found\n")
+    }
+    counts := make(map[string]int)
+    for _, f := range findings {
+        counts[f.Severity]++
+        output.WriteString(fmt.Sprintf("  [%s] %s\n", strings.ToUpper(f.Severity), f.Title))
+        output.WriteString(fmt.Sprintf("      %s\n", f.Detail))
+        output.WriteString(fmt.Sprintf("      Fix: %s\n", f.Remediation))
+        e.emitRecord("privesc", map[string]interface{}{"user": user, "severity": f.Severity, "check": f.Check,
+            "title": f.Title, "detail": f.Detail, "remediation": f.Remediation})
+    }
+    if len(findings) > 0 {
+        output.WriteString(fmt.Sprintf("  %d findings: %d critical, %d high, %d medium, %d low\n", len(findings),
+            counts[privescCritical], counts[privescHigh], counts[privescMedium], counts[privescLow]))
+    }
+
+    result := strings.TrimRight(output.String(), "\n")
+    if log != nil {
+        log.WriteString(result + "\n")
+    }
+    return result
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+    "bufio"
+    "crypto/rand"
+    "crypto/sha256"
+    "crypto/subtle"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "strings"
+)
+
+// proofPrefix marks a recorded password that --prove-only replaced with a commitment
+const proofPrefix = "sha256-commit:"
+
+// commitPassword returns a salted commitment to pass, "sha256-commit:<salt>:<SHA-256(salt||pass)>"
+// in hex. It can be published as proof that the password was recovered without disclosing it,
+// and whoever knows the password can check it with 'sqlblaster check-proof'. An empty password
+// is no secret and is kept as it is.
+func commitPassword(pass string) (string, error) {
+    if pass == "" {
+        return "", nil
+    }
+    salt := make([]byte, 16)
+    if _, err := rand.Read(salt); err != nil {
+        return "", err
+    }
+    sum := sha256.Sum256(append(salt, pass...))
+    return proofPrefix + hex.EncodeToString(salt) + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// isPasswordProof reports whether a recorded password is a --prove-only commitment
+func isPasswordProof(pass string) bool {
+    return strings.HasPrefix(pass, proofPrefix)
+}
+
+// checkPasswordProof reports whether pass is the password committed to by proof
+func checkPasswordProof(proof, pass string) (bool, error) {
+    saltHex, sumHex, ok := strings.Cut(strings.TrimPrefix(proof, proofPrefix), ":")
+    if !isPasswordProof(proof) || !ok {
+        return false, fmt.Errorf("'%s' is not a %s<salt>:<hash> commitment", proof, proofPrefix)
+    }
+    salt, err := hex.DecodeString(saltHex)
+    if err != nil {
+        return false, fmt.Errorf("invalid salt: %v", err)
+    }
+    want, err := hex.DecodeString(sumHex)
+    if err != nil {
+        return false, fmt.Errorf("invalid hash: %v", err)
+    }
+    sum := sha256.Sum256(append(salt, pass...))
+    return subtle.ConstantTimeCompare(sum[:], want) == 1, nil
+}
+
+// proveLogin records a successful login under --prove-only: the connection is already closed,
+// and only a commitment to the password is kept, reported and handed to hooks
+func (e *Engine) proveLogin(user, pass string, log *os.File) string {
+    proof, err := commitPassword(pass)
+    if err != nil {
+        e.printError("Error committing to the password of %s: %v", user, err)
+        return ""
+    }
+    e.recordSuccess(user, proof, "", accountInfo{})
+    e.emit(Event{Type: EventSuccess, User: user, Pass: proof})
+
+    if e.opts.Verbose {
+        fmt.Fprintln(e.out) // Newline after "Testing..." message
+    }
+    if proof == "" {
+        return e.successString("%s", e.tr("login.success_nopass", user))
+    }
+    return e.successString("Login as %s proven, password not kept: %s", user, proof)
+}
+
+// runCheckProof implements the 'check-proof' subcommand, which tells whoever knows a password
+// whether a published commitment is to it. The password is read from stdin unless given.
+func runCheckProof(args []string) {
+    if len(args) < 1 || len(args) > 2 {
+        printError("Error: check-proof requires a commitment.")
+        fmt.Println("Usage: sqlblaster check-proof <sha256-commit:...> [password]")
+        os.Exit(1)
+    }
+
+    pass := ""
+    if len(args) == 2 {
+        pass = args[1]
+    } else {
+        fmt.Print("Password: ")
+        line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+        if err != nil && line == "" {
+            printError("Error reading password: %v", err)
+            os.Exit(1)
+        }
+        pass = strings.TrimRight(line, "\r\n")
+    }
+
+    ok, err := checkPasswordProof(args[0], pass)
+    if err != nil {
+        printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if !ok {
+        printError("The commitment is not to this password.")
+        os.Exit(1)
+    }
+    printSuccess("The commitment is to this password.")
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "mime/multipart"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// pushClient is used for all uploads to vulnerability management platforms
+var pushClient = &http.Client{Timeout: 60 * time.Second}
+
+// findingSeverity maps a finding rule level to the severity names used by DefectDojo and Faraday
+func findingSeverity(level string) string {
+    switch level {
+    case "error":
+        return "high"
+    case "warning":
+        return "medium"
+    default:
+        return "low"
+    }
+}
+
+// pushDefectDojo uploads the findings of the run to a DefectDojo engagement using the import-scan API
+func (e *Engine) pushDefectDojo(baseURL, apiKey, engagement string, records []ResultRecord) error {
+    if engagement == "" {
+        return fmt.Errorf("an engagement ID is required (--push-engagement)")
+    }
+
+    sarifData, err := json.Marshal(e.buildSARIF(records))
+    if err != nil {
+        return err
+    }
+
+    // DefectDojo ingests SARIF natively, so upload the same document as --export-sarif
+    var body bytes.Buffer
+    writer := multipart.NewWriter(&body)
+    fields := map[string]string{
+        "scan_type":        "SARIF",
+        "engagement":       engagement,
+        "active":           "true",
+        "verified":         "false",
+        "minimum_severity": "Info",
+        "scan_date":        time.Now().Format("2006-01-02"),
+    }
+    for name, value := range fields {
+        if err := writer.WriteField(name, value); err != nil {
+            return err
+        }
+    }
+    part, err := writer.CreateFormFile("file", "sqlblaster.sarif")
+    if err != nil {
+        return err
+    }
+    if _, err := part.Write(sarifData); err != nil {
+        return err
+    }
+    if err := writer.Close(); err != nil {
+        return err
+    }
+
+    endpoint := strings.TrimRight(baseURL, "/") + "/api/v2/import-scan/"
+    e.verbosePrintln("Uploading findings to DefectDojo:", endpoint)
+    req, err := http.NewRequest("POST", endpoint, &body)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Authorization", "Token "+apiKey)
+    req.Header.Set("Content-Type", writer.FormDataContentType())
+
+    return e.doPushRequest(req)
+}
+
+// Faraday bulk_create document structure (only the parts sqlblaster emits)
+type faradayBulk struct {
+    Hosts   []faradayHost  `json:"hosts"`
+    Command faradayCommand `json:"command"`
+}
+
+type faradayHost struct {
+    IP          string           `json:"ip"`
+    Description string           `json:"description"`
+    Services    []faradayService `json:"services"`
+}
+
+type faradayService struct {
+    Name            string        `json:"name"`
+    Port            int           `json:"port"`
+    Protocol        string        `json:"protocol"`
+    Status          string        `json:"status"`
+    Vulnerabilities []faradayVuln `json:"vulnerabilities"`
+}
+
+type faradayVuln struct {
+    Name       string   `json:"name"`
+    Desc       string   `json:"desc"`
+    Severity   string   `json:"severity"`
+    Type       string   `json:"type"`
+    Status     string   `json:"status"`
+    Resolution string   `json:"resolution"`
+    Refs       []string `json:"refs"`
+}
+
+type faradayCommand struct {
+    Tool      string `json:"tool"`
+    Command   string `json:"command"`
+    Params    string `json:"params"`
+    StartDate string `json:"start_date"`
+    EndDate   string `json:"end_date"`
+}
+
+// pushFaraday uploads the findings of the run to a Faraday workspace using the bulk_create API
+func (e *Engine) pushFaraday(baseURL, apiKey, workspace string, records []ResultRecord, started time.Time) error {
+    if workspace == "" {
+        return fmt.Errorf("a workspace name is required (--push-engagement)")
+    }
+
+    // Group findings by host and port so each service is created once
+    hostIndex := make(map[string]int)
+    var hosts []faradayHost
+    for _, f := range e.buildFindings(records) {
+        idx, ok := hostIndex[f.Record.hostKey()]
+        if !ok {
+            idx = len(hosts)
+            hostIndex[f.Record.hostKey()] = idx
+            hosts = append(hosts, faradayHost{
+                IP:          f.Record.Host,
+                Description: f.Record.serverName() + " server tested by sqlblaster",
+                Services: []faradayService{{
+                    Name:     "mysql",
+                    Port:     f.Record.Port,
+                    Protocol: "tcp",
+                    Status:   "open",
+                }},
+            })
+        }
+        service := &hosts[idx].Services[0]
+        service.Vulnerabilities = append(service.Vulnerabilities, faradayVuln{
+            Name:       f.Rule.Description,
+            Desc:       f.Message,
+            Severity:   findingSeverity(f.Rule.Level),
+            Type:       "Vulnerability",
+            Status:     "open",
+            Resolution: f.Rule.Help,
+            Refs:       []string{},
+        })
+    }
+
+    doc := faradayBulk{
+        Hosts: hosts,
+        Command: faradayCommand{
+            Tool:      "sqlblaster",
+            Command:   "sqlblaster",
+            Params:    fmt.Sprintf("-h %s --port %d", e.opts.Host, e.opts.Port),
+            StartDate: started.UTC().Format(time.RFC3339),
+            EndDate:   time.Now().UTC().Format(time.RFC3339),
+        },
+    }
+    data, err := json.Marshal(doc)
+    if err != nil {
+        return err
+    }
+
+    endpoint := strings.TrimRight(baseURL, "/") + "/_api/v3/ws/" + url.PathEscape(workspace) + "/bulk_create"
+    e.verbosePrintln("Uploading findings to Faraday:", endpoint)
+    req, err := http.NewRequest("POST", endpoint, bytes.NewReader(data))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Authorization", "Token "+apiKey)
+    req.Header.Set("Content-Type", "application/json")
+
+    return e.doPushRequest(req)
+}
+
+// doPushRequest sends an upload request and turns non-2xx responses into errors
+func (e *Engine) doPushRequest(req *http.Request) error {
+    resp, err := pushClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+        return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+    }
+
+    e.verbosePrintln("Upload accepted with status", resp.Status)
+    return nil
+}
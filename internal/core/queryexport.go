@@ -0,0 +1,159 @@
+package core
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+)
+
+// queryExport is where the next interactive query's rows go instead of the screen
+type queryExport struct {
+    format string
+    path   string
+}
+
+// exportFormats lists the formats \o and INTO LOCAL FILE write
+var exportFormats = []string{"csv", "json", "jsonl"}
+
+// intoLocalFilePattern matches a trailing INTO LOCAL [CSV|JSON|JSONL] FILE 'path' clause
+var intoLocalFilePattern = regexp.MustCompile(`(?is)\s+INTO\s+LOCAL\s+(?:(CSV|JSONL|JSON)\s+)?FILE\s+(?:'([^']*)'|"([^"]*)")\s*;?\s*$`)
+
+// exportFormatFor picks the format from path's extension, CSV unless it is .json or .jsonl
+func exportFormatFor(path string) string {
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".json":
+        return "json"
+    case ".jsonl", ".ndjson":
+        return "jsonl"
+    }
+    return "csv"
+}
+
+// parseExportCommand parses "\o [csv|json|jsonl] <file>". A bare \o returns nil, cancelling a
+// pending export.
+func parseExportCommand(cmd string) (*queryExport, error) {
+    args := strings.Fields(strings.TrimSuffix(strings.TrimSpace(cmd[2:]), ";"))
+    switch len(args) {
+    case 0:
+        return nil, nil
+    case 1:
+        return &queryExport{format: exportFormatFor(args[0]), path: args[0]}, nil
+    case 2:
+        format := strings.ToLower(args[0])
+        for _, f := range exportFormats {
+            if f == format {
+                return &queryExport{format: format, path: args[1]}, nil
+            }
+        }
+        return nil, fmt.Errorf("unknown export format '%s', use %s", args[0], strings.Join(exportFormats, ", "))
+    }
+    return nil, fmt.Errorf("usage: \\o [csv|json|jsonl] <file>")
+}
+
+// splitIntoLocalFile strips a trailing INTO LOCAL FILE clause from a query and returns the
+// export it asks for, or nil when there is none
+func splitIntoLocalFile(cmd string) (string, *queryExport) {
+    m := intoLocalFilePattern.FindStringSubmatchIndex(cmd)
+    if m == nil {
+        return cmd, nil
+    }
+    var path string
+    if m[4] >= 0 {
+        path = cmd[m[4]:m[5]]
+    } else {
+        path = cmd[m[6]:m[7]]
+    }
+    format := exportFormatFor(path)
+    if m[2] >= 0 {
+        format = strings.ToLower(cmd[m[2]:m[3]])
+    }
+    return cmd[:m[0]], &queryExport{format: format, path: path}
+}
+
+// exportQueryResults writes every row to the export file and returns how many were written.
+// NULLs are --csv-null-string in CSV and null in JSON; JSON keeps the same typed values as
+// --dump-format jsonl. The file is private since query results are usually loot.
+func (e *Engine) exportQueryResults(rows *sql.Rows, exp *queryExport) (int, error) {
+    columns, err := rows.Columns()
+    if err != nil {
+        return 0, err
+    }
+    colTypes, err := rows.ColumnTypes()
+    if err != nil {
+        return 0, err
+    }
+    kinds := make([]string, len(colTypes))
+    for i, ct := range colTypes {
+        kinds[i] = columnKind(ct.DatabaseTypeName())
+    }
+
+    file, err := os.OpenFile(exp.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+    if err != nil {
+        return 0, err
+    }
+    defer file.Close()
+    out := bufio.NewWriterSize(file, dumpBufferSize)
+
+    var csvOut *csvWriter
+    switch exp.format {
+    case "csv":
+        csvOut = e.newCSVWriter(out)
+        csvOut.Write(columns)
+    case "json":
+        out.WriteString("[")
+    }
+
+    values := make([]interface{}, len(columns))
+    valuePtrs := make([]interface{}, len(columns))
+    for i := range values {
+        valuePtrs[i] = &values[i]
+    }
+    count := 0
+    for rows.Next() {
+        if err := rows.Scan(valuePtrs...); err != nil {
+            return count, err
+        }
+        switch exp.format {
+        case "csv":
+            csvOut.WriteValues(values, e.formatValueForCSV)
+        case "json":
+            if count > 0 {
+                out.WriteString(",")
+            }
+            out.WriteString("\n  ")
+            out.Write(bytes.TrimSuffix(jsonlRow(columns, kinds, values), []byte("\n")))
+        default:
+            out.Write(jsonlRow(columns, kinds, values))
+        }
+        count++
+    }
+    // An interrupted query still leaves a well-formed file of the rows read so far
+    iterErr := rows.Err()
+    if errors.Is(iterErr, context.Canceled) {
+        iterErr = fmt.Errorf("%s Rows written: %d", e.tr("shell.interrupted"), count)
+    }
+
+    if exp.format == "json" {
+        out.WriteString("\n]\n")
+    }
+    if csvOut != nil {
+        csvOut.Flush()
+        if err := csvOut.Error(); err != nil {
+            return count, err
+        }
+    }
+    if err := out.Flush(); err != nil {
+        return count, err
+    }
+    if err := file.Close(); err != nil {
+        return count, err
+    }
+    return count, iterErr
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+    "fmt"
+    "strings"
+)
+
+// nullParam is typed at a parameter prompt to bind NULL, as in the mysql client's batch output
+const nullParam = `\N`
+
+// isParamNameByte reports whether b can appear in a :name placeholder
+func isParamNameByte(b byte, first bool) bool {
+    switch {
+    case b == '_' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z':
+        return true
+    case '0' <= b && b <= '9':
+        return !first
+    }
+    return false
+}
+
+// parseNamedParams replaces the :name placeholders of an interactive statement with ? and
+// returns the names in the order they occur, one per ?. Placeholders are not looked for inside
+// quoted strings, quoted identifiers and comments, and := assignments are left alone.
+func parseNamedParams(stmt string) (string, []string) {
+    var b strings.Builder
+    var names []string
+    for i := 0; i < len(stmt); i++ {
+        c := stmt[i]
+        switch {
+        case c == '\'' || c == '"' || c == '`':
+            end := i + 1
+            for end < len(stmt) && stmt[end] != c {
+                if stmt[end] == '\\' && c != '`' {
+                    end++
+                }
+                end++
+            }
+            end = min(end+1, len(stmt))
+            b.WriteString(stmt[i:end])
+            i = end - 1
+        case c == '#' || c == '-' && strings.HasPrefix(stmt[i:], "-- "):
+            end := strings.IndexByte(stmt[i:], '\n')
+            if end < 0 {
+                end = len(stmt) - i
+            }
+            b.WriteString(stmt[i : i+end])
+            i += end - 1
+        case c == '/' && strings.HasPrefix(stmt[i:], "/*"):
+            end := strings.Index(stmt[i+2:], "*/")
+            if end < 0 {
+                end = len(stmt) - i
+            } else {
+                end += 4
+            }
+            b.WriteString(stmt[i : i+end])
+            i += end - 1
+        case c == ':' && i+1 < len(stmt) && isParamNameByte(stmt[i+1], true) &&
+            (i == 0 || !isParamNameByte(stmt[i-1], false) && stmt[i-1] != ':'):
+            end := i + 1
+            for end < len(stmt) && isParamNameByte(stmt[end], false) {
+                end++
+            }
+            names = append(names, stmt[i+1:end])
+            b.WriteByte('?')
+            i = end - 1
+        default:
+            b.WriteByte(c)
+        }
+    }
+    return b.String(), names
+}
+
+// promptParams asks for the value of each distinct placeholder, offering the value it was last
+// given in this session, and returns the arguments in placeholder order. \N binds NULL.
+func promptParams(line lineEditor, names []string, last map[string]string) ([]interface{}, error) {
+    values := make(map[string]interface{})
+    args := make([]interface{}, len(names))
+    for i, name := range names {
+        if _, ok := values[name]; !ok {
+            text, err := line.PromptWithSuggestion(fmt.Sprintf("  :%s = ", name), last[name], -1)
+            if err != nil {
+                return nil, err
+            }
+            last[name] = text
+            if text == nullParam {
+                values[name] = nil
+            } else {
+                values[name] = text
+            }
+        }
+        args[i] = values[name]
+    }
+    return args, nil
+}
+
+// paramList collects the values of a repeated --param flag in order
+type paramList []string
+
+// String joins the values for the flag package's defaults output
+func (p *paramList) String() string {
+    return strings.Join(*p, ",")
+}
+
+// Set appends one --param value
+func (p *paramList) Set(value string) error {
+    *p = append(*p, value)
+    return nil
+}
+
+// countPlaceholders counts the ? placeholders of a statement, skipping quoted strings, quoted
+// identifiers and comments like parseNamedParams
+func countPlaceholders(stmt string) int {
+    count := 0
+    for i := 0; i < len(stmt); i++ {
+        c := stmt[i]
+        switch {
+        case c == '\'' || c == '"' || c == '`':
+            end := i + 1
+            for end < len(stmt) && stmt[end] != c {
+                if stmt[end] == '\\' && c != '`' {
+                    end++
+                }
+                end++
+            }
+            i = end
+        case c == '#' || c == '-' && strings.HasPrefix(stmt[i:], "-- "):
+            end := strings.IndexByte(stmt[i:], '\n')
+            if end < 0 {
+                return count
+            }
+            i += end
+        case c == '/' && strings.HasPrefix(stmt[i:], "/*"):
+            end := strings.Index(stmt[i+2:], "*/")
+            if end < 0 {
+                return count
+            }
+            i += end + 3
+        case c == '?':
+            count++
+        }
+    }
+    return count
+}
+
+// execArgs returns the --param values bound to the -e statement's placeholders; \N binds NULL
+func (e *Engine) execArgs() []interface{} {
+    args := make([]interface{}, len(e.opts.Params))
+    for i, value := range e.opts.Params {
+        if value != nullParam {
+            args[i] = value
+        }
+    }
+    return args
+}
+
+// checkExecParams checks that --param is only given with --exec-params and that there is one
+// value for each ? in the -e statement
+func checkExecParams(execParams bool, stmt string, params []string) error {
+    if !execParams {
+        if len(params) > 0 {
+            return fmt.Errorf("--param requires --exec-params")
+        }
+        return nil
+    }
+    if n := countPlaceholders(stmt); n != len(params) {
+        return fmt.Errorf("-e has %d ? placeholder(s) but %d --param value(s) were given", n, len(params))
+    }
+    return nil
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+// rateLimiter spaces attempts across the whole worker pool. It is a token bucket holding a
+// single token, so bursts never exceed one attempt per interval.
+type rateLimiter struct {
+    mu       sync.Mutex
+    interval time.Duration
+    next     time.Time
+}
+
+// setupRateLimit builds the limiter shared by the engine's workers from attempts/sec and a
+// minimum delay in milliseconds, using whichever is slower. It is left nil when neither --rate
+// nor --delay is set.
+func (e *Engine) setupRateLimit(rate float64, delayMs int) {
+    var interval time.Duration
+    if rate > 0 {
+        interval = time.Duration(float64(time.Second) / rate)
+    }
+    if delay := time.Duration(delayMs) * time.Millisecond; delay > interval {
+        interval = delay
+    }
+    if interval <= 0 {
+        return
+    }
+    e.verbosePrintln("Limiting attempts to one every", interval)
+    e.attemptLimiter = &rateLimiter{interval: interval}
+}
+
+// wait blocks until the caller may make its next attempt, returning false if ctx is cancelled
+func (l *rateLimiter) wait(ctx context.Context) bool {
+    if l == nil {
+        return true
+    }
+
+    l.mu.Lock()
+    now := time.Now()
+    if l.next.Before(now) {
+        l.next = now
+    }
+    slot := l.next
+    l.next = slot.Add(l.interval)
+    l.mu.Unlock()
+
+    select {
+    case <-ctx.Done():
+        return false
+    case <-time.After(time.Until(slot)):
+        return true
+    }
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "github.com/peterh/liner"
+)
+
+// historyFileName is the interactive mode history file kept in the user's home directory
+const historyFileName = ".sqlblaster_history"
+
+// historyPath returns the path of the interactive mode history file, or "" without a home directory
+func historyPath() string {
+    home, err := os.UserHomeDir()
+    if err != nil {
+        return ""
+    }
+    return filepath.Join(home, historyFileName)
+}
+
+// lineEditor reads the lines typed at the interactive prompt
+type lineEditor interface {
+    Prompt(prompt string) (string, error)
+    PromptWithSuggestion(prompt, text string, pos int) (string, error)
+    AppendHistory(item string)
+    SetWordCompleter(f liner.WordCompleter)
+    Close() error
+}
+
+// newLineEditor reads the run's input: the process's terminal with arrow-key history, Ctrl-R
+// search and the saved history, or the reader a library caller gave the run
+func (e *Engine) newLineEditor() lineEditor {
+    if e.in != nil {
+        return &plainEditor{in: bufio.NewReader(e.in), out: e.out}
+    }
+
+    line := liner.NewLiner()
+    line.SetCtrlCAborts(true)
+
+    if path := historyPath(); path != "" {
+        if file, err := os.Open(path); err == nil {
+            line.ReadHistory(file)
+            file.Close()
+        }
+    }
+    return &termEditor{State: line, engine: e}
+}
+
+// termEditor edits lines on the process's terminal and saves the history when it is closed
+type termEditor struct {
+    *liner.State
+    engine *Engine
+}
+
+// Close saves the history and gives the terminal back
+func (t *termEditor) Close() error {
+    t.engine.saveHistory(t.State)
+    return t.State.Close()
+}
+
+// plainEditor reads lines from a reader without editing, completion or history
+type plainEditor struct {
+    in  *bufio.Reader
+    out io.Writer
+}
+
+// Prompt writes prompt and reads the next line, returning io.EOF once the input is used up
+func (p *plainEditor) Prompt(prompt string) (string, error) {
+    fmt.Fprint(p.out, prompt)
+    text, err := p.in.ReadString('\n')
+    if err != nil && text == "" {
+        return "", err
+    }
+    return strings.TrimRight(text, "\r\n"), nil
+}
+
+// PromptWithSuggestion reads a line like Prompt; an empty line takes the suggested text
+func (p *plainEditor) PromptWithSuggestion(prompt, text string, pos int) (string, error) {
+    answer, err := p.Prompt(prompt)
+    if err == nil && answer == "" {
+        answer = text
+    }
+    return answer, err
+}
+
+// Input from a reader has no history or completion
+func (p *plainEditor) AppendHistory(item string)              {}
+func (p *plainEditor) SetWordCompleter(f liner.WordCompleter) {}
+func (p *plainEditor) Close() error                           { return nil }
+
+// saveHistory writes the session's history back to ~/.sqlblaster_history
+func (e *Engine) saveHistory(line *liner.State) {
+    path := historyPath()
+    if path == "" {
+        return
+    }
+    // Queries can contain credentials, so keep the file private
+    file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+    if err != nil {
+        e.verbosePrintln("Failed to save history:", err)
+        return
+    }
+    defer file.Close()
+    if _, err := line.WriteHistory(file); err != nil {
+        e.verbosePrintln("Failed to save history:", err)
+    }
+}
+
+// askYesNo asks a y/N question at the interactive prompt; anything but y or yes, Ctrl-C
+// included, is no
+func askYesNo(line lineEditor, question string) bool {
+    answer, err := line.Prompt(question)
+    if err != nil {
+        return false
+    }
+    answer = strings.ToLower(strings.TrimSpace(answer))
+    return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+)
+
+// readOnlyVerbs are the only statement verbs --read-only lets through
+var readOnlyVerbs = []string{"SELECT", "SHOW", "DESCRIBE", "DESC", "EXPLAIN", "USE", "HELP"}
+
+// readOnlyDenyFunctions are clauses and functions that write to the server or its host even
+// inside a SELECT
+var readOnlyDenyFunctions = []string{
+    "INTO OUTFILE", "INTO DUMPFILE", "SYS_EXEC", "SYS_EVAL", "SYSTEM_EXEC", "SHELL",
+    "GET_LOCK", "FOR UPDATE", "LOCK IN SHARE MODE", "NEXTVAL", "SETVAL",
+}
+
+// readOnlyVariables hold the session's default transaction access mode, newest name first
+var readOnlyVariables = []string{"transaction_read_only", "tx_read_only"}
+
+// readOnlyPolicy blocks every statement that is not a plain read. It applies on top of the
+// --policy file and --allow-dangerous cannot lift it.
+var readOnlyPolicy = &Policy{
+    Mode:          policyBlock,
+    AllowVerbs:    readOnlyVerbs,
+    DenyFunctions: readOnlyDenyFunctions,
+}
+
+// checkReadOnly applies the read-only policy when --read-only is set
+func (e *Engine) checkReadOnly(stmt string) policyVerdict {
+    if !e.opts.ReadOnly {
+        return policyVerdict{}
+    }
+    verdict := readOnlyPolicy.check(stmt)
+    if verdict.action != "" {
+        verdict.reason = "read-only mode: " + verdict.reason
+    }
+    return verdict
+}
+
+// readOnlySession makes conn's transactions read-only and returns the DSN parameter that does
+// the same for every later connection. Servers without read-only transactions (MySQL before
+// 5.6) return "" and are left to the statement policy.
+func (e *Engine) readOnlySession(ctx context.Context, conn *sql.Conn) string {
+    if _, err := conn.ExecContext(ctx, "SET SESSION TRANSACTION READ ONLY"); err != nil {
+        e.printWarning("Server does not support read-only transactions (%v); only the statement policy enforces --read-only", err)
+        return ""
+    }
+    for _, name := range readOnlyVariables {
+        if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET SESSION %s = 1", name)); err == nil {
+            e.verbosePrintf("Session transactions are read-only (%s)\n", name)
+            return "&" + name + "=1"
+        }
+    }
+    return ""
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "runtime/debug"
+)
+
+// safeTestLogin runs testLogin, recovering from any panic so one bad credential or driver
+// bug only costs that attempt instead of the whole run. An attempt that panicked has no verdict.
+func (e *Engine) safeTestLogin(ctx context.Context, user, pass string, log *os.File) (string, bool) {
+    e.recordAttempt(user)
+    return e.safeLogin(ctx, user, pass, log)
+}
+
+// safeLogin is safeTestLogin without counting the attempt, for a hit replayed over a regular
+// connection after batchTestLogin counted it
+func (e *Engine) safeLogin(ctx context.Context, user, pass string, log *os.File) (result string, concluded bool) {
+    defer func() {
+        if r := recover(); r != nil {
+            e.notePanic(fmt.Sprintf("testing user '%s' on %s:%d", user, e.opts.Host, e.opts.Port), r, log)
+            result, concluded = "", false
+        }
+    }()
+    return e.attemptLogin(ctx, user, pass, log)
+}
+
+// notePanic reports a recovered panic, with a stack trace when --debug-crash is set
+func (e *Engine) notePanic(where string, r interface{}, log *os.File) {
+    e.panicMu.Lock()
+    e.recoveredPanics++
+    e.panicMu.Unlock()
+
+    msg := fmt.Sprintf("Recovered from panic while %s: %v", where, r)
+    e.printError("\n%s", msg)
+    if log != nil {
+        log.WriteString(msg + "\n")
+    }
+
+    if e.opts.DebugCrash {
+        stack := debug.Stack()
+        fmt.Fprintf(os.Stderr, "%s\n", stack)
+        if log != nil {
+            log.Write(stack)
+        }
+    } else {
+        fmt.Fprintln(e.out, "Run with --debug-crash to print the stack trace.")
+    }
+}
+
+// reportPanics prints how many attempts were lost to recovered panics
+func (e *Engine) reportPanics(log *os.File) {
+    e.panicMu.Lock()
+    count := e.recoveredPanics
+    e.panicMu.Unlock()
+    if count == 0 {
+        return
+    }
+
+    msg := fmt.Sprintf("%d attempt(s) were skipped after recovered panics, see the messages above.", count)
+    e.printWarning("\n%s", msg)
+    if log != nil {
+        log.WriteString(msg + "\n")
+    }
+}
@@ -0,0 +1,525 @@
+package core
+
+import (
+    "database/sql"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    _ "modernc.org/sqlite"
+)
+
+// ResultRecord represents a successful login stored in a results database
+type ResultRecord struct {
+    Host         string
+    Port         int
+    User         string
+    Pass         string
+    FoundAt      string
+    Backend      string
+    Plugin       string
+    Restrictions string
+    Notes        []string // notes taken on the account or its target
+}
+
+// openResultsDB opens (or creates) a SQLite results database and ensures the schema exists
+func openResultsDB(filename string) (*sql.DB, error) {
+    db, err := sql.Open("sqlite", filename)
+    if err != nil {
+        return nil, err
+    }
+
+    // SQLite only supports a single writer, so serialize access from the workers
+    db.SetMaxOpenConns(1)
+
+    _, err = db.Exec(`CREATE TABLE IF NOT EXISTS successes (
+        host     TEXT NOT NULL,
+        port     INTEGER NOT NULL,
+        user     TEXT NOT NULL,
+        pass     TEXT NOT NULL,
+        found_at TEXT NOT NULL,
+        UNIQUE(host, port, user, pass)
+    )`)
+    if err != nil {
+        db.Close()
+        return nil, err
+    }
+
+    _, err = db.Exec(`CREATE TABLE IF NOT EXISTS notes (
+        host     TEXT NOT NULL,
+        port     INTEGER NOT NULL,
+        user     TEXT NOT NULL,
+        note     TEXT NOT NULL,
+        added_at TEXT NOT NULL
+    )`)
+    if err != nil {
+        db.Close()
+        return nil, err
+    }
+
+    // The targets that answered an attempt, so compare can tell a fixed account from one whose
+    // host was not tested again
+    _, err = db.Exec(`CREATE TABLE IF NOT EXISTS targets (
+        host      TEXT NOT NULL,
+        port      INTEGER NOT NULL,
+        tested_at TEXT NOT NULL
+    )`)
+    if err != nil {
+        db.Close()
+        return nil, err
+    }
+
+    // Databases from older versions lack the later columns, so add them when missing
+    for _, column := range []string{"backend", "plugin", "restrictions"} {
+        if err := addColumnIfMissing(db, "successes", column); err != nil {
+            db.Close()
+            return nil, err
+        }
+    }
+
+    // Older databases were created without the constraint and may hold a credential more than
+    // once, so keep its first row and index the rest away
+    _, err = db.Exec(`DELETE FROM successes WHERE rowid NOT IN (SELECT MIN(rowid) FROM successes GROUP BY host, port, user, pass)`)
+    if err == nil {
+        _, err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS successes_credential ON successes (host, port, user, pass)`)
+    }
+    if err != nil {
+        db.Close()
+        return nil, err
+    }
+
+    return db, nil
+}
+
+// addColumnIfMissing adds a text column to a results database table that doesn't have it yet
+func addColumnIfMissing(db *sql.DB, table, column string) error {
+    var exists int
+    err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = ?", table), column).Scan(&exists)
+    if err != nil || exists > 0 {
+        return err
+    }
+    _, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s TEXT NOT NULL DEFAULT ''", table, column))
+    return err
+}
+
+// recordSuccess records a successful login against the configured target
+func (e *Engine) recordSuccess(user, pass, backend string, account accountInfo) {
+    e.recordSuccessAt(e.opts.Host, e.opts.Port, user, pass, backend, account)
+}
+
+// recordSuccessAt keeps a successful login for the end-of-run exports and stores it
+// in the results database if one is open. backend names the server software when it was detected
+// and account holds what could be read about the account from mysql.user.
+func (e *Engine) recordSuccessAt(host string, port int, user, pass, backend string, account accountInfo) {
+    record := ResultRecord{
+        Host:         host,
+        Port:         port,
+        User:         user,
+        Pass:         pass,
+        FoundAt:      time.Now().Format(time.RFC3339),
+        Backend:      backend,
+        Plugin:       account.Plugin,
+        Restrictions: strings.Join(account.restrictions(), ", "),
+    }
+
+    e.successesMu.Lock()
+    e.successes = append(e.successes, record)
+    e.successesMu.Unlock()
+    e.addKnownCred(host, port, user, pass)
+
+    if e.resultsDB == nil {
+        return
+    }
+
+    // A credential found again by a re-run is kept once, with the time it was first found; the
+    // unique constraint makes the check and the insert one statement so workers can't race
+    e.verbosePrintln("Recording success in results database")
+    res, err := e.resultsDB.Exec("INSERT OR IGNORE INTO successes (host, port, user, pass, found_at, backend, plugin, restrictions) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+        record.Host, record.Port, record.User, record.Pass, record.FoundAt, record.Backend, record.Plugin, record.Restrictions)
+    if err != nil {
+        e.printError("Error recording result: %v", err)
+        return
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        e.verbosePrintln("Credential already in results database")
+    }
+}
+
+// recordAttempt notes that a username was tested against the target during the current run
+func (e *Engine) recordAttempt(user string) {
+    e.recordAttemptAt(e.opts.Host, e.opts.Port, user)
+}
+
+// recordAttemptAt notes that a username was tested against a specific host during the current run
+func (e *Engine) recordAttemptAt(host string, port int, user string) {
+    e.successesMu.Lock()
+    e.testedUsers[user] = true
+    account := ResultRecord{Host: host, Port: port, User: user}
+    e.testedAccounts[account.accountKey()] = account
+    e.targetAttempts[fmt.Sprintf("%s:%d", host, port)]++
+    e.successesMu.Unlock()
+}
+
+// recordTested notes that a host answered an attempt with a verdict on the credential, and
+// stores it in the results database the first time in the run. Attempts that never reached the
+// server are not recorded, so a host that was down does not count as tested.
+func (e *Engine) recordTested(host string, port int) {
+    key := fmt.Sprintf("%s:%d", host, port)
+    e.successesMu.Lock()
+    seen := e.testedTargets[key]
+    e.testedTargets[key] = true
+    e.successesMu.Unlock()
+    if seen || e.resultsDB == nil {
+        return
+    }
+
+    _, err := e.resultsDB.Exec("INSERT INTO targets (host, port, tested_at) SELECT ?, ?, ? WHERE NOT EXISTS (SELECT 1 FROM targets WHERE host = ? AND port = ?)",
+        host, port, time.Now().Format(time.RFC3339), host, port)
+    if err != nil {
+        e.printError("Error recording tested target: %v", err)
+    }
+}
+
+// getTargetAttempts returns a copy of the number of attempts made per host:port
+func (e *Engine) getTargetAttempts() map[string]int {
+    e.successesMu.Lock()
+    defer e.successesMu.Unlock()
+    attempts := make(map[string]int, len(e.targetAttempts))
+    for target, n := range e.targetAttempts {
+        attempts[target] = n
+    }
+    return attempts
+}
+
+// getTestedUsers returns the sorted usernames tested during the current run
+func (e *Engine) getTestedUsers() []string {
+    e.successesMu.Lock()
+    defer e.successesMu.Unlock()
+    users := make([]string, 0, len(e.testedUsers))
+    for user := range e.testedUsers {
+        users = append(users, user)
+    }
+    sort.Strings(users)
+    return users
+}
+
+// getTestedAccounts returns the accounts tested during the current run, sorted by target and user
+func (e *Engine) getTestedAccounts() []ResultRecord {
+    e.successesMu.Lock()
+    defer e.successesMu.Unlock()
+    accounts := make([]ResultRecord, 0, len(e.testedAccounts))
+    for _, account := range e.testedAccounts {
+        accounts = append(accounts, account)
+    }
+    sortAccounts(accounts)
+    return accounts
+}
+
+// sortAccounts orders records by host, port and user
+func sortAccounts(accounts []ResultRecord) {
+    sort.Slice(accounts, func(i, j int) bool {
+        a, b := accounts[i], accounts[j]
+        if a.Host != b.Host {
+            return a.Host < b.Host
+        }
+        if a.Port != b.Port {
+            return a.Port < b.Port
+        }
+        return a.User < b.User
+    })
+}
+
+// getSuccesses returns a copy of the successful logins recorded so far, with their notes
+func (e *Engine) getSuccesses() []ResultRecord {
+    e.successesMu.Lock()
+    defer e.successesMu.Unlock()
+    records := append([]ResultRecord(nil), e.successes...)
+    attachNotes(records, e.notes)
+    return records
+}
+
+// loadResults reads all successful logins, with their notes, from a results database
+func (e *Engine) loadResults(filename string) ([]ResultRecord, error) {
+    if !e.fileExists(filename) {
+        return nil, fmt.Errorf("results database '%s' not found", filename)
+    }
+
+    db, err := openResultsDB(filename)
+    if err != nil {
+        return nil, err
+    }
+    defer db.Close()
+
+    rows, err := db.Query("SELECT host, port, user, pass, found_at, backend, plugin, restrictions FROM successes ORDER BY found_at")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var records []ResultRecord
+    for rows.Next() {
+        var r ResultRecord
+        if err := rows.Scan(&r.Host, &r.Port, &r.User, &r.Pass, &r.FoundAt, &r.Backend, &r.Plugin, &r.Restrictions); err != nil {
+            return nil, err
+        }
+        records = append(records, r)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    list, err := queryNotes(db)
+    if err != nil {
+        return nil, err
+    }
+    attachNotes(records, list)
+    return records, nil
+}
+
+// loadTestedTargets reads the host:port targets that answered an attempt from a results
+// database. Databases from older versions have none recorded.
+func loadTestedTargets(filename string) (map[string]bool, error) {
+    db, err := openResultsDB(filename)
+    if err != nil {
+        return nil, err
+    }
+    defer db.Close()
+
+    rows, err := db.Query("SELECT host, port FROM targets")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    targets := make(map[string]bool)
+    for rows.Next() {
+        var r ResultRecord
+        if err := rows.Scan(&r.Host, &r.Port); err != nil {
+            return nil, err
+        }
+        targets[r.hostKey()] = true
+    }
+    return targets, rows.Err()
+}
+
+// hostKey identifies a target within a results database
+func (r ResultRecord) hostKey() string {
+    return fmt.Sprintf("%s:%d", r.Host, r.Port)
+}
+
+// serverName names the server software behind the record, MySQL unless another backend was detected
+func (r ResultRecord) serverName() string {
+    if r.Backend == "" {
+        return backendMySQL
+    }
+    return r.Backend
+}
+
+// accountStatus describes how usable the account is, or "" when nothing could be read about it
+func (r ResultRecord) accountStatus() string {
+    if r.Restrictions != "" {
+        return "restricted: " + r.Restrictions
+    }
+    if r.Plugin != "" {
+        return "fully usable"
+    }
+    return ""
+}
+
+// target describes the record's host for findings, naming the backend when it is not plain MySQL
+func (r ResultRecord) target() string {
+    if r.serverName() == backendMySQL {
+        return r.hostKey()
+    }
+    return fmt.Sprintf("%s (%s)", r.hostKey(), r.Backend)
+}
+
+// accountKey identifies an account on a target within a results database
+func (r ResultRecord) accountKey() string {
+    return fmt.Sprintf("%s@%s:%d", r.User, r.Host, r.Port)
+}
+
+// runCompare implements the 'compare' subcommand, reporting differences between two campaigns.
+// Main turns a returned error into the exit status.
+func (e *Engine) runCompare(args []string) error {
+    if len(args) != 2 {
+        fmt.Fprintln(e.out, "Usage: sqlblaster compare <run1.sqlite> <run2.sqlite>")
+        return fmt.Errorf("compare requires exactly two results databases")
+    }
+
+    oldRecords, err := e.loadResults(args[0])
+    if err != nil {
+        return fmt.Errorf("loading %s: %v", args[0], err)
+    }
+    newRecords, err := e.loadResults(args[1])
+    if err != nil {
+        return fmt.Errorf("loading %s: %v", args[1], err)
+    }
+    retested, err := loadTestedTargets(args[1])
+    if err != nil {
+        return fmt.Errorf("loading %s: %v", args[1], err)
+    }
+    if len(retested) == 0 {
+        e.printWarning("%s records no tested targets (it may predate target tracking), so no account can be confirmed remediated", args[1])
+    }
+
+    // Index the passwords found per account and the hosts with any success
+    oldAccounts := make(map[string]map[string]bool)
+    newAccounts := make(map[string]map[string]bool)
+    oldHosts := make(map[string]bool)
+    newHosts := make(map[string]bool)
+    accountHost := make(map[string]string)
+    for _, r := range oldRecords {
+        if oldAccounts[r.accountKey()] == nil {
+            oldAccounts[r.accountKey()] = make(map[string]bool)
+        }
+        accountHost[r.accountKey()] = r.hostKey()
+        oldAccounts[r.accountKey()][r.Pass] = true
+        oldHosts[r.hostKey()] = true
+    }
+    for _, r := range newRecords {
+        if newAccounts[r.accountKey()] == nil {
+            newAccounts[r.accountKey()] = make(map[string]bool)
+        }
+        accountHost[r.accountKey()] = r.hostKey()
+        newAccounts[r.accountKey()][r.Pass] = true
+        newHosts[r.hostKey()] = true
+    }
+
+    var newlyVulnerable, newlyCracked, remediated, notRetested, changed, unchanged, undetermined []string
+    for host := range newHosts {
+        if !oldHosts[host] {
+            newlyVulnerable = append(newlyVulnerable, host)
+        }
+    }
+    // Accounts cracked only by run2 on hosts run1 already had successes on; those on new hosts
+    // are covered by newlyVulnerable
+    for account := range newAccounts {
+        if _, ok := oldAccounts[account]; !ok && oldHosts[accountHost[account]] {
+            newlyCracked = append(newlyCracked, account)
+        }
+    }
+    for account, oldPasses := range oldAccounts {
+        newPasses, ok := newAccounts[account]
+        if !ok {
+            // Only a host run2 tested again can show the account was fixed
+            if retested[accountHost[account]] {
+                remediated = append(remediated, account)
+            } else {
+                notRetested = append(notRetested, account)
+            }
+            continue
+        }
+
+        // The account is still accessible, check whether the working passwords changed
+        switch sameCredentials(oldPasses, newPasses) {
+        case credsSame:
+            unchanged = append(unchanged, account)
+        case credsChanged:
+            changed = append(changed, account)
+        default:
+            undetermined = append(undetermined, account)
+        }
+    }
+    sort.Strings(newlyVulnerable)
+    sort.Strings(newlyCracked)
+    sort.Strings(remediated)
+    sort.Strings(notRetested)
+    sort.Strings(changed)
+    sort.Strings(unchanged)
+    sort.Strings(undetermined)
+
+    fmt.Fprintln(e.out, e.tr("compare.header", args[0], len(oldRecords), args[1], len(newRecords))+"\n")
+
+    e.theme.heading.Fprintln(e.out, e.tr("compare.new_hosts", len(newlyVulnerable)))
+    for _, host := range newlyVulnerable {
+        e.printError("  %s", host)
+    }
+
+    e.theme.heading.Fprintln(e.out, "\n"+e.tr("compare.new_accounts", len(newlyCracked)))
+    for _, account := range newlyCracked {
+        e.printError("  %s", account)
+    }
+
+    e.theme.heading.Fprintln(e.out, "\n"+e.tr("compare.remediated", len(remediated)))
+    for _, account := range remediated {
+        e.printSuccess("  %s", account)
+    }
+
+    e.theme.heading.Fprintln(e.out, "\n"+e.tr("compare.not_retested", len(notRetested)))
+    for _, account := range notRetested {
+        e.printWarning("  %s", account)
+    }
+
+    e.theme.heading.Fprintln(e.out, "\n"+e.tr("compare.changed", len(changed)))
+    for _, account := range changed {
+        e.printWarning("  %s", account)
+    }
+
+    e.theme.heading.Fprintln(e.out, "\n"+e.tr("compare.unchanged", len(unchanged)))
+    for _, account := range unchanged {
+        fmt.Fprintf(e.out, "  %s\n", account)
+    }
+
+    if len(undetermined) > 0 {
+        e.theme.heading.Fprintln(e.out, "\n"+e.tr("compare.undetermined", len(undetermined)))
+        for _, account := range undetermined {
+            fmt.Fprintf(e.out, "  %s\n", account)
+        }
+    }
+    return nil
+}
+
+// Outcomes of comparing an account's working passwords across two runs
+const (
+    credsSame = iota
+    credsChanged
+    credsUnknown
+)
+
+// sameCredentials compares the passwords found for an account in two runs. A --prove-only
+// commitment is checked against a password kept in the other run, but two commitments are
+// salted separately and cannot be compared, so then the outcome is unknown.
+func sameCredentials(oldPasses, newPasses map[string]bool) int {
+    outcome := credsSame
+    for _, pair := range [][2]map[string]bool{{oldPasses, newPasses}, {newPasses, oldPasses}} {
+        for pass := range pair[0] {
+            switch passwordFoundIn(pass, pair[1]) {
+            case credsChanged:
+                return credsChanged
+            case credsUnknown:
+                outcome = credsUnknown
+            }
+        }
+    }
+    return outcome
+}
+
+// passwordFoundIn reports whether pass, a password or commitment, is among passes
+func passwordFoundIn(pass string, passes map[string]bool) int {
+    if passes[pass] {
+        return credsSame
+    }
+    unknown := false
+    for other := range passes {
+        proof, plain := pass, other
+        if !isPasswordProof(proof) {
+            proof, plain = other, pass
+        }
+        switch {
+        case !isPasswordProof(proof):
+            continue
+        case isPasswordProof(plain):
+            unknown = true
+        default:
+            if ok, err := checkPasswordProof(proof, plain); err == nil && ok {
+                return credsSame
+            }
+        }
+    }
+    if unknown {
+        return credsUnknown
+    }
+    return credsChanged
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+    "bytes"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+)
+
+// TestCompareNewAccountOnKnownHost checks that an account cracked only by the second run is
+// reported even when run1 already had a success on its host
+func TestCompareNewAccountOnKnownHost(t *testing.T) {
+    dir := t.TempDir()
+    record := func(name string, users ...string) string {
+        path := filepath.Join(dir, name)
+        db, err := openResultsDB(path)
+        if err != nil {
+            t.Fatalf("opening %s: %v", name, err)
+        }
+        defer db.Close()
+        opts := defaultOptions()
+        e := NewEngine(&opts)
+        e.resultsDB = db
+        e.recordTested("db1", 3306)
+        for _, user := range users {
+            e.recordSuccessAt("db1", 3306, user, "secret", "", accountInfo{})
+        }
+        return path
+    }
+    run1 := record("run1.sqlite", "root")
+    run2 := record("run2.sqlite", "root", "app")
+
+    opts := defaultOptions()
+    e := NewEngine(&opts)
+    var out bytes.Buffer
+    e.out = &out
+    if err := e.runCompare([]string{run1, run2}); err != nil {
+        t.Fatal(err)
+    }
+
+    report := out.String()
+    section := strings.Index(report, "Newly vulnerable accounts")
+    if section < 0 {
+        t.Fatalf("no newly vulnerable accounts section in:\n%s", report)
+    }
+    next := strings.Index(report[section:], "Remediated")
+    if !strings.Contains(report[section:section+next], "app@db1:3306") {
+        t.Errorf("app@db1:3306 is not listed as newly vulnerable in:\n%s", report)
+    }
+    if strings.Contains(report[section:section+next], "root@db1:3306") {
+        t.Errorf("root@db1:3306, cracked by both runs, is listed as newly vulnerable in:\n%s", report)
+    }
+}
+
+// TestRecordSuccessOnce checks that workers finding the same credential at once leave a single
+// row behind, carrying the time it was first found
+func TestRecordSuccessOnce(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "results.sqlite")
+    db, err := openResultsDB(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer db.Close()
+    opts := defaultOptions()
+    e := NewEngine(&opts)
+    e.resultsDB = db
+    if _, err := db.Exec("INSERT INTO successes (host, port, user, pass, found_at) VALUES ('db1', 3306, 'root', 'secret', '2020-01-01T00:00:00Z')"); err != nil {
+        t.Fatal(err)
+    }
+
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            e.recordSuccessAt("db1", 3306, "root", "secret", "", accountInfo{})
+        }()
+    }
+    wg.Wait()
+
+    records, err := e.loadResults(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(records) != 1 {
+        t.Fatalf("got %d rows for one credential, want 1", len(records))
+    }
+    if records[0].FoundAt != "2020-01-01T00:00:00Z" {
+        t.Errorf("found_at is %s, want the first 2020-01-01T00:00:00Z", records[0].FoundAt)
+    }
+}
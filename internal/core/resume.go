@@ -0,0 +1,252 @@
+package core
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "text/tabwriter"
+)
+
+// hashWordlist returns the hex SHA-256 of a wordlist file
+func hashWordlist(path string) (string, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return "", err
+    }
+    defer file.Close()
+
+    h := sha256.New()
+    if _, err := io.Copy(h, file); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashWordlists checksums the user and password lists of the run
+func (e *Engine) hashWordlists() {
+    var err error
+    if e.opts.UserList != "" {
+        e.verbosePrintln("Checksumming username list:", e.opts.UserList)
+        if e.userListSum, err = hashWordlist(e.opts.UserList); err != nil {
+            e.printWarning("Cannot checksum %s: %v", e.opts.UserList, err)
+        }
+    }
+    if e.opts.PassList != "" {
+        e.verbosePrintln("Checksumming password list:", e.opts.PassList)
+        if e.passListSum, err = hashWordlist(e.opts.PassList); err != nil {
+            e.printWarning("Cannot checksum %s: %v", e.opts.PassList, err)
+        }
+    }
+}
+
+// checkResumeState compares the saved wordlist checksums and pair order with the current ones.
+// Resuming by attempt index against changed lists or another order silently skips or repeats
+// ranges, so a mismatch is an error unless --force-resume is set.
+func (e *Engine) checkResumeState(state State) error {
+    var changed []string
+    if state.UserListSum != "" && e.userListSum != "" && state.UserListSum != e.userListSum {
+        changed = append(changed, e.opts.UserList)
+    }
+    if state.PassListSum != "" && e.passListSum != "" && state.PassListSum != e.passListSum {
+        changed = append(changed, e.opts.PassList)
+    }
+
+    if order := e.pairOrder(); state.Order != order {
+        changed = append(changed, e.tr("resume.order", state.Order, order))
+    }
+
+    if state.UserListSum == "" && state.PassListSum == "" && len(changed) == 0 {
+        e.printWarning("%s", e.tr("resume.no_checksums", e.statePath))
+        return nil
+    }
+    if len(changed) == 0 {
+        e.verbosePrintln("Wordlists match the saved state")
+        return nil
+    }
+    if e.forceResume {
+        e.printWarning("%s", e.tr("resume.forced", changed))
+        return nil
+    }
+    return errors.New(e.tr("resume.changed", changed))
+}
+
+// attemptTracker records which credential pairs of a run are finished. Workers finish pairs out
+// of order, so the state keeps the index below which every pair is finished: resuming from it
+// repeats at most the pairs that were in flight and never skips one.
+type attemptTracker struct {
+    mu        sync.Mutex
+    total     int64
+    completed int64          // every pair with a lower index is finished
+    done      map[int64]bool // finished pairs at or above completed
+    highWater []int64        // highest pair index each worker finished, -1 for none yet
+}
+
+// newAttemptTracker creates a tracker for total pairs of which the first start are finished
+func newAttemptTracker(start, total int64, workers int) *attemptTracker {
+    t := &attemptTracker{total: total, completed: start, done: make(map[int64]bool), highWater: make([]int64, workers)}
+    for i := range t.highWater {
+        t.highWater[i] = -1
+    }
+    return t
+}
+
+// finishAttempt records that worker finished pair seq and saves the session
+func (e *Engine) finishAttempt(t *attemptTracker, worker int, seq int64) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    t.highWater[worker] = max(t.highWater[worker], seq)
+    t.done[seq] = true
+    for t.done[t.completed] {
+        delete(t.done, t.completed)
+        t.completed++
+    }
+    e.saveState(t)
+}
+
+// pairOrder describes how the run orders its credential pairs; attempt indexes only mean the
+// same pairs when it has not changed
+func (e *Engine) pairOrder() string {
+    order := "password-first"
+    if e.opts.UserFirst {
+        order = "user-first"
+    }
+    if e.opts.Mutate {
+        order += ",mutate"
+        if e.opts.Company != "" {
+            order += ",company=" + e.opts.Company
+        }
+    }
+    if e.opts.Rules != "" {
+        order += ",rules=" + absPath(e.opts.Rules)
+    }
+    return order
+}
+
+// absPath returns path made absolute, so a session started in another directory still matches
+func absPath(path string) string {
+    if path == "" {
+        return ""
+    }
+    if abs, err := filepath.Abs(path); err == nil {
+        return abs
+    }
+    return path
+}
+
+// sessionSource describes one side of the credential pairs for the session key: the absolute
+// path of its wordlist, or its single value
+func sessionSource(list, single string) string {
+    if single != "" {
+        return "value:" + single
+    }
+    return "file:" + absPath(list)
+}
+
+// sessionID identifies a run by its target and wordlists, so runs against other targets or with
+// other lists keep their own state
+func (e *Engine) sessionID() string {
+    h := sha256.New()
+    fmt.Fprintf(h, "%s\n%d\n%s\n%s\n", strings.ToLower(e.opts.Host), e.opts.Port,
+        sessionSource(e.opts.UserList, e.opts.SingleUser), sessionSource(e.opts.PassList, e.opts.SinglePass))
+    return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// useSession points statePath at the session file of the current target and wordlists
+func (e *Engine) useSession() {
+    e.statePath = filepath.Join(e.opts.SessionDir, e.sessionID()+".json")
+    e.verbosePrintln("Session state file:", e.statePath)
+}
+
+// readSession decodes a session file
+func readSession(path string) (State, error) {
+    var state State
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return state, err
+    }
+    if err := json.Unmarshal(data, &state); err != nil {
+        return state, fmt.Errorf("%s: %v", path, err)
+    }
+    return state, nil
+}
+
+// restoreSession fills the target and wordlists of --resume-session id from its state file.
+// Options given on the command line win, but they must lead back to the same session.
+func (e *Engine) restoreSession(id string) error {
+    path := filepath.Join(e.opts.SessionDir, id+".json")
+    state, err := readSession(path)
+    if os.IsNotExist(err) {
+        return errors.New(e.tr("resume.no_session", id, e.opts.SessionDir))
+    } else if err != nil {
+        return err
+    }
+
+    if e.opts.Host == "" {
+        e.opts.Host = state.Host
+    }
+    if e.opts.Port == 3306 && state.Port != 0 {
+        e.opts.Port = state.Port
+    }
+    if e.opts.UserList == "" && e.opts.SingleUser == "" {
+        e.opts.UserList, e.opts.SingleUser = state.UserList, state.SingleUser
+    }
+    if e.opts.PassList == "" && e.opts.SinglePass == "" {
+        e.opts.PassList, e.opts.SinglePass = state.PassList, state.SinglePass
+    }
+    if e.sessionID() != id {
+        return errors.New(e.tr("resume.other_target", id, state.Host, state.Port))
+    }
+    return nil
+}
+
+// listSessions prints the saved sessions in --session-dir, most recently updated first
+func (e *Engine) listSessions() error {
+    paths, err := filepath.Glob(filepath.Join(e.opts.SessionDir, "*.json"))
+    if err != nil {
+        return err
+    }
+    type entry struct {
+        id    string
+        state State
+    }
+    var sessions []entry
+    for _, path := range paths {
+        state, err := readSession(path)
+        if err != nil {
+            e.printWarning("%s", e.tr("resume.skipping", err))
+            continue
+        }
+        sessions = append(sessions, entry{strings.TrimSuffix(filepath.Base(path), ".json"), state})
+    }
+    if len(sessions) == 0 {
+        e.printInfo("%s", e.tr("resume.none", e.opts.SessionDir))
+        return nil
+    }
+    sort.Slice(sessions, func(i, j int) bool { return sessions[i].state.Updated.After(sessions[j].state.Updated) })
+
+    w := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, e.tr("resume.header"))
+    for _, s := range sessions {
+        users, passes := s.state.UserList, s.state.PassList
+        if s.state.SingleUser != "" {
+            users = s.state.SingleUser
+        }
+        if s.state.SinglePass != "" {
+            passes = e.tr("resume.single_pass")
+        }
+        fmt.Fprintf(w, "%s\t%s:%d\t%s\t%s\t%d/%d\t%s\n", s.id, s.state.Host, s.state.Port, users, passes,
+            s.state.Completed, s.state.Total, s.state.Updated.Format("2006-01-02 15:04:05"))
+    }
+    w.Flush()
+    fmt.Fprintln(e.out, e.tr("resume.hint"))
+    return nil
+}
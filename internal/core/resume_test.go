@@ -0,0 +1,63 @@
+package core
+
+import (
+    "context"
+    "net"
+    "os"
+    "path/filepath"
+    "strconv"
+    "testing"
+)
+
+// TestResumeRetriesInconclusivePair checks that a pair lost to a transient error is not counted
+// as finished, so --resume tests it again instead of skipping it
+func TestResumeRetriesInconclusivePair(t *testing.T) {
+    server, err := newFakeServer(selftestVersion)
+    if err != nil {
+        t.Fatalf("starting fake server: %v", err)
+    }
+    defer server.close()
+    server.addUser("root", "toor")
+    server.setBusy("flaky", true)
+
+    dir := t.TempDir()
+    userList := filepath.Join(dir, "users.txt")
+    if err := os.WriteFile(userList, []byte("flaky\nroot\n"), 0600); err != nil {
+        t.Fatal(err)
+    }
+    host, port, _ := net.SplitHostPort(server.addr())
+
+    opts := DefaultOptions()
+    opts.Host = host
+    opts.Port, _ = strconv.Atoi(port)
+    opts.UserList = userList
+    opts.SinglePass = "toor"
+    opts.SessionDir = filepath.Join(dir, "sessions")
+    opts.SkipSSL = true
+    opts.Workers = 1
+    opts.Retries = 0
+    run := func(resume bool) State {
+        e, err := beginRun(opts, nil)
+        if err != nil {
+            t.Fatalf("setting up run: %v", err)
+        }
+        e.performTesting(context.Background(), resume, nil)
+        return e.loadState()
+    }
+
+    // flaky is the first pair and has no verdict, so nothing is finished although root was tested
+    if state := run(false); state.Completed != 0 {
+        t.Fatalf("after the transient failure %d pair(s) are finished, want 0", state.Completed)
+    }
+
+    server.setBusy("flaky", false)
+    server.addUser("flaky", "toor")
+    before := server.loginCount("flaky")
+    state := run(true)
+    if server.loginCount("flaky") == before {
+        t.Errorf("resume did not test flaky again")
+    }
+    if state.Completed != 2 {
+        t.Errorf("after the resume %d pair(s) are finished, want 2", state.Completed)
+    }
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+    "context"
+    "database/sql/driver"
+    "errors"
+    "fmt"
+    "io"
+    "math/rand"
+    "net"
+    "os"
+    "syscall"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// Backoff between retries of an attempt that failed with a transient error
+const (
+    retryBaseDelay = 500 * time.Millisecond
+    retryMaxDelay  = 30 * time.Second
+)
+
+// MySQL/MariaDB error numbers for server-side conditions that pass on their own
+const (
+    errConCount          = 1040 // ER_CON_COUNT_ERROR, too many connections
+    errServerShutdown    = 1053 // ER_SERVER_SHUTDOWN
+    errTooManyUserConns  = 1203 // ER_TOO_MANY_USER_CONNECTIONS
+    errUserLimitReached  = 1226 // ER_USER_LIMIT_REACHED
+    errNetReadInterrupt  = 1159 // ER_NET_READ_INTERRUPTED
+    errNetWriteInterrupt = 1161 // ER_NET_WRITE_INTERRUPTED
+)
+
+// isTransientError reports whether a failed attempt says nothing about the credential: the
+// connection was refused, reset or timed out, or the server was out of connections. Access
+// denied and lockout errors are not transient.
+func isTransientError(err error) bool {
+    if err == nil || isAuthFailure(err) {
+        return false
+    }
+    if reason, _ := classifyLockout(err); reason != "" {
+        return false
+    }
+
+    var myErr *mysql.MySQLError
+    if errors.As(err, &myErr) {
+        switch myErr.Number {
+        case errConCount, errServerShutdown, errTooManyUserConns, errUserLimitReached,
+            errNetReadInterrupt, errNetWriteInterrupt:
+            return true
+        }
+        return false
+    }
+
+    var netErr net.Error
+    switch {
+    case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+        errors.Is(err, syscall.ECONNABORTED), errors.Is(err, syscall.EPIPE):
+        return true
+    case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+        return true
+    case errors.Is(err, driver.ErrBadConn), errors.Is(err, mysql.ErrInvalidConn):
+        return true
+    case errors.Is(err, context.DeadlineExceeded):
+        return true
+    case errors.As(err, &netErr):
+        return true
+    }
+    return false
+}
+
+// retryBackoff returns the wait before retry n (0 for the first): retryBaseDelay doubled each
+// time up to retryMaxDelay, with up to a quarter added at random so workers do not retry in step
+func retryBackoff(n int) time.Duration {
+    d := retryMaxDelay
+    if n < 16 {
+        d = min(retryBaseDelay<<n, retryMaxDelay)
+    }
+    return d + time.Duration(rand.Int63n(int64(d)/4+1))
+}
+
+// sleepContext waits for d, returning early with the context's error if it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+    select {
+    case <-timer.C:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+// withRetries runs attempt until it succeeds, fails with an error that is not transient, or
+// has been retried --retries times, backing off between tries. It returns the last error.
+func (e *Engine) withRetries(ctx context.Context, user string, attempt func() error) error {
+    var err error
+    for n := 0; ; n++ {
+        if err = attempt(); err == nil || !isTransientError(err) || ctx.Err() != nil {
+            return err
+        }
+        if n >= e.opts.Retries {
+            return err
+        }
+        wait := retryBackoff(n)
+        e.verbosePrintf("Transient error for %s (%v), retry %d/%d in %v\n", user, err, n+1, e.opts.Retries, wait.Round(time.Millisecond))
+        if sleepContext(ctx, wait) != nil {
+            return err
+        }
+    }
+}
+
+// noteInconclusive records an attempt that gave up on a transient error, which leaves the
+// credential untested rather than wrong
+func (e *Engine) noteInconclusive(user, pass string, err error, log *os.File) {
+    e.inconclusiveAttempts.Add(1)
+    msg := fmt.Sprintf("Could not test %s:%s after %d retries: %v", user, pass, e.opts.Retries, err)
+    e.printWarning("%s", msg)
+    if log != nil {
+        log.WriteString(msg + "\n")
+    }
+}
+
+// reportInconclusive prints how many attempts could not be completed because of transient errors
+func (e *Engine) reportInconclusive(log *os.File) {
+    count := e.inconclusiveAttempts.Load()
+    if count == 0 {
+        return
+    }
+    msg := fmt.Sprintf("%d attempt(s) failed with network or server errors after all retries and were not tested, see the warnings above.", count)
+    e.printWarning("\n%s", msg)
+    if log != nil {
+        log.WriteString(msg + "\n")
+    }
+}
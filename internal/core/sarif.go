@@ -0,0 +1,167 @@
+package core
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+)
+
+// SARIF 2.1.0 document structure (only the parts sqlblaster emits)
+type sarifLog struct {
+    Schema  string     `json:"$schema"`
+    Version string     `json:"version"`
+    Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+    Tool    sarifTool     `json:"tool"`
+    Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+    Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+    Name           string      `json:"name"`
+    InformationURI string      `json:"informationUri"`
+    Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+    ID                   string             `json:"id"`
+    Name                 string             `json:"name"`
+    ShortDescription     sarifMessage       `json:"shortDescription"`
+    Help                 sarifMessage       `json:"help"`
+    DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+    Level string `json:"level"`
+}
+
+type sarifMessage struct {
+    Text string `json:"text"`
+}
+
+type sarifResult struct {
+    RuleID              string            `json:"ruleId"`
+    RuleIndex           int               `json:"ruleIndex"`
+    Level               string            `json:"level"`
+    Message             sarifMessage      `json:"message"`
+    Locations           []sarifLocation   `json:"locations"`
+    PartialFingerprints map[string]string `json:"partialFingerprints"`
+    Properties          *sarifProperties  `json:"properties,omitempty"`
+}
+
+// sarifProperties carries the password score of credential findings and the notes taken on
+// the account, either of which may be missing
+type sarifProperties struct {
+    *sarifScore
+    Notes []string `json:"notes,omitempty"`
+}
+
+// sarifScore is the password score part of a finding's properties
+type sarifScore struct {
+    PasswordScore   int     `json:"passwordScore"`
+    PasswordRating  string  `json:"passwordRating"`
+    PasswordLength  int     `json:"passwordLength"`
+    PasswordClasses int     `json:"passwordClasses"`
+    PasswordEntropy float64 `json:"passwordEntropyBits"`
+    CommonPassword  bool    `json:"commonPassword"`
+}
+
+type sarifLocation struct {
+    PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+    ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+    URI string `json:"uri"`
+}
+
+// buildSARIF converts the findings of the run into a SARIF 2.1.0 document
+func (e *Engine) buildSARIF(records []ResultRecord) sarifLog {
+    driver := sarifDriver{
+        Name:           "sqlblaster",
+        InformationURI: "https://github.com/xmarkinmtlx/sqlblaster",
+    }
+    ruleIndex := make(map[string]int)
+    for i, rule := range findingRules {
+        ruleIndex[rule.ID] = i
+        driver.Rules = append(driver.Rules, sarifRule{
+            ID:                   rule.ID,
+            Name:                 rule.Name,
+            ShortDescription:     sarifMessage{Text: rule.Description},
+            Help:                 sarifMessage{Text: rule.Help},
+            DefaultConfiguration: sarifConfiguration{Level: rule.Level},
+        })
+    }
+
+    // Results must be an empty array rather than null when nothing was found
+    results := []sarifResult{}
+    for _, f := range e.buildFindings(records) {
+        var props *sarifProperties
+        if f.Score != nil || len(f.Record.Notes) > 0 {
+            props = &sarifProperties{Notes: f.Record.Notes}
+        }
+        if f.Score != nil {
+            props.sarifScore = &sarifScore{
+                PasswordScore:   f.Score.Score,
+                PasswordRating:  f.Score.Rating,
+                PasswordLength:  f.Score.Length,
+                PasswordClasses: f.Score.Classes,
+                PasswordEntropy: f.Score.Entropy,
+                CommonPassword:  f.Score.Common,
+            }
+        }
+        results = append(results, sarifResult{
+            RuleID:    f.Rule.ID,
+            RuleIndex: ruleIndex[f.Rule.ID],
+            Level:     f.Rule.Level,
+            Message:   sarifMessage{Text: f.Message},
+            Locations: []sarifLocation{{
+                PhysicalLocation: sarifPhysicalLocation{
+                    ArtifactLocation: sarifArtifactLocation{URI: fmt.Sprintf("mysql://%s/", f.Record.hostKey())},
+                },
+            }},
+            PartialFingerprints: map[string]string{
+                "account": f.Record.accountKey(),
+            },
+            Properties: props,
+        })
+    }
+
+    return sarifLog{
+        Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+        Version: "2.1.0",
+        Runs: []sarifRun{{
+            Tool:    sarifTool{Driver: driver},
+            Results: results,
+        }},
+    }
+}
+
+// exportSARIF writes the findings of the run to a SARIF 2.1.0 file
+func (e *Engine) exportSARIF(filename string, records []ResultRecord) error {
+    e.verbosePrintln("Exporting findings to SARIF file:", filename)
+    doc := e.buildSARIF(records)
+
+    file, err := os.Create(filename)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    encoder := json.NewEncoder(file)
+    encoder.SetIndent("", "  ")
+    if err := encoder.Encode(doc); err != nil {
+        return err
+    }
+
+    e.verbosePrintf("Exported %d findings to %s\n", len(doc.Runs[0].Results), filename)
+    return nil
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+    "encoding/json"
+    "strings"
+    "testing"
+)
+
+// TestSARIFNotesWithoutScore checks that notes on a --prove-only finding, which has no password
+// score, still reach the SARIF properties
+func TestSARIFNotesWithoutScore(t *testing.T) {
+    opts := DefaultOptions()
+    e := NewEngine(&opts)
+    proof, err := commitPassword("toor")
+    if err != nil {
+        t.Fatal(err)
+    }
+    records := []ResultRecord{{Host: "db1", Port: 3306, User: "app", Pass: proof, Notes: []string{"ticket SEC-12"}}}
+
+    data, err := json.Marshal(e.buildSARIF(records))
+    if err != nil {
+        t.Fatal(err)
+    }
+    var log sarifLog
+    if err := json.Unmarshal(data, &log); err != nil {
+        t.Fatal(err)
+    }
+    results := log.Runs[0].Results
+    if len(results) != 1 || results[0].Properties == nil {
+        t.Fatalf("no properties on the finding:\n%s", data)
+    }
+    if notes := results[0].Properties.Notes; len(notes) != 1 || notes[0] != "ticket SEC-12" {
+        t.Errorf("got notes %v, want [ticket SEC-12]", notes)
+    }
+    if strings.Contains(string(data), "passwordScore") {
+        t.Errorf("a commitment was given a password score:\n%s", data)
+    }
+}
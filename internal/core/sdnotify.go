@@ -0,0 +1,95 @@
+package core
+
+import (
+    "context"
+    "net"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// sdStatusInterval is how often the unit's status text is refreshed when no watchdog is configured
+const sdStatusInterval = 10 * time.Second
+
+// sdNotify sends a state string to systemd's notification socket, doing nothing when the process
+// is not a Type=notify service
+func (e *Engine) sdNotify(state string) {
+    socket := os.Getenv("NOTIFY_SOCKET")
+    if socket == "" {
+        return
+    }
+    if strings.HasPrefix(socket, "@") {
+        // Abstract namespace socket
+        socket = "\x00" + socket[1:]
+    }
+
+    conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+    if err != nil {
+        e.verbosePrintln("sd_notify failed:", err)
+        return
+    }
+    defer conn.Close()
+    if _, err := conn.Write([]byte(state)); err != nil {
+        e.verbosePrintln("sd_notify failed:", err)
+    }
+}
+
+// sdWatchdogTimeout returns the service's WatchdogSec, or 0 when the watchdog is not enabled for this process
+func sdWatchdogTimeout() time.Duration {
+    usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+    if err != nil || usec <= 0 {
+        return 0
+    }
+    if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+        return 0
+    }
+    return time.Duration(usec) * time.Microsecond
+}
+
+// startSystemdNotify reports readiness to systemd, then keeps the unit's status text current and
+// pings the watchdog while attempts keep finishing. A run that stops making progress outside of
+// throttle and spray pauses stops pinging, so systemd can restart it (e.g. with --resume).
+func (e *Engine) startSystemdNotify(ctx context.Context, stats *runStats) {
+    if os.Getenv("NOTIFY_SOCKET") == "" {
+        return
+    }
+    e.sdNotify("READY=1\nSTATUS=" + stats.plainLine())
+
+    timeout := sdWatchdogTimeout()
+    interval := sdStatusInterval
+    if timeout > 0 {
+        e.verbosePrintln("systemd watchdog enabled, timeout", timeout)
+        interval = timeout / 2
+    }
+
+    go func() {
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+
+        lastCount := stats.finished()
+        lastProgress := time.Now()
+        for {
+            select {
+            case <-ctx.Done():
+                e.sdNotify("STOPPING=1")
+                return
+            case <-ticker.C:
+            }
+
+            state := "STATUS=" + stats.plainLine()
+            if timeout > 0 {
+                if count := stats.finished(); count != lastCount || e.isThrottlePaused() || e.isBlockPaused() || e.sprayWaiting.Load() {
+                    lastCount = count
+                    lastProgress = time.Now()
+                }
+                if time.Since(lastProgress) < timeout {
+                    state += "\nWATCHDOG=1"
+                } else {
+                    e.verbosePrintln("No attempts finished for", timeout, "- withholding watchdog ping")
+                }
+            }
+            e.sdNotify(state)
+        }
+    }()
+}
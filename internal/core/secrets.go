@@ -0,0 +1,244 @@
+package core
+
+import (
+    "bufio"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// secretScanLimit is how much of each value is scanned, so large BLOBs don't stall the dump
+const secretScanLimit = 64 << 10
+
+// secretRefsPerColumn is how many row references are kept per detector and column; later
+// matches are only counted
+const secretRefsPerColumn = 10
+
+// secretDetector finds one kind of sensitive value. validate, when set, rejects matches the
+// pattern alone cannot rule out.
+type secretDetector struct {
+    name     string
+    pattern  *regexp.Regexp
+    validate func(string) bool
+}
+
+// builtinSecretDetectors are used unless --secret-detectors names a file
+var builtinSecretDetectors = []secretDetector{
+    {"credit_card", regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), luhnValid},
+    {"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`), nil},
+    {"bcrypt_hash", regexp.MustCompile(`\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}`), nil},
+    {"md5_hash", regexp.MustCompile(`\b[a-fA-F0-9]{32}\b`), nil},
+    {"mysql_native_hash", regexp.MustCompile(`\*[0-9A-F]{40}\b`), nil},
+    {"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), nil},
+    {"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), nil},
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum used by card numbers
+func luhnValid(s string) bool {
+    sum, n := 0, 0
+    for i := len(s) - 1; i >= 0; i-- {
+        c := s[i]
+        if c < '0' || c > '9' {
+            continue
+        }
+        d := int(c - '0')
+        if n%2 == 1 {
+            d *= 2
+            if d > 9 {
+                d -= 9
+            }
+        }
+        sum += d
+        n++
+    }
+    return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// loadSecretDetectors reads detectors from a file of "name regex" lines. Blank lines and lines
+// starting with # are skipped.
+func loadSecretDetectors(filename string) ([]secretDetector, error) {
+    file, err := os.Open(filename)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    var detectors []secretDetector
+    lineNum := 0
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        lineNum++
+        line := strings.TrimSpace(scanner.Text())
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        name, expr, ok := strings.Cut(line, " ")
+        if !ok {
+            name, expr, ok = strings.Cut(line, "\t")
+        }
+        expr = strings.TrimSpace(expr)
+        if !ok || expr == "" {
+            return nil, fmt.Errorf("%s:%d: expected 'name regex'", filename, lineNum)
+        }
+        pattern, err := regexp.Compile(expr)
+        if err != nil {
+            return nil, fmt.Errorf("%s:%d: %v", filename, lineNum, err)
+        }
+        detector := secretDetector{name: name, pattern: pattern}
+        if name == "credit_card" {
+            // Keep the checksum for custom card patterns too
+            detector.validate = luhnValid
+        }
+        detectors = append(detectors, detector)
+    }
+    return detectors, scanner.Err()
+}
+
+// secretDetectors returns the detectors for --find-secrets
+func (e *Engine) secretDetectors() ([]secretDetector, error) {
+    if e.opts.SecretDetectors == "" {
+        return builtinSecretDetectors, nil
+    }
+    return loadSecretDetectors(e.opts.SecretDetectors)
+}
+
+// secretColumn is what one detector found in one column
+type secretColumn struct {
+    detector string
+    database string
+    table    string
+    column   string
+    count    int
+    refs     []string
+}
+
+// secretScanner checks dumped rows against the detectors, keeping row references per column
+type secretScanner struct {
+    engine    *Engine
+    detectors []secretDetector
+    found     map[string]*secretColumn
+    order     []string
+
+    database string
+    table    string
+    columns  []string
+    row      int
+}
+
+// newSecretScanner creates a scanner using detectors
+func (e *Engine) newSecretScanner(detectors []secretDetector) *secretScanner {
+    return &secretScanner{engine: e, detectors: detectors, found: make(map[string]*secretColumn)}
+}
+
+// startTable resets the row numbering for the next table
+func (s *secretScanner) startTable(database, table string, columns []string) {
+    s.database, s.table, s.columns, s.row = database, table, columns, 0
+}
+
+// scanRow checks every value of the next row of the current table
+func (s *secretScanner) scanRow(values []interface{}) {
+    s.row++
+    for i, val := range values {
+        var text string
+        switch v := val.(type) {
+        case nil:
+            continue
+        case []byte:
+            text = string(v[:min(len(v), secretScanLimit)])
+        case string:
+            text = v[:min(len(v), secretScanLimit)]
+        default:
+            text = fmt.Sprint(v)
+        }
+        for _, d := range s.detectors {
+            for _, match := range d.pattern.FindAllString(text, -1) {
+                if d.validate != nil && !d.validate(match) {
+                    continue
+                }
+                s.record(d.name, s.columns[i], match)
+            }
+        }
+    }
+}
+
+// record counts a match and keeps a redacted reference to it while under the limit
+func (s *secretScanner) record(detector, column, match string) {
+    key := strings.Join([]string{detector, s.database, s.table, column}, "\x00")
+    col, ok := s.found[key]
+    if !ok {
+        col = &secretColumn{detector: detector, database: s.database, table: s.table, column: column}
+        s.found[key] = col
+        s.order = append(s.order, key)
+    }
+    col.count++
+    if len(col.refs) < secretRefsPerColumn {
+        col.refs = append(col.refs, fmt.Sprintf("row %d: %s", s.row, redactSecret(match)))
+    }
+}
+
+// redactSecret keeps enough of a match to recognize it without copying the secret into reports
+func redactSecret(s string) string {
+    runes := []rune(s)
+    if len(runes) <= 8 {
+        return strings.Repeat("*", len(runes))
+    }
+    return string(runes[:4]) + strings.Repeat("*", len(runes)-8) + string(runes[len(runes)-4:])
+}
+
+// matches returns the total number of matches
+func (s *secretScanner) matches() int {
+    total := 0
+    for _, col := range s.found {
+        total += col.count
+    }
+    return total
+}
+
+// writeReport writes the findings, grouped by detector, to secrets_report.txt under dir and
+// emits one record per column. It returns the report's path.
+func (s *secretScanner) writeReport(dir string) (string, error) {
+    path := s.engine.dumpFileName(filepath.Join(dir, "secrets_report.txt"))
+    file, err := s.engine.createDumpFile(path)
+    if err != nil {
+        return "", err
+    }
+    defer file.Close()
+
+    keys := append([]string(nil), s.order...)
+    sort.SliceStable(keys, func(i, j int) bool {
+        return s.found[keys[i]].detector < s.found[keys[j]].detector
+    })
+
+    columns := make(map[string]bool)
+    for _, col := range s.found {
+        columns[col.database+"."+col.table+"."+col.column] = true
+    }
+
+    w := bufio.NewWriter(file)
+    fmt.Fprintf(w, "Sensitive data found in dump of %s:%d\n", s.engine.opts.Host, s.engine.opts.Port)
+    fmt.Fprintf(w, "%d matches in %d columns\n", s.matches(), len(columns))
+    detector := ""
+    for _, key := range keys {
+        col := s.found[key]
+        if col.detector != detector {
+            detector = col.detector
+            fmt.Fprintf(w, "\n[%s]\n", detector)
+        }
+        fmt.Fprintf(w, "  %s.%s.%s: %d matches\n", col.database, col.table, col.column, col.count)
+        for _, ref := range col.refs {
+            fmt.Fprintf(w, "    %s\n", ref)
+        }
+        if col.count > len(col.refs) {
+            fmt.Fprintf(w, "    ... %d more\n", col.count-len(col.refs))
+        }
+        s.engine.emitRecord("secret", map[string]interface{}{"detector": col.detector, "database": col.database, "table": col.table,
+            "column": col.column, "matches": col.count, "refs": col.refs})
+    }
+    if err := w.Flush(); err != nil {
+        return "", err
+    }
+    return path, file.Close()
+}
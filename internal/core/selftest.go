@@ -0,0 +1,299 @@
+package core
+
+import (
+    "bytes"
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// Credentials and identity of the self-test's fake server. Outputs name the target as
+// fake.test:3306 whatever port the server actually listens on, so golden files stay stable.
+const (
+    selftestUser    = "selftest"
+    selftestPass    = "s3lf'test\\pass"
+    selftestHost    = "fake.test"
+    selftestPort    = 3306
+    selftestVersion = "8.0.36-fake"
+)
+
+// timeNormalizers blank out run times in JSON records and JUnit reports
+var timeNormalizers = []normalizer{
+    {regexp.MustCompile(`"time":"[^"]*"`), `"time":"TIME"`},
+    {regexp.MustCompile(`(time|timestamp)="[^"]*"`), `$1="TIME"`},
+}
+
+// runSelftest implements "sqlblaster selftest [--update] [--golden-dir dir]": it runs the
+// output formats against a scripted fake server and fixed findings, comparing every file with
+// its golden copy
+func (e *Engine) runSelftest(args []string) {
+    // Start from the defaults before parsing, so -v is not reset along with them
+    *e.opts = defaultOptions()
+    fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+    update := fs.Bool("update", false, "Record the current outputs as the new golden files")
+    dir := fs.String("golden-dir", goldenDir, "Directory holding the golden files")
+    fs.BoolVar(&e.opts.Verbose, "v", false, "Enable verbose mode")
+    fs.Parse(args)
+
+    g := &goldenSet{dir: *dir, update: *update}
+    if err := e.selftest(g); err != nil {
+        e.printError("Error starting fake server: %v", err)
+        os.Exit(1)
+    }
+    if len(g.failed) > 0 {
+        e.printError("%d self-test check(s) failed", len(g.failed))
+        os.Exit(1)
+    }
+    if !*update {
+        e.printSuccess("All self-test checks passed")
+    }
+}
+
+// selftest runs every check against a fresh fake server, recording the results in g. It only
+// fails when the server cannot be started.
+func (e *Engine) selftest(g *goldenSet) error {
+    e.opts.Host, e.opts.Port = selftestHost, selftestPort
+    e.opts.SingleUser = selftestUser
+
+    server, err := newFakeServer(selftestVersion)
+    if err != nil {
+        return err
+    }
+    defer server.close()
+    server.addUser(selftestUser, selftestPass)
+    scriptFixtures(server)
+
+    ctx := context.Background()
+    selftestLogin(ctx, g, server)
+    for _, format := range dumpFormats {
+        e.selftestDump(ctx, g, server, format)
+    }
+    e.selftestReports(g)
+    e.selftestRecords(g)
+    return nil
+}
+
+// scriptFixtures loads the fake server with a database whose tables cover the values dumps get
+// wrong most easily: NULLs in every column type, binary data with NUL and ^Z bytes, escapes,
+// multi-byte unicode, empty strings versus NULL, and a table without rows
+func scriptFixtures(s *fakeServer) {
+    s.reply(`^SELECT VERSION\(\)$`, &fakeResult{
+        columns: []fakeColumn{{name: "VERSION()", typ: fakeTypeVarchar}},
+        rows:    [][]interface{}{{selftestVersion}},
+    })
+    s.reply(`^SHOW DATABASES$`, &fakeResult{
+        columns: []fakeColumn{{name: "Database", typ: fakeTypeVarchar}},
+        rows:    [][]interface{}{{"information_schema"}, {"shop"}},
+    })
+    s.reply("^SHOW TABLES FROM `shop`$", &fakeResult{
+        columns: []fakeColumn{{name: "Tables_in_shop", typ: fakeTypeVarchar}},
+        rows:    [][]interface{}{{"odd_cases"}, {"empty_table"}},
+    })
+
+    createColumns := []fakeColumn{{name: "Table", typ: fakeTypeVarchar}, {name: "Create Table", typ: fakeTypeVarchar}}
+    s.reply("^SHOW CREATE TABLE `shop`.`odd_cases`$", &fakeResult{
+        columns: createColumns,
+        rows: [][]interface{}{{"odd_cases", "CREATE TABLE `odd_cases` (\n" +
+            "  `id` int NOT NULL,\n" +
+            "  `name` varchar(64) DEFAULT NULL,\n" +
+            "  `note` text,\n" +
+            "  `payload` blob,\n" +
+            "  `price` decimal(10,2) DEFAULT NULL,\n" +
+            "  `big` bigint unsigned DEFAULT NULL,\n" +
+            "  `ratio` double DEFAULT NULL,\n" +
+            "  `created` datetime DEFAULT NULL,\n" +
+            "  PRIMARY KEY (`id`)\n" +
+            ") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+    })
+    s.reply("^SHOW CREATE TABLE `shop`.`empty_table`$", &fakeResult{
+        columns: createColumns,
+        rows: [][]interface{}{{"empty_table", "CREATE TABLE `empty_table` (\n" +
+            "  `id` int NOT NULL\n" +
+            ") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+    })
+
+    s.reply("^SELECT COUNT\\(\\*\\) FROM `odd_cases`$", &fakeResult{
+        columns: []fakeColumn{{name: "COUNT(*)", typ: fakeTypeLongLong}},
+        rows:    [][]interface{}{{"5"}},
+    })
+    // With --dump-batch-size 2 the table is read in three pages keyed on id
+    s.reply("KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = 'shop' AND TABLE_NAME = 'odd_cases'", &fakeResult{
+        columns: []fakeColumn{{name: "COLUMN_NAME", typ: fakeTypeVarchar}},
+        rows:    [][]interface{}{{"id"}},
+    })
+    oddColumns := []fakeColumn{
+        {name: "id", typ: fakeTypeLong},
+        {name: "name", typ: fakeTypeVarchar},
+        {name: "note", typ: fakeTypeBlob},
+        {name: "payload", typ: fakeTypeBlob, flags: fakeFlagBinary, charset: fakeCharsetBinary},
+        {name: "price", typ: fakeTypeDecimal},
+        {name: "big", typ: fakeTypeLongLong, flags: fakeFlagUnsigned},
+        {name: "ratio", typ: fakeTypeDouble},
+        {name: "created", typ: fakeTypeDateTime},
+    }
+    oddRows := [][]interface{}{
+        {"1", "plain", "simple note", []byte{0x00, 0x01, 0x7f, 0xff, 0x1a}, "9.99", "18446744073709551615", "0.5", "2024-01-02 03:04:05"},
+        {"2", "Zoë 🚀 日本語", "line1\nline2\r\n\t\"double\" 'single' back\\slash, comma; semi", []byte{}, "0.00", "0", "-1e-10", "1970-01-01 00:00:00"},
+        {"3", nil, nil, nil, nil, nil, nil, nil},
+        {"4", "", "", []byte("\r\n,;'\""), "-1.50", "42", "3.141592653589793", nil},
+        {"5", "NULL", "\\N", []byte("NULL"), "100.00", "1", "0", "2038-01-19 03:14:07"},
+    }
+    s.reply("^SELECT \\* FROM `odd_cases` ORDER BY `id` LIMIT 2$", &fakeResult{columns: oddColumns, rows: oddRows[0:2]})
+    s.reply("^SELECT \\* FROM `odd_cases` WHERE `id` > 2 ORDER BY `id` LIMIT 2$", &fakeResult{columns: oddColumns, rows: oddRows[2:4]})
+    s.reply("^SELECT \\* FROM `odd_cases` WHERE `id` > 4 ORDER BY `id` LIMIT 2$", &fakeResult{columns: oddColumns, rows: oddRows[4:]})
+    s.reply("^SELECT COUNT\\(\\*\\) FROM `empty_table`$", &fakeResult{
+        columns: []fakeColumn{{name: "COUNT(*)", typ: fakeTypeLongLong}},
+        rows:    [][]interface{}{{"0"}},
+    })
+    // No primary key, so it is paged with LIMIT/OFFSET
+    s.reply("KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = 'shop' AND TABLE_NAME = 'empty_table'", &fakeResult{
+        columns: []fakeColumn{{name: "COLUMN_NAME", typ: fakeTypeVarchar}},
+    })
+    s.reply("^SELECT \\* FROM `empty_table` LIMIT 2 OFFSET 0$", &fakeResult{
+        columns: []fakeColumn{{name: "id", typ: fakeTypeLong}},
+    })
+}
+
+// selftestDSN returns a DSN for the fake server without TLS, which it does not offer
+func selftestDSN(server *fakeServer, user, pass string) string {
+    return fmt.Sprintf("%s:%s@tcp(%s)/", user, pass, server.addr())
+}
+
+// selftestLogin checks that the fake server accepts the scripted credential and rejects others
+// with the errors a real server sends, so the remaining checks exercise a realistic login
+func selftestLogin(ctx context.Context, g *goldenSet, server *fakeServer) {
+    good, _ := sql.Open("mysql", selftestDSN(server, selftestUser, selftestPass))
+    defer good.Close()
+    if err := good.PingContext(ctx); err != nil {
+        g.fail("login/accepted", err)
+    } else {
+        printSuccess("PASS login/accepted")
+    }
+
+    bad, _ := sql.Open("mysql", selftestDSN(server, selftestUser, "wrong"))
+    defer bad.Close()
+    var myErr *mysql.MySQLError
+    switch err := bad.PingContext(ctx); {
+    case err == nil:
+        g.fail("login/rejected", fmt.Errorf("wrong password was accepted"))
+    case !errors.As(err, &myErr) || myErr.Number != 1045 || !isAuthFailure(err):
+        g.fail("login/rejected", fmt.Errorf("unexpected error %v", err))
+    default:
+        printSuccess("PASS login/rejected")
+    }
+}
+
+// selftestDump dumps the fixture database in format through a pinned session, with --max-rows
+// low enough to split a table into parts, and checks every file written except the index,
+// which records the local host name and time
+func (e *Engine) selftestDump(ctx context.Context, g *goldenSet, server *fakeServer, format string) {
+    name := "dump_" + format
+    tmp, err := os.MkdirTemp("", "sqlblaster-selftest-")
+    if err != nil {
+        g.fail(name, err)
+        return
+    }
+    defer os.RemoveAll(tmp)
+
+    e.opts.DumpDir = tmp
+    e.opts.DumpFormat = format
+    e.opts.MaxRowsPerFile = 3
+    e.opts.DumpBatchSize = 2
+    e.opts.QuietDump = true
+    e.opts.KeepAlive = 0
+
+    db, _ := sql.Open("mysql", selftestDSN(server, selftestUser, selftestPass))
+    defer db.Close()
+    sess, err := e.openSession(ctx, db)
+    if err != nil {
+        g.fail(name, err)
+        return
+    }
+    defer sess.close()
+    e.dumpAllDatabases(ctx, sess, backendMySQL)
+
+    var files []string
+    filepath.Walk(tmp, func(path string, info os.FileInfo, err error) error {
+        if err == nil && !info.IsDir() && info.Name() != "dump_index.txt" && info.Name() != manifestName {
+            files = append(files, path)
+        }
+        return nil
+    })
+    sort.Strings(files)
+    if len(files) == 0 {
+        g.fail(name, fmt.Errorf("no files written"))
+        return
+    }
+    for _, path := range files {
+        data, err := os.ReadFile(path)
+        if err != nil {
+            g.fail(name, err)
+            continue
+        }
+        rel, _ := filepath.Rel(tmp, path)
+        g.check(name+"/"+filepath.ToSlash(rel), data)
+    }
+}
+
+// selftestFindings are the fixed successes the report checks are built from
+var selftestFindings = []ResultRecord{
+    {Host: selftestHost, Port: selftestPort, User: "root", Pass: "", FoundAt: "2024-01-02T03:04:05Z", Backend: backendMySQL, Plugin: "mysql_native_password"},
+    {Host: selftestHost, Port: selftestPort, User: "app", Pass: "Summer2024!", FoundAt: "2024-01-02T03:04:06Z", Backend: backendMySQL, Plugin: "caching_sha2_password", Restrictions: "password expired"},
+    {Host: selftestHost, Port: selftestPort, User: "report\"er", Pass: "pässwörd<&>", FoundAt: "2024-01-02T03:04:07Z", Backend: "MariaDB", Plugin: "mysql_native_password"},
+}
+
+// selftestReports checks the SARIF and JUnit reports built from fixed findings
+func (e *Engine) selftestReports(g *goldenSet) {
+    sarif, err := json.MarshalIndent(e.buildSARIF(selftestFindings), "", "  ")
+    if err != nil {
+        g.fail("reports/findings.sarif", err)
+    } else {
+        g.check("reports/findings.sarif", append(sarif, '\n'))
+    }
+
+    tmp, err := os.CreateTemp("", "sqlblaster-selftest-*.xml")
+    if err != nil {
+        g.fail("reports/junit.xml", err)
+        return
+    }
+    tmp.Close()
+    defer os.Remove(tmp.Name())
+    var accounts []ResultRecord
+    for _, user := range []string{"root", "app", "report\"er", "nobody"} {
+        accounts = append(accounts, ResultRecord{Host: selftestHost, Port: selftestPort, User: user})
+    }
+    if _, err := e.exportJUnit(tmp.Name(), accounts, selftestFindings, time.Now()); err != nil {
+        g.fail("reports/junit.xml", err)
+        return
+    }
+    data, err := os.ReadFile(tmp.Name())
+    if err != nil {
+        g.fail("reports/junit.xml", err)
+        return
+    }
+    g.check("reports/junit.xml", data, timeNormalizers...)
+}
+
+// selftestRecords checks the JSON Lines records produced for engine events
+func (e *Engine) selftestRecords(g *goldenSet) {
+    var buf bytes.Buffer
+    rec := NewEngine(e.opts)
+    rec.jsonOutput, rec.jsonOut = true, &buf
+    rec.registerBuiltinHandlers(nil)
+    rec.emit(Event{Type: EventFailure, User: "root", Pass: "toor", Err: errors.New("Error 1045 (28000): Access denied for user 'root'@'10.0.0.5' (using password: YES)")})
+    rec.emit(Event{Type: EventSuccess, User: "app", Pass: "Summer2024!", Backend: backendMySQL,
+        Plugin: "caching_sha2_password", Restrictions: []string{"password expired"}})
+    rec.emit(Event{Type: EventSuccess, User: "zoë", Pass: "pässwörd\t\"<&>\"", Backend: "MariaDB", Plugin: "mysql_native_password"})
+
+    g.check("records/attempts.jsonl", buf.Bytes(), timeNormalizers...)
+}
@@ -0,0 +1,24 @@
+package core
+
+import (
+    "flag"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+var updateGolden = flag.Bool("update", false, "Record the current outputs as the new golden files")
+
+// TestSelftest runs the selftest checks against the golden files committed at the top of the
+// repository, so a change to a dump format, report or JSON record fails go test
+func TestSelftest(t *testing.T) {
+    opts := defaultOptions()
+    e := NewEngine(&opts)
+    g := &goldenSet{dir: filepath.Join("..", "..", goldenDir), update: *updateGolden}
+    if err := e.selftest(g); err != nil {
+        t.Fatalf("starting fake server: %v", err)
+    }
+    if len(g.failed) > 0 {
+        t.Errorf("%d check(s) failed: %s", len(g.failed), strings.Join(g.failed, ", "))
+    }
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "errors"
+    "fmt"
+    "io"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// keepalivePingTimeout bounds each keep-alive ping
+const keepalivePingTimeout = 5 * time.Second
+
+// session is one pinned server connection for interactive mode and dumps. It pings the server
+// while idle so wait_timeout does not close it, and transparently reconnects when the connection
+// is lost, restoring the current database and the session variables set on it.
+type session struct {
+    engine *Engine
+    db     *sql.DB
+    conn   *sql.Conn
+
+    // mu is held while a statement (and its rows) use the connection, keeping pings off the wire
+    mu        sync.Mutex
+    currentDB string
+    setStmts  []string
+    stop      context.CancelFunc
+}
+
+// sessionRows releases the session when the rows are closed
+type sessionRows struct {
+    *sql.Rows
+    s    *session
+    once sync.Once
+}
+
+// Close closes the rows and lets the session run the next statement
+func (r *sessionRows) Close() error {
+    err := r.Rows.Close()
+    r.once.Do(r.s.mu.Unlock)
+    return err
+}
+
+// openSession pins a connection from db, warming it up with a ping, and starts the keep-alive
+// pings when --keepalive is set
+func (e *Engine) openSession(ctx context.Context, db *sql.DB) (*session, error) {
+    conn, err := db.Conn(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if err := conn.PingContext(ctx); err != nil {
+        conn.Close()
+        return nil, err
+    }
+
+    s := &session{engine: e, db: db, conn: conn}
+    if e.opts.KeepAlive > 0 {
+        kaCtx, stop := context.WithCancel(ctx)
+        s.stop = stop
+        go s.keepalive(kaCtx, time.Duration(e.opts.KeepAlive)*time.Second)
+    }
+    return s, nil
+}
+
+// close stops the keep-alive pings and returns the connection
+func (s *session) close() {
+    if s.stop != nil {
+        s.stop()
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.conn.Close()
+}
+
+// keepalive pings the server every interval while no statement is running
+func (s *session) keepalive(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+        if !s.mu.TryLock() {
+            continue
+        }
+        pingCtx, cancel := context.WithTimeout(ctx, keepalivePingTimeout)
+        err := s.conn.PingContext(pingCtx)
+        if connectionLost(err) {
+            s.engine.verbosePrintln("Keep-alive ping failed, reconnecting:", err)
+            if err := s.reconnect(pingCtx); err != nil {
+                s.engine.verbosePrintln("Reconnect failed:", err)
+            }
+        }
+        cancel()
+        s.mu.Unlock()
+    }
+}
+
+// connectionLost reports whether err means the connection itself is gone
+func connectionLost(err error) bool {
+    return err != nil && (errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+        errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF))
+}
+
+// reconnect replaces the lost connection and restores the database and session variables.
+// The caller holds s.mu.
+func (s *session) reconnect(ctx context.Context) error {
+    s.conn.Close()
+    conn, err := s.db.Conn(ctx)
+    if err != nil {
+        return err
+    }
+    s.conn = conn
+
+    if s.currentDB != "" {
+        if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE `%s`", strings.ReplaceAll(s.currentDB, "`", "``"))); err != nil {
+            return fmt.Errorf("restoring database %s: %v", s.currentDB, err)
+        }
+    }
+    for _, stmt := range s.setStmts {
+        if _, err := conn.ExecContext(ctx, stmt); err != nil {
+            s.engine.printWarning("Could not restore session setting %q: %v", stmt, err)
+        }
+    }
+    s.engine.verbosePrintf("Reconnected, restored database '%s' and %d session setting(s)\n", s.currentDB, len(s.setStmts))
+    return nil
+}
+
+// run executes fn on the connection, reconnecting when it was lost. Statements that never
+// reached the server are retried on the new connection; others are reported, since they may
+// have run.
+func (s *session) run(ctx context.Context, fn func(*sql.Conn) error) error {
+    err := fn(s.conn)
+    if !connectionLost(err) {
+        return err
+    }
+
+    s.engine.printWarning("Connection lost (%v), reconnecting...", err)
+    if rerr := s.reconnect(ctx); rerr != nil {
+        return fmt.Errorf("%v; reconnect failed: %v", err, rerr)
+    }
+    if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+        return fn(s.conn)
+    }
+    return fmt.Errorf("%w (reconnected, statement not retried)", err)
+}
+
+// exec runs a statement, remembering USE and session SET statements for reconnects
+func (s *session) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    var result sql.Result
+    err := s.run(ctx, func(conn *sql.Conn) error {
+        var err error
+        result, err = conn.ExecContext(ctx, query, args...)
+        return err
+    })
+    // A prepared statement's placeholders cannot be replayed without its arguments
+    if err == nil && len(args) == 0 {
+        s.track(query)
+    }
+    return result, err
+}
+
+// queryRow runs a single-row query and scans it into dest
+func (s *session) queryRow(ctx context.Context, query string, dest ...interface{}) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    return s.run(ctx, func(conn *sql.Conn) error {
+        return conn.QueryRowContext(ctx, query).Scan(dest...)
+    })
+}
+
+// query runs a query; the session stays busy until the returned rows are closed
+func (s *session) query(ctx context.Context, query string, args ...interface{}) (*sessionRows, error) {
+    s.mu.Lock()
+
+    var rows *sql.Rows
+    err := s.run(ctx, func(conn *sql.Conn) error {
+        var err error
+        rows, err = conn.QueryContext(ctx, query, args...)
+        return err
+    })
+    if err != nil {
+        s.mu.Unlock()
+        return nil, err
+    }
+    return &sessionRows{Rows: rows, s: s}, nil
+}
+
+// track records statements that change session state, so reconnect can replay them
+func (s *session) track(query string) {
+    stmt := strings.TrimSuffix(strings.TrimSpace(query), ";")
+    upper := strings.ToUpper(stmt)
+    switch {
+    case strings.HasPrefix(upper, "USE "):
+        s.currentDB = strings.Trim(strings.TrimSpace(stmt[4:]), "`'\"")
+    case strings.HasPrefix(upper, "SET "):
+        // Global, persisted and password changes do not belong to the session
+        for _, skip := range []string{"GLOBAL", "PERSIST", "PASSWORD", "@@GLOBAL."} {
+            if strings.Contains(upper, skip) {
+                return
+            }
+        }
+        s.setStmts = append(s.setStmts, stmt)
+    }
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "net/url"
+    "regexp"
+    "strings"
+)
+
+// defaultSessionVars keep enumeration and dumps consistent across servers: a permissive SQL mode
+// without ANSI_QUOTES or ONLY_FULL_GROUP_BY, generous network timeouts for slow dump clients, no
+// server-side statement cap (MySQL and MariaDB spell it differently) and untruncated GROUP_CONCAT
+const defaultSessionVars = "sql_mode='NO_ENGINE_SUBSTITUTION',net_read_timeout=600,net_write_timeout=600," +
+    "max_execution_time=0,max_statement_time=0,group_concat_max_len=1048576"
+
+// sessionVarName matches the system variable names --session-vars accepts
+var sessionVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// sessionVar is one name=value pair from --session-vars, with the value as SQL
+type sessionVar struct {
+    name  string
+    value string
+}
+
+// parseSessionVars parses a comma-separated name=value list, allowing commas inside quoted values
+func parseSessionVars(list string) ([]sessionVar, error) {
+    if strings.EqualFold(strings.TrimSpace(list), "none") {
+        return nil, nil
+    }
+
+    var parts []string
+    var current strings.Builder
+    var quote rune
+    for _, r := range list {
+        switch {
+        case quote != 0:
+            if r == quote {
+                quote = 0
+            }
+        case r == '\'' || r == '"':
+            quote = r
+        case r == ',':
+            parts = append(parts, current.String())
+            current.Reset()
+            continue
+        }
+        current.WriteRune(r)
+    }
+    if quote != 0 {
+        return nil, fmt.Errorf("unterminated quote in '%s'", list)
+    }
+    parts = append(parts, current.String())
+
+    var vars []sessionVar
+    for _, part := range parts {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        name, value, ok := strings.Cut(part, "=")
+        name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+        if !ok || value == "" {
+            return nil, fmt.Errorf("'%s' is not name=value", part)
+        }
+        if !sessionVarName.MatchString(name) {
+            return nil, fmt.Errorf("invalid variable name '%s'", name)
+        }
+        vars = append(vars, sessionVar{name: name, value: value})
+    }
+    return vars, nil
+}
+
+// setupSessionVars parses --session-vars into the variables set on every post-login connection,
+// none with --session-vars none
+func (e *Engine) setupSessionVars(list string) error {
+    vars, err := parseSessionVars(list)
+    if err != nil {
+        return err
+    }
+    e.sessionVars = vars
+    return nil
+}
+
+// sessionVarParams sets each session variable on one connection of db and returns the accepted
+// ones as DSN parameters, so every later connection starts with them. Variables the server
+// rejects (unknown on the backend, or not permitted) are skipped. With --read-only the session's
+// transactions are made read-only as well.
+func (e *Engine) sessionVarParams(ctx context.Context, db *sql.DB) string {
+    if len(e.sessionVars) == 0 && !e.opts.ReadOnly {
+        return ""
+    }
+    conn, err := db.Conn(ctx)
+    if err != nil {
+        e.verbosePrintln("Session variables not set:", err)
+        return ""
+    }
+    defer conn.Close()
+
+    var params strings.Builder
+    for _, v := range e.sessionVars {
+        if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET SESSION %s = %s", v.name, v.value)); err != nil {
+            e.verbosePrintf("Skipping session variable %s: %v\n", v.name, err)
+            continue
+        }
+        params.WriteString("&" + v.name + "=" + url.QueryEscape(v.value))
+    }
+    if e.opts.ReadOnly {
+        params.WriteString(e.readOnlySession(ctx, conn))
+    }
+    return params.String()
+}
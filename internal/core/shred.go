@@ -0,0 +1,252 @@
+package core
+
+import (
+    "bufio"
+    "crypto/rand"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "slices"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// parseRetention parses a --shred-after period such as "30d", "2w" or "12h"
+func parseRetention(s string) (time.Duration, error) {
+    s = strings.TrimSpace(s)
+    unit := time.Duration(0)
+    switch {
+    case strings.HasSuffix(s, "d"):
+        unit = 24 * time.Hour
+    case strings.HasSuffix(s, "w"):
+        unit = 7 * 24 * time.Hour
+    }
+    var d time.Duration
+    if unit != 0 {
+        n, err := strconv.Atoi(s[:len(s)-1])
+        if err != nil {
+            return 0, fmt.Errorf("invalid period '%s': use e.g. 30d, 2w or 12h", s)
+        }
+        d = time.Duration(n) * unit
+    } else {
+        var err error
+        if d, err = time.ParseDuration(s); err != nil {
+            return 0, fmt.Errorf("invalid period '%s': use e.g. 30d, 2w or 12h", s)
+        }
+    }
+    if d <= 0 {
+        return 0, fmt.Errorf("invalid period '%s': must be positive", s)
+    }
+    return d, nil
+}
+
+// makeRunDir creates dir like os.MkdirAll and records the outermost directory it had to create,
+// so shred removes what the run made and never a directory that was already there, such as
+// --dump-dir . or a shared loot directory
+func (e *Engine) makeRunDir(dir string, perm os.FileMode) error {
+    created := ""
+    for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+        if _, err := os.Lstat(d); err == nil || filepath.Dir(d) == d {
+            break
+        }
+        created = d
+    }
+    if err := os.MkdirAll(dir, perm); err != nil {
+        return err
+    }
+    if created != "" {
+        e.noteCreated(created)
+    }
+    return nil
+}
+
+// noteCreated records a file or directory the run wrote, unless it lies under one already recorded
+func (e *Engine) noteCreated(path string) {
+    abs, err := filepath.Abs(path)
+    if err != nil {
+        return
+    }
+    e.createdMu.Lock()
+    defer e.createdMu.Unlock()
+    for _, p := range e.createdPaths {
+        if abs == p || strings.HasPrefix(abs, p+string(filepath.Separator)) {
+            return
+        }
+    }
+    e.createdPaths = append(e.createdPaths, abs)
+}
+
+// campaignArtifacts lists the files and directories a run wrote outside its campaign directory,
+// such as an explicit --log-file, --results-db, --dump-dir or --loot-dir, so shred can find
+// them later. Directories are only listed when the run created them.
+func (e *Engine) campaignArtifacts() []string {
+    candidates := []string{e.opts.LogFile, e.opts.ResultsDB, e.opts.SARIFFile, e.opts.JUnitFile, e.opts.InventoryFile}
+    e.createdMu.Lock()
+    candidates = append(candidates, e.createdPaths...)
+    e.createdMu.Unlock()
+
+    var paths []string
+    for _, path := range candidates {
+        if path == "" {
+            continue
+        }
+        rel, err := filepath.Rel(e.campaignDir, path)
+        if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+            if abs, err := filepath.Abs(path); err == nil && !slices.Contains(paths, abs) {
+                paths = append(paths, abs)
+            }
+        }
+    }
+    return paths
+}
+
+// shredFile overwrites a file with random data and flushes it to disk before removing it.
+// Copy-on-write filesystems and SSDs may keep the old blocks regardless.
+func shredFile(path string) (int64, error) {
+    info, err := os.Lstat(path)
+    if err != nil {
+        return 0, err
+    }
+    // Remove links without touching what they point to
+    if !info.Mode().IsRegular() {
+        return 0, os.Remove(path)
+    }
+
+    file, err := os.OpenFile(path, os.O_WRONLY, 0)
+    if err != nil {
+        return 0, err
+    }
+    _, err = io.CopyN(file, rand.Reader, info.Size())
+    if err == nil {
+        err = file.Sync()
+    }
+    if closeErr := file.Close(); err == nil {
+        err = closeErr
+    }
+    if err != nil {
+        return 0, err
+    }
+    return info.Size(), os.Remove(path)
+}
+
+// shredPath shreds a file, or every file under a directory and then the directory itself
+func shredPath(root string) (files int, size int64, err error) {
+    err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return err
+        }
+        if d.IsDir() {
+            return nil
+        }
+        n, err := shredFile(path)
+        if err != nil {
+            return err
+        }
+        files++
+        size += n
+        return nil
+    })
+    if err == nil {
+        err = os.RemoveAll(root)
+    }
+    return files, size, err
+}
+
+// campaignShredPaths returns what shredding a campaign deletes: its directory and the artifacts
+// its runs recorded outside it that still exist
+func campaignShredPaths(name string, c *Campaign) []string {
+    paths := []string{filepath.Join(campaignRoot, name)}
+    for _, p := range c.Artifacts {
+        if _, err := os.Lstat(p); err == nil {
+            paths = append(paths, p)
+        }
+    }
+    return paths
+}
+
+// runShred implements "sqlblaster shred --campaign <name>" and "sqlblaster shred --expired",
+// which overwrite and delete a campaign's results, logs, dumps, loot and reports
+func runShred(args []string) {
+    flags := flag.NewFlagSet("shred", flag.ExitOnError)
+    name := flags.String("campaign", "", "Campaign to shred")
+    expired := flags.Bool("expired", false, "Shred every campaign whose --shred-after period has passed")
+    yes := flags.Bool("yes", false, "Do not ask for confirmation")
+    flags.Parse(args)
+
+    if (*name == "") == !*expired || flags.NArg() > 0 {
+        printError("Error: shred requires either --campaign <name> or --expired.")
+        fmt.Println("Usage: sqlblaster shred --campaign <name> [--yes]")
+        fmt.Println("       sqlblaster shred --expired [--yes]")
+        os.Exit(1)
+    }
+
+    var names []string
+    if *name != "" {
+        if !validCampaignName(*name) {
+            printError("Error: invalid campaign name '%s'", *name)
+            os.Exit(1)
+        }
+        names = []string{*name}
+    } else {
+        entries, err := os.ReadDir(campaignRoot)
+        if err != nil && !os.IsNotExist(err) {
+            printError("Error: %v", err)
+            os.Exit(1)
+        }
+        for _, entry := range entries {
+            c, err := loadCampaign(filepath.Join(campaignRoot, entry.Name()))
+            if err == nil && c.ShredBy != nil && time.Now().After(*c.ShredBy) {
+                names = append(names, entry.Name())
+            }
+        }
+        if len(names) == 0 {
+            fmt.Println("No campaigns past their shred date")
+            return
+        }
+    }
+
+    reader := bufio.NewReader(os.Stdin)
+    failed := false
+    for _, campaign := range names {
+        c, err := loadCampaign(filepath.Join(campaignRoot, campaign))
+        if err != nil {
+            printError("Error: campaign '%s' not found: %v", campaign, err)
+            failed = true
+            continue
+        }
+        paths := campaignShredPaths(campaign, c)
+        fmt.Printf("Campaign %s will be overwritten and deleted:\n", campaign)
+        for _, p := range paths {
+            fmt.Printf("  %s\n", p)
+        }
+        if !*yes && !askBool(reader, "Shred these files", false) {
+            fmt.Println("Skipped", campaign)
+            continue
+        }
+
+        total, size := 0, int64(0)
+        var errs []error
+        for _, p := range paths {
+            files, written, err := shredPath(p)
+            total += files
+            size += written
+            if err != nil {
+                errs = append(errs, err)
+            }
+        }
+        if err := errors.Join(errs...); err != nil {
+            printError("Error shredding campaign %s: %v", campaign, err)
+            failed = true
+            continue
+        }
+        printSuccess("Shredded campaign %s: %d file(s), %d bytes", campaign, total, size)
+    }
+    if failed {
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+    "os"
+    "path/filepath"
+    "slices"
+    "testing"
+)
+
+// TestCampaignArtifactsOnlyCreatedPaths checks that a run writing into a directory that already
+// existed, such as --dump-dir ., records what it created there and not the directory itself
+func TestCampaignArtifactsOnlyCreatedPaths(t *testing.T) {
+    dir := t.TempDir()
+    shared := filepath.Join(dir, "shared")
+    if err := os.MkdirAll(shared, 0755); err != nil {
+        t.Fatal(err)
+    }
+    opts := DefaultOptions()
+    e := NewEngine(&opts)
+    e.campaignDir = filepath.Join(dir, "campaigns", "acme")
+
+    dbDir := filepath.Join(shared, "shop")
+    if err := e.makeRunDir(dbDir, 0755); err != nil {
+        t.Fatal(err)
+    }
+    e.noteCreated(filepath.Join(dbDir, "users.csv"))
+    index := filepath.Join(shared, "dump_index.txt")
+    e.noteCreated(index)
+    loot := filepath.Join(dir, "loot")
+    e.writeLoot(filepath.Join(loot, "db1_3306"), nil, nil)
+
+    artifacts := e.campaignArtifacts()
+    for _, want := range []string{dbDir, index, loot} {
+        if !slices.Contains(artifacts, want) {
+            t.Errorf("%s is not among the artifacts %v", want, artifacts)
+        }
+    }
+    for _, unwanted := range []string{shared, filepath.Join(dbDir, "users.csv")} {
+        if slices.Contains(artifacts, unwanted) {
+            t.Errorf("%s is among the artifacts %v", unwanted, artifacts)
+        }
+    }
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+    "context"
+    "time"
+)
+
+// sprayPollInterval is how often the producer checks whether a spray round has finished
+const sprayPollInterval = 200 * time.Millisecond
+
+// reserveUserAttempt counts an attempt against user, reporting false when the user has already
+// reached --max-attempts-per-user and must be skipped
+func (e *Engine) reserveUserAttempt(user string) bool {
+    if e.opts.MaxAttemptsPerUser <= 0 {
+        return true
+    }
+
+    e.userAttemptsMu.Lock()
+    defer e.userAttemptsMu.Unlock()
+    if e.userAttempts[user] >= e.opts.MaxAttemptsPerUser {
+        if !e.userCapNoticed[user] {
+            e.userCapNoticed[user] = true
+            e.verbosePrintf("\nUser '%s' reached --max-attempts-per-user (%d), skipping it\n", user, e.opts.MaxAttemptsPerUser)
+        }
+        return false
+    }
+    e.userAttempts[user]++
+    return true
+}
+
+// finishSprayRound waits until every attempt submitted so far has finished, then pauses for
+// --spray-interval so the server's lockout observation window can reset. It reports false if
+// ctx was cancelled meanwhile.
+func (e *Engine) finishSprayRound(ctx context.Context, stats *runStats, submitted, round int) bool {
+    for stats.finished() < submitted {
+        select {
+        case <-ctx.Done():
+            return false
+        case <-time.After(sprayPollInterval):
+        }
+    }
+
+    wait := time.Duration(e.opts.SprayInterval) * time.Second
+    if wait <= 0 {
+        return true
+    }
+    e.sprayWaiting.Store(true)
+    defer e.sprayWaiting.Store(false)
+    e.printInfo("\nSpray round %d complete, waiting %s before the next password (until %s)",
+        round, wait, time.Now().Add(wait).Format("15:04:05"))
+    select {
+    case <-ctx.Done():
+        return false
+    case <-time.After(wait):
+        return true
+    }
+}
+
+// sprayRounds passes pairs on in order, holding back the first pair of each round until every
+// attempt of the previous round has finished and --spray-interval has passed
+func (e *Engine) sprayRounds(ctx context.Context, pairs <-chan Credential, stats *runStats) <-chan Credential {
+    out := make(chan Credential)
+    go func() {
+        defer close(out)
+        submitted, round := 0, 0
+        for cred := range pairs {
+            if cred.passIdx != round {
+                if round > 0 && !e.finishSprayRound(ctx, stats, submitted, round) {
+                    return
+                }
+                round = cred.passIdx
+                e.verbosePrintf("\nStarting spray round %d\n", round)
+            }
+            select {
+            case <-ctx.Done():
+                return
+            case out <- cred:
+                submitted++
+            }
+        }
+    }()
+    return out
+}
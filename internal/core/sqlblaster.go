@@ -0,0 +1,1616 @@
+package core
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "os/signal"
+    "strings"
+    "syscall"
+    "time"
+
+    _ "github.com/go-sql-driver/mysql"
+)
+
+// Options holds all the options of a run, from flags and the config file
+type Options struct {
+    Host               string            `json:"host"`
+    Port               int               `json:"port"`
+    SingleUser         string            `json:"singleUser"`
+    UserList           string            `json:"userList"`
+    SinglePass         string            `json:"singlePass"`
+    PassList           string            `json:"passList"`
+    Verbose            bool              `json:"verbose"`
+    FirstOnly          bool              `json:"firstOnly"`
+    UserFirst          bool              `json:"userFirst"`
+    ExecCmd            string            `json:"execCmd"`
+    AllowDangerous     bool              `json:"allowDangerous"`
+    LogFile            string            `json:"logFile"`
+    UseSSL             bool              `json:"useSSL"`
+    SkipSSL            bool              `json:"skipSSL"`
+    Workers            int               `json:"workers"`
+    Sources            string            `json:"sources"`
+    MaxConnFraction    float64           `json:"maxConnFraction"`
+    MonitorCreds       string            `json:"monitorCreds"`
+    Enum               bool              `json:"enum"`
+    EnumOutputFile     string            `json:"enumOutputFile"`
+    Dump               bool              `json:"dump"`
+    DumpDir            string            `json:"dumpDir"`
+    QuietDump          bool              `json:"quietDump"`
+    MaxRowsPerFile     int               `json:"maxRowsPerFile"`
+    ResultsDB          string            `json:"resultsDB"`
+    Lang               string            `json:"lang"`
+    Theme              string            `json:"theme"`
+    ThemeColors        map[string]string `json:"themeColors"`
+    SARIFFile          string            `json:"sarifFile"`
+    PushDefectDojo     string            `json:"pushDefectDojo"`
+    PushFaraday        string            `json:"pushFaraday"`
+    APIKey             string            `json:"apiKey"`
+    PushEngagement     string            `json:"pushEngagement"`
+    JUnitFile          string            `json:"junitFile"`
+    DebugCrash         bool              `json:"debugCrash"`
+    MaxMemory          string            `json:"maxMemory"`
+    BatchAuth          int               `json:"batchAuth"`
+    NoTLSResume        bool              `json:"noTLSResume"`
+    Resolver           string            `json:"resolver"`
+    DNSTTL             int               `json:"dnsTTL"`
+    Output             string            `json:"output"`
+    HostsFile          string            `json:"hostsFile"`
+    Rate               float64           `json:"rate"`
+    Delay              int               `json:"delay"`
+    OnSuccess          string            `json:"onSuccess"`
+    HookTimeout        int               `json:"hookTimeout"`
+    OnErrorThreshold   string            `json:"onErrorThreshold"`
+    ErrorThreshold     float64           `json:"errorThreshold"`
+    DumpFormat         string            `json:"dumpFormat"`
+    DumpCompress       bool              `json:"dumpCompress"`
+    LootHashes         bool              `json:"lootHashes"`
+    LootDir            string            `json:"lootDir"`
+    EnumFull           bool              `json:"enumFull"`
+    VerifyQuery        string            `json:"verifyQuery"`
+    DumpInclude        string            `json:"dumpInclude"`
+    DumpExclude        string            `json:"dumpExclude"`
+    Spray              bool              `json:"spray"`
+    SprayInterval      int               `json:"sprayInterval"`
+    MaxAttemptsPerUser int               `json:"maxAttemptsPerUser"`
+    Campaign           string            `json:"campaign"`
+    Mutate             bool              `json:"mutate"`
+    Company            string            `json:"company"`
+    Rules              string            `json:"rules"`
+    AttemptTimeout     string            `json:"attemptTimeout"`
+    ReadTimeout        string            `json:"readTimeout"`
+    WriteTimeout       string            `json:"writeTimeout"`
+    KeepAlive          int               `json:"keepAlive"`
+    ConnectTimeout     string            `json:"connectTimeout"`
+    QueryTimeout       string            `json:"queryTimeout"`
+    SessionVars        string            `json:"sessionVars"`
+    CommonPasswords    string            `json:"commonPasswords"`
+    DeployUDF          bool              `json:"deployUdf"`
+    UDFDir             string            `json:"udfDir"`
+    PrivescCheck       bool              `json:"privescCheck"`
+    InventoryFile      string            `json:"inventoryFile"`
+    VaultPasswordFile  string            `json:"vaultPasswordFile"`
+    FindSecrets        bool              `json:"findSecrets"`
+    SecretDetectors    string            `json:"secretDetectors"`
+    DumpBatchSize      int               `json:"dumpBatchSize"`
+    CredCSV            string            `json:"credCSV"`
+    ConnAttrs          string            `json:"connAttrs"`
+    SummaryOnly        bool              `json:"summaryOnly"`
+    Discover           string            `json:"discover"`
+    DiscoverPorts      string            `json:"discoverPorts"`
+    ProveOnly          bool              `json:"proveOnly"`
+    IPVersion          int               `json:"ipVersion"`
+    Follow             bool              `json:"follow"`
+    TLSCert            string            `json:"tlsCert"`
+    TLSKey             string            `json:"tlsKey"`
+    TLSCA              string            `json:"tlsCA"`
+    Defaults           bool              `json:"defaults"`
+    DefaultsFile       string            `json:"defaultsFile"`
+    ShredAfter         string            `json:"shredAfter"`
+    DataVersion        string            `json:"dataVersion"`
+    SkipKnown          bool              `json:"skipKnown"`
+    Retries            int               `json:"retries"`
+    BlockPause         string            `json:"blockPause"`
+    CSVDelimiter       string            `json:"csvDelimiter"`
+    CSVNullString      string            `json:"csvNullString"`
+    SessionDir         string            `json:"sessionDir"`
+    Pager              string            `json:"pager"`
+    RowLimit           int               `json:"rowLimit"`
+    MaxColWidth        int               `json:"maxColWidth"`
+    ExecParams         bool              `json:"execParams"`
+    Params             []string          `json:"params"`
+    Policy             string            `json:"policy"`
+    AuditLog           string            `json:"auditLog"`
+    ReadOnly           bool              `json:"readOnly"`
+    AuthPlugin         string            `json:"authPlugin"`
+    AllowCleartext     bool              `json:"allowCleartext"`
+    DumpSchemaOnly     bool              `json:"dumpSchemaOnly"`
+    DumpDataOnly       bool              `json:"dumpDataOnly"`
+    DumpWhere          []string          `json:"dumpWhere"`
+    DumpEncrypt        string            `json:"dumpEncrypt"`
+    MaxBytes           string            `json:"maxBytes"`
+    MaxBytesPerTable   string            `json:"maxBytesPerTable"`
+    BlobMode           string            `json:"blobMode"`
+}
+
+// verbosePrintf prints a message if verbose mode is enabled
+func (e *Engine) verbosePrintf(format string, a ...interface{}) {
+    if e.opts.Verbose {
+        fmt.Fprintf(e.out, format, a...)
+    }
+}
+
+// verbosePrintln prints a line if verbose mode is enabled
+func (e *Engine) verbosePrintln(a ...interface{}) {
+    if e.opts.Verbose {
+        fmt.Fprintln(e.out, a...)
+    }
+}
+
+// Main runs the sqlblaster command line with the process arguments
+func Main() {
+    // The flags and the config file fill opts, and every part of the run reads it from the engine
+    opts := &Options{}
+    e := NewEngine(opts)
+
+    // Pick up the message language from the environment until --lang is parsed
+    e.detectLang()
+
+    // Always display the banner at program start, unless stdout is reserved for JSON records
+    if !wantsJSONOutput(os.Args[1:]) {
+        displayBanner()
+    }
+
+    // Dispatch subcommands before regular flag parsing
+    if len(os.Args) > 1 {
+        switch os.Args[1] {
+        case "analyze":
+            e.runAnalyze(os.Args[2:])
+            return
+        case "campaign":
+            e.runCampaignCommand(os.Args[2:])
+            return
+        case "check-proof":
+            runCheckProof(os.Args[2:])
+            return
+        case "shred":
+            runShred(os.Args[2:])
+            return
+        case "verify-dump", "--verify-dump":
+            runVerifyDump(os.Args[2:])
+            return
+        case "update-data":
+            e.runUpdateData(os.Args[2:])
+            return
+        case "compare":
+            if err := e.runCompare(os.Args[2:]); err != nil {
+                e.printError("Error: %v", err)
+                os.Exit(1)
+            }
+            return
+        case "config":
+            e.runConfigCommand(os.Args[2:])
+            return
+        case "init":
+            e.runInitWizard(os.Args[2:])
+            return
+        case "selftest":
+            e.runSelftest(os.Args[2:])
+            return
+        }
+    }
+
+    // Define command-line flags
+    flag.StringVar(&opts.Host, "h", "", "Remote MySQL server address (required)")
+    flag.StringVar(&opts.SingleUser, "u", "", "Single username to test")
+    flag.StringVar(&opts.UserList, "U", "", "File containing usernames, one per line")
+    flag.IntVar(&opts.Port, "port", 3306, "MySQL server port")
+    flag.StringVar(&opts.SinglePass, "p", "", "Single password to test")
+    flag.StringVar(&opts.PassList, "P", "", "File containing passwords, one per line")
+    flag.BoolVar(&opts.Verbose, "v", false, "Enable verbose mode")
+    flag.BoolVar(&opts.FirstOnly, "f", false, "Stop at first successful login")
+    flag.BoolVar(&opts.UserFirst, "user-first", false, "Loop over all usernames before next password")
+
+    // Fix for the -e flag: Define with default value as a separate variable
+    execCmdFlag := flag.String("e", "SHOW DATABASES;", "MySQL command to execute on success")
+
+    flag.BoolVar(&opts.AllowDangerous, "allow-dangerous", false, "Allow dangerous commands")
+
+    var help bool
+    flag.BoolVar(&help, "help", false, "Display help message")
+
+    flag.StringVar(&opts.LogFile, "log-file", "", "Log output to a file")
+    flag.StringVar(&opts.Lang, "lang", "", "Language of run messages, reports and the interactive shell (en, es); help and verbose output stay in English")
+    flag.StringVar(&opts.Theme, "theme", "", "Color theme (default, high-contrast, colorblind, none)")
+
+    var configFile string
+    flag.StringVar(&configFile, "config", "", "Load settings from a JSON config file")
+
+    flag.BoolVar(&opts.UseSSL, "use-ssl", false, "Enable SSL/TLS for MySQL connection")
+    flag.BoolVar(&opts.SkipSSL, "skip-ssl", false, "Skip SSL/TLS entirely (overrides --use-ssl)")
+    flag.IntVar(&opts.Workers, "workers", 10, "Number of concurrent workers")
+    flag.Float64Var(&opts.MaxConnFraction, "max-conn-fraction", 0, "Pause attempts while Threads_connected exceeds this fraction of max_connections (0 to disable)")
+    flag.StringVar(&opts.MonitorCreds, "monitor-creds", "", "user:pass used to poll server status for --max-conn-fraction (default: first success)")
+    flag.StringVar(&opts.Sources, "sources", "", "Comma-separated source IPs or socks5:// proxies to fail over between when the host blocks us")
+
+    var generateConfig bool
+    flag.BoolVar(&generateConfig, "generate-config", false, "Generate a sample config file and exit")
+
+    var resume, listSessions bool
+    var resumeSession string
+    flag.BoolVar(&resume, "resume", false, "Resume from the last tested credentials")
+    flag.StringVar(&resumeSession, "resume-session", "", "Resume the session with this ID, taking its target and wordlists when not given")
+    flag.BoolVar(&listSessions, "list-sessions", false, "List the saved sessions in --session-dir and exit")
+    flag.BoolVar(&e.forceResume, "force-resume", false, "Resume even if the wordlists changed since the state was saved")
+    flag.BoolVar(&e.dryRun, "dry-run", false, "Print the attempts, estimated duration and files of the run without connecting")
+
+    flag.BoolVar(&opts.Enum, "Enum", false, "Enumerate privileges, databases, and tables on success")
+    flag.StringVar(&opts.EnumOutputFile, "enum-output", "", "Save enumeration results to a file")
+
+    flag.BoolVar(&e.connectMode, "connect", false, "Enter interactive mode after successful login")
+
+    var verifyOnly string
+    flag.StringVar(&verifyOnly, "verify-only", "", "Re-validate credentials from a CSV file (auth + SELECT 1) without enumeration")
+    var runNote string
+    flag.StringVar(&runNote, "note", "", "Attach a note to the target (every target with --cred-csv), or with --verify-only to every credential that passes")
+    var fingerprintOnly bool
+    flag.BoolVar(&fingerprintOnly, "fingerprint-only", false, "Fingerprint the server from its handshake greeting and exit without testing credentials")
+
+    // New dump flags
+    flag.BoolVar(&opts.Dump, "dump", false, "Dump all databases and tables to files")
+    flag.StringVar(&opts.DumpDir, "dump-dir", "mysql_dump", "Directory to save dumped data")
+    flag.BoolVar(&opts.QuietDump, "quiet-dump", false, "Only show progress during dump, not actual data")
+    flag.IntVar(&opts.MaxRowsPerFile, "max-rows", 10000, "Maximum rows per dump file (0 for unlimited)")
+
+    flag.StringVar(&opts.ResultsDB, "results-db", "", "Record successful logins to a SQLite results database")
+    flag.StringVar(&opts.SARIFFile, "export-sarif", "", "Export findings to a SARIF file at the end of the run")
+    flag.StringVar(&opts.PushDefectDojo, "push-defectdojo", "", "Upload findings to a DefectDojo instance at the end of the run")
+    flag.StringVar(&opts.PushFaraday, "push-faraday", "", "Upload findings to a Faraday instance at the end of the run")
+    flag.StringVar(&opts.APIKey, "api-key", "", "API key for --push-defectdojo or --push-faraday")
+    flag.StringVar(&opts.PushEngagement, "push-engagement", "", "DefectDojo engagement ID or Faraday workspace to upload to")
+    flag.StringVar(&opts.JUnitFile, "junit", "", "Write a JUnit XML report and exit non-zero if any credential succeeds")
+    flag.BoolVar(&opts.DebugCrash, "debug-crash", false, "Print stack traces for panics recovered in workers")
+    flag.StringVar(&opts.MaxMemory, "max-memory", "", "Spool result formatting and dumps to disk when heap use exceeds this size (e.g. 2GB)")
+    flag.IntVar(&opts.BatchAuth, "batch-auth", 0, "Test up to this many credentials per connection with COM_CHANGE_USER (0 to disable)")
+    flag.BoolVar(&opts.NoTLSResume, "no-tls-resume", false, "Disable TLS session resumption between connections")
+    flag.StringVar(&opts.Resolver, "resolver", "", "DNS server (ip or ip:port) used to resolve the target instead of the system resolver")
+    flag.IntVar(&opts.DNSTTL, "dns-ttl", 300, "Seconds to cache resolved target addresses")
+    flag.StringVar(&opts.Output, "output", "", "Output format: text (default) or json for JSON Lines records on stdout")
+    flag.StringVar(&opts.HostsFile, "hosts-file", "", "/etc/hosts-style file mapping target names to IPs, used only by sqlblaster")
+    flag.Float64Var(&opts.Rate, "rate", 0, "Maximum attempts per second across all workers (0 for unlimited)")
+    flag.IntVar(&opts.Delay, "delay", 0, "Minimum milliseconds between attempts across all workers")
+    flag.StringVar(&opts.OnSuccess, "on-success", "", "Command to run for each success, with {{.Host}} {{.Port}} {{.User}} {{.Pass}} templates")
+    flag.IntVar(&opts.HookTimeout, "hook-timeout", 30, "Seconds before a hook command is killed")
+    flag.StringVar(&opts.OnErrorThreshold, "on-error-threshold", "", "Command to run when the error rate passes --error-threshold or the host is circuit-broken")
+    flag.Float64Var(&opts.ErrorThreshold, "error-threshold", 0.5, "Fraction of attempts in the last minute that must error to fire --on-error-threshold")
+    flag.StringVar(&opts.DumpFormat, "dump-format", "csv", "Dump file format: csv, sql (INSERT statements restorable with 'mysql < file.sql'), jsonl or parquet")
+    flag.BoolVar(&opts.DumpCompress, "dump-compress", false, "Gzip dump files as they are written (.csv.gz / .sql.gz / .jsonl.gz), zstd inside Parquet files")
+    flag.BoolVar(&opts.LootHashes, "loot-hashes", false, "Extract mysql.user password hashes in hashcat (300/7401) and John formats on success")
+    flag.StringVar(&opts.LootDir, "loot-dir", "loot", "Directory to write extracted password hashes to")
+    flag.BoolVar(&opts.EnumFull, "enum-full", false, "List every table during -Enum instead of summarizing databases with more than 50 tables")
+    flag.StringVar(&opts.VerifyQuery, "verify-query", "", "Query that verifies a successful login instead of the default -e command, or 'none' to skip it")
+    flag.StringVar(&opts.DumpInclude, "dump-include", "", "Comma-separated db.table globs or /regex/ patterns of tables to dump (default: all)")
+    flag.StringVar(&opts.DumpExclude, "dump-exclude", "", "Comma-separated db.table globs or /regex/ patterns of tables to skip when dumping")
+    flag.BoolVar(&opts.Spray, "spray", false, "Password spraying: test one password against all users per round, pausing --spray-interval between rounds")
+    flag.IntVar(&opts.SprayInterval, "spray-interval", 1800, "Seconds to wait between spray rounds")
+    flag.IntVar(&opts.MaxAttemptsPerUser, "max-attempts-per-user", 0, "Stop testing a user after this many attempts (0 for unlimited)")
+    flag.StringVar(&opts.Campaign, "campaign", "", "Organize state, results, logs, dumps, loot and reports under campaigns/<name>")
+    flag.BoolVar(&opts.Mutate, "mutate", false, "Expand each password with built-in rules (capitalization, leetspeak, years, common suffixes, --company)")
+    flag.StringVar(&opts.Company, "company", "", "Company name combined with each password by --mutate")
+    flag.StringVar(&opts.Rules, "rules", "", "Hashcat-style rules file applied to each password")
+    flag.StringVar(&opts.AttemptTimeout, "attempt-timeout", "10s", "Time limit for a whole login attempt: dial, TLS and authentication (e.g. 5s)")
+    flag.StringVar(&opts.ReadTimeout, "read-timeout", "", "I/O read timeout for connections, e.g. 30s (default: none)")
+    flag.StringVar(&opts.WriteTimeout, "write-timeout", "", "I/O write timeout for connections, e.g. 30s (default: none)")
+    flag.IntVar(&opts.KeepAlive, "keepalive", 60, "Seconds between keep-alive pings on idle interactive and dump connections (0 to disable)")
+    flag.StringVar(&opts.ConnectTimeout, "connect-timeout", "10s", "TCP connect timeout (e.g. 3s)")
+    flag.StringVar(&opts.QueryTimeout, "query-timeout", "30s", "Time limit for each statement after login, 0 for none (e.g. 2m)")
+    flag.StringVar(&opts.SessionVars, "session-vars", defaultSessionVars, "Session variables set after login as name=value,... ('none' to keep server defaults)")
+    flag.StringVar(&opts.CommonPasswords, "common-passwords", "", "Top-10k password list used to score found passwords (default: built-in list)")
+    flag.BoolVar(&opts.DeployUDF, "deploy-udf", false, "Install the lib_mysqludf_sys sys_exec/sys_eval functions on success (requires --allow-dangerous)")
+    flag.StringVar(&opts.UDFDir, "udf-dir", "udf", "Directory holding lib_mysqludf_sys builds named lib_mysqludf_sys_<os>_<arch>.so/.dll")
+    flag.BoolVar(&opts.PrivescCheck, "privesc-check", false, "Audit privilege escalation paths on success and print a prioritized findings list")
+    flag.StringVar(&opts.InventoryFile, "export-inventory", "", "Export discovered access as an Ansible YAML inventory with vaulted passwords")
+    flag.StringVar(&opts.VaultPasswordFile, "vault-password-file", os.Getenv("ANSIBLE_VAULT_PASSWORD_FILE"), "ansible-vault password file used to encrypt passwords in --export-inventory")
+    flag.BoolVar(&opts.FindSecrets, "find-secrets", false, "Scan dumped rows for card numbers, emails, hashes, AWS keys and JWTs and write secrets_report.txt")
+    flag.StringVar(&opts.SecretDetectors, "secret-detectors", "", "File of 'name regex' lines replacing the built-in --find-secrets detectors")
+    flag.IntVar(&opts.DumpBatchSize, "dump-batch-size", 10000, "Rows read per query while dumping, paged by primary key (0 reads each table in one query)")
+    flag.StringVar(&opts.CredCSV, "cred-csv", "", "Try each host,port,user,pass row of a CSV file as one attempt, across every target it names")
+    flag.StringVar(&opts.ConnAttrs, "conn-attrs", "", "Comma-separated name=value connection attributes sent with every attempt, e.g. program_name=mysql")
+    flag.BoolVar(&opts.SummaryOnly, "summary-only", false, "Print only an end-of-run summary table on stdout; details go to JSON records in the log file")
+    flag.StringVar(&opts.Discover, "discover", "", "Sweep comma-separated hosts and CIDR ranges for MySQL before testing, instead of -h")
+    flag.StringVar(&opts.DiscoverPorts, "discover-ports", defaultDiscoverPorts, "Comma-separated ports swept by --discover")
+    flag.BoolVar(&opts.ProveOnly, "prove-only", false, "On success close the connection at once and keep only a salted hash commitment of the password")
+    flag.IntVar(&opts.IPVersion, "ip-version", 0, "Resolve targets to IPv4 (4) or IPv6 (6) addresses only (0 uses any)")
+    flag.BoolVar(&opts.Follow, "follow", false, "Keep reading -U, -P and --cred-csv files and test lines appended to them until interrupted")
+    flag.StringVar(&opts.TLSCert, "tls-cert", "", "Client certificate (PEM) presented to targets that require mutual TLS")
+    flag.StringVar(&opts.TLSKey, "tls-key", "", "Private key (PEM) of the --tls-cert client certificate")
+    flag.StringVar(&opts.TLSCA, "tls-ca", "", "CA certificates (PEM) that target certificates are verified against")
+    flag.BoolVar(&opts.Defaults, "defaults", false, "Try built-in vendor default credentials before the wordlists, or on their own without -u/-U")
+    flag.StringVar(&opts.DefaultsFile, "defaults-file", "", "Extra user:password default credentials for --defaults, one per line")
+    flag.StringVar(&opts.ShredAfter, "shred-after", "", "Record in the campaign that its files are due for shredding this long after the last run (e.g. 30d)")
+    flag.StringVar(&opts.DataVersion, "data-version", "", "Refuse to run unless this data bundle version (or builtin) is in use")
+    flag.BoolVar(&opts.SkipKnown, "skip-known", false, "Skip accounts already cracked on a target according to the results database or log file")
+    flag.IntVar(&opts.Retries, "retries", 2, "Retry an attempt this many times with exponential backoff when it fails with a network or server error (0 to disable)")
+    flag.StringVar(&opts.BlockPause, "block-pause", "", "When the target blocks every source, pause this long and resume instead of stopping (e.g. 15m)")
+    flag.StringVar(&opts.CSVDelimiter, "csv-delimiter", ",", "Field delimiter for CSV dumps, a single character or 'tab'")
+    flag.StringVar(&opts.CSVNullString, "csv-null-string", "NULL", "Text written for NULL values in CSV dumps")
+    flag.StringVar(&opts.SessionDir, "session-dir", "sessions", "Directory of the --resume state files, one per target and wordlists")
+    flag.StringVar(&opts.Pager, "pager", "", "Command that pages interactive query results, e.g. 'less -S' (default: built-in pager, 'off' to disable)")
+    flag.IntVar(&opts.RowLimit, "row-limit", 1000, "Rows an interactive query fetches before asking whether to read the rest (0 to disable)")
+    flag.IntVar(&opts.MaxColWidth, "max-col-width", 0, "Truncate query result columns wider than this many characters (0: only fit the terminal)")
+    flag.BoolVar(&opts.ExecParams, "exec-params", false, "Run -e as a prepared statement with its ? placeholders bound to the --param values")
+    flag.StringVar(&opts.Policy, "policy", "", "JSON file of verb, function, schema and regex rules deciding which statements are blocked, warned about or confirmed")
+    flag.StringVar(&opts.AuditLog, "audit-log", "", "Append every statement run after login (not the login attempts) to this JSONL file with target, user, duration, rows and outcome")
+    flag.BoolVar(&opts.ReadOnly, "read-only", false, "Never modify the target: allow only reading statements and open every session as READ ONLY where supported")
+    flag.StringVar(&opts.AuthPlugin, "auth-plugin", "", "Force the client auth plugin: mysql_native_password, caching_sha2_password or mysql_clear_password (default: the server's choice)")
+    flag.BoolVar(&opts.AllowCleartext, "allow-cleartext", false, "Allow mysql_clear_password, which sends passwords unencrypted without TLS (PAM/LDAP accounts)")
+    flag.BoolVar(&opts.DumpSchemaOnly, "dump-schema-only", false, "Dump only schema.sql (tables, views, routines, triggers and events), skipping table data")
+    flag.BoolVar(&opts.DumpDataOnly, "dump-data-only", false, "Dump only table data, without schema.sql or CREATE TABLE statements in SQL files")
+    flag.StringVar(&opts.DumpEncrypt, "dump-encrypt", "", "Encrypt dump files with age as they are written: 'age:<recipient>[,<recipient>...]' or 'passphrase:<file>'")
+    flag.StringVar(&opts.MaxBytes, "max-bytes", "", "Stop dumping table data once the dump's files reach this size (e.g. 10GB)")
+    flag.StringVar(&opts.MaxBytesPerTable, "max-bytes-per-table", "", "Truncate a table's dump once its files reach this size (e.g. 500MB)")
+    flag.StringVar(&opts.BlobMode, "blob-mode", "raw", "How CSV and JSON Lines dumps write binary columns: raw, skip, hex, base64 or file (one file per value, referenced from the row)")
+    flag.Var((*paramList)(&opts.DumpWhere), "dump-where", "Dump only the rows of matching tables that satisfy a predicate, as 'db.table:predicate' (glob names, repeatable)")
+    flag.Var((*paramList)(&opts.Params), "param", "Value for the next ? placeholder of -e with --exec-params (repeat in order, \\N for NULL)")
+
+    flag.Parse()
+
+    // Ensure the SQL command doesn't contain flags (sanitize it)
+    opts.ExecCmd = sanitizeCommand(*execCmdFlag)
+
+    // Set up context for graceful shutdown
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    // Set up signal handling
+    sigChan := make(chan os.Signal, 1)
+    signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+    go func() {
+        for sig := range sigChan {
+            // Ctrl-C during an interactive statement stops only that statement
+            if sig == os.Interrupt && e.interruptForeground() {
+                continue
+            }
+            fmt.Println(e.tr("run.shutdown"))
+            cancel()
+            return
+        }
+    }()
+
+    // Generate config file and exit if requested
+    if generateConfig {
+        e.verbosePrintln("Generating sample configuration file")
+        e.createSampleConfig()
+        return
+    }
+
+    // Load config file if specified
+    if configFile != "" {
+        e.verbosePrintln("Loading configuration from", configFile)
+        e.loadConfig(configFile)
+    }
+
+    // Switch the message catalog now that flags and config are known
+    if opts.Lang != "" && !e.setLang(opts.Lang) {
+        printWarning("%s", e.tr("err.unknown_lang", opts.Lang, strings.Join(availableLangs(), ", ")))
+    }
+
+    // Apply the color theme before any further output
+    if err := e.applyTheme(opts.Theme, opts.ThemeColors); err != nil {
+        printError("Error: %v", err)
+        os.Exit(1)
+    }
+
+    // Show help and exit if requested
+    if help {
+        showHelp()
+        return
+    }
+
+    if listSessions {
+        if err := e.listSessions(); err != nil {
+            e.printError("Error listing sessions: %v", err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    // A named session brings back its target and wordlists before anything checks for them
+    if resumeSession != "" {
+        if err := e.restoreSession(resumeSession); err != nil {
+            e.printError("Error: %v", err)
+            os.Exit(1)
+        }
+        resume = true
+    }
+
+    // Display verbose configuration information
+    if opts.Verbose {
+        fmt.Println("Configuration:")
+        fmt.Println("  Host:", opts.Host)
+        fmt.Println("  Port:", opts.Port)
+        if opts.SingleUser != "" {
+            fmt.Println("  Username:", opts.SingleUser)
+        } else {
+            fmt.Println("  Username list:", opts.UserList)
+        }
+        if opts.SinglePass != "" {
+            fmt.Println("  Password:", opts.SinglePass)
+        } else if opts.PassList != "" {
+            fmt.Println("  Password list:", opts.PassList)
+        } else {
+            fmt.Println("  Testing with no password")
+        }
+        fmt.Println("  Workers:", opts.Workers)
+        if opts.Sources != "" {
+            fmt.Println("  Sources:", opts.Sources)
+        }
+        if opts.MaxConnFraction > 0 {
+            fmt.Println("  Max connection fraction:", opts.MaxConnFraction)
+        }
+        fmt.Println("  Execute command:", opts.ExecCmd)
+        fmt.Println("  SSL enabled:", opts.UseSSL)
+        fmt.Println("  SSL skipped:", opts.SkipSSL)
+        fmt.Println("  First match only:", opts.FirstOnly)
+        fmt.Println("  User-first strategy:", opts.UserFirst)
+        fmt.Println("  Allow dangerous commands:", opts.AllowDangerous)
+        fmt.Println("  Enumeration enabled:", opts.Enum)
+        if opts.EnumOutputFile != "" {
+            fmt.Println("  Enumeration output file:", opts.EnumOutputFile)
+        }
+        if opts.LogFile != "" {
+            fmt.Println("  Log file:", opts.LogFile)
+        }
+        if opts.ResultsDB != "" {
+            fmt.Println("  Results database:", opts.ResultsDB)
+        }
+        if opts.SARIFFile != "" {
+            fmt.Println("  SARIF export file:", opts.SARIFFile)
+        }
+        if opts.PushDefectDojo != "" {
+            fmt.Println("  DefectDojo URL:", opts.PushDefectDojo)
+        }
+        if opts.PushFaraday != "" {
+            fmt.Println("  Faraday URL:", opts.PushFaraday)
+        }
+        if opts.PushEngagement != "" {
+            fmt.Println("  Push engagement:", opts.PushEngagement)
+        }
+        if opts.JUnitFile != "" {
+            fmt.Println("  JUnit report file:", opts.JUnitFile)
+        }
+        if opts.Theme != "" {
+            fmt.Println("  Color theme:", opts.Theme)
+        }
+        fmt.Println("  Debug crash traces:", opts.DebugCrash)
+        if opts.MaxMemory != "" {
+            fmt.Println("  Max memory:", opts.MaxMemory)
+        }
+        if opts.BatchAuth > 0 {
+            fmt.Println("  Credentials per batched connection:", opts.BatchAuth)
+        }
+        fmt.Println("  TLS session resumption:", !opts.SkipSSL && !opts.NoTLSResume)
+        if opts.Resolver != "" {
+            fmt.Println("  DNS resolver:", opts.Resolver)
+        }
+        fmt.Println("  DNS cache TTL:", opts.DNSTTL)
+        if opts.Output != "" {
+            fmt.Println("  Output format:", opts.Output)
+        }
+        if opts.HostsFile != "" {
+            fmt.Println("  Hosts file:", opts.HostsFile)
+        }
+        if opts.Rate > 0 {
+            fmt.Println("  Rate limit (attempts/sec):", opts.Rate)
+        }
+        if opts.Delay > 0 {
+            fmt.Println("  Delay between attempts (ms):", opts.Delay)
+        }
+        if opts.OnSuccess != "" {
+            fmt.Println("  On-success command:", opts.OnSuccess)
+        }
+        if opts.OnSuccess != "" || opts.OnErrorThreshold != "" {
+            fmt.Println("  Hook timeout (seconds):", opts.HookTimeout)
+        }
+        if opts.OnErrorThreshold != "" {
+            fmt.Println("  On-error-threshold command:", opts.OnErrorThreshold)
+            fmt.Println("  Error threshold:", opts.ErrorThreshold)
+        }
+        if opts.LootHashes {
+            fmt.Println("  Loot hashes to:", opts.LootDir)
+        }
+        if opts.VerifyQuery != "" {
+            fmt.Println("  Verification query:", opts.VerifyQuery)
+        }
+        if opts.Spray {
+            fmt.Println("  Spray interval (seconds):", opts.SprayInterval)
+        }
+        if opts.MaxAttemptsPerUser > 0 {
+            fmt.Println("  Max attempts per user:", opts.MaxAttemptsPerUser)
+        }
+        if opts.Campaign != "" {
+            fmt.Println("  Campaign:", opts.Campaign)
+        }
+        if opts.Mutate {
+            fmt.Println("  Mutate passwords:", opts.Mutate)
+        }
+        if opts.Company != "" {
+            fmt.Println("  Company name:", opts.Company)
+        }
+        if opts.Rules != "" {
+            fmt.Println("  Rules file:", opts.Rules)
+        }
+        fmt.Println("  Attempt timeout:", opts.AttemptTimeout)
+        if opts.ReadTimeout != "" {
+            fmt.Println("  Read timeout:", opts.ReadTimeout)
+        }
+        if opts.WriteTimeout != "" {
+            fmt.Println("  Write timeout:", opts.WriteTimeout)
+        }
+        fmt.Println("  Keep-alive interval (seconds):", opts.KeepAlive)
+        fmt.Println("  Connect timeout:", opts.ConnectTimeout)
+        fmt.Println("  Query timeout:", opts.QueryTimeout)
+        fmt.Println("  Session variables:", opts.SessionVars)
+        if opts.CommonPasswords != "" {
+            fmt.Println("  Common passwords list:", opts.CommonPasswords)
+        }
+        if opts.DeployUDF {
+            fmt.Println("  Deploy UDF from:", opts.UDFDir)
+        }
+        fmt.Println("  Privilege escalation audit:", opts.PrivescCheck)
+        if opts.InventoryFile != "" {
+            fmt.Println("  Ansible inventory file:", opts.InventoryFile)
+        }
+        if opts.FindSecrets {
+            fmt.Println("  Find secrets in dump:", true)
+        }
+        if opts.CredCSV != "" {
+            fmt.Println("  Credential CSV:", opts.CredCSV)
+        }
+        if opts.ConnAttrs != "" {
+            fmt.Println("  Connection attributes:", opts.ConnAttrs)
+        }
+        if opts.SummaryOnly {
+            fmt.Println("  Summary only:", true)
+        }
+        if opts.Discover != "" {
+            fmt.Println("  Discover:", opts.Discover, "on ports", opts.DiscoverPorts)
+        }
+        if opts.ProveOnly {
+            fmt.Println("  Prove only:", true)
+        }
+        if opts.IPVersion != 0 {
+            fmt.Println("  IP version:", opts.IPVersion)
+        }
+        if opts.Follow {
+            fmt.Println("  Follow:", true)
+        }
+        if opts.TLSCert != "" {
+            fmt.Println("  TLS client certificate:", opts.TLSCert)
+        }
+        if opts.TLSKey != "" {
+            fmt.Println("  TLS client key:", opts.TLSKey)
+        }
+        if opts.TLSCA != "" {
+            fmt.Println("  TLS CA file:", opts.TLSCA)
+        }
+        if opts.Defaults {
+            fmt.Println("  Default credentials:", true)
+        }
+        if opts.DefaultsFile != "" {
+            fmt.Println("  Defaults file:", opts.DefaultsFile)
+        }
+        if opts.ShredAfter != "" {
+            fmt.Println("  Shred after:", opts.ShredAfter)
+        }
+        if opts.DataVersion != "" {
+            fmt.Println("  Pinned data version:", opts.DataVersion)
+        }
+        if opts.SkipKnown {
+            fmt.Println("  Skip known accounts:", true)
+        }
+        fmt.Println("  Retries on transient errors:", opts.Retries)
+        if opts.BlockPause != "" {
+            fmt.Println("  Pause on host block:", opts.BlockPause)
+        }
+        if opts.Pager != "" {
+            fmt.Println("  Pager:", opts.Pager)
+        }
+        fmt.Println("  Interactive row limit:", opts.RowLimit)
+        if opts.MaxColWidth > 0 {
+            fmt.Println("  Max column width:", opts.MaxColWidth)
+        }
+        if opts.ExecParams {
+            fmt.Println("  Prepared statement parameters:", len(opts.Params))
+        }
+        if opts.Policy != "" {
+            fmt.Println("  Statement policy:", opts.Policy)
+        }
+        if opts.AuditLog != "" {
+            fmt.Println("  Audit log:", opts.AuditLog)
+        }
+        fmt.Println("  Read-only mode:", opts.ReadOnly)
+        if opts.AuthPlugin != "" {
+            fmt.Println("  Auth plugin:", opts.AuthPlugin)
+        }
+        fmt.Println("  Allow cleartext passwords:", opts.AllowCleartext)
+        if opts.DumpSchemaOnly {
+            fmt.Println("  Dump: schema only")
+        }
+        if opts.DumpDataOnly {
+            fmt.Println("  Dump: data only")
+        }
+        for _, where := range opts.DumpWhere {
+            fmt.Println("  Dump filter:", where)
+        }
+        if opts.DumpEncrypt != "" {
+            fmt.Println("  Encrypt dump files:", opts.DumpEncrypt)
+        }
+        if opts.MaxBytes != "" {
+            fmt.Println("  Max dump size:", opts.MaxBytes)
+        }
+        if opts.MaxBytesPerTable != "" {
+            fmt.Println("  Max size per table:", opts.MaxBytesPerTable)
+        }
+        if opts.BlobMode != "raw" {
+            fmt.Println("  Binary columns:", opts.BlobMode)
+        }
+        fmt.Println("  Interactive mode:", e.connectMode)
+        if opts.Dump {
+            fmt.Println("  Database dump enabled:", opts.Dump)
+            fmt.Println("  Dump directory:", opts.DumpDir)
+            fmt.Println("  Quiet dump mode:", opts.QuietDump)
+            fmt.Println("  Max rows per file:", opts.MaxRowsPerFile)
+            fmt.Println("  Dump batch size:", opts.DumpBatchSize)
+            fmt.Println("  Dump format:", opts.DumpFormat)
+            if opts.DumpFormat == "csv" {
+                fmt.Printf("  CSV delimiter: %q, NULL as: %q\n", opts.CSVDelimiter, opts.CSVNullString)
+            }
+            fmt.Println("  Compress dump files:", opts.DumpCompress)
+            if opts.DumpInclude != "" {
+                fmt.Println("  Dump include patterns:", opts.DumpInclude)
+            }
+            if opts.DumpExclude != "" {
+                fmt.Println("  Dump exclude patterns:", opts.DumpExclude)
+            }
+        }
+        fmt.Println("")
+    }
+
+    // Replace the built-in datasets with an installed update, unless it fails verification
+    if err := e.loadInstalledData(); err != nil {
+        e.printWarning("Installed data bundle ignored, using built-in data: %v", err)
+    }
+    if opts.DataVersion != "" && e.dataVersions["bundle"] != opts.DataVersion {
+        e.printError("Error: data bundle %s is in use, but --data-version pins %s.", e.dataVersions["bundle"], opts.DataVersion)
+        os.Exit(1)
+    }
+
+    // Validate inputs
+    opts.Host = normalizeHost(opts.Host)
+    if opts.Host == "" && verifyOnly == "" && opts.CredCSV == "" && opts.Discover == "" {
+        e.printError("%s", e.tr("err.host_required"))
+        showHelp()
+        os.Exit(1)
+    }
+    if opts.DefaultsFile != "" {
+        opts.Defaults = true
+    }
+    if opts.SingleUser == "" && opts.UserList == "" && verifyOnly == "" && opts.CredCSV == "" && opts.Discover == "" && !fingerprintOnly && !opts.Defaults {
+        e.printError("%s", e.tr("err.user_required"))
+        showHelp()
+        os.Exit(1)
+    }
+    if opts.SingleUser != "" && opts.UserList != "" {
+        e.printError("%s", e.tr("err.user_exclusive"))
+        showHelp()
+        os.Exit(1)
+    }
+    if opts.UserList != "" && !e.fileExists(opts.UserList) {
+        e.printError("%s", e.tr("err.userfile_missing", opts.UserList))
+        os.Exit(1)
+    }
+    if opts.PassList != "" && !e.fileExists(opts.PassList) {
+        e.printError("%s", e.tr("err.passfile_missing", opts.PassList))
+        os.Exit(1)
+    }
+    if e.connectMode {
+        if opts.SingleUser == "" || opts.SinglePass == "" {
+            e.printError("%s", e.tr("err.connect_single"))
+            showHelp()
+            os.Exit(1)
+        }
+        if opts.UserList != "" || opts.PassList != "" {
+            e.printError("%s", e.tr("err.connect_lists"))
+            showHelp()
+            os.Exit(1)
+        }
+    }
+    if opts.Dump {
+        if opts.SingleUser == "" || opts.SinglePass == "" {
+            e.printError("%s", e.tr("err.dump_single"))
+            showHelp()
+            os.Exit(1)
+        }
+        if opts.UserList != "" || opts.PassList != "" {
+            e.printError("%s", e.tr("err.dump_lists"))
+            showHelp()
+            os.Exit(1)
+        }
+    }
+
+    if opts.MaxConnFraction < 0 || opts.MaxConnFraction > 1 {
+        e.printError("Error: --max-conn-fraction must be between 0 and 1.")
+        os.Exit(1)
+    }
+    if err := e.setupSources(opts.Sources); err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if opts.IPVersion != 0 && opts.IPVersion != 4 && opts.IPVersion != 6 {
+        e.printError("Error: --ip-version must be 4 or 6.")
+        os.Exit(1)
+    }
+    if opts.DNSTTL < 0 {
+        e.printError("Error: --dns-ttl must be 0 or more.")
+        os.Exit(1)
+    }
+    if err := e.setupHostsFile(opts.HostsFile); err != nil {
+        e.printError("Error: --hosts-file: %v", err)
+        os.Exit(1)
+    }
+    if err := e.setupResolver(opts.Resolver); err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if opts.Host != "" {
+        if err := e.preResolve(ctx, opts.Host); err != nil {
+            e.printError("Error: cannot resolve %s: %v", opts.Host, err)
+            os.Exit(1)
+        }
+    }
+    if err := e.checkTLSFiles(opts.TLSCert, opts.TLSKey, opts.TLSCA, opts.SkipSSL); err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if err := e.setupTLS(); err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if opts.Rate < 0 || opts.Delay < 0 {
+        e.printError("Error: --rate and --delay must be 0 or more.")
+        os.Exit(1)
+    }
+    e.setupRateLimit(opts.Rate, opts.Delay)
+    if err := e.setupSuccessHook(opts.OnSuccess); err != nil {
+        e.printError("Error: --on-success: %v", err)
+        os.Exit(1)
+    }
+    if err := e.setupErrorHook(opts.OnErrorThreshold); err != nil {
+        e.printError("Error: --on-error-threshold: %v", err)
+        os.Exit(1)
+    }
+    if opts.ErrorThreshold <= 0 || opts.ErrorThreshold > 1 {
+        e.printError("Error: --error-threshold must be between 0 and 1.")
+        os.Exit(1)
+    }
+    if opts.HookTimeout < 1 {
+        e.printError("Error: --hook-timeout must be at least 1 second.")
+        os.Exit(1)
+    }
+    if !validDumpFormat(opts.DumpFormat) {
+        e.printError("Error: --dump-format must be one of: %s.", strings.Join(dumpFormats, ", "))
+        os.Exit(1)
+    }
+    if _, err := parseCSVDelimiter(opts.CSVDelimiter); err != nil {
+        e.printError("Error: --csv-delimiter: %v", err)
+        os.Exit(1)
+    }
+    if !validBlobMode(opts.BlobMode) {
+        e.printError("Error: --blob-mode must be one of: %s.", strings.Join(blobModes, ", "))
+        os.Exit(1)
+    }
+    if opts.BlobMode != "raw" && (opts.DumpFormat == "sql" || opts.DumpFormat == "parquet") {
+        e.printWarning("--blob-mode only applies to csv and jsonl dumps, %s keeps binary columns as they are", opts.DumpFormat)
+    }
+    if err := e.setupDumpFilter(opts.DumpInclude, opts.DumpExclude); err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if err := e.setupDumpWhere(opts.DumpWhere); err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if err := e.setupDumpEncrypt(opts.DumpEncrypt); err != nil {
+        e.printError("Error: --dump-encrypt: %v", err)
+        os.Exit(1)
+    }
+    if _, err := parseDumpBudget(opts.MaxBytes, opts.MaxBytesPerTable); err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if opts.Rules != "" && !e.fileExists(opts.Rules) {
+        e.printError("Error: rules file '%s' not found.", opts.Rules)
+        os.Exit(1)
+    }
+    if opts.Company != "" && !opts.Mutate {
+        e.printWarning("--company only has an effect together with --mutate")
+    }
+    if err := e.setupMutator(opts.Mutate, opts.Company, opts.Rules); err != nil {
+        e.printError("Error: --rules: %v", err)
+        os.Exit(1)
+    }
+    if err := e.setupPolicy(opts.Policy); err != nil {
+        e.printError("Error: --policy: %v", err)
+        os.Exit(1)
+    }
+    if err := e.loadCommonPasswords(opts.CommonPasswords); err != nil {
+        e.printError("Error: --common-passwords: %v", err)
+        os.Exit(1)
+    }
+    if err := e.setupSessionVars(opts.SessionVars); err != nil {
+        e.printError("Error: --session-vars: %v", err)
+        os.Exit(1)
+    }
+    if err := e.setupConnAttrs(opts.ConnAttrs); err != nil {
+        e.printError("Error: --conn-attrs: %v", err)
+        os.Exit(1)
+    }
+    if opts.SummaryOnly && (opts.Output == "json" || e.connectMode) {
+        e.printError("Error: --summary-only cannot be combined with --output json or --connect.")
+        os.Exit(1)
+    }
+    if opts.Discover != "" {
+        if opts.Host != "" || opts.CredCSV != "" || verifyOnly != "" || fingerprintOnly || e.connectMode {
+            e.printError("Error: --discover finds its own targets and cannot be combined with -h, --cred-csv, --verify-only, --fingerprint-only or --connect.")
+            os.Exit(1)
+        }
+        if _, err := expandDiscoverTargets(opts.Discover); err != nil {
+            e.printError("Error: --discover: %v", err)
+            os.Exit(1)
+        }
+        if _, err := parseDiscoverPorts(opts.DiscoverPorts); err != nil {
+            e.printError("Error: --discover-ports: %v", err)
+            os.Exit(1)
+        }
+    }
+    if opts.CredCSV != "" {
+        if opts.SingleUser != "" || opts.UserList != "" || opts.SinglePass != "" || opts.PassList != "" {
+            e.printError("Error: --cred-csv supplies every credential and cannot be combined with -u, -U, -p or -P.")
+            os.Exit(1)
+        }
+        if opts.Dump || verifyOnly != "" {
+            e.printError("Error: --cred-csv cannot be combined with --dump or --verify-only.")
+            os.Exit(1)
+        }
+        if !e.fileExists(opts.CredCSV) {
+            e.printError("%s", e.tr("err.credfile_missing", opts.CredCSV))
+            os.Exit(1)
+        }
+        if _, err := e.loadCredCSVTargets(opts.CredCSV); err != nil {
+            e.printError("Error reading credentials file: %v", err)
+            os.Exit(1)
+        }
+    }
+    if opts.DumpBatchSize < 0 {
+        e.printError("Error: --dump-batch-size must be 0 or more.")
+        os.Exit(1)
+    }
+    if opts.InventoryFile != "" && opts.VaultPasswordFile == "" {
+        e.printError("Error: --export-inventory encrypts passwords with ansible-vault and requires --vault-password-file.")
+        os.Exit(1)
+    }
+    if opts.VaultPasswordFile != "" && !e.fileExists(opts.VaultPasswordFile) {
+        e.printError("Error: Vault password file '%s' not found.", opts.VaultPasswordFile)
+        os.Exit(1)
+    }
+    if opts.SecretDetectors != "" {
+        if _, err := loadSecretDetectors(opts.SecretDetectors); err != nil {
+            e.printError("Error loading secret detectors: %v", err)
+            os.Exit(1)
+        }
+    }
+    if opts.ProveOnly && (opts.Dump || e.connectMode || opts.Enum || opts.LootHashes || opts.DeployUDF || opts.PrivescCheck || opts.InventoryFile != "") {
+        e.printError("Error: --prove-only closes every successful connection at once and cannot be combined with --dump, --connect, -Enum, --loot-hashes, --deploy-udf, --privesc-check or --export-inventory.")
+        os.Exit(1)
+    }
+    if opts.ShredAfter != "" {
+        if opts.Campaign == "" {
+            e.printError("Error: --shred-after records the retention period in a campaign and requires --campaign.")
+            os.Exit(1)
+        }
+        if _, err := parseRetention(opts.ShredAfter); err != nil {
+            e.printError("Error: --shred-after: %v", err)
+            os.Exit(1)
+        }
+    }
+    if opts.SkipKnown {
+        if opts.ResultsDB == "" && opts.LogFile == "" && opts.Campaign == "" {
+            e.printError("Error: --skip-known reads earlier successes from --results-db or --log-file (or a --campaign's).")
+            os.Exit(1)
+        }
+        if verifyOnly != "" || opts.Dump || e.connectMode {
+            e.printError("Error: --skip-known cannot be combined with --verify-only, --dump or --connect.")
+            os.Exit(1)
+        }
+    }
+    if opts.Defaults {
+        if opts.CredCSV != "" || opts.Discover != "" || verifyOnly != "" || opts.Dump || e.connectMode {
+            e.printError("Error: --defaults cannot be combined with --cred-csv, --discover, --verify-only, --dump or --connect.")
+            os.Exit(1)
+        }
+        if _, err := e.loadDefaultCreds(opts.DefaultsFile); err != nil {
+            e.printError("Error reading defaults file: %v", err)
+            os.Exit(1)
+        }
+    }
+    if opts.Follow {
+        if opts.UserList == "" && opts.PassList == "" && opts.CredCSV == "" {
+            e.printError("Error: --follow needs a -U, -P or --cred-csv file to follow.")
+            os.Exit(1)
+        }
+        if resume || opts.UserFirst || opts.Spray || opts.Dump || opts.Discover != "" || verifyOnly != "" {
+            e.printError("Error: --follow tests lines as they arrive and cannot be combined with --resume, --user-first, --spray, --dump, --discover or --verify-only.")
+            os.Exit(1)
+        }
+    }
+    if opts.ReadOnly && opts.AllowDangerous {
+        e.printError("Error: --read-only cannot be combined with --allow-dangerous or --deploy-udf.")
+        os.Exit(1)
+    }
+    if opts.DeployUDF && !opts.AllowDangerous {
+        e.printError("Error: --deploy-udf installs functions that run OS commands and requires --allow-dangerous.")
+        os.Exit(1)
+    }
+    if opts.DumpSchemaOnly && opts.DumpDataOnly {
+        e.printError("Error: --dump-schema-only and --dump-data-only cannot be combined.")
+        os.Exit(1)
+    }
+    if opts.AuthPlugin != "" && !validAuthPlugin(opts.AuthPlugin) {
+        e.printError("Error: --auth-plugin must be one of: %s", strings.Join(authPlugins, ", "))
+        os.Exit(1)
+    }
+    if opts.AuthPlugin == "mysql_clear_password" && !opts.AllowCleartext {
+        e.printError("Error: --auth-plugin mysql_clear_password sends passwords unencrypted and requires --allow-cleartext.")
+        os.Exit(1)
+    }
+    if opts.KeepAlive < 0 {
+        e.printError("Error: --keepalive must be 0 or more.")
+        os.Exit(1)
+    }
+    if opts.RowLimit < 0 {
+        e.printError("Error: --row-limit must be 0 or more.")
+        os.Exit(1)
+    }
+    if opts.MaxColWidth < 0 {
+        e.printError("Error: --max-col-width must be 0 or more.")
+        os.Exit(1)
+    }
+    if err := checkExecParams(opts.ExecParams, opts.ExecCmd, opts.Params); err != nil {
+        e.printError("Error: %v.", err)
+        os.Exit(1)
+    }
+    if opts.Retries < 0 {
+        e.printError("Error: --retries must be 0 or more.")
+        os.Exit(1)
+    }
+    if opts.BlockPause != "" {
+        d, err := time.ParseDuration(opts.BlockPause)
+        if err != nil || d <= 0 {
+            e.printError("Error: invalid --block-pause '%s', use a duration such as 15m.", opts.BlockPause)
+            os.Exit(1)
+        }
+        e.blockPause = d
+    }
+    if opts.Spray && opts.UserFirst {
+        e.printError("Error: --spray tests one password across all users per round and cannot be combined with --user-first.")
+        os.Exit(1)
+    }
+    if opts.SprayInterval < 0 || opts.MaxAttemptsPerUser < 0 {
+        e.printError("Error: --spray-interval and --max-attempts-per-user must be 0 or more.")
+        os.Exit(1)
+    }
+    if opts.BatchAuth < 0 {
+        e.printError("Error: --batch-auth must be 0 or more.")
+        os.Exit(1)
+    }
+    if err := e.setupTimeouts(opts.AttemptTimeout, opts.ConnectTimeout, opts.ReadTimeout, opts.WriteTimeout, opts.QueryTimeout); err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if opts.MaxMemory != "" {
+        limit, err := parseByteSize(opts.MaxMemory)
+        if err != nil {
+            e.printError("Error: --max-memory: %v", err)
+            os.Exit(1)
+        }
+        e.verbosePrintln("Starting memory watchdog with limit", formatByteSize(limit))
+        e.startMemoryWatchdog(ctx, limit)
+    }
+    if verifyOnly != "" && !e.fileExists(verifyOnly) {
+        e.printError("%s", e.tr("err.credfile_missing", verifyOnly))
+        os.Exit(1)
+    }
+    if (opts.PushDefectDojo != "" || opts.PushFaraday != "") && (opts.APIKey == "" || opts.PushEngagement == "") {
+        e.printError("Error: --push-defectdojo and --push-faraday require --api-key and --push-engagement.")
+        showHelp()
+        os.Exit(1)
+    }
+
+    // Move the run's files into the campaign tree before anything is opened
+    if opts.Campaign != "" {
+        if err := e.applyCampaign(opts.Campaign); err != nil {
+            e.printError("Error: --campaign: %v", err)
+            os.Exit(1)
+        }
+    }
+
+    // Only statements run after login are audited, so nothing reaches the file before here
+    if !e.dryRun {
+        if err := e.openAuditLog(opts.AuditLog); err != nil {
+            e.printError("Error: --audit-log: %v", err)
+            os.Exit(1)
+        }
+        defer e.closeAuditLog()
+    }
+
+    // A summary-only run keeps its details in the log file
+    if opts.SummaryOnly && opts.LogFile == "" {
+        opts.LogFile = summaryDetailsFile
+    }
+
+    // Earlier runs' successes, before this run's log and results database are opened
+    if opts.SkipKnown {
+        count, err := e.loadKnownCreds(opts.ResultsDB, opts.LogFile)
+        if err != nil {
+            e.printError("Error: --skip-known: %v", err)
+            os.Exit(1)
+        }
+        e.printInfo("Skipping %d account(s) already cracked according to earlier runs", count)
+    }
+
+    if e.dryRun {
+        e.printPlan(resume, verifyOnly)
+        return
+    }
+
+    if verifyOnly == "" && opts.CredCSV == "" && opts.Discover == "" {
+        fmt.Println(e.tr("run.starting", opts.Host, opts.Port))
+    }
+    startTime := time.Now()
+
+    // Set up logging
+    var logFile *os.File
+    if opts.LogFile != "" {
+        e.verbosePrintln("Opening log file:", opts.LogFile)
+        var err error
+        logFile, err = os.OpenFile(opts.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+        if err != nil {
+            e.printError("%s", e.tr("err.log_file", err))
+            os.Exit(1)
+        }
+        defer logFile.Close()
+        e.verbosePrintln("Log file opened successfully")
+    }
+
+    // Switch stdout to JSON Lines records if requested
+    if err := e.setupOutput(opts.Output, &logFile); err != nil {
+        e.printError("Error: %v", err)
+        os.Exit(1)
+    }
+    if opts.SummaryOnly {
+        e.setupSummaryOnly(&logFile)
+    }
+    e.registerBuiltinHandlers(logFile)
+    e.emitRecord("run", map[string]interface{}{"data_versions": e.dataVersions})
+    if logFile != nil {
+        logFile.WriteString("Data versions: " + e.formatDataVersions() + "\n")
+    }
+
+    // Set up the results database
+    if opts.ResultsDB != "" {
+        e.verbosePrintln("Opening results database:", opts.ResultsDB)
+        var err error
+        e.resultsDB, err = openResultsDB(opts.ResultsDB)
+        if err != nil {
+            e.printError("Error opening results database: %v", err)
+            os.Exit(1)
+        }
+        defer e.resultsDB.Close()
+        e.verbosePrintln("Results database opened successfully")
+    }
+
+    // Fingerprint the server before spending a wordlist on it
+    if fingerprintOnly {
+        ok := e.runFingerprint(ctx, logFile)
+        e.recordCampaignRun("fingerprint", startTime)
+        if !ok {
+            os.Exit(1)
+        }
+        return
+    }
+    if verifyOnly == "" && opts.CredCSV == "" && opts.Discover == "" {
+        e.runFingerprint(ctx, logFile)
+    }
+
+    if runNote != "" && verifyOnly == "" && opts.CredCSV == "" && opts.Discover == "" {
+        e.addNote(opts.Host, opts.Port, "", runNote)
+    }
+
+    // Perform the testing
+    if verifyOnly != "" {
+        e.runVerifyOnly(ctx, verifyOnly, runNote, logFile)
+    } else if opts.CredCSV != "" {
+        e.runCredCSV(ctx, opts.CredCSV, runNote, logFile)
+    } else if opts.Discover != "" {
+        e.runDiscover(ctx, logFile)
+    } else {
+        // Default credentials go first; a hit under -f or a blocked host ends the run there
+        var err error
+        if opts.Defaults {
+            err = e.runDefaults(ctx, logFile)
+        }
+        if (opts.SingleUser != "" || opts.UserList != "") && !errors.Is(err, errFirstSuccess) && !errors.Is(err, errBlockedHost) {
+            e.performTesting(ctx, resume, logFile)
+        }
+    }
+
+    e.waitForHooks()
+    e.reportLockouts(logFile)
+    e.reportPanics(logFile)
+    e.reportInconclusive(logFile)
+    e.reportPluginMismatches(logFile)
+    if n := e.knownSkipped.Load(); n > 0 {
+        e.printInfo("Skipped %d attempt(s) against accounts already cracked", n)
+    }
+
+    mode := "brute"
+    switch {
+    case verifyOnly != "":
+        mode = "verify"
+    case opts.CredCSV != "":
+        mode = "replay"
+    case opts.Discover != "":
+        mode = "discover"
+    case opts.Defaults && opts.SingleUser == "" && opts.UserList == "":
+        mode = "defaults"
+    case opts.Dump:
+        mode = "dump"
+    case e.connectMode:
+        mode = "connect"
+    }
+    e.recordCampaignRun(mode, startTime)
+
+    // Export findings for security platforms
+    if opts.SARIFFile != "" {
+        if err := e.exportSARIF(opts.SARIFFile, e.getSuccesses()); err != nil {
+            e.printError("Error exporting SARIF file: %v", err)
+        } else {
+            fmt.Fprintln(e.out, e.tr("export.sarif", opts.SARIFFile))
+        }
+    }
+
+    // Hand affected hosts to remediation teams' configuration management
+    if opts.InventoryFile != "" {
+        if err := e.exportInventory(opts.InventoryFile, opts.VaultPasswordFile, e.getSuccesses()); err != nil {
+            e.printError("Error exporting Ansible inventory: %v", err)
+        } else {
+            fmt.Fprintln(e.out, e.tr("export.inventory", opts.InventoryFile))
+        }
+    }
+
+    // Push findings to vulnerability management platforms
+    if opts.PushDefectDojo != "" {
+        if err := e.pushDefectDojo(opts.PushDefectDojo, opts.APIKey, opts.PushEngagement, e.getSuccesses()); err != nil {
+            e.printError("Error pushing findings to DefectDojo: %v", err)
+        } else {
+            fmt.Fprintln(e.out, "Findings uploaded to DefectDojo engagement", opts.PushEngagement)
+        }
+    }
+    if opts.PushFaraday != "" {
+        if err := e.pushFaraday(opts.PushFaraday, opts.APIKey, opts.PushEngagement, e.getSuccesses(), startTime); err != nil {
+            e.printError("Error pushing findings to Faraday: %v", err)
+        } else {
+            fmt.Fprintln(e.out, "Findings uploaded to Faraday workspace", opts.PushEngagement)
+        }
+    }
+
+    e.printRunSummary(startTime)
+
+    // Write the CI report last so a failing check can set the exit status
+    if opts.JUnitFile != "" {
+        failures, err := e.exportJUnit(opts.JUnitFile, e.getTestedAccounts(), e.getSuccesses(), startTime)
+        if err != nil {
+            e.printError("Error exporting JUnit report: %v", err)
+            os.Exit(1)
+        }
+        fmt.Fprintln(e.out, e.tr("export.junit", opts.JUnitFile))
+        if failures > 0 {
+            e.printError("%s", e.tr("export.junit_failures", failures))
+            if logFile != nil {
+                logFile.Close()
+            }
+            if e.resultsDB != nil {
+                e.resultsDB.Close()
+            }
+            os.Exit(1)
+        }
+    }
+}
+
+// sanitizeCommand ensures the SQL command is safe to execute
+func sanitizeCommand(cmd string) string {
+    // Trim whitespace
+    cmd = strings.TrimSpace(cmd)
+
+    // Remove any trailing semicolons (MySQL will add them)
+    cmd = strings.TrimRight(cmd, ";")
+
+    // Add a single semicolon at the end
+    if cmd != "" && !strings.HasSuffix(cmd, ";") {
+        cmd += ";"
+    }
+
+    // If somehow the command is empty, use a safe default
+    if cmd == "" || cmd == ";" {
+        cmd = "SHOW DATABASES;"
+    }
+
+    return cmd
+}
+
+// displayBanner shows the program banner
+func displayBanner() {
+    fmt.Println(`
+                                                                 █                                   
+                                                            █████                                   
+                                                ████████    ████                                    
+                                  ████████    ███████████  █████                                    
+                                ███████████  █████  █████  █████                                    
+                               █████  █████ █████   █████ ██████       ███                          
+                               █████  ████ █████    █████ █████      ██████████████████             
+                               ██████ ██   █████    █████ █████      ██████████████████             
+                                ███████   █████    ███████████       ██████████████████             
+                                 ███████  █████    █████ ████    ██   ████████████                  
+                               ███ ███████████    █████████████████  ██████████                     
+                             ████   ███████████████████ ███████████  █████                          
+                            ████    █████ ███████████  ██████  ████  ████     ██                    
+                           █████   ██████  ███████████ ██     █████  ███    █████                   
+                            ███████████     █████████████    ███████        ██████████              
+                       █████ ████████              █████    ████████ ███████ ████████               
+                  █████████████   █████               ██   ██████  █████████ ████████               
+                ████████████████  ████              ████    ████  █████ ████████████  ██            
+               ████ █████  █████ █████    ████████  ████    ████  █████████ ███████  ███            
+             █████  █████  █████ ████   █████████  ██████  █████ █████████ ███ ████████             
+             ████  █████  █████  ████ ███████████ ███████  ████  █████   ████  ███████              
+            █████  ███████████  ████  ████  ████  ███ █████████████████████                         
+             ████████████████   ████ █████ █████ ████ ███████████████████     █████████             
+              ███ ████████████ ████  █████ ████ ████████████████      ███████████████               
+                  ████    █████████ ████████████████████     ██████████████                         
+                 █████    ██████████████████████    █████████████████                               
+                 ████    ████████████ ████   ██████████████████                                     
+                █████████████         █████████████████                                             
+                ████████████   ███████████████████                                                  
+               ██████████ ███████████████████                                                       
+                      █████████████████                                                             
+                            ███████                                                                 
+                                                                                                    `)
+
+    fmt.Println("SQL Blaster - A MySQL Enumeration & Dumping Tool Written in Go!")
+    fmt.Println()
+}
+
+// fileExists checks if a file exists and is not a directory
+func (e *Engine) fileExists(filename string) bool {
+    e.verbosePrintf("Checking if file exists: %s... ", filename)
+    info, err := os.Stat(filename)
+    if os.IsNotExist(err) {
+        e.verbosePrintln("not found")
+        return false
+    }
+    isFile := !info.IsDir()
+    e.verbosePrintf("found, is file: %v\n", isFile)
+    return isFile
+}
+
+// getSqlVerb extracts the first SQL verb from a command
+func getSqlVerb(cmd string) string {
+    cmd = strings.TrimSpace(cmd)
+    cmd = strings.Split(cmd, "--")[0] // Remove comments
+    cmd = strings.Split(cmd, "#")[0]
+    words := strings.Fields(cmd)
+    if len(words) > 0 {
+        return strings.ToUpper(words[0])
+    }
+    return ""
+}
+
+// showHelp displays the usage information
+func showHelp() {
+    displayBanner()
+
+    fmt.Println("Usage: program [options]")
+    fmt.Println()
+    fmt.Println("Options:")
+    fmt.Println("  -h <hostname>       Remote MySQL server address (required)")
+    fmt.Println("  -u <username>       Single username to test")
+    fmt.Println("  -U <username_file>  File containing usernames, one per line")
+    fmt.Println("  --port <port>       MySQL server port (default: 3306)")
+    fmt.Println("  -p <password>       Single password to test")
+    fmt.Println("  -P <password_file>  File containing passwords, one per line")
+    fmt.Println("  -v                  Enable verbose mode")
+    fmt.Println("  -f                  Stop at first successful login")
+    fmt.Println("  --user-first        Loop over all usernames before next password")
+    fmt.Println("  -e <command>        MySQL command to execute on success (default: 'SHOW DATABASES;')")
+    fmt.Println("  --allow-dangerous   Allow dangerous commands")
+    fmt.Println("  --log-file <file>   Log output to a file")
+    fmt.Println("  --lang <code>       Language of run messages, reports and the shell, not help or verbose output (en, es; default: from $LANG)")
+    fmt.Println("  --theme <name>      Color theme: default, high-contrast, colorblind, none")
+    fmt.Println("  --config <file>     Load settings from a JSON config file")
+    fmt.Println("  --use-ssl           Enable SSL/TLS for MySQL connection")
+    fmt.Println("  --skip-ssl          Skip SSL/TLS entirely (overrides --use-ssl)")
+    fmt.Println("  --workers <number>  Number of concurrent workers (default: 10)")
+    fmt.Println("  --max-conn-fraction <f> Pause attempts while Threads_connected exceeds this fraction of max_connections")
+    fmt.Println("  --monitor-creds <user:pass> Credential used to poll server status (default: first success)")
+    fmt.Println("  --sources <list>    Comma-separated source IPs or socks5:// proxies to fail over between when the host blocks us")
+    fmt.Println("  --generate-config   Generate a sample config file and exit")
+    fmt.Println("  --resume            Resume from the last tested credentials")
+    fmt.Println("  --resume-session <id> Resume a saved session, taking its target and wordlists when not given")
+    fmt.Println("  --list-sessions     List the saved sessions in --session-dir and exit")
+    fmt.Println("  --force-resume      Resume even if the wordlists changed since the state was saved")
+    fmt.Println("  --dry-run           Print the attempts, estimated duration and files of the run without connecting")
+    fmt.Println("  -Enum               Enumerate privileges, databases, and tables on success")
+    fmt.Println("  --enum-output <file> Save enumeration results to a file")
+    fmt.Println("  --connect           Enter interactive mode after successful login (requires -u and -p)")
+    fmt.Println("  --verify-only <file> Re-validate credentials from a CSV file (user,pass or host,port,user,pass)")
+    fmt.Println("  --note <text>        Attach a note to the target (every target with --cred-csv), or with --verify-only to every credential that passes")
+    fmt.Println("  --fingerprint-only  Fingerprint the server from its handshake greeting and exit")
+    fmt.Println("  --dump              Dump all databases and tables to files (requires -u and -p)")
+    fmt.Println("  --dump-dir <dir>    Directory to save dumped data (default: mysql_dump)")
+    fmt.Println("  --quiet-dump        Only show progress during dump, not actual data")
+    fmt.Println("  --max-rows <n>      Maximum rows per dump file (default: 10000, 0 for unlimited)")
+    fmt.Println("  --results-db <file> Record successful logins to a SQLite results database")
+    fmt.Println("  --export-sarif <file> Export findings to a SARIF file at the end of the run")
+    fmt.Println("  --push-defectdojo <url>")
+    fmt.Println("                      Upload findings to a DefectDojo instance at the end of the run")
+    fmt.Println("  --push-faraday <url>")
+    fmt.Println("                      Upload findings to a Faraday instance at the end of the run")
+    fmt.Println("  --api-key <key>     API key for --push-defectdojo or --push-faraday")
+    fmt.Println("  --push-engagement <id>")
+    fmt.Println("                      DefectDojo engagement ID or Faraday workspace to upload to")
+    fmt.Println("  --junit <file>      Write a JUnit XML report and exit non-zero if any credential succeeds")
+    fmt.Println("  --debug-crash       Print stack traces for panics recovered in workers")
+    fmt.Println("  --max-memory <size> Spool result formatting and dumps to disk when heap use exceeds this size (e.g. 2GB)")
+    fmt.Println("  --batch-auth <n>    Test up to n credentials per connection with COM_CHANGE_USER (0 to disable)")
+    fmt.Println("  --no-tls-resume     Disable TLS session resumption between connections")
+    fmt.Println("  --resolver <ip>     DNS server (ip or ip:port) used to resolve the target instead of the system resolver")
+    fmt.Println("  --dns-ttl <seconds> Seconds to cache resolved target addresses (default: 300)")
+    fmt.Println("  --output <format>   Output format: text (default) or json for JSON Lines records on stdout")
+    fmt.Println("  --hosts-file <file> /etc/hosts-style file mapping target names to IPs, used only by sqlblaster")
+    fmt.Println("  --rate <n>          Maximum attempts per second across all workers (0 for unlimited)")
+    fmt.Println("  --delay <ms>        Minimum milliseconds between attempts across all workers")
+    fmt.Println("  --on-success <cmd>  Command to run for each success, with {{.Host}} {{.Port}} {{.User}} {{.Pass}} templates")
+    fmt.Println("  --hook-timeout <s>  Seconds before a hook command is killed (default: 30)")
+    fmt.Println("  --on-error-threshold <cmd> Command to run when the error rate passes --error-threshold or the host is circuit-broken")
+    fmt.Println("  --error-threshold <f> Fraction of attempts in the last minute that must error to fire --on-error-threshold (default: 0.5)")
+    fmt.Println("  --dump-format <fmt> Dump file format: csv, sql, jsonl or parquet (default: csv)")
+    fmt.Println("  --dump-compress     Gzip dump files as they are written (.csv.gz / .sql.gz / .jsonl.gz), zstd inside Parquet files")
+    fmt.Println("  --loot-hashes       Extract mysql.user password hashes in hashcat (300/7401) and John formats on success")
+    fmt.Println("  --loot-dir <dir>    Directory to write extracted password hashes to (default: loot)")
+    fmt.Println("  --enum-full         List every table during -Enum instead of summarizing databases with more than 50 tables")
+    fmt.Println("  --verify-query <sql> Query that verifies a successful login instead of the default -e command ('none' to skip)")
+    fmt.Println("  --dump-include <p>  Comma-separated db.table globs or /regex/ patterns of tables to dump (default: all)")
+    fmt.Println("  --dump-exclude <p>  Comma-separated db.table globs or /regex/ patterns of tables to skip when dumping")
+    fmt.Println("  --spray             Test one password against all users per round, pausing --spray-interval between rounds")
+    fmt.Println("  --spray-interval <s> Seconds to wait between spray rounds (default: 1800)")
+    fmt.Println("  --max-attempts-per-user <n> Stop testing a user after this many attempts (0 for unlimited)")
+    fmt.Println("  --campaign <name>   Organize state, results, logs, dumps, loot and reports under campaigns/<name>")
+    fmt.Println("  --mutate            Expand each password with built-in rules (capitalization, leetspeak, years, common suffixes)")
+    fmt.Println("  --company <name>    Company name combined with each password by --mutate")
+    fmt.Println("  --rules <file>      Hashcat-style rules file applied to each password")
+    fmt.Println("  --attempt-timeout <d> Time limit for a whole login attempt: dial, TLS and authentication (default: 10s)")
+    fmt.Println("  --read-timeout <d>  I/O read timeout for connections, e.g. 30s (default: none)")
+    fmt.Println("  --write-timeout <d> I/O write timeout for connections, e.g. 30s (default: none)")
+    fmt.Println("  --keepalive <s>     Seconds between keep-alive pings on idle interactive and dump connections (default: 60, 0 to disable)")
+    fmt.Println("  --connect-timeout <d> TCP connect timeout (default: 10s)")
+    fmt.Println("  --query-timeout <d> Time limit for each statement after login, 0 for none (default: 30s)")
+    fmt.Println("  --session-vars <list> Session variables set after login as name=value,... ('none' to keep server defaults)")
+    fmt.Println("  --common-passwords <file> Top-10k password list used to score found passwords (default: built-in list)")
+    fmt.Println("  --deploy-udf           Install the lib_mysqludf_sys sys_exec/sys_eval functions on success (requires --allow-dangerous)")
+    fmt.Println("  --udf-dir <dir>        Directory holding lib_mysqludf_sys builds named lib_mysqludf_sys_<os>_<arch>.so/.dll (default: udf)")
+    fmt.Println("  --privesc-check      Audit privilege escalation paths on success and print a prioritized findings list")
+    fmt.Println("  --export-inventory <file> Export discovered access as an Ansible YAML inventory with vaulted passwords")
+    fmt.Println("  --vault-password-file <file> ansible-vault password file used to encrypt passwords in --export-inventory (default: $ANSIBLE_VAULT_PASSWORD_FILE)")
+    fmt.Println("  --find-secrets       Scan dumped rows for card numbers, emails, hashes, AWS keys and JWTs and write secrets_report.txt")
+    fmt.Println("  --secret-detectors <file> File of 'name regex' lines replacing the built-in --find-secrets detectors")
+    fmt.Println("  --dump-batch-size <n> Rows read per query while dumping, paged by primary key (0 reads each table in one query, default: 10000)")
+    fmt.Println("  --cred-csv <file>    Try each row of a CSV file (host,port,user,pass or user,pass with -h) as one attempt")
+    fmt.Println("  --conn-attrs <list>  Connection attributes (name=value,...) sent with every attempt, e.g. program_name=mysql,client_host=ws042")
+    fmt.Println("  --summary-only       Print only a summary table (targets, attempts, successes, duration) on stdout; details go to JSON records in --log-file (default: sqlblaster-details.jsonl)")
+    fmt.Println("  --discover <targets> Sweep hosts and CIDR ranges (e.g. 10.0.0.0/24,db01) for MySQL, then test each server found")
+    fmt.Println("  --discover-ports <list> Ports swept by --discover (default: 3306,3307,33060)")
+    fmt.Println("  --prove-only         On success close the connection at once and record only a salted SHA-256 commitment of the password")
+    fmt.Println("  --ip-version <4|6>   Resolve targets to IPv4 or IPv6 addresses only (default: either)")
+    fmt.Println("  --follow             Keep reading -U, -P and --cred-csv files and test lines appended during the run")
+    fmt.Println("  --tls-cert <file>    Client certificate (PEM) for targets that require mutual TLS")
+    fmt.Println("  --tls-key <file>     Private key (PEM) of the --tls-cert certificate")
+    fmt.Println("  --tls-ca <file>      Verify target certificates against these CA certificates (PEM)")
+    fmt.Println("  --defaults           Try built-in vendor default credentials first, or alone without -u/-U")
+    fmt.Println("  --defaults-file <f>  Extra user:password defaults for --defaults (implies --defaults)")
+    fmt.Println("  --shred-after <d>    Mark the campaign's files for shredding this long after its last run (e.g. 30d)")
+    fmt.Println("  --data-version <v>   Refuse to run unless this data bundle version (or builtin) is in use")
+    fmt.Println("  --skip-known         Skip accounts already cracked on a target per the results DB or log file")
+    fmt.Println("  --retries <n>        Retries with backoff when an attempt fails on a network error (default: 2)")
+    fmt.Println("  --block-pause <d>    Pause this long when the host blocks every source, then resume (e.g. 15m)")
+    fmt.Println("  --csv-delimiter <c>  Field delimiter for CSV dumps, a single character or 'tab' (default: ,)")
+    fmt.Println("  --csv-null-string <s> Text written for NULL values in CSV dumps (default: NULL)")
+    fmt.Println("  --session-dir <dir>  Directory of the --resume state files, one per target and wordlists (default sessions)")
+    fmt.Println("  --pager <cmd>        Command that pages interactive query results, e.g. 'less -S' (default: built-in pager, 'off' to disable)")
+    fmt.Println("  --row-limit <n>      Rows an interactive query fetches before asking whether to read the rest (default 1000, 0 to disable)")
+    fmt.Println("  --max-col-width <n>  Truncate query result columns wider than n characters (default 0: only fit the terminal)")
+    fmt.Println("  --exec-params        Run -e as a prepared statement with its ? placeholders bound to the --param values")
+    fmt.Println("  --param <value>      Value for the next ? placeholder of -e with --exec-params (repeat in order, \\N for NULL)")
+    fmt.Println("  --policy <file>      JSON file of verb, function, schema and regex rules deciding which statements are blocked, warned about or confirmed")
+    fmt.Println("  --audit-log <file>   Append every statement run after login (not the login attempts) to this JSONL file with target, user, duration, rows and outcome")
+    fmt.Println("  --read-only          Never modify the target: allow only reading statements and open every session as READ ONLY where supported")
+    fmt.Println("  --auth-plugin <name> Force the client auth plugin: mysql_native_password, caching_sha2_password or mysql_clear_password (default: the server's choice)")
+    fmt.Println("  --allow-cleartext    Allow mysql_clear_password, which sends passwords unencrypted without TLS (PAM/LDAP accounts)")
+    fmt.Println("  --dump-schema-only   Dump only schema.sql (tables, views, routines, triggers and events), skipping table data")
+    fmt.Println("  --dump-data-only     Dump only table data, without schema.sql or CREATE TABLE statements in SQL files")
+    fmt.Println("  --dump-where <w>    Dump only rows matching a predicate, as db.table:predicate (glob table names, repeatable)")
+    fmt.Println("  --dump-encrypt <e>  Encrypt dump files with age, as age:<recipient>[,...] or passphrase:<file> (adds .age)")
+    fmt.Println("  --max-bytes <size>  Stop dumping table data once the dump's files reach this size (e.g. 10GB)")
+    fmt.Println("  --max-bytes-per-table <size> Truncate a table's dump once its files reach this size (e.g. 500MB)")
+    fmt.Println("  --blob-mode <mode>  Binary columns in CSV and JSON Lines dumps: raw, skip, hex, base64 or file (default: raw)")
+    fmt.Println()
+    fmt.Println("Subcommands:")
+    fmt.Println("  analyze <file.db|dump.sql> ...       Classify columns and loot password hashes from offline SQLite or SQL dump files")
+    fmt.Println("  check-proof <commitment> [password]  Check a --prove-only commitment against a password (read from stdin if omitted)")
+    fmt.Println("  compare <run1.sqlite> <run2.sqlite>  Compare the results databases of two campaigns")
+    fmt.Println("  config validate <file.json>          Check a config file and print the effective configuration")
+    fmt.Println("  init [file.json]                     Interactively create a config file")
+    fmt.Println("  selftest [--update]                  Check dump formats, reports and JSON records against golden files")
+    fmt.Println("  shred --campaign <name> | --expired  Overwrite and delete a campaign's results, logs, dumps, loot and reports")
+    fmt.Println("  update-data <bundle|https://...>     Install a signed bundle of default credentials and common passwords")
+    fmt.Println("  verify-dump <dump-dir>               Check a dump's files against the checksums in its manifest.json")
+    fmt.Println()
+    fmt.Println("Examples:")
+    fmt.Println("  program -h mysql.server.com -u admin -p pass123 -e 'SHOW TABLES;'")
+    fmt.Println("  program -h mysql.server.com -U users.txt -P pass.txt -v --log-file results.log")
+    fmt.Println("  program -h mysql.server.com -u admin -p pass123 -e 'DROP DATABASE test;' --allow-dangerous")
+    fmt.Println("  program -h mysql.server.com -u admin -p pass123 --connect")
+    fmt.Println("  program -h mysql.server.com -u admin -p pass123 --dump --dump-dir ./mysql_data")
+    fmt.Println("  program --config config.json")
+    fmt.Println("  program --generate-config")
+    fmt.Println("  program compare q1_results.sqlite q3_results.sqlite")
+    fmt.Println("  program analyze --loot-dir ./loot app.db backup.sql")
+    fmt.Println()
+    fmt.Println("Config File Format (JSON):")
+    fmt.Println(`{
+  "host": "mysql.server.com",
+  "port": 3306,
+  "singleUser": "admin",
+  "userList": "users.txt",
+  "singlePass": "pass123",
+  "passList": "pass.txt",
+  "verbose": true,
+  "firstOnly": false,
+  "userFirst": false,
+  "execCmd": "SHOW DATABASES;",
+  "allowDangerous": false,
+  "logFile": "results.log",
+  "useSSL": false,
+  "workers": 10,
+  "sources": "",
+  "maxConnFraction": 0,
+  "monitorCreds": "",
+  "enum": false,
+  "enumOutputFile": "enum_results.txt",
+  "dump": false,
+  "dumpDir": "mysql_dump",
+  "quietDump": false,
+  "maxRowsPerFile": 10000,
+  "resultsDB": "results.sqlite",
+  "lang": "en",
+  "theme": "default",
+  "themeColors": {"success": "green", "warning": "yellow", "error": "red"},
+  "sarifFile": "findings.sarif",
+  "pushDefectDojo": "",
+  "pushFaraday": "",
+  "apiKey": "",
+  "pushEngagement": "",
+  "junitFile": "",
+  "debugCrash": false,
+  "maxMemory": "",
+  "batchAuth": 0,
+  "noTLSResume": false,
+  "resolver": "",
+  "dnsTTL": 300,
+  "output": "text",
+  "hostsFile": "",
+  "rate": 0,
+  "delay": 0,
+  "onSuccess": "",
+  "hookTimeout": 30,
+  "onErrorThreshold": "",
+  "errorThreshold": 0.5,
+  "dumpFormat": "csv",
+  "dumpCompress": false,
+  "lootHashes": false,
+  "lootDir": "loot",
+  "enumFull": false,
+  "verifyQuery": "",
+  "dumpInclude": "",
+  "dumpExclude": "",
+  "spray": false,
+  "sprayInterval": 1800,
+  "maxAttemptsPerUser": 0,
+  "campaign": "",
+  "mutate": false,
+  "company": "",
+  "rules": "",
+  "attemptTimeout": "10s",
+  "readTimeout": "",
+  "writeTimeout": "",
+  "keepAlive": 60,
+  "connectTimeout": "10s",
+  "queryTimeout": "30s",
+  "sessionVars": "sql_mode='NO_ENGINE_SUBSTITUTION',net_read_timeout=600,net_write_timeout=600,max_execution_time=0,max_statement_time=0,group_concat_max_len=1048576",
+  "commonPasswords": "",
+  "deployUdf": false,
+  "udfDir": "udf",
+  "privescCheck": false,
+  "inventoryFile": "",
+  "vaultPasswordFile": "",
+  "findSecrets": false,
+  "secretDetectors": "",
+  "dumpBatchSize": 10000,
+  "credCSV": "",
+  "connAttrs": "",
+  "summaryOnly": false,
+  "discover": "",
+  "discoverPorts": "3306,3307,33060",
+  "proveOnly": false,
+  "ipVersion": 0,
+  "follow": false,
+  "tlsCert": "",
+  "tlsKey": "",
+  "tlsCA": "",
+  "defaults": false,
+  "defaultsFile": "",
+  "shredAfter": "",
+  "dataVersion": "",
+  "skipKnown": false,
+  "retries": 2,
+  "blockPause": "",
+  "csvDelimiter": ",",
+  "csvNullString": "NULL",
+  "sessionDir": "sessions",
+  "pager": "",
+  "rowLimit": 1000,
+  "maxColWidth": 0,
+  "execParams": false,
+  "params": [],
+  "policy": "",
+  "auditLog": "",
+  "readOnly": false,
+  "authPlugin": "",
+  "allowCleartext": false,
+  "dumpSchemaOnly": false,
+  "dumpDataOnly": false,
+  "dumpWhere": [],
+  "dumpEncrypt": "age:age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p",
+  "maxBytes": "10GB",
+  "maxBytesPerTable": "500MB",
+  "blobMode": "raw"
+}`)
+    fmt.Println()
+    fmt.Println("Notes:")
+    fmt.Println("  - Command-line flags override config file settings.")
+    fmt.Println("  - Dangerous commands are blocked unless --allow-dangerous is set.")
+    fmt.Println("  - Dump mode saves all databases, tables, and schemas to the specified directory.")
+    fmt.Println("  - System databases like 'information_schema' are skipped during dump.")
+    fmt.Println("  - Interactive mode provides a MySQL shell-like experience with pentest helpers.")
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+    "encoding/json"
+    "os"
+    "time"
+)
+
+// State struct to hold the last tested credentials and the checksums of the wordlists they came from
+type State struct {
+    Host        string `json:"host"`
+    Port        int    `json:"port"`
+    UserList    string `json:"user_list,omitempty"`
+    SingleUser  string `json:"single_user,omitempty"`
+    PassList    string `json:"pass_list,omitempty"`
+    SinglePass  string `json:"single_pass,omitempty"`
+    UserListSum string `json:"user_list_sha256,omitempty"`
+    PassListSum string `json:"pass_list_sha256,omitempty"`
+    Order       string `json:"order"`
+    Total       int64  `json:"total"`
+    // Completed is the attempt index below which every pair is finished; workers finish pairs
+    // out of order, so some above it may be done too, up to each worker's high-water mark
+    Completed       int64     `json:"completed"`
+    WorkerHighWater []int64   `json:"worker_high_water"`
+    Updated         time.Time `json:"updated"`
+}
+
+// loadState loads the testing state from the state file
+func (e *Engine) loadState() State {
+    var state State
+
+    e.verbosePrintln("Loading state from", e.statePath)
+    stateFile, err := os.Open(e.statePath)
+    if err != nil {
+        e.printError("%s", e.tr("state.open_error", err))
+        return State{}
+    }
+    defer stateFile.Close()
+
+    decoder := json.NewDecoder(stateFile)
+    if err := decoder.Decode(&state); err != nil {
+        e.printError("%s", e.tr("state.decode_error", err))
+        return State{}
+    }
+
+    e.verbosePrintln("Loaded state - completed:", state.Completed, "of", state.Total)
+    return state
+}
+
+// saveState saves the tracker's progress to the session's state file. The file is replaced
+// atomically, so an interrupted write never leaves a session that cannot be resumed.
+func (e *Engine) saveState(t *attemptTracker) {
+    state := State{
+        Host:            e.opts.Host,
+        Port:            e.opts.Port,
+        UserList:        absPath(e.opts.UserList),
+        SingleUser:      e.opts.SingleUser,
+        PassList:        absPath(e.opts.PassList),
+        UserListSum:     e.userListSum,
+        PassListSum:     e.passListSum,
+        Order:           e.pairOrder(),
+        Total:           t.total,
+        Completed:       t.completed,
+        WorkerHighWater: t.highWater,
+        Updated:         time.Now(),
+    }
+    // A password --prove-only found must not be kept
+    if !e.opts.ProveOnly {
+        state.SinglePass = e.opts.SinglePass
+    }
+
+    data, err := json.MarshalIndent(state, "", "  ")
+    if err != nil {
+        e.printError("%s", e.tr("state.encode_error", err))
+        return
+    }
+    tmp := e.statePath + ".tmp"
+    if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+        e.printError("%s", e.tr("state.write_error", err))
+        return
+    }
+    if err := os.Rename(tmp, e.statePath); err != nil {
+        e.printError("%s", e.tr("state.write_error", err))
+        return
+    }
+    e.noteCreated(e.statePath)
+}
@@ -0,0 +1,80 @@
+package core
+
+import "strings"
+
+// continuationPrompt is shown while a statement spans several lines, as in the mysql client
+const continuationPrompt = "    -> "
+
+// shellCommands are the interactive commands that run as soon as they are entered, without a
+// terminating semicolon, when no statement is being continued
+var shellCommands = map[string]bool{
+    "exit": true, "quit": true, `\q`: true, "help": true, `\h`: true, `\?`: true,
+    "status": true, `\s`: true, "pentest": true, `\p`: true, `\w`: true, `\o`: true,
+    "download": true, "upload": true, "udf": true, "note": true, "use": true,
+}
+
+// isShellCommand reports whether a line starting a new statement is an interactive command
+// rather than SQL
+func isShellCommand(line string) bool {
+    if strings.HasPrefix(line, "!") {
+        return true
+    }
+    fields := strings.Fields(line)
+    return len(fields) > 0 && shellCommands[strings.ToLower(fields[0])]
+}
+
+// splitStatements cuts the complete statements, each ended by ; or \g outside quoted strings,
+// quoted identifiers and comments, off the front of buffered input. It returns them without
+// their terminators, along with the unfinished rest ("" when only comments are left).
+func splitStatements(input string) ([]string, string) {
+    var stmts []string
+    start := 0
+    // Whether anything but whitespace and comments follows the last terminator
+    content := false
+    for i := 0; i < len(input); i++ {
+        c := input[i]
+        switch {
+        case c == '\'' || c == '"' || c == '`':
+            content = true
+            end := i + 1
+            for end < len(input) && input[end] != c {
+                if input[end] == '\\' && c != '`' {
+                    end++
+                }
+                end++
+            }
+            // An unterminated quote runs to the end, so the statement waits for more lines
+            i = end
+        case c == '#' || c == '-' && strings.HasPrefix(input[i:], "-- "):
+            end := strings.IndexByte(input[i:], '\n')
+            if end < 0 {
+                end = len(input) - i
+            }
+            i += end
+        case c == '/' && strings.HasPrefix(input[i:], "/*"):
+            end := strings.Index(input[i+2:], "*/")
+            if end < 0 {
+                // Wait for the rest of the comment
+                content = true
+                i = len(input)
+            } else {
+                i += end + 3
+            }
+        case c == ';' || c == '\\' && strings.HasPrefix(input[i:], `\g`):
+            if stmt := strings.TrimSpace(input[start:i]); stmt != "" {
+                stmts = append(stmts, stmt)
+            }
+            if c == '\\' {
+                i++
+            }
+            start = i + 1
+            content = false
+        case c != ' ' && c != '\t' && c != '\r' && c != '\n':
+            content = true
+        }
+    }
+    if !content {
+        return stmts, ""
+    }
+    return stmts, input[start:]
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/schollz/progressbar/v3"
+)
+
+// statusWindow is the period the attempts/sec figure is averaged over
+const statusWindow = 60
+
+// runStats is the progress of a run: it drives the run's progress bar and tracks the live
+// numbers shown in the status line. Every credential pair handed to the workers ends as one
+// attempt or one skip, so resumed pairs, attempts and skips reach total when the run is not
+// stopped early.
+type runStats struct {
+    bar        *progressbar.ProgressBar
+    theme      themeColors
+    lang       string
+    mu         sync.Mutex
+    started    time.Time
+    total      int // -1 when the pairs are not known in advance, as with --follow
+    userCount  int // 0 for replayed pairs, which have no wordlist position
+    passCount  int
+    resumed    int // pairs a resumed session had finished, not sent again
+    attempts   int
+    skipped    int
+    successes  int
+    errors     int
+    userIdx    int
+    passIdx    int
+    buckets    [statusWindow]int
+    bucketSecs [statusWindow]int64
+}
+
+// newRunStats creates the progress bar and stats of a run of total credential pairs over
+// userCount users and passCount passwords. Attempts that fail with anything but a rejected
+// password are counted as errors through the engine's failure events.
+func (e *Engine) newRunStats(desc string, total, userCount, passCount int, options ...progressbar.Option) *runStats {
+    options = append([]progressbar.Option{
+        progressbar.OptionSetDescription(desc),
+        progressbar.OptionSetWidth(30),
+        progressbar.OptionShowCount(),
+        // The status line has the attempt rate and an ETA that ignores skipped pairs
+        progressbar.OptionSetPredictTime(false),
+        progressbar.OptionSetWriter(e.out),
+    }, options...)
+    s := &runStats{
+        bar:       progressbar.NewOptions(total, options...),
+        theme:     e.theme,
+        lang:      e.lang,
+        started:   time.Now(),
+        total:     total,
+        userCount: userCount,
+        passCount: passCount,
+    }
+    e.RegisterHandler(EventFailure, func(ev Event) {
+        if ev.Err != nil && !isAuthFailure(ev.Err) {
+            s.mu.Lock()
+            s.errors++
+            s.mu.Unlock()
+        }
+    })
+    return s
+}
+
+// position records the user and password index of the credential most recently handed to a worker
+func (s *runStats) position(userIdx, passIdx int) {
+    s.mu.Lock()
+    s.userIdx = userIdx
+    s.passIdx = passIdx
+    s.mu.Unlock()
+}
+
+// attempt counts one finished attempt in the current one-second bucket
+func (s *runStats) attempt() {
+    now := time.Now().Unix()
+    slot := now % statusWindow
+
+    s.mu.Lock()
+    if s.bucketSecs[slot] != now {
+        s.bucketSecs[slot] = now
+        s.buckets[slot] = 0
+    }
+    s.buckets[slot]++
+    s.attempts++
+    s.mu.Unlock()
+    s.bar.Add(1)
+}
+
+// resumeAt starts the progress after the n pairs a resumed session finished
+func (s *runStats) resumeAt(n int) {
+    if n == 0 {
+        return
+    }
+    s.mu.Lock()
+    s.resumed = n
+    s.mu.Unlock()
+    s.bar.Add(n)
+}
+
+// skip counts n credential pairs that were not tried, e.g. for a locked-out user
+func (s *runStats) skip(n int) {
+    s.mu.Lock()
+    s.skipped += n
+    s.mu.Unlock()
+    s.bar.Add(n)
+}
+
+// success counts one successful login
+func (s *runStats) success() {
+    s.mu.Lock()
+    s.successes++
+    s.mu.Unlock()
+}
+
+// finished returns the number of credential pairs finished so far, tried or skipped
+func (s *runStats) finished() int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.attempts + s.skipped
+}
+
+// progressEvent describes the run's progress for the engine's EventProgress handlers
+func (s *runStats) progressEvent() Event {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return Event{Type: EventProgress, Attempts: s.attempts + s.skipped, Total: s.total, Successes: s.successes}
+}
+
+// rate returns the attempts per second over the last minute (or since the start if shorter)
+func (s *runStats) rate() float64 {
+    now := time.Now().Unix()
+    count := 0
+    for i := range s.buckets {
+        if now-s.bucketSecs[i] < statusWindow {
+            count += s.buckets[i]
+        }
+    }
+    window := time.Since(s.started).Seconds()
+    if window > statusWindow {
+        window = statusWindow
+    }
+    if window < 1 {
+        window = 1
+    }
+    return float64(count) / window
+}
+
+// line formats the status line, e.g.
+// "user 3/10 pass 120/5000 | 42.1/s | 1 found | 2 errors | ETA 14:05:31 (1h2m)"
+func (s *runStats) line() string {
+    return s.render(true)
+}
+
+// plainLine formats the status line without colors, for systemd's status text
+func (s *runStats) plainLine() string {
+    return s.render(false)
+}
+
+// render formats the status line, highlighting successes when colored is set
+func (s *runStats) render(colored bool) string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    rate := s.rate()
+    eta := "ETA --"
+    if remaining := s.total - s.resumed - s.attempts - s.skipped; s.total >= 0 && rate > 0 && remaining > 0 {
+        left := time.Duration(float64(remaining)/rate) * time.Second
+        eta = fmt.Sprintf("ETA %s (%s)", time.Now().Add(left).Format("15:04:05"), left.Round(time.Second))
+    }
+
+    found := fmt.Sprintf("%d found", s.successes)
+    if s.successes > 0 && colored {
+        found = s.theme.success.Sprint(found)
+    }
+    errs := fmt.Sprintf("%d errors", s.errors)
+    if s.errors > 0 && colored {
+        errs = s.theme.warning.Sprint(errs)
+    }
+
+    position := ""
+    if s.userCount > 0 {
+        position = fmt.Sprintf("user %d/%d pass %d/%d | ", s.userIdx, s.userCount, s.passIdx, s.passCount)
+    }
+    return fmt.Sprintf("%s%.1f/s | %s | %s | %s", position, rate, found, errs, eta)
+}
+
+// summary describes the finished run in one line, including how far a run stopped early got
+func (s *runStats) summary() string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    tested := translate(s.lang, "stats.tested", s.attempts)
+    if s.total >= 0 {
+        tested = translate(s.lang, "stats.tested_of", s.attempts, s.total)
+    }
+    if s.resumed > 0 {
+        tested += translate(s.lang, "stats.resumed", s.resumed)
+    }
+    if s.skipped > 0 {
+        tested += translate(s.lang, "stats.skipped", s.skipped)
+    }
+    elapsed := time.Since(s.started)
+    return translate(s.lang, "stats.summary", tested, elapsed.Round(time.Second),
+        float64(s.attempts)/max(elapsed.Seconds(), 1), s.successes, s.errors)
+}
+
+// startStatusLine refreshes the progress bar description with the live stats every second
+func startStatusLine(ctx context.Context, stats *runStats) {
+    go func() {
+        ticker := time.NewTicker(time.Second)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+                stats.bar.Describe(stats.line())
+            }
+        }
+    }()
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strings"
+    "time"
+)
+
+// summaryDetailsFile receives the JSON records of a --summary-only run when no log file is set
+const summaryDetailsFile = "sqlblaster-details.jsonl"
+
+// setupSummaryOnly keeps stdout for the end-of-run summary. Progress bars, results and messages
+// are discarded, errors still reach stderr, and the log file receives the JSON records that
+// describe every attempt, finding and dump instead of text lines.
+func (e *Engine) setupSummaryOnly(logFile **os.File) {
+    e.summaryOut = os.Stdout
+    e.out = io.Discard
+
+    e.jsonOutput = true
+    e.jsonOut = *logFile
+    *logFile = nil
+}
+
+// summaryArtifacts lists the files the run wrote its details to
+func (e *Engine) summaryArtifacts() [][2]string {
+    var artifacts [][2]string
+    add := func(label, path string) {
+        if path != "" {
+            artifacts = append(artifacts, [2]string{label, path})
+        }
+    }
+    add(e.tr("summary.details"), e.opts.LogFile)
+    add(e.tr("summary.results_db"), e.opts.ResultsDB)
+    add("SARIF", e.opts.SARIFFile)
+    add("JUnit", e.opts.JUnitFile)
+    add(e.tr("summary.inventory"), e.opts.InventoryFile)
+    if e.opts.Dump {
+        add(e.tr("summary.dump"), e.opts.DumpDir)
+    }
+    e.spoolMu.Lock()
+    for _, path := range e.spoolFiles {
+        add(e.tr("summary.spooled"), path)
+    }
+    e.spoolMu.Unlock()
+    return artifacts
+}
+
+// printRunSummary writes the --summary-only table to the real stdout: attempts and successes
+// per target, the totals, the run's duration and where the details are
+func (e *Engine) printRunSummary(startTime time.Time) {
+    if e.summaryOut == nil {
+        return
+    }
+
+    attempts := e.getTargetAttempts()
+    found := make(map[string]int)
+    for _, r := range e.getSuccesses() {
+        found[r.hostKey()]++
+        if _, ok := attempts[r.hostKey()]; !ok {
+            attempts[r.hostKey()] = 0
+        }
+    }
+    targets := make([]string, 0, len(attempts))
+    totalAttempts, totalFound := 0, 0
+    for target, n := range attempts {
+        targets = append(targets, target)
+        totalAttempts += n
+        totalFound += found[target]
+    }
+    sort.Strings(targets)
+
+    total := e.tr("summary.total", len(targets))
+    width := len(e.tr("summary.target"))
+    for _, target := range append([]string{total}, targets...) {
+        width = max(width, len(target))
+    }
+
+    var b strings.Builder
+    fmt.Fprintf(&b, "%-*s  %10s  %10s\n", width, e.tr("summary.target"), e.tr("summary.attempts"), e.tr("summary.successes"))
+    for _, target := range targets {
+        fmt.Fprintf(&b, "%-*s  %10d  %10d\n", width, target, attempts[target], found[target])
+    }
+    if len(targets) > 1 {
+        fmt.Fprintf(&b, "%-*s  %10d  %10d\n", width, total, totalAttempts, totalFound)
+    }
+    fmt.Fprintf(&b, "\n%s\n", e.tr("summary.duration", time.Since(startTime).Round(time.Second)))
+    for _, a := range e.summaryArtifacts() {
+        fmt.Fprintf(&b, "%s: %s\n", a[0], a[1])
+    }
+    fmt.Fprint(e.summaryOut, b.String())
+}
@@ -0,0 +1,308 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "golang.org/x/term"
+)
+
+// tableSampleRows is how many rows are read before the column widths are fixed; later rows are
+// streamed with the same widths
+const tableSampleRows = 1000
+
+// tableMinColumn is the narrowest a column is squeezed to when the table is wider than the terminal
+const tableMinColumn = 6
+
+// Box-drawing pieces of the table: cells are framed by "│ " and " │" and separated by " │ "
+const (
+    tableSeparator = " │ "
+    tableBorder    = 4
+)
+
+// updateTermWidth reads the current width and height of the terminal the run writes to
+func (e *Engine) updateTermWidth() {
+    width, height := 0, 0
+    if f, ok := e.out.(*os.File); ok {
+        var err error
+        if width, height, err = term.GetSize(int(f.Fd())); err != nil || width <= 0 {
+            width, height = 0, 0
+        }
+    }
+    e.termWidth.Store(int32(width))
+    e.termHeight.Store(int32(height))
+}
+
+// toggleTableWrap switches between wrapping and truncating wide cells and describes the new mode
+func (e *Engine) toggleTableWrap() string {
+    if e.tableWrap.Load() {
+        e.tableWrap.Store(false)
+        return "Wide columns are now truncated"
+    }
+    e.tableWrap.Store(true)
+    return "Wide columns now wrap onto extra lines"
+}
+
+// cellText renders a scanned value for display, keeping each cell on one line
+func cellText(val interface{}) string {
+    var s string
+    switch v := val.(type) {
+    case nil:
+        return "NULL"
+    case []byte:
+        s = string(v)
+    default:
+        s = fmt.Sprintf("%v", v)
+    }
+
+    var sb strings.Builder
+    for _, r := range s {
+        switch {
+        case r == '\n':
+            sb.WriteString(`\n`)
+        case r == '\r':
+            sb.WriteString(`\r`)
+        case r == '\t':
+            sb.WriteString(`\t`)
+        case r == utf8.RuneError || !unicode.IsPrint(r):
+            // Binary data and control characters would corrupt the terminal
+            sb.WriteByte('.')
+        default:
+            sb.WriteRune(r)
+        }
+    }
+    return sb.String()
+}
+
+// fitColumns returns widths for columns whose natural widths are natural, capped at maxWidth
+// and then squeezing the widest ones until the table fits into width (no limit when either is 0)
+func fitColumns(natural []int, width, maxWidth int) []int {
+    widths := append([]int(nil), natural...)
+    if maxWidth > 0 {
+        for i, w := range widths {
+            widths[i] = min(w, max(maxWidth, tableMinColumn))
+        }
+    }
+    if width <= 0 || len(widths) == 0 {
+        return widths
+    }
+    available := width - tableBorder - utf8.RuneCountInString(tableSeparator)*(len(widths)-1)
+    total := func() int {
+        sum := 0
+        for _, w := range widths {
+            sum += w
+        }
+        return sum
+    }
+    for total() > available {
+        widest := 0
+        for i, w := range widths {
+            if w > widths[widest] {
+                widest = i
+            }
+        }
+        if widths[widest] <= tableMinColumn {
+            // Every column is as narrow as it goes; let the terminal wrap the rest
+            break
+        }
+        widths[widest]--
+    }
+    return widths
+}
+
+// padCell pads or cuts s to exactly width runes
+func padCell(s string, width int) string {
+    n := utf8.RuneCountInString(s)
+    if n <= width {
+        return s + strings.Repeat(" ", width-n)
+    }
+    runes := []rune(s)
+    if width <= 1 {
+        return string(runes[:width])
+    }
+    return string(runes[:width-1]) + "…"
+}
+
+// wrapCell splits s into lines of at most width runes
+func wrapCell(s string, width int) []string {
+    runes := []rune(s)
+    if width <= 0 || len(runes) <= width {
+        return []string{s}
+    }
+    var lines []string
+    for len(runes) > width {
+        lines = append(lines, string(runes[:width]))
+        runes = runes[width:]
+    }
+    return append(lines, string(runes))
+}
+
+// tableRule returns a horizontal line of the table, e.g. ├──────┼────┤
+func tableRule(left, middle, right string, widths []int) string {
+    segments := make([]string, len(widths))
+    for i, w := range widths {
+        segments[i] = strings.Repeat("─", w+2)
+    }
+    return left + strings.Join(segments, middle) + right + "\n"
+}
+
+// writeTableRow writes one row, truncating or wrapping cells to their column widths. Cells
+// flagged in nulls are SQL NULLs, shown in the theme's null color on a terminal.
+func (e *Engine) writeTableRow(out *spoolBuffer, cells []string, nulls []bool, widths []int) {
+    // Output that is not a terminal keeps rows longer than the sample in full unless
+    // --max-col-width asks for truncation
+    keepFull := e.termWidth.Load() == 0 && e.opts.MaxColWidth == 0
+    styled := e.termWidth.Load() != 0
+    writeLine := func(parts []string) {
+        for i := range parts {
+            if styled && nulls != nil && nulls[i] && parts[i] != "" {
+                parts[i] = e.theme.null.Sprint(parts[i])
+            }
+        }
+        out.WriteString("│ " + strings.Join(parts, tableSeparator) + " │\n")
+    }
+
+    if !e.tableWrap.Load() {
+        parts := make([]string, len(cells))
+        for i, c := range cells {
+            if keepFull && utf8.RuneCountInString(c) > widths[i] {
+                parts[i] = c
+                continue
+            }
+            parts[i] = padCell(c, widths[i])
+        }
+        writeLine(parts)
+        return
+    }
+
+    wrapped := make([][]string, len(cells))
+    height := 1
+    for i, c := range cells {
+        wrapped[i] = wrapCell(c, widths[i])
+        if len(wrapped[i]) > height {
+            height = len(wrapped[i])
+        }
+    }
+    for line := 0; line < height; line++ {
+        parts := make([]string, len(cells))
+        for i := range cells {
+            part := ""
+            if line < len(wrapped[i]) {
+                part = wrapped[i][line]
+            }
+            parts[i] = padCell(part, widths[i])
+        }
+        writeLine(parts)
+    }
+}
+
+// formatQueryResults formats query results as a box-drawn table that fits the terminal. Column
+// widths come from the first tableSampleRows rows and --max-col-width; wider cells are
+// truncated, or wrapped with \W.
+// guard, when set, is asked before each row is fetched and stops the fetch by returning false.
+func (e *Engine) formatQueryResults(rows *sql.Rows, guard func(fetched int) bool) string {
+    output := spoolBuffer{engine: e}
+    output.WriteString("Query Results:\n")
+
+    // Get column names
+    columns, err := rows.Columns()
+    if err != nil {
+        return fmt.Sprintf("Error fetching column info: %v", err)
+    }
+
+    // Create a slice of interface{} to store the row values
+    values := make([]interface{}, len(columns))
+    valuePtrs := make([]interface{}, len(columns))
+    for i := range values {
+        valuePtrs[i] = &values[i]
+    }
+    type tableRow struct {
+        cells []string
+        nulls []bool
+    }
+    scanRow := func() (tableRow, error) {
+        if err := rows.Scan(valuePtrs...); err != nil {
+            return tableRow{}, err
+        }
+        row := tableRow{cells: make([]string, len(values)), nulls: make([]bool, len(values))}
+        for i, val := range values {
+            row.cells[i] = cellText(val)
+            row.nulls[i] = val == nil
+        }
+        return row, nil
+    }
+
+    // Size the columns from the headers and a sample of the rows
+    natural := make([]int, len(columns))
+    for i, col := range columns {
+        natural[i] = utf8.RuneCountInString(col)
+    }
+    stopped := false
+    fetch := func(fetched int) bool {
+        if guard != nil && !guard(fetched) {
+            stopped = true
+            return false
+        }
+        return rows.Next()
+    }
+    var sample []tableRow
+    more := false
+    for fetch(len(sample)) {
+        row, err := scanRow()
+        if err != nil {
+            return fmt.Sprintf("Error scanning row: %v", err)
+        }
+        sample = append(sample, row)
+        for i, c := range row.cells {
+            if n := utf8.RuneCountInString(c); n > natural[i] {
+                natural[i] = n
+            }
+        }
+        if len(sample) == tableSampleRows {
+            more = true
+            break
+        }
+    }
+    widths := fitColumns(natural, int(e.termWidth.Load()), e.opts.MaxColWidth)
+
+    // Column headers between rules
+    output.WriteString(tableRule("┌", "┬", "┐", widths))
+    e.writeTableRow(&output, columns, nil, widths)
+    output.WriteString(tableRule("├", "┼", "┤", widths))
+
+    // Row data
+    rowCount := 0
+    for _, row := range sample {
+        e.writeTableRow(&output, row.cells, row.nulls, widths)
+        rowCount++
+    }
+    for more && fetch(rowCount) {
+        row, err := scanRow()
+        if err != nil {
+            return fmt.Sprintf("Error scanning row: %v", err)
+        }
+        e.writeTableRow(&output, row.cells, row.nulls, widths)
+        rowCount++
+    }
+    output.WriteString(tableRule("└", "┴", "┘", widths))
+
+    err = rows.Err()
+    switch {
+    case errors.Is(err, context.Canceled):
+        // Ctrl-C in interactive mode; keep the rows read so far
+        output.WriteString(fmt.Sprintf("\n%s Rows fetched: %d\n", e.tr("shell.interrupted"), rowCount))
+    case err != nil:
+        return fmt.Sprintf("Error iterating rows: %v", err)
+    case stopped:
+        output.WriteString(fmt.Sprintf("\nStopped after %d rows (--row-limit)\n", rowCount))
+    default:
+        output.WriteString(fmt.Sprintf("\nTotal rows: %d\n", rowCount))
+    }
+    return output.String()
+}
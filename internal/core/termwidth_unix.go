@@ -0,0 +1,29 @@
+//go:build !windows
+
+package core
+
+import (
+    "context"
+    "os"
+    "os/signal"
+    "syscall"
+)
+
+// watchTermWidth keeps the run's terminal width current by re-reading it whenever the terminal
+// is resized
+func (e *Engine) watchTermWidth(ctx context.Context) {
+    e.updateTermWidth()
+    resized := make(chan os.Signal, 1)
+    signal.Notify(resized, syscall.SIGWINCH)
+    go func() {
+        defer signal.Stop(resized)
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-resized:
+                e.updateTermWidth()
+            }
+        }
+    }()
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package core
+
+import (
+    "context"
+    "time"
+)
+
+// watchTermWidth keeps the run's terminal width current by polling the console width, since
+// Windows has no resize signal
+func (e *Engine) watchTermWidth(ctx context.Context) {
+    e.updateTermWidth()
+    go func() {
+        ticker := time.NewTicker(time.Second)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+                e.updateTermWidth()
+            }
+        }
+    }()
+}
@@ -0,0 +1,266 @@
+package core
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strings"
+
+    "github.com/fatih/color"
+)
+
+// Theme holds the colors used for each kind of user-facing message
+type Theme struct {
+    Success []color.Attribute
+    Warning []color.Attribute
+    Error   []color.Attribute
+    Info    []color.Attribute
+    Prompt  []color.Attribute
+    Heading []color.Attribute
+    Null    []color.Attribute
+}
+
+// themes lists the built-in presets selectable with --theme
+var themes = map[string]Theme{
+    "default": {
+        Success: []color.Attribute{color.FgGreen},
+        Warning: []color.Attribute{color.FgYellow},
+        Error:   []color.Attribute{color.FgRed},
+        Info:    []color.Attribute{color.FgBlue},
+        Prompt:  []color.Attribute{},
+        Heading: []color.Attribute{color.FgHiGreen, color.Bold},
+        Null:    []color.Attribute{color.FgHiBlack},
+    },
+    // Bright, bold colors with underlined errors for low-contrast terminals and projectors
+    "high-contrast": {
+        Success: []color.Attribute{color.FgHiGreen, color.Bold},
+        Warning: []color.Attribute{color.FgHiYellow, color.Bold},
+        Error:   []color.Attribute{color.FgHiRed, color.Bold, color.Underline},
+        Info:    []color.Attribute{color.FgHiCyan, color.Bold},
+        Prompt:  []color.Attribute{color.FgHiWhite, color.Bold},
+        Heading: []color.Attribute{color.FgHiWhite, color.Bold, color.Underline},
+        Null:    []color.Attribute{color.FgHiMagenta},
+    },
+    // Blue/yellow/magenta stay distinguishable with red-green color blindness
+    "colorblind": {
+        Success: []color.Attribute{color.FgHiBlue, color.Bold},
+        Warning: []color.Attribute{color.FgHiYellow},
+        Error:   []color.Attribute{color.FgHiMagenta, color.Bold},
+        Info:    []color.Attribute{color.FgCyan},
+        Prompt:  []color.Attribute{color.FgHiWhite},
+        Heading: []color.Attribute{color.FgHiWhite, color.Bold},
+        Null:    []color.Attribute{color.FgCyan},
+    },
+    "none": {},
+}
+
+// colorNames maps config color names to terminal attributes
+var colorNames = map[string]color.Attribute{
+    "black":      color.FgBlack,
+    "red":        color.FgRed,
+    "green":      color.FgGreen,
+    "yellow":     color.FgYellow,
+    "blue":       color.FgBlue,
+    "magenta":    color.FgMagenta,
+    "cyan":       color.FgCyan,
+    "white":      color.FgWhite,
+    "hi-black":   color.FgHiBlack,
+    "hi-red":     color.FgHiRed,
+    "hi-green":   color.FgHiGreen,
+    "hi-yellow":  color.FgHiYellow,
+    "hi-blue":    color.FgHiBlue,
+    "hi-magenta": color.FgHiMagenta,
+    "hi-cyan":    color.FgHiCyan,
+    "hi-white":   color.FgHiWhite,
+    "bold":       color.Bold,
+    "underline":  color.Underline,
+}
+
+// themeColors are the colors a run prints each kind of message in
+type themeColors struct {
+    success *color.Color
+    warning *color.Color
+    error   *color.Color
+    info    *color.Color
+    prompt  *color.Color
+    heading *color.Color
+    null    *color.Color
+}
+
+// newThemeColors creates the colors of a theme
+func newThemeColors(theme Theme) themeColors {
+    return themeColors{
+        success: color.New(theme.Success...),
+        warning: color.New(theme.Warning...),
+        error:   color.New(theme.Error...),
+        info:    color.New(theme.Info...),
+        prompt:  color.New(theme.Prompt...),
+        heading: color.New(theme.Heading...),
+        null:    color.New(theme.Null...),
+    }
+}
+
+// disable prints the colors as plain text
+func (t themeColors) disable() {
+    for _, c := range []*color.Color{t.success, t.warning, t.error, t.info, t.prompt, t.heading, t.null} {
+        c.DisableColor()
+    }
+}
+
+// defaultColors are the default theme's colors, for the command-line code that prints before
+// or without an engine
+var defaultColors = newThemeColors(themes["default"])
+
+// parseColorSpec converts a spec like "hi-red+bold" into terminal attributes
+func parseColorSpec(spec string) ([]color.Attribute, error) {
+    var attrs []color.Attribute
+    for _, name := range strings.Split(spec, "+") {
+        name = strings.ToLower(strings.TrimSpace(name))
+        if name == "" || name == "plain" {
+            continue
+        }
+        attr, ok := colorNames[name]
+        if !ok {
+            return nil, fmt.Errorf("unknown color '%s'", name)
+        }
+        attrs = append(attrs, attr)
+    }
+    return attrs, nil
+}
+
+// availableThemes returns the sorted names of the built-in presets
+func availableThemes() []string {
+    names := make([]string, 0, len(themes))
+    for name := range themes {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// applyTheme sets the run's colors from a preset and then the per-role overrides from the config
+// file
+func (e *Engine) applyTheme(name string, overrides map[string]string) error {
+    if name == "" {
+        name = "default"
+    }
+    theme, ok := themes[name]
+    if !ok {
+        return fmt.Errorf("unknown theme '%s' (available: %s)", name, strings.Join(availableThemes(), ", "))
+    }
+    roles := map[string]*[]color.Attribute{
+        "success": &theme.Success,
+        "warning": &theme.Warning,
+        "error":   &theme.Error,
+        "info":    &theme.Info,
+        "prompt":  &theme.Prompt,
+        "heading": &theme.Heading,
+        "null":    &theme.Null,
+    }
+    for role, spec := range overrides {
+        target, ok := roles[strings.ToLower(role)]
+        if !ok {
+            return fmt.Errorf("unknown theme color role '%s'", role)
+        }
+        attrs, err := parseColorSpec(spec)
+        if err != nil {
+            return err
+        }
+        *target = attrs
+    }
+
+    e.theme = newThemeColors(theme)
+    if name == "none" {
+        e.theme.disable()
+    }
+    return nil
+}
+
+// validateTheme checks a theme name and overrides without activating them
+func validateTheme(name string, overrides map[string]string) error {
+    if name != "" {
+        if _, ok := themes[name]; !ok {
+            return fmt.Errorf("unknown theme '%s' (available: %s)", name, strings.Join(availableThemes(), ", "))
+        }
+    }
+    for role, spec := range overrides {
+        switch strings.ToLower(role) {
+        case "success", "warning", "error", "info", "prompt", "heading", "null":
+        default:
+            return fmt.Errorf("unknown theme color role '%s'", role)
+        }
+        if _, err := parseColorSpec(spec); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// themedPrint prints a line in the given color, adding the newline like color.Red and friends
+func themedPrint(c *color.Color, format string, a ...interface{}) {
+    themedFprint(color.Output, c, format, a...)
+}
+
+// themedFprint prints a line in the given color to w
+func themedFprint(w io.Writer, c *color.Color, format string, a ...interface{}) {
+    if !strings.HasSuffix(format, "\n") {
+        format += "\n"
+    }
+    c.Fprintf(w, format, a...)
+}
+
+// The package-level print helpers use the default theme and serve code that runs before the
+// engine's theme is applied; everything after that prints through the engine's methods
+
+// printSuccess prints a success message in the default theme's success color
+func printSuccess(format string, a ...interface{}) { themedPrint(defaultColors.success, format, a...) }
+
+// printWarning prints a warning message in the default theme's warning color
+func printWarning(format string, a ...interface{}) { themedPrint(defaultColors.warning, format, a...) }
+
+// printError prints an error message in the default theme's error color
+func printError(format string, a ...interface{}) { themedPrint(defaultColors.error, format, a...) }
+
+// printInfo prints an informational message in the default theme's info color
+func printInfo(format string, a ...interface{}) { themedPrint(defaultColors.info, format, a...) }
+
+// printSuccess prints a success message of the run to its output
+func (e *Engine) printSuccess(format string, a ...interface{}) {
+    themedFprint(e.out, e.theme.success, format, a...)
+}
+
+// printWarning prints a warning message of the run to its output
+func (e *Engine) printWarning(format string, a ...interface{}) {
+    themedFprint(e.out, e.theme.warning, format, a...)
+}
+
+// printError prints an error message of the run to its output, or to stderr when --summary-only
+// keeps stdout for the summary
+func (e *Engine) printError(format string, a ...interface{}) {
+    w := e.out
+    if e.summaryOut != nil {
+        w = os.Stderr
+    }
+    themedFprint(w, e.theme.error, format, a...)
+}
+
+// printInfo prints an informational message of the run to its output
+func (e *Engine) printInfo(format string, a ...interface{}) {
+    themedFprint(e.out, e.theme.info, format, a...)
+}
+
+// successString formats a message in the run's success color
+func (e *Engine) successString(format string, a ...interface{}) string {
+    return e.theme.success.Sprintf(format, a...)
+}
+
+// warningString formats a message in the run's warning color
+func (e *Engine) warningString(format string, a ...interface{}) string {
+    return e.theme.warning.Sprintf(format, a...)
+}
+
+// errorString formats a message in the run's error color
+func (e *Engine) errorString(format string, a ...interface{}) string {
+    return e.theme.error.Sprintf(format, a...)
+}
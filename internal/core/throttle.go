@@ -0,0 +1,146 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "strings"
+    "time"
+)
+
+// throttlePollInterval is how often the server's connection counters are polled
+const throttlePollInterval = 2 * time.Second
+
+// startConnectionMonitor polls Threads_connected on the target and pauses new attempts while
+// it is above --max-conn-fraction of max_connections. It uses --monitor-creds when given,
+// otherwise the first credential found during the run.
+func (e *Engine) startConnectionMonitor(ctx context.Context) {
+    go func() {
+        var db *sql.DB
+        defer func() {
+            if db != nil {
+                db.Close()
+            }
+        }()
+
+        ticker := time.NewTicker(throttlePollInterval)
+        defer ticker.Stop()
+
+        for {
+            select {
+            case <-ctx.Done():
+                e.setThrottlePaused(false)
+                return
+            case <-ticker.C:
+            }
+
+            if db == nil {
+                user, pass, ok := e.monitorCredential()
+                if !ok {
+                    continue
+                }
+                e.verbosePrintln("Connection monitor using credential for user", user)
+                var err error
+                db, err = e.openDB(e.buildDSN(user, pass, e.opts.Host, e.opts.Port))
+                if err != nil {
+                    e.verbosePrintln("Connection monitor failed to open connection:", err)
+                    db = nil
+                    continue
+                }
+                db.SetMaxOpenConns(1)
+            }
+
+            threads, maxConns, maxUsed, err := pollConnectionCounters(ctx, db)
+            if err != nil {
+                e.verbosePrintln("Connection monitor failed to poll status:", err)
+                continue
+            }
+
+            limit := int(float64(maxConns) * e.opts.MaxConnFraction)
+            if threads >= limit {
+                if !e.isThrottlePaused() {
+                    e.printWarning("\nThrottling: Threads_connected %d >= %d (%.0f%% of max_connections %d, Max_used_connections %d), pausing new attempts",
+                        threads, limit, e.opts.MaxConnFraction*100, maxConns, maxUsed)
+                }
+                e.setThrottlePaused(true)
+            } else if e.isThrottlePaused() {
+                e.printWarning("\nThrottling: Threads_connected %d below %d, resuming attempts", threads, limit)
+                e.setThrottlePaused(false)
+            }
+        }
+    }()
+}
+
+// monitorCredential returns the credential the connection monitor should log in with
+func (e *Engine) monitorCredential() (string, string, bool) {
+    if e.opts.MonitorCreds != "" {
+        parts := strings.SplitN(e.opts.MonitorCreds, ":", 2)
+        if len(parts) == 2 {
+            return parts[0], parts[1], true
+        }
+        return parts[0], "", true
+    }
+    // Passwords --prove-only replaced with a commitment cannot log in
+    for _, r := range e.getSuccesses() {
+        if !isPasswordProof(r.Pass) {
+            return r.User, r.Pass, true
+        }
+    }
+    return "", "", false
+}
+
+// pollConnectionCounters reads Threads_connected, max_connections, and Max_used_connections
+func pollConnectionCounters(ctx context.Context, db *sql.DB) (threads, maxConns, maxUsed int, err error) {
+    pollCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+    defer cancel()
+
+    if err = db.QueryRowContext(pollCtx, "SELECT @@max_connections").Scan(&maxConns); err != nil {
+        return
+    }
+
+    rows, err := db.QueryContext(pollCtx, "SHOW GLOBAL STATUS WHERE Variable_name IN ('Threads_connected', 'Max_used_connections')")
+    if err != nil {
+        return
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var name string
+        var value int
+        if err = rows.Scan(&name, &value); err != nil {
+            return
+        }
+        switch strings.ToLower(name) {
+        case "threads_connected":
+            threads = value
+        case "max_used_connections":
+            maxUsed = value
+        }
+    }
+    err = rows.Err()
+    return
+}
+
+// waitForThrottle blocks while the connection monitor has paused new attempts
+func (e *Engine) waitForThrottle(ctx context.Context) {
+    for e.isThrottlePaused() {
+        select {
+        case <-ctx.Done():
+            return
+        case <-time.After(200 * time.Millisecond):
+        }
+    }
+}
+
+// isThrottlePaused reports whether new attempts are currently paused
+func (e *Engine) isThrottlePaused() bool {
+    e.throttleMu.Lock()
+    defer e.throttleMu.Unlock()
+    return e.throttlePaused
+}
+
+// setThrottlePaused pauses or resumes new attempts
+func (e *Engine) setThrottlePaused(paused bool) {
+    e.throttleMu.Lock()
+    e.throttlePaused = paused
+    e.throttleMu.Unlock()
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Default timeouts of an engine: the attempt timeout bounds a whole login attempt (dial, TLS and
+// authentication), the connect timeout the TCP dial, and the query timeout each statement after
+// login. Read and write timeouts, for each network I/O once connected, default to none.
+const (
+    defaultAttemptTimeout = 10 * time.Second
+    defaultConnectTimeout = 10 * time.Second
+    defaultQueryTimeout   = 30 * time.Second
+)
+
+// parseTimeout parses a duration such as "5s" or "500ms", treating a bare number as seconds
+func parseTimeout(s string) (time.Duration, error) {
+    s = strings.TrimSpace(s)
+    if secs, err := strconv.ParseFloat(s, 64); err == nil {
+        if secs < 0 {
+            return 0, fmt.Errorf("invalid timeout '%s': must not be negative", s)
+        }
+        return time.Duration(secs * float64(time.Second)), nil
+    }
+    d, err := time.ParseDuration(s)
+    if err != nil {
+        return 0, fmt.Errorf("invalid timeout '%s': use e.g. 5s or 500ms", s)
+    }
+    if d < 0 {
+        return 0, fmt.Errorf("invalid timeout '%s': must not be negative", s)
+    }
+    return d, nil
+}
+
+// setupTimeouts parses --attempt-timeout, --connect-timeout, --read-timeout, --write-timeout and --query-timeout
+func (e *Engine) setupTimeouts(attempt, connect, read, write, query string) error {
+    var err error
+    if attempt != "" {
+        if e.attemptTimeout, err = parseTimeout(attempt); err != nil {
+            return fmt.Errorf("--attempt-timeout: %v", err)
+        }
+        if e.attemptTimeout <= 0 {
+            return fmt.Errorf("--attempt-timeout must be greater than 0")
+        }
+    }
+    if connect != "" {
+        if e.connectTimeout, err = parseTimeout(connect); err != nil {
+            return fmt.Errorf("--connect-timeout: %v", err)
+        }
+        if e.connectTimeout <= 0 {
+            return fmt.Errorf("--connect-timeout must be greater than 0")
+        }
+    }
+    if read != "" {
+        if e.readTimeout, err = parseTimeout(read); err != nil {
+            return fmt.Errorf("--read-timeout: %v", err)
+        }
+    }
+    if write != "" {
+        if e.writeTimeout, err = parseTimeout(write); err != nil {
+            return fmt.Errorf("--write-timeout: %v", err)
+        }
+    }
+    if query != "" {
+        if e.queryTimeout, err = parseTimeout(query); err != nil {
+            return fmt.Errorf("--query-timeout: %v", err)
+        }
+    }
+    return nil
+}
+
+// withQueryTimeout bounds a post-login statement by --query-timeout
+func (e *Engine) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+    if e.queryTimeout <= 0 {
+        return context.WithCancel(ctx)
+    }
+    return context.WithTimeout(ctx, e.queryTimeout)
+}
+
+// timeoutDSNParams returns the driver's dial, read and write timeout parameters
+func (e *Engine) timeoutDSNParams() string {
+    params := "timeout=" + e.connectTimeout.String()
+    if e.readTimeout > 0 {
+        params += "&readTimeout=" + e.readTimeout.String()
+    }
+    if e.writeTimeout > 0 {
+        params += "&writeTimeout=" + e.writeTimeout.String()
+    }
+    return params
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "fmt"
+    "os"
+)
+
+// tlsSessionCacheSize is how many TLS sessions are kept for resumption, one per target address
+const tlsSessionCacheSize = 64
+
+// setupTLS builds the TLS config the engine's connections share when they need more than the
+// driver's built-in ones: a client session cache so repeated connections to a target resume the
+// session instead of doing a full handshake, and the --tls-cert/--tls-key/--tls-ca files for
+// mutual TLS and private CAs. openDB hands it to the driver, so it is never registered globally.
+func (e *Engine) setupTLS() error {
+    custom := e.opts.TLSCert != "" || e.opts.TLSCA != ""
+    if e.opts.SkipSSL || (e.opts.NoTLSResume && !custom) {
+        return nil
+    }
+
+    // ServerName is left empty so the driver fills it in from each DSN's host. A private CA is
+    // given to be verified against, with or without --use-ssl.
+    c := &tls.Config{InsecureSkipVerify: !e.opts.UseSSL && e.opts.TLSCA == ""}
+    if !e.opts.NoTLSResume {
+        c.ClientSessionCache = tls.NewLRUClientSessionCache(tlsSessionCacheSize)
+    }
+    if e.opts.TLSCA != "" {
+        pem, err := os.ReadFile(e.opts.TLSCA)
+        if err != nil {
+            return fmt.Errorf("reading --tls-ca: %v", err)
+        }
+        c.RootCAs = x509.NewCertPool()
+        if !c.RootCAs.AppendCertsFromPEM(pem) {
+            return fmt.Errorf("no PEM certificates in %s", e.opts.TLSCA)
+        }
+    }
+    if e.opts.TLSCert != "" {
+        cert, err := tls.LoadX509KeyPair(e.opts.TLSCert, e.opts.TLSKey)
+        if err != nil {
+            return fmt.Errorf("loading client certificate: %v", err)
+        }
+        c.Certificates = []tls.Certificate{cert}
+    }
+
+    e.tlsConfig = c
+    e.verbosePrintln("Using TLS config, session resumption:", !e.opts.NoTLSResume, "client certificate:", e.opts.TLSCert != "")
+    return nil
+}
+
+// checkTLSFiles validates the --tls-cert, --tls-key and --tls-ca combination
+func (e *Engine) checkTLSFiles(cert, key, ca string, skipSSL bool) error {
+    if (cert == "") != (key == "") {
+        return errors.New("--tls-cert and --tls-key must be given together")
+    }
+    if skipSSL && (cert != "" || ca != "") {
+        return errors.New("--tls-cert and --tls-ca cannot be combined with --skip-ssl")
+    }
+    for _, f := range []string{cert, key, ca} {
+        if f != "" && !e.fileExists(f) {
+            return fmt.Errorf("file '%s' not found", f)
+        }
+    }
+    return nil
+}
+
+// tlsConfigFor returns the TLS config for a raw connection to host, sharing the session cache
+// and client certificate
+func (e *Engine) tlsConfigFor(host string) *tls.Config {
+    if e.tlsConfig == nil {
+        return &tls.Config{ServerName: host, InsecureSkipVerify: !e.opts.UseSSL}
+    }
+    c := e.tlsConfig.Clone()
+    c.ServerName = host
+    return c
+}
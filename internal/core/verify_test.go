@@ -0,0 +1,49 @@
+package core
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// TestVerifyOnlyProveOnlyKeepsCommitment checks that --verify-only with --prove-only records a
+// commitment to each password that passes, never the password itself
+func TestVerifyOnlyProveOnlyKeepsCommitment(t *testing.T) {
+    server, err := newFakeServer(selftestVersion)
+    if err != nil {
+        t.Fatalf("starting fake server: %v", err)
+    }
+    defer server.close()
+    server.addUser("root", "toor")
+    server.reply(`^SELECT 1$`, &fakeResult{
+        columns: []fakeColumn{{name: "1", typ: fakeTypeVarchar}},
+        rows:    [][]interface{}{{"1"}},
+    })
+
+    credFile := filepath.Join(t.TempDir(), "creds.csv")
+    host, port := server.addr()[:len("127.0.0.1")], server.addr()[len("127.0.0.1:"):]
+    if err := os.WriteFile(credFile, []byte(host+","+port+",root,toor\n"), 0600); err != nil {
+        t.Fatal(err)
+    }
+
+    opts := DefaultOptions()
+    opts.Host = host
+    opts.SkipSSL = true
+    opts.ProveOnly = true
+    e, err := beginRun(opts, nil)
+    if err != nil {
+        t.Fatalf("setting up run: %v", err)
+    }
+    e.runVerifyOnly(context.Background(), credFile, "", nil)
+
+    successes := e.getSuccesses()
+    if len(successes) != 1 {
+        t.Fatalf("%d credential(s) passed, want 1", len(successes))
+    }
+    if pass := successes[0].Pass; !isPasswordProof(pass) {
+        t.Errorf("recorded password %q, want a commitment", pass)
+    } else if ok, _ := checkPasswordProof(pass, "toor"); !ok {
+        t.Errorf("commitment %s is not to the password", pass)
+    }
+}
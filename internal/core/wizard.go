@@ -0,0 +1,167 @@
+package core
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// askString prompts for a value, returning def when the answer is empty
+func askString(reader *bufio.Reader, question, def string) string {
+    if def != "" {
+        defaultColors.prompt.Printf("%s [%s]: ", question, def)
+    } else {
+        defaultColors.prompt.Printf("%s: ", question)
+    }
+    answer, _ := reader.ReadString('\n')
+    answer = strings.TrimSpace(answer)
+    if answer == "" {
+        return def
+    }
+    return answer
+}
+
+// askBool prompts for a yes/no answer
+func askBool(reader *bufio.Reader, question string, def bool) bool {
+    hint := "y/N"
+    if def {
+        hint = "Y/n"
+    }
+    for {
+        answer := strings.ToLower(askString(reader, fmt.Sprintf("%s (%s)", question, hint), ""))
+        switch answer {
+        case "":
+            return def
+        case "y", "yes":
+            return true
+        case "n", "no":
+            return false
+        }
+        printWarning("Please answer 'y' or 'n'.")
+    }
+}
+
+// askInt prompts for a whole number
+func askInt(reader *bufio.Reader, question string, def int) int {
+    for {
+        answer := askString(reader, question, strconv.Itoa(def))
+        n, err := strconv.Atoi(answer)
+        if err == nil {
+            return n
+        }
+        printWarning("Please enter a number.")
+    }
+}
+
+// askFloat prompts for a decimal number
+func askFloat(reader *bufio.Reader, question string, def float64) float64 {
+    for {
+        answer := askString(reader, question, strconv.FormatFloat(def, 'f', -1, 64))
+        f, err := strconv.ParseFloat(answer, 64)
+        if err == nil {
+            return f
+        }
+        printWarning("Please enter a number.")
+    }
+}
+
+// askFile prompts for a path and warns when the file does not exist yet
+func (e *Engine) askFile(reader *bufio.Reader, question, def string) string {
+    path := askString(reader, question, def)
+    if path != "" && !e.fileExists(path) {
+        e.printWarning("Note: '%s' does not exist yet.", path)
+    }
+    return path
+}
+
+// runInitWizard implements the 'init' subcommand, interactively building a config file
+func (e *Engine) runInitWizard(args []string) {
+    reader := bufio.NewReader(os.Stdin)
+    newCfg := defaultOptions()
+
+    fmt.Fprintln(e.out, "This wizard writes a config file for use with --config. Press Enter to accept defaults.")
+
+    e.theme.heading.Fprintln(e.out, "\nTarget")
+    newCfg.Host = askString(reader, "MySQL server address", "")
+    newCfg.Port = askInt(reader, "Port", newCfg.Port)
+    if askBool(reader, "Require verified SSL/TLS", false) {
+        newCfg.UseSSL = true
+    } else if askBool(reader, "Disable SSL/TLS entirely", false) {
+        newCfg.SkipSSL = true
+    }
+
+    e.theme.heading.Fprintln(e.out, "\nCredentials")
+    if askBool(reader, "Test a username list (instead of a single user)", true) {
+        newCfg.UserList = e.askFile(reader, "Username file", "users.txt")
+    } else {
+        newCfg.SingleUser = askString(reader, "Username", "root")
+    }
+    if askBool(reader, "Test a password list (instead of a single password)", true) {
+        newCfg.PassList = e.askFile(reader, "Password file", "passwords.txt")
+    } else {
+        newCfg.SinglePass = askString(reader, "Password (empty for none)", "")
+    }
+    newCfg.UserFirst = askBool(reader, "Try all passwords for one user before moving on", false)
+    newCfg.FirstOnly = askBool(reader, "Stop at the first successful login", false)
+
+    e.theme.heading.Fprintln(e.out, "\nLoad limits")
+    newCfg.Workers = askInt(reader, "Concurrent workers", newCfg.Workers)
+    newCfg.MaxConnFraction = askFloat(reader, "Max fraction of server max_connections to use (0 to disable)", 0)
+    newCfg.Sources = askString(reader, "Source IPs or socks5:// proxies for failover (comma-separated)", "")
+
+    e.theme.heading.Fprintln(e.out, "\nOn success")
+    newCfg.ExecCmd = sanitizeCommand(askString(reader, "Command to execute", newCfg.ExecCmd))
+    newCfg.Enum = askBool(reader, "Enumerate privileges, databases, and tables", false)
+    if newCfg.Enum {
+        newCfg.EnumOutputFile = askString(reader, "Enumeration output file", "enum_results.txt")
+    }
+
+    e.theme.heading.Fprintln(e.out, "\nOutput")
+    newCfg.Verbose = askBool(reader, "Verbose output", false)
+    newCfg.LogFile = askString(reader, "Log file (empty for none)", "results.log")
+    newCfg.ResultsDB = askString(reader, "Results database (empty for none)", "results.sqlite")
+    newCfg.SARIFFile = askString(reader, "SARIF export file (empty for none)", "")
+    newCfg.JUnitFile = askString(reader, "JUnit report file (empty for none)", "")
+
+    filename := "config.json"
+    if len(args) > 0 {
+        filename = args[0]
+    }
+    filename = askString(reader, "\nWrite config to", filename)
+    if e.fileExists(filename) && !askBool(reader, fmt.Sprintf("'%s' exists, overwrite", filename), false) {
+        fmt.Fprintln(e.out, "Aborted, nothing written.")
+        return
+    }
+
+    file, err := os.Create(filename)
+    if err != nil {
+        e.printError("Error creating config file: %v", err)
+        os.Exit(1)
+    }
+    encoder := json.NewEncoder(file)
+    encoder.SetIndent("", "  ")
+    if err := encoder.Encode(newCfg); err != nil {
+        file.Close()
+        e.printError("Error encoding config file: %v", err)
+        os.Exit(1)
+    }
+    file.Close()
+
+    // Validate what was written so mistakes surface now rather than mid-run
+    fmt.Fprintln(e.out)
+    errs, warnings := e.validateConfigFile(filename)
+    for _, w := range warnings {
+        e.printWarning("Warning: %s", w)
+    }
+    for _, msg := range errs {
+        e.printError("Error: %s", msg)
+    }
+    if len(errs) > 0 {
+        e.printError("Config written to %s but has errors, edit it before use.", filename)
+        os.Exit(1)
+    }
+    e.printSuccess("Config written to %s. Run it with: sqlblaster --config %s", filename, filename)
+}
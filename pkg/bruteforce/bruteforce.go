@@ -0,0 +1,78 @@
+// Package bruteforce tests MySQL and MariaDB credentials from Go programs, with the worker pool,
+// lockout detection, retries and rate limiting of the sqlblaster command.
+//
+// Credentials arrive on Run.Results as they log in, and Run.Wait tells a run that tested every
+// pair from one cut short by a host block or by the caller. Users that get locked out are
+// skipped for the rest of the run.
+package bruteforce
+
+import (
+    "context"
+    "io"
+    "time"
+
+    "github.com/xmarkinmtlx/sqlblaster/internal/core"
+)
+
+// Result is a credential that logged in
+type Result = core.Result
+
+// Run is a run in progress. Read its Results until the channel is closed, then call Wait to
+// learn whether every pair was tested.
+type Run = core.BruteforceRun
+
+// ErrHostBlocked is returned by Wait when the host refused further connections from this client
+// before every pair was tested
+var ErrHostBlocked = core.ErrHostBlocked
+
+// Options configure a run. Zero values take the command's defaults.
+type Options struct {
+    Host      string
+    Port      int // 3306 when 0
+    Users     []string
+    Passwords []string
+
+    Workers        int           // concurrent attempts, 10 when 0
+    FirstOnly      bool          // stop at the first credential that logs in
+    Rate           float64       // attempts per second across all workers, unlimited when 0
+    AttemptTimeout time.Duration // time limit for dial, TLS and authentication, 10s when 0
+    Retries        int           // retries of attempts that fail with network errors, 2 when 0
+    UseSSL         bool          // require TLS and verify the server's certificate
+    SkipSSL        bool          // connect without TLS
+
+    // Output receives the human-readable messages of the run; they are discarded when nil
+    Output io.Writer
+
+    // Progress, when set, is called after every attempt with the pairs done so far, the pairs
+    // in the run and the credentials found. It is called from the workers concurrently.
+    Progress func(attempts, total, successes int)
+}
+
+// Start tests every user with every password and sends each credential that logs in on the
+// run's Results, which is closed when the run is over. The caller must read Results until it
+// is closed, or cancel ctx.
+func Start(ctx context.Context, opts Options) (*Run, error) {
+    c := core.DefaultOptions()
+    c.Host = opts.Host
+    if opts.Port != 0 {
+        c.Port = opts.Port
+    }
+    if opts.Workers != 0 {
+        c.Workers = opts.Workers
+    }
+    if opts.AttemptTimeout != 0 {
+        c.AttemptTimeout = opts.AttemptTimeout.String()
+    }
+    if opts.Retries != 0 {
+        c.Retries = opts.Retries
+    }
+    c.FirstOnly = opts.FirstOnly
+    c.Rate = opts.Rate
+    c.UseSSL = opts.UseSSL
+    c.SkipSSL = opts.SkipSSL
+    var progress core.EventHandler
+    if opts.Progress != nil {
+        progress = func(ev core.Event) { opts.Progress(ev.Attempts, ev.Total, ev.Successes) }
+    }
+    return core.Bruteforce(ctx, c, opts.Users, opts.Passwords, opts.Output, progress)
+}
@@ -0,0 +1,58 @@
+// Package dump copies every database a MySQL or MariaDB account can read to local files, as
+// the sqlblaster command's --dump does.
+//
+// The system databases are skipped. Tables that cannot be read are listed in the summary Run
+// returns rather than failing the dump, so check it before trusting the files are complete.
+package dump
+
+import (
+    "context"
+    "io"
+
+    "github.com/xmarkinmtlx/sqlblaster/internal/core"
+)
+
+// Options configure a dump. Zero values take the command's defaults.
+type Options struct {
+    Host    string
+    Port    int // 3306 when 0
+    User    string
+    Pass    string
+    UseSSL  bool // require TLS and verify the server's certificate
+    SkipSSL bool // connect without TLS
+
+    Dir      string // output directory, mysql_dump when empty
+    Format   string // csv, sql, jsonl or parquet, csv when empty
+    Compress bool   // gzip the files, or compress Parquet columns with zstd
+    MaxRows  int    // rows per file before a new part is started, 10000 when 0
+    Include  string // comma-separated db.table globs or /regex/ to dump, all when empty
+    Exclude  string // comma-separated db.table globs or /regex/ to skip
+
+    // Output receives the human-readable messages of the run; they are discarded when nil
+    Output io.Writer
+}
+
+// Run logs in, dumps the databases and returns the dump's summary
+func Run(ctx context.Context, opts Options) (string, error) {
+    c := core.DefaultOptions()
+    c.Host = opts.Host
+    if opts.Port != 0 {
+        c.Port = opts.Port
+    }
+    c.SingleUser, c.SinglePass = opts.User, opts.Pass
+    c.UseSSL = opts.UseSSL
+    c.SkipSSL = opts.SkipSSL
+    if opts.Dir != "" {
+        c.DumpDir = opts.Dir
+    }
+    if opts.Format != "" {
+        c.DumpFormat = opts.Format
+    }
+    if opts.MaxRows != 0 {
+        c.MaxRowsPerFile = opts.MaxRows
+    }
+    c.DumpCompress = opts.Compress
+    c.DumpInclude, c.DumpExclude = opts.Include, opts.Exclude
+    c.QuietDump = true
+    return core.Dump(ctx, c, opts.Output)
+}
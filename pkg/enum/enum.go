@@ -0,0 +1,39 @@
+// Package enum reports what a MySQL or MariaDB account can see and do, as the sqlblaster
+// command's -Enum does: server version, users and privileges, databases and tables.
+//
+// The report is the text the command prints. Run fails only when the login does; a query the
+// account may not run is noted in the report and the enumeration goes on.
+package enum
+
+import (
+    "context"
+    "io"
+
+    "github.com/xmarkinmtlx/sqlblaster/internal/core"
+)
+
+// Options configure an enumeration. Zero values take the command's defaults.
+type Options struct {
+    Host    string
+    Port    int // 3306 when 0
+    User    string
+    Pass    string
+    UseSSL  bool // require TLS and verify the server's certificate
+    SkipSSL bool // connect without TLS
+
+    // Output receives the human-readable messages of the run; they are discarded when nil
+    Output io.Writer
+}
+
+// Run logs in and returns the enumeration report
+func Run(ctx context.Context, opts Options) (string, error) {
+    c := core.DefaultOptions()
+    c.Host = opts.Host
+    if opts.Port != 0 {
+        c.Port = opts.Port
+    }
+    c.SingleUser, c.SinglePass = opts.User, opts.Pass
+    c.UseSSL = opts.UseSSL
+    c.SkipSSL = opts.SkipSSL
+    return core.Enumerate(ctx, c, opts.Output)
+}
@@ -0,0 +1,45 @@
+// Package shell opens the sqlblaster interactive SQL shell, with its pentest command
+// library and safety checks.
+//
+// The shell reads the process's terminal, or Options.Input to drive it from a program or a
+// script. Statements it considers dangerous, such as DROP, and the file transfer commands are
+// refused unless Options.AllowDangerous is set.
+package shell
+
+import (
+    "context"
+    "io"
+
+    "github.com/xmarkinmtlx/sqlblaster/internal/core"
+)
+
+// Options configure a shell session. Zero values take the command's defaults.
+type Options struct {
+    Host           string
+    Port           int // 3306 when 0
+    User           string
+    Pass           string
+    UseSSL         bool // require TLS and verify the server's certificate
+    SkipSSL        bool // connect without TLS
+    AllowDangerous bool // run statements the shell would otherwise refuse, such as DROP
+
+    // Input supplies the lines typed at the prompt. When nil the shell reads the process's
+    // terminal, with line editing and a saved history.
+    Input io.Reader
+    // Output receives the prompts and results; standard output when nil
+    Output io.Writer
+}
+
+// Run logs in and runs the shell until the user leaves it or Input ends
+func Run(ctx context.Context, opts Options) error {
+    c := core.DefaultOptions()
+    c.Host = opts.Host
+    if opts.Port != 0 {
+        c.Port = opts.Port
+    }
+    c.SingleUser, c.SinglePass = opts.User, opts.Pass
+    c.UseSSL = opts.UseSSL
+    c.SkipSSL = opts.SkipSSL
+    c.AllowDangerous = opts.AllowDangerous
+    return core.Shell(ctx, c, opts.Input, opts.Output)
+}
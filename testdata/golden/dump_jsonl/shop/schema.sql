@@ -0,0 +1,16 @@
+CREATE TABLE `odd_cases` (
+  `id` int NOT NULL,
+  `name` varchar(64) DEFAULT NULL,
+  `note` text,
+  `payload` blob,
+  `price` decimal(10,2) DEFAULT NULL,
+  `big` bigint unsigned DEFAULT NULL,
+  `ratio` double DEFAULT NULL,
+  `created` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `empty_table` (
+  `id` int NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
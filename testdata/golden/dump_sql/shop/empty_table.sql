@@ -0,0 +1,13 @@
+-- sqlblaster dump of shop.empty_table from fake.test:3306 (part 1)
+SET NAMES utf8mb4;
+SET FOREIGN_KEY_CHECKS=0;
+CREATE DATABASE IF NOT EXISTS `shop`;
+USE `shop`;
+
+DROP TABLE IF EXISTS `empty_table`;
+CREATE TABLE `empty_table` (
+  `id` int NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+
+SET FOREIGN_KEY_CHECKS=1;
@@ -0,0 +1,11 @@
+-- sqlblaster dump of shop.odd_cases from fake.test:3306 (part 2)
+SET NAMES utf8mb4;
+SET FOREIGN_KEY_CHECKS=0;
+CREATE DATABASE IF NOT EXISTS `shop`;
+USE `shop`;
+
+INSERT INTO `odd_cases` (`id`,`name`,`note`,`payload`,`price`,`big`,`ratio`,`created`) VALUES
+(4,'','',0x0d0a2c3b2722,-1.50,42,3.141592653589793,NULL),
+(5,'NULL','\\N',0x4e554c4c,100.00,1,0,'2038-01-19 03:14:07');
+
+SET FOREIGN_KEY_CHECKS=1;
@@ -0,0 +1,25 @@
+-- sqlblaster dump of shop.odd_cases from fake.test:3306 (part 1)
+SET NAMES utf8mb4;
+SET FOREIGN_KEY_CHECKS=0;
+CREATE DATABASE IF NOT EXISTS `shop`;
+USE `shop`;
+
+DROP TABLE IF EXISTS `odd_cases`;
+CREATE TABLE `odd_cases` (
+  `id` int NOT NULL,
+  `name` varchar(64) DEFAULT NULL,
+  `note` text,
+  `payload` blob,
+  `price` decimal(10,2) DEFAULT NULL,
+  `big` bigint unsigned DEFAULT NULL,
+  `ratio` double DEFAULT NULL,
+  `created` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO `odd_cases` (`id`,`name`,`note`,`payload`,`price`,`big`,`ratio`,`created`) VALUES
+(1,'plain','simple note',0x00017fff1a,9.99,18446744073709551615,0.5,'2024-01-02 03:04:05'),
+(2,'Zoë 🚀 日本語','line1\nline2\r\n	\"double\" \'single\' back\\slash, comma; semi','',0.00,0,-1e-10,'1970-01-01 00:00:00'),
+(3,NULL,NULL,NULL,NULL,NULL,NULL,NULL);
+
+SET FOREIGN_KEY_CHECKS=1;